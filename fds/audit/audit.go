@@ -0,0 +1,84 @@
+// Package audit keeps the hash-chained audit log. The monitor and fds-api both append to
+// it, so the chain is computed here only.
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"token-monitor/models"
+
+	"gorm.io/gorm"
+)
+
+// lockKey is the advisory lock serialising appends to the audit log
+const lockKey = 7410038
+
+// GenesisHash is the previous hash of the first audit entry
+var GenesisHash = hex.EncodeToString(make([]byte, sha256.Size))
+
+// Hash returns the hash of an audit entry over its content and the previous hash
+func Hash(entry *models.AuditLog) string {
+	content, _ := json.Marshal(struct {
+		Seq        uint64 `json:"seq"`
+		CreatedAt  string `json:"created_at"`
+		RequestID  string `json:"request_id"`
+		Actor      string `json:"actor"`
+		Role       string `json:"role"`
+		Action     string `json:"action"`
+		Method     string `json:"method"`
+		Path       string `json:"path"`
+		Parameters string `json:"parameters"`
+		Reason     string `json:"reason"`
+		TxHash     string `json:"tx_hash"`
+		Result     string `json:"result"`
+		Status     int    `json:"status"`
+		Error      string `json:"error"`
+		PrevHash   string `json:"prev_hash"`
+	}{
+		entry.Seq, entry.CreatedAt.UTC().Format(time.RFC3339Nano), entry.RequestID, entry.Actor, entry.Role,
+		entry.Action, entry.Method, entry.Path, entry.Parameters, entry.Reason, entry.TxHash,
+		entry.Result, entry.Status, entry.Error, entry.PrevHash,
+	})
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// Chain links entry to prev, the last entry of the log or nil if there is none, and
+// seals it with its hash
+func Chain(prev, entry *models.AuditLog) {
+	entry.Seq = 1
+	entry.PrevHash = GenesisHash
+	if prev != nil {
+		entry.Seq = prev.Seq + 1
+		entry.PrevHash = prev.Hash
+	}
+	// Postgres keeps microseconds; the hash must match the stored timestamp
+	entry.CreatedAt = entry.CreatedAt.UTC().Truncate(time.Microsecond)
+	entry.Hash = Hash(entry)
+}
+
+// Append appends an entry to the audit log, stamped with the current time unless it has
+// a creation time
+func Append(db *gorm.DB, entry *models.AuditLog) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", lockKey).Error; err != nil {
+			return err
+		}
+		var last []models.AuditLog
+		if err := tx.Order("seq DESC").Limit(1).Find(&last).Error; err != nil {
+			return err
+		}
+		var prev *models.AuditLog
+		if len(last) > 0 {
+			prev = &last[0]
+		}
+		if entry.CreatedAt.IsZero() {
+			entry.CreatedAt = time.Now()
+		}
+		Chain(prev, entry)
+		return tx.Create(entry).Error
+	})
+}
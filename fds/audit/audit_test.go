@@ -0,0 +1,42 @@
+package audit
+
+import (
+	"testing"
+	"time"
+
+	"token-monitor/models"
+)
+
+func TestChain(t *testing.T) {
+	created := time.Date(2026, 3, 1, 9, 30, 0, 123456789, time.FixedZone("UTC+7", 7*3600))
+	first := &models.AuditLog{CreatedAt: created, Actor: "alice", Action: "blacklist", Result: "started"}
+	Chain(nil, first)
+	if first.Seq != 1 || first.PrevHash != GenesisHash {
+		t.Fatalf("first entry: seq %d, prev %s", first.Seq, first.PrevHash)
+	}
+	if first.CreatedAt.Nanosecond() != 123456000 || first.CreatedAt.Location() != time.UTC {
+		t.Errorf("created_at must be truncated to microseconds in UTC, got %s", first.CreatedAt)
+	}
+
+	second := &models.AuditLog{CreatedAt: created, Actor: "alice", Action: "blacklist", Result: "succeeded", Status: 200}
+	Chain(first, second)
+	if second.Seq != 2 || second.PrevHash != first.Hash {
+		t.Fatalf("second entry: seq %d, prev %s", second.Seq, second.PrevHash)
+	}
+	if second.Hash == first.Hash {
+		t.Error("entries with different content must have different hashes")
+	}
+
+	// The hash does not depend on the time zone the timestamp is read back in
+	readBack := *second
+	readBack.CreatedAt = second.CreatedAt.Local()
+	if Hash(&readBack) != second.Hash {
+		t.Error("hash changed with the time zone")
+	}
+
+	tampered := *second
+	tampered.Actor = "mallory"
+	if Hash(&tampered) == second.Hash {
+		t.Error("changing the actor must change the hash")
+	}
+}
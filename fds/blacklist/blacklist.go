@@ -0,0 +1,181 @@
+// Package blacklist queues blacklist actions for the monitor's enforcement executor. It only
+// needs the database, so fds-api queues its manual blacklists through it as well.
+package blacklist
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"token-monitor/models"
+
+	"github.com/ethereum/go-ethereum/common"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Blacklist directions, matching blacklist, blacklistFrom and blacklistTo of AddressRestrictionCompliance
+const (
+	DirectionBoth = "both" // Restricted from sending and receiving
+	DirectionFrom = "from" // Restricted from sending
+	DirectionTo   = "to"   // Restricted from receiving
+)
+
+// Blacklist hold types, each with its own lifecycle
+const (
+	HoldAutomated      = "automated"       // Provisional freeze by detection; lifted on expiry unless confirmed
+	HoldManual         = "manual"          // Blacklisted by an officer; confirmed and periodically reviewed
+	HoldLawEnforcement = "law_enforcement" // Hold ordered by law enforcement; never lifted automatically
+)
+
+// HoldRank orders hold types by strength. Entries recorded before hold types were tracked
+// are manual blacklists.
+func HoldRank(holdType string) int {
+	switch holdType {
+	case HoldAutomated:
+		return 0
+	case HoldLawEnforcement:
+		return 2
+	}
+	return 1
+}
+
+// CoversDirection reports whether an entry restricted in existing already restricts requested
+func CoversDirection(existing, requested string) bool {
+	return existing == "" || existing == DirectionBoth || existing == requested
+}
+
+// ActionActor returns who requested an action, for the audit trail
+func ActionActor(action models.EnforcementAction) string {
+	if action.RequestedBy != "" {
+		return action.RequestedBy
+	}
+	return action.Source
+}
+
+// RecordStatusChange records a blacklist lifecycle transition
+func RecordStatusChange(db *gorm.DB, address, from, to, actor, reason string, actionID uint) error {
+	return db.Create(&models.BlacklistStatusChange{
+		Address:             address,
+		FromStatus:          from,
+		ToStatus:            to,
+		Actor:               actor,
+		Reason:              reason,
+		EnforcementActionID: actionID,
+	}).Error
+}
+
+// UpgradeHold raises the hold type of an entry when action requests a stronger hold,
+// confirming a provisional entry on the way
+func UpgradeHold(db *gorm.DB, existing *models.BlacklistedAddress, action models.EnforcementAction) error {
+	holdType := action.HoldType
+	if holdType == "" {
+		holdType = HoldAutomated
+	}
+	if HoldRank(holdType) <= HoldRank(existing.HoldType) {
+		return nil
+	}
+
+	updates := map[string]interface{}{
+		"hold_type":       holdType,
+		"legal_reference": action.LegalReference,
+	}
+	// Updates writes the new status back into existing
+	provisional := existing.Status == "provisional"
+	if provisional {
+		now := time.Now()
+		updates["status"] = "confirmed"
+		updates["expires_at"] = nil
+		updates["confirmed_by"] = ActionActor(action)
+		updates["confirmed_at"] = &now
+	}
+	if err := db.Model(existing).Updates(updates).Error; err != nil {
+		return err
+	}
+
+	if provisional {
+		return RecordStatusChange(db, existing.Address, "provisional", "confirmed", ActionActor(action),
+			fmt.Sprintf("Upgraded to %s hold: %s", holdType, action.Reason), action.ID)
+	}
+	return nil
+}
+
+// ActiveStatuses are the statuses of an action that is still to be approved, sent or mined
+var ActiveStatuses = []string{"awaiting_approval", "pending", "submitted"}
+
+// QueuedStatus returns the status a new action starts in: an action with RequiredApprovals
+// waits for approval before it is sent
+func QueuedStatus(action models.EnforcementAction) string {
+	if action.RequiredApprovals > 0 {
+		return "awaiting_approval"
+	}
+	return "pending"
+}
+
+// Enqueue queues a blacklist action for action.Address in action.Direction. Nothing is
+// queued when the address is already blacklisted in that direction or already has such a
+// blacklist in flight, which makes enqueueing idempotent per address; a stronger hold is
+// still applied to the existing entry. A pending expiry of the address is cancelled. An
+// action with RequiredApprovals waits for approval before it is sent. It reports whether a
+// new action was queued.
+func Enqueue(db *gorm.DB, action models.EnforcementAction) (bool, error) {
+	if !common.IsHexAddress(action.Address) {
+		return false, fmt.Errorf("invalid address %q", action.Address)
+	}
+	action.Address = common.HexToAddress(action.Address).Hex()
+	action.Action = "blacklist"
+	if action.Direction != DirectionFrom && action.Direction != DirectionTo {
+		action.Direction = DirectionBoth
+	}
+	action.Status = QueuedStatus(action)
+	action.NextAttemptAt = time.Now()
+
+	queued := false
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// Renewed suspicion keeps a provisional freeze in place
+		if err := tx.Model(&models.EnforcementAction{}).
+			Where("address = ? AND action = ? AND source = ? AND status = ?", action.Address, "unblacklist", "expiry", "pending").
+			Updates(map[string]interface{}{
+				"status":     "cancelled",
+				"last_error": "cancelled by a new blacklist request",
+			}).Error; err != nil {
+			return err
+		}
+
+		var existing models.BlacklistedAddress
+		if err := tx.Where("address = ?", action.Address).First(&existing).Error; err == nil {
+			if CoversDirection(existing.Direction, action.Direction) {
+				return UpgradeHold(tx, &existing, action)
+			}
+		} else if err != gorm.ErrRecordNotFound {
+			return err
+		}
+
+		var count int64
+		if err := tx.Model(&models.EnforcementAction{}).
+			Where("address = ? AND action = ? AND direction IN ? AND status IN ?",
+				action.Address, action.Action, []string{action.Direction, DirectionBoth}, ActiveStatuses).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return nil
+		}
+
+		// The partial unique index on active actions catches concurrent enqueues
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&action)
+		if result.Error != nil {
+			return result.Error
+		}
+		queued = result.RowsAffected > 0
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("error queueing blacklist for %s: %w", action.Address, err)
+	}
+
+	if queued {
+		log.Printf("Queued blacklist of %s, direction %s (source: %s, status: %s)", action.Address, action.Direction, action.Source, action.Status)
+	}
+	return queued, nil
+}
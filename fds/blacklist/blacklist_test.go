@@ -0,0 +1,121 @@
+package blacklist
+
+import (
+	"testing"
+	"time"
+
+	"token-monitor/models"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// testDB opens an in-memory database with the blacklist tables
+func testDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("opening test database: %v", err)
+	}
+	// Every connection to :memory: is a database of its own
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	if err := db.AutoMigrate(&models.EnforcementAction{}, &models.BlacklistedAddress{}, &models.BlacklistStatusChange{}); err != nil {
+		t.Fatalf("migrating test database: %v", err)
+	}
+	return db
+}
+
+func TestUpgradeHold(t *testing.T) {
+	db := testDB(t)
+
+	expires := time.Now().Add(time.Hour)
+	entry := models.BlacklistedAddress{Address: "0x722122dF12D4e14e13Ac3b6895a86e84145b6967", TxHash: "0x01", BlockNumber: 1,
+		Status: "provisional", HoldType: HoldAutomated, ExpiresAt: &expires}
+	db.Create(&entry)
+
+	// A weaker or equal hold leaves the entry alone
+	if err := UpgradeHold(db, &entry, models.EnforcementAction{Source: "blacklist_monitor"}); err != nil {
+		t.Fatalf("automated hold: %v", err)
+	}
+	if entry.Status != "provisional" {
+		t.Errorf("automated hold changed the entry to %s", entry.Status)
+	}
+
+	action := models.EnforcementAction{HoldType: HoldLawEnforcement, LegalReference: "Order 17", RequestedBy: "olga", Reason: "Court order"}
+	if err := UpgradeHold(db, &entry, action); err != nil {
+		t.Fatalf("law-enforcement hold: %v", err)
+	}
+	var stored models.BlacklistedAddress
+	db.First(&stored, entry.ID)
+	if stored.Status != "confirmed" || stored.HoldType != HoldLawEnforcement || stored.ExpiresAt != nil || stored.ConfirmedBy != "olga" {
+		t.Errorf("entry %s with %s hold, confirmed by %q, expiring %v", stored.Status, stored.HoldType, stored.ConfirmedBy, stored.ExpiresAt)
+	}
+	var change models.BlacklistStatusChange
+	if err := db.Where("address = ?", entry.Address).First(&change).Error; err != nil {
+		t.Fatalf("confirmation not recorded: %v", err)
+	}
+	if change.FromStatus != "provisional" || change.ToStatus != "confirmed" || change.Actor != "olga" {
+		t.Errorf("recorded %s -> %s by %q", change.FromStatus, change.ToStatus, change.Actor)
+	}
+}
+
+func TestEnqueue(t *testing.T) {
+	db := testDB(t)
+
+	const (
+		fresh  = "0x8589427373D6D84E98730D7795D8f6f8731FDA16"
+		frozen = "0x722122dF12D4e14e13Ac3b6895a86e84145b6967"
+	)
+	expires := time.Now().Add(time.Hour)
+	db.Create(&models.BlacklistedAddress{Address: frozen, TxHash: "0x01", BlockNumber: 1, Status: "provisional",
+		HoldType: HoldAutomated, ExpiresAt: &expires})
+	db.Create(&models.EnforcementAction{Address: frozen, Action: "unblacklist", Source: "expiry", Status: "pending"})
+
+	manual := func(address, direction string) models.EnforcementAction {
+		return models.EnforcementAction{Address: address, Direction: direction, Source: "api", Reason: "Manual blacklist",
+			HoldType: HoldManual, RequestedBy: "olga"}
+	}
+	cases := []struct {
+		name   string
+		action models.EnforcementAction
+		queued bool
+	}{
+		{"new address", manual(fresh, DirectionFrom), true},
+		{"already in flight", manual(fresh, DirectionFrom), false},
+		{"other direction", manual(fresh, DirectionTo), true},
+		{"in flight both ways", manual(fresh, ""), true},
+		{"blacklisted entry", manual(frozen, DirectionTo), false},
+	}
+	for _, tc := range cases {
+		queued, err := Enqueue(db, tc.action)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if queued != tc.queued {
+			t.Errorf("%s: queued %v, want %v", tc.name, queued, tc.queued)
+		}
+	}
+	if _, err := Enqueue(db, manual("0x1234", DirectionBoth)); err == nil {
+		t.Errorf("invalid address queued")
+	}
+
+	// The stronger hold confirmed the provisional freeze and cancelled its expiry
+	var entry models.BlacklistedAddress
+	db.Where("address = ?", frozen).First(&entry)
+	if entry.Status != "confirmed" || entry.HoldType != HoldManual || entry.ExpiresAt != nil || entry.ConfirmedBy != "olga" {
+		t.Errorf("entry %s with %s hold, confirmed by %q, expiring %v", entry.Status, entry.HoldType, entry.ConfirmedBy, entry.ExpiresAt)
+	}
+	var expiry models.EnforcementAction
+	db.Where("address = ? AND action = ?", frozen, "unblacklist").First(&expiry)
+	if expiry.Status != "cancelled" {
+		t.Errorf("expiry %s, want cancelled", expiry.Status)
+	}
+	var changes int64
+	db.Model(&models.BlacklistStatusChange{}).Where("address = ? AND to_status = ?", frozen, "confirmed").Count(&changes)
+	if changes != 1 {
+		t.Errorf("%d status changes recorded, want 1", changes)
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"token-monitor/config"
+	"token-monitor/services"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Replays stored transactions through the rules with candidate parameters and compares the
+// hits with the current parameters, e.g.
+//
+//	go run cmd/backtest/main.go -since 2024-05-01 -until 2024-05-31 -set large_transfer.threshold=5000
+func main() {
+	fromBlock := flag.Int64("from-block", -1, "First block to replay")
+	toBlock := flag.Int64("to-block", -1, "Last block to replay")
+	since := flag.String("since", "", "Replay transactions from this RFC 3339 time or YYYY-MM-DD date")
+	until := flag.String("until", "", "Replay transactions before this RFC 3339 time, or up to and including this YYYY-MM-DD date")
+	asJSON := flag.Bool("json", false, "Print the report as JSON")
+	parameters := make(map[string]map[string]interface{})
+	flag.Func("set", "Candidate parameter as rule.parameter=value; repeatable", func(value string) error {
+		key, raw, ok := strings.Cut(value, "=")
+		rule, param, dotted := strings.Cut(key, ".")
+		if !ok || !dotted || rule == "" || param == "" {
+			return fmt.Errorf("want rule.parameter=value")
+		}
+		var parsed interface{}
+		if err := json.Unmarshal([]byte(raw), &parsed); err != nil {
+			parsed = raw // Not JSON: a plain string
+		}
+		if parameters[rule] == nil {
+			parameters[rule] = make(map[string]interface{})
+		}
+		parameters[rule][param] = parsed
+		return nil
+	})
+	flag.Parse()
+
+	req := services.BacktestRequest{Parameters: parameters}
+	if *fromBlock >= 0 {
+		block := uint64(*fromBlock)
+		req.FromBlock = &block
+	}
+	if *toBlock >= 0 {
+		block := uint64(*toBlock)
+		req.ToBlock = &block
+	}
+	var err error
+	if req.Since, err = parseTime(*since, false); err != nil {
+		log.Fatalf("Invalid -since: %v", err)
+	}
+	if req.Until, err = parseTime(*until, true); err != nil {
+		log.Fatalf("Invalid -until: %v", err)
+	}
+
+	// Load configuration
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	// Connect to database
+	db, err := gorm.Open(postgres.Open(cfg.Database.GetDSN()), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	report, err := services.RunBacktest(ctx, db, req)
+	if err != nil {
+		log.Fatalf("Backtest failed: %v", err)
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(report)
+		return
+	}
+	printReport(report)
+}
+
+// parseTime parses an RFC 3339 time or a YYYY-MM-DD date; an end date covers the whole day
+func parseTime(value string, end bool) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	day, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, fmt.Errorf("want an RFC 3339 time or a YYYY-MM-DD date")
+	}
+	if end {
+		day = day.AddDate(0, 0, 1)
+	}
+	return &day, nil
+}
+
+func printReport(report *services.BacktestReport) {
+	fmt.Printf("Replayed %d transactions of blocks %d-%d (%d whitelisted)\n", report.Transactions, report.FromBlock, report.ToBlock, report.Whitelisted)
+	fmt.Printf("Hits: %d now, %d with the candidate parameters\n\n", report.CurrentHits, report.CandidateHits)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "RULE\tNOW\tCANDIDATE\tADDED\tREMOVED\tADDRESSES\tNEW ADDRESSES\tCLEARED ADDRESSES")
+	for _, r := range report.Rules {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%d\t%d\t%d\n", r.Rule, r.CurrentHits, r.CandidateHits,
+			len(r.Added), len(r.Removed), len(r.Addresses), len(r.NewAddresses), len(r.ClearedAddresses))
+	}
+	w.Flush()
+
+	for _, r := range report.Rules {
+		if len(r.NewAddresses) == 0 && len(r.ClearedAddresses) == 0 {
+			continue
+		}
+		fmt.Printf("\n%s\n", r.Rule)
+		for _, address := range r.NewAddresses {
+			fmt.Printf("  + %s\n", address)
+		}
+		for _, address := range r.ClearedAddresses {
+			fmt.Printf("  - %s\n", address)
+		}
+	}
+	if report.Truncated {
+		fmt.Println("\nSome lists were capped at 1000 entries.")
+	}
+}
@@ -49,6 +49,39 @@ func main() {
 		log.Println("Dropping existing tables...")
 		// Drop tables in reverse order of dependencies
 		if err := db.Migrator().DropTable(
+			&models.WatchlistMatch{},
+			&models.WatchlistChange{},
+			&models.WatchlistEntry{},
+			&models.WatchlistVersion{},
+			&models.WatchlistSource{},
+			&models.WhitelistExemption{},
+			&models.WhitelistAddress{},
+			&models.AlertLabel{},
+			&models.RuleEvaluation{},
+			&models.RuleVersion{},
+			&models.ShadowViolation{},
+			&models.BacktestRun{},
+			&models.AlertSnooze{},
+			&models.AlertIncident{},
+			&models.NotificationDelivery{},
+			&models.StreamEvent{},
+			&models.AuditLog{},
+			&models.User{},
+			&models.CtrReport{},
+			&models.StrReport{},
+			&models.CaseEvent{},
+			&models.CaseAttachment{},
+			&models.CaseNote{},
+			&models.CaseViolation{},
+			&models.CaseTransfer{},
+			&models.CaseAddress{},
+			&models.Case{},
+			&models.ActionApproval{},
+			&models.BlacklistStatusChange{},
+			&models.BlacklistDrift{},
+			&models.RestrictionEvent{},
+			&models.EnforcementAction{},
+			&models.PreemptiveBlacklist{},
 			&models.SuspiciousTransferRelatedTx{},
 			&models.SuspiciousTransfer{},
 			&models.TokenTransfer{},
@@ -72,10 +105,92 @@ func main() {
 		&models.PendingTransaction{},
 		&models.BlacklistedAddress{},
 		&models.Rule{},
+		&models.PreemptiveBlacklist{},
+		&models.EnforcementAction{},
+		&models.RestrictionEvent{},
+		&models.BlacklistDrift{},
+		&models.BlacklistStatusChange{},
+		&models.ActionApproval{},
+		&models.Case{},
+		&models.CaseAddress{},
+		&models.CaseTransfer{},
+		&models.CaseViolation{},
+		&models.CaseNote{},
+		&models.CaseAttachment{},
+		&models.CaseEvent{},
+		&models.StrReport{},
+		&models.CtrReport{},
+		&models.User{},
+		&models.AuditLog{},
+		&models.StreamEvent{},
+		&models.NotificationDelivery{},
+		&models.AlertIncident{},
+		&models.AlertSnooze{},
+		&models.BacktestRun{},
+		&models.ShadowViolation{},
+		&models.RuleVersion{},
+		&models.RuleEvaluation{},
+		&models.AlertLabel{},
+		&models.WhitelistAddress{},
+		&models.WhitelistExemption{},
+		&models.WatchlistSource{},
+		&models.WatchlistVersion{},
+		&models.WatchlistEntry{},
+		&models.WatchlistChange{},
+		&models.WatchlistMatch{},
 	); err != nil {
 		log.Fatalf("Failed to migrate base tables: %v", err)
 	}
 
+	// The audit log is append-only
+	if err := db.Exec(`CREATE OR REPLACE FUNCTION audit_logs_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_logs is append-only';
+END;
+$$ LANGUAGE plpgsql`).Error; err != nil {
+		log.Fatalf("Failed to create audit log trigger function: %v", err)
+	}
+	for _, stmt := range []string{
+		`DROP TRIGGER IF EXISTS audit_logs_no_modify ON audit_logs`,
+		`CREATE TRIGGER audit_logs_no_modify BEFORE UPDATE OR DELETE ON audit_logs FOR EACH ROW EXECUTE FUNCTION audit_logs_append_only()`,
+		`DROP TRIGGER IF EXISTS audit_logs_no_truncate ON audit_logs`,
+		`CREATE TRIGGER audit_logs_no_truncate BEFORE TRUNCATE ON audit_logs FOR EACH STATEMENT EXECUTE FUNCTION audit_logs_append_only()`,
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			log.Fatalf("Failed to create audit log triggers: %v", err)
+		}
+	}
+
+	// Rule versions are immutable
+	if err := db.Exec(`CREATE OR REPLACE FUNCTION rule_versions_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'rule_versions is append-only';
+END;
+$$ LANGUAGE plpgsql`).Error; err != nil {
+		log.Fatalf("Failed to create rule version trigger function: %v", err)
+	}
+	for _, stmt := range []string{
+		`DROP TRIGGER IF EXISTS rule_versions_no_modify ON rule_versions`,
+		`CREATE TRIGGER rule_versions_no_modify BEFORE UPDATE OR DELETE ON rule_versions FOR EACH ROW EXECUTE FUNCTION rule_versions_append_only()`,
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			log.Fatalf("Failed to create rule version triggers: %v", err)
+		}
+	}
+
+	// At most one open (awaiting approval, pending or submitted) action per address, action type and direction
+	if err := db.Exec(`DROP INDEX IF EXISTS idx_enforcement_actions_active`).Error; err != nil {
+		log.Fatalf("Failed to drop enforcement action index: %v", err)
+	}
+	if err := db.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_enforcement_actions_open ON enforcement_actions(address, action, direction) WHERE status IN ('awaiting_approval', 'pending', 'submitted') AND deleted_at IS NULL`).Error; err != nil {
+		log.Fatalf("Failed to create enforcement action index: %v", err)
+	}
+
+	// An address may have several whitelist entries, scoped differently
+	if err := db.Exec(`ALTER TABLE whitelist_addresses DROP CONSTRAINT IF EXISTS whitelist_addresses_address_key`).Error; err != nil {
+		log.Fatalf("Failed to drop whitelist address constraint: %v", err)
+	}
+
 	// Then create tables with foreign keys
 	if err := db.AutoMigrate(
 		&models.TokenTransfer{},
@@ -86,6 +201,68 @@ func main() {
 		log.Fatalf("Failed to migrate tables with foreign keys: %v", err)
 	}
 
+	// New alerts are published to fds-api stream subscribers through stream_events
+	if err := db.Exec(`CREATE OR REPLACE FUNCTION publish_stream_event() RETURNS trigger AS $$
+DECLARE
+    r RECORD;
+    ev stream_events%ROWTYPE;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        r := OLD;
+    ELSE
+        r := NEW;
+    END IF;
+    -- Only blacklist entries are updated; other updates are not alerts
+    IF TG_OP = 'UPDATE' THEN
+        IF NEW.status IS NOT DISTINCT FROM OLD.status
+            AND NEW.direction IS NOT DISTINCT FROM OLD.direction
+            AND NEW.deleted_at IS NOT DISTINCT FROM OLD.deleted_at THEN
+            RETURN NULL;
+        END IF;
+    END IF;
+
+    -- Event IDs must follow commit order so a client resuming after an ID misses nothing
+    PERFORM pg_advisory_xact_lock(7410041);
+
+    ev.payload := to_jsonb(r);
+    IF TG_TABLE_NAME = 'suspicious_transfers' THEN
+        ev.type := 'suspicious_transfer';
+        ev.severity := r.severity;
+        ev.from_address := r.from_address;
+        ev.to_address := r.to_address;
+    ELSIF TG_TABLE_NAME = 'rule_violations' THEN
+        ev.type := 'rule_violation';
+        SELECT name, severity INTO ev.rule, ev.severity FROM rules WHERE id = r.rule_id;
+        SELECT from_address, to_address INTO ev.from_address, ev.to_address FROM transactions WHERE hash = r.tx_hash;
+    ELSE
+        ev.type := 'blacklist';
+        ev.severity := r.severity;
+        ev.from_address := r.address;
+        ev.payload := ev.payload || jsonb_build_object('operation', lower(TG_OP));
+    END IF;
+
+    INSERT INTO stream_events (created_at, type, severity, rule, from_address, to_address, payload)
+    VALUES (CURRENT_TIMESTAMP, ev.type, ev.severity, ev.rule, ev.from_address, ev.to_address, ev.payload)
+    RETURNING id INTO ev.id;
+    PERFORM pg_notify('fds_stream', ev.id::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql`).Error; err != nil {
+		log.Fatalf("Failed to create stream trigger function: %v", err)
+	}
+	for _, stmt := range []string{
+		`DROP TRIGGER IF EXISTS suspicious_transfers_stream ON suspicious_transfers`,
+		`CREATE TRIGGER suspicious_transfers_stream AFTER INSERT ON suspicious_transfers FOR EACH ROW EXECUTE FUNCTION publish_stream_event()`,
+		`DROP TRIGGER IF EXISTS rule_violations_stream ON rule_violations`,
+		`CREATE TRIGGER rule_violations_stream AFTER INSERT ON rule_violations FOR EACH ROW WHEN (NEW.suppressed IS NOT TRUE) EXECUTE FUNCTION publish_stream_event()`,
+		`DROP TRIGGER IF EXISTS blacklisted_addresses_stream ON blacklisted_addresses`,
+		`CREATE TRIGGER blacklisted_addresses_stream AFTER INSERT OR UPDATE OR DELETE ON blacklisted_addresses FOR EACH ROW EXECUTE FUNCTION publish_stream_event()`,
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			log.Fatalf("Failed to create stream triggers: %v", err)
+		}
+	}
+
 	// Initialize default rules
 	defaultRules := []models.Rule{
 		{
@@ -94,7 +271,7 @@ func main() {
 			Status:      "active",
 			Severity:    "high",
 			Parameters:  `{"threshold": "1000000000000000000000", "description": "Transfer amount threshold in wei"}`,
-			Actions:     `{"action": "record_violation", "description": "Record violation when transfer amount exceeds threshold"}`,
+			Actions:     `{"action": "record_violation", "blacklist_direction": "both", "enforcement": "auto", "description": "Record violation when transfer amount exceeds threshold"}`,
 		},
 		{
 			Name:        "multiple_transfers",
@@ -118,7 +295,7 @@ func main() {
 				"block_range": 10,
 				"description": "Total amount threshold in wei and block range to check"
 			}`,
-			Actions:     `{"action": "record_violation", "description": "Record violation when address receives multiple transfers exceeding threshold"}`,
+			Actions:     `{"action": "record_violation", "blacklist_direction": "to", "enforcement": "auto", "description": "Record violation when address receives multiple transfers exceeding threshold"}`,
 		},
 		{
 			Name:        "suspicious_address",
@@ -129,7 +306,7 @@ func main() {
 				"addresses": [],
 				"description": "List of known suspicious addresses to monitor"
 			}`,
-			Actions:     `{"action": "record_violation", "description": "Record violation when transaction involves suspicious address"}`,
+			Actions:     `{"action": "record_violation", "blacklist_direction": "both", "enforcement": "auto", "description": "Record violation when transaction involves suspicious address"}`,
 		},
 		{
 			Name:        "insufficient_balance",
@@ -143,6 +320,18 @@ func main() {
 			}`,
 			Actions:     `{"action": "record_violation", "description": "Record violation when transfer amount exceeds sender's previous balance"}`,
 		},
+		{
+			Name:        "repeated_blocked_attempts",
+			Description: "Detects addresses repeatedly attempting transfers that are blocked by compliance modules",
+			Status:      "active",
+			Severity:    "medium",
+			Parameters:  `{
+				"min_attempts": 3,
+				"block_range": 100,
+				"description": "Minimum number of blocked attempts and block range to check"
+			}`,
+			Actions:     `{"action": "record_violation", "description": "Record violation when an address keeps retrying blocked transfers"}`,
+		},
 	}
 
 	// Insert default rules
@@ -153,6 +342,23 @@ func main() {
 		}
 	}
 
+	// Rules from before rule types run the check named like them, or that of the rule they
+	// shadow; every rule starts at version 1
+	for _, stmt := range []string{
+		`UPDATE rules SET type = COALESCE(NULLIF(shadow_of, ''), name) WHERE type IS NULL OR type = ''`,
+		`INSERT INTO rule_versions (rule_id, version, name, type, description, status, severity, parameters, actions, shadow_of, change, comment, changed_by, created_at)
+		SELECT id, 1, name, type, description, status, severity, parameters::jsonb, actions::jsonb, COALESCE(shadow_of, ''), 'create', 'Initial version', 'system', NOW()
+		FROM rules
+		WHERE NOT EXISTS (SELECT 1 FROM rule_versions WHERE rule_versions.rule_id = rules.id)`,
+		`UPDATE rules SET version = rule_versions.version, version_id = rule_versions.id
+		FROM rule_versions
+		WHERE rule_versions.rule_id = rules.id AND rule_versions.version = 1 AND (rules.version_id IS NULL OR rules.version_id = 0)`,
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			log.Fatalf("Failed to version rules: %v", err)
+		}
+	}
+
 	// Print schema information
 	tables, err := db.Migrator().GetTables()
 	if err != nil {
@@ -12,9 +12,7 @@ import (
 	"token-monitor/contracts/restrict"
 	"token-monitor/services"
 
-	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/rpc"
 	"gorm.io/driver/postgres"
@@ -41,10 +39,10 @@ func main() {
 	}
 	client := ethclient.NewClient(rpcClient)
 
-	// Create owner key for contract interactions
-	privateKey, err := crypto.HexToECDSA(os.Getenv("BLACKLIST_PRIVATE_KEY"))
+	// Create the signer of contract interactions; every signature is rate-limited and audited
+	signer, err := services.NewSigner(context.Background(), cfg.Signer)
 	if err != nil {
-		log.Fatalf("Failed to parse private key: %v", err)
+		log.Fatalf("Failed to create signer: %v", err)
 	}
 
 	chainID, err := client.ChainID(context.Background())
@@ -52,10 +50,8 @@ func main() {
 		log.Fatalf("Failed to get chain ID: %v", err)
 	}
 
-	auth, err := bind.NewKeyedTransactorWithChainID(privateKey, chainID)
-	if err != nil {
-		log.Fatalf("Failed to create authorized transactor: %v", err)
-	}
+	auth := services.NewSignerTransactor(services.NewAuditedSigner(signer, db, cfg.Signer.MaxPerMinute), chainID)
+	log.Printf("Signing as %s with the %s signer", auth.From.Hex(), cfg.Signer.Type)
 
 	// Create analyzer service
 	analyzer := services.NewAnalyzer(
@@ -65,6 +61,23 @@ func main() {
 		cfg.Monitor.SuspiciousAddresses,
 		time.Second*5, // analysis interval
 	)
+	analyzer.SetSuppressionWindow(cfg.Monitor.AlertSuppressionWindow)
+
+	// Create notifier; the analyzer queues alerts of rule violations for the configured channels
+	channels, err := services.LoadNotificationChannels(cfg.Notifier.ChannelsFile)
+	if err != nil {
+		log.Fatalf("Failed to load notification channels: %v", err)
+	}
+	notifier := services.NewNotifier(
+		db,
+		channels,
+		cfg.Notifier.MaxAttempts,
+		cfg.Notifier.RetryBackoff,
+		cfg.Notifier.Timeout,
+		cfg.Notifier.Interval,
+	)
+	analyzer.SetNotifier(notifier)
+	log.Printf("Sending alerts to %d notification channels", len(channels))
 
 	// Create monitor service
 	monitor, err := services.NewMonitor(db, cfg.Monitor, analyzer)
@@ -80,18 +93,97 @@ func main() {
 
 	blacklistMonitor := services.NewBlacklistMonitor(
 		db,
+		time.Second*5, // Check for new suspicious addresses every 10s
+	)
+
+	// Create enforcement executor; every blacklist is queued and sent from here
+	nonces := services.NewNonceManager(client, auth.From)
+	enforcementExecutor := services.NewEnforcementExecutor(
+		db,
+		client,
 		restrictContract,
-		common.HexToAddress(os.Getenv("RESTRICT_CONTRACT_ADDRESS")),
 		auth,
-		time.Second*5, // Check for new suspicious addresses every 10s
+		nonces,
+		cfg.Monitor.MaxRetryAttempts,
+		cfg.Monitor.RetryBackoff,
+		time.Second*2,
+	)
+
+	// Create pre-emptive blacklist tracker; the analyzer uses it to front-run suspicious pending transactions
+	preemptionTracker := services.NewPreemptionTracker(
+		db,
+		client,
+		restrictContract,
+		auth,
+		nonces,
+		services.PreemptionConfig{
+			TipBumpPercent:  cfg.Monitor.PreemptTipBumpPercent,
+			ReplaceAfter:    cfg.Monitor.PreemptReplaceAfter,
+			MaxReplacements: cfg.Monitor.PreemptMaxReplacements,
+		},
+		time.Second*2,
+	)
+	analyzer.SetPreemptionTracker(preemptionTracker)
+
+	// Create reconciler; keeps the blacklist table in line with the restriction contract
+	reconciler, err := services.NewReconciler(
+		db,
+		client,
+		restrictContract,
+		common.HexToAddress(os.Getenv("RESTRICT_CONTRACT_ADDRESS")),
+		cfg.Monitor.ReconcileStartBlock,
+		cfg.Monitor.ReconcileRepair,
+		cfg.Monitor.ReconcileInterval,
 	)
+	if err != nil {
+		log.Fatalf("Failed to create reconciler: %v", err)
+	}
+
+	// Create blacklist lifecycle scheduler; lifts expired provisional freezes through the executor
+	blacklistLifecycle := services.NewBlacklistLifecycle(
+		db,
+		services.LifecyclePolicy{
+			ProvisionalTTL:               cfg.Monitor.ProvisionalFreezeTTL,
+			ReviewInterval:               cfg.Monitor.BlacklistReviewEvery,
+			LawEnforcementReviewInterval: cfg.Monitor.LawEnforcementReview,
+		},
+		time.Minute,
+	)
+
+	// Create large-value transaction reporter
+	ctrReporter, err := services.NewCtrReporter(
+		db,
+		cfg.Monitor.CtrThreshold,
+		cfg.Monitor.CtrReportDir,
+		cfg.Monitor.CtrPeriods,
+		cfg.Monitor.CtrUTCOffsetHours,
+		cfg.Monitor.CtrInterval,
+	)
+	if err != nil {
+		log.Fatalf("Failed to create CTR reporter: %v", err)
+	}
+
+	// Create backtest runner; fds-api queues the backtests
+	backtestRunner := services.NewBacktestRunner(db, time.Second*5)
+
+	// Create rule evaluator; fds-api queues the dry runs and waits for them
+	ruleEvaluator := services.NewRuleEvaluator(db, time.Second, cfg.Monitor.AlertSuppressionWindow)
+
+	// Create watchlist syncer; fds-api manages the sources
+	watchlistSyncer := services.NewWatchlistSyncer(db, cfg.Monitor.WatchlistInterval, cfg.Monitor.WatchlistLookback, cfg.Monitor.WatchlistFetchTimeout)
 
 	// Create mempool monitor
+	var systemContracts []common.Address
+	for _, addr := range cfg.Monitor.SystemContracts {
+		systemContracts = append(systemContracts, common.HexToAddress(addr))
+	}
+
 	mempoolMonitor := services.NewMempoolMonitor(
 		client,
 		rpcClient,
 		db,
 		common.HexToAddress(cfg.Monitor.ContractAddress),
+		systemContracts,
 		analyzer,
 		time.Second,
 	)
@@ -113,6 +205,33 @@ func main() {
 	// Start blacklist monitor
 	blacklistMonitor.Start(ctx)
 
+	// Start enforcement executor
+	enforcementExecutor.Start(ctx)
+
+	// Start pre-emptive blacklist tracker
+	preemptionTracker.Start(ctx)
+
+	// Start reconciler
+	reconciler.Start(ctx)
+
+	// Start blacklist lifecycle scheduler
+	blacklistLifecycle.Start(ctx)
+
+	// Start large-value transaction reporter
+	ctrReporter.Start(ctx)
+
+	// Start notifier
+	notifier.Start(ctx)
+
+	// Start backtest runner
+	backtestRunner.Start(ctx)
+
+	// Start rule evaluator
+	ruleEvaluator.Start(ctx)
+
+	// Start watchlist syncer
+	watchlistSyncer.Start(ctx)
+
 	// Start mempool monitor
 	mempoolMonitor.Start(ctx)
 
@@ -127,5 +246,14 @@ func main() {
 	monitor.Stop()
 	analyzer.Stop()
 	blacklistMonitor.Stop()
+	enforcementExecutor.Stop()
 	mempoolMonitor.Stop()
+	preemptionTracker.Stop()
+	reconciler.Stop()
+	blacklistLifecycle.Stop()
+	ctrReporter.Stop()
+	notifier.Stop()
+	backtestRunner.Stop()
+	ruleEvaluator.Stop()
+	watchlistSyncer.Stop()
 }
@@ -14,6 +14,8 @@ import (
 type Config struct {
 	Database DatabaseConfig
 	Monitor  MonitorConfig
+	Signer   SignerConfig
+	Notifier NotifierConfig
 }
 
 // DatabaseConfig holds database-related configuration
@@ -26,6 +28,27 @@ type DatabaseConfig struct {
 	SSLMode  string
 }
 
+// SignerConfig holds the configuration of the signer of blacklist transactions
+type SignerConfig struct {
+	Type           string        // "keystore", "remote" or "memory"
+	KeystorePath   string        // Encrypted keystore file
+	PassphraseFile string        // File holding the keystore passphrase
+	RemoteURL      string        // JSON-RPC endpoint of the remote signer
+	Address        string        // Account of the remote signer; its first account if empty
+	Timeout        time.Duration // Timeout of remote signing requests
+	PrivateKey     string        // Hex private key, only read by the memory signer for local chains
+	MaxPerMinute   int           // Signing requests allowed per minute
+}
+
+// NotifierConfig holds the configuration of alert notifications
+type NotifierConfig struct {
+	ChannelsFile string        // JSON file of notification channels; notifications are off if empty
+	MaxAttempts  int           // Attempts before a delivery is marked as failed
+	RetryBackoff time.Duration // Wait after the first failed attempt, doubled after each further one
+	Timeout      time.Duration // Timeout of a single delivery attempt
+	Interval     time.Duration // How often queued alerts are sent
+}
+
 // EventCondition defines a condition to check for an event
 type EventCondition struct {
 	Field    string      // Field to check (e.g., "amount", "from", "to")
@@ -36,15 +59,34 @@ type EventCondition struct {
 
 // MonitorConfig holds monitor service configuration
 type MonitorConfig struct {
-	EthereumWSURL        string
-	ContractAddress      string
-	ContractABI          string // Path to the ABI file
-	MaxRetryAttempts     int
-	RetryBackoff         time.Duration
-	LargeAmountThreshold float64
-	SuspiciousAddresses  []string
-	EventConditions      map[string][]EventCondition // Map of event name to conditions
-	ExcludedEvents       []string                    // Events to exclude from monitoring
+	EthereumWSURL          string
+	ContractAddress        string
+	ContractABI            string // Path to the ABI file
+	MaxRetryAttempts       int
+	RetryBackoff           time.Duration
+	LargeAmountThreshold   float64
+	SuspiciousAddresses    []string
+	SystemContracts        []string                    // Additional system contracts (e.g. ExchangePortal) whose events are decoded
+	EventConditions        map[string][]EventCondition // Map of event name to conditions
+	ExcludedEvents         []string                    // Events to exclude from monitoring
+	PreemptTipBumpPercent  int64                       // How much higher than a suspicious pending tx's tip to bid, in percent
+	PreemptReplaceAfter    time.Duration               // How long a pre-emptive blacklist may stay pending before it is sped up
+	PreemptMaxReplacements int                         // Maximum number of speed-ups per pre-emptive blacklist
+	ReconcileStartBlock    uint64                      // First block to ingest restriction contract events from
+	ReconcileInterval      time.Duration               // How often the blacklist is reconciled with the contract
+	ReconcileRepair        bool                        // Whether blacklist drift is repaired or only reported
+	ProvisionalFreezeTTL   time.Duration               // How long an automated blacklist lasts unless an officer confirms it
+	BlacklistReviewEvery   time.Duration               // Review interval of confirmed manual blacklists
+	LawEnforcementReview   time.Duration               // Review interval of law-enforcement holds
+	CtrThreshold           string                      // Amount in token base units from which transfers are reported as large-value
+	CtrReportDir           string                      // Directory large-value transaction reports are written to
+	CtrPeriods             []string                    // Reporting periods: "daily", "weekly" and/or "monthly"
+	CtrUTCOffsetHours      int                         // UTC offset the reporting periods are reckoned in
+	CtrInterval            time.Duration               // How often due reports are generated
+	AlertSuppressionWindow time.Duration               // Default window grouping repeats of a rule's alert on an address
+	WatchlistInterval      time.Duration               // How often watchlist sources are checked for a due sync
+	WatchlistFetchTimeout  time.Duration               // Timeout of fetching a watchlist
+	WatchlistLookback      time.Duration               // How far back the transactions of newly listed addresses are screened
 }
 
 // Load loads configuration from environment variables
@@ -70,6 +112,7 @@ func Load() (*Config, error) {
 			RetryBackoff:         time.Second * 5,
 			LargeAmountThreshold: getEnvAsFloat64("LARGE_AMOUNT_THRESHOLD", 1000.0),
 			SuspiciousAddresses:  strings.Split(getEnv("SUSPICIOUS_ADDRESSES", ""), ","),
+			SystemContracts:      strings.Split(getEnv("SYSTEM_CONTRACT_ADDRESSES", ""), ","),
 			EventConditions: map[string][]EventCondition{
 				"Transfer": {
 					{
@@ -100,7 +143,42 @@ func Load() (*Config, error) {
 					},
 				},
 			},
-			ExcludedEvents: strings.Split(getEnv("EXCLUDED_EVENTS", ""), ","),
+			ExcludedEvents:         strings.Split(getEnv("EXCLUDED_EVENTS", ""), ","),
+			PreemptTipBumpPercent:  int64(getEnvAsInt("PREEMPT_TIP_BUMP_PERCENT", 25)),
+			PreemptReplaceAfter:    time.Duration(getEnvAsInt("PREEMPT_REPLACE_AFTER_SECONDS", 12)) * time.Second,
+			PreemptMaxReplacements: getEnvAsInt("PREEMPT_MAX_REPLACEMENTS", 5),
+			ReconcileStartBlock:    uint64(getEnvAsInt("RECONCILE_START_BLOCK", 0)),
+			ReconcileInterval:      time.Duration(getEnvAsInt("RECONCILE_INTERVAL_SECONDS", 60)) * time.Second,
+			ReconcileRepair:        getEnvAsBool("RECONCILE_REPAIR", true),
+			ProvisionalFreezeTTL:   time.Duration(getEnvAsInt("PROVISIONAL_FREEZE_HOURS", 72)) * time.Hour,
+			BlacklistReviewEvery:   time.Duration(getEnvAsInt("BLACKLIST_REVIEW_DAYS", 90)) * 24 * time.Hour,
+			LawEnforcementReview:   time.Duration(getEnvAsInt("LAW_ENFORCEMENT_REVIEW_DAYS", 180)) * 24 * time.Hour,
+			CtrThreshold:           getEnv("CTR_THRESHOLD", "500000000000000000000000000"),
+			CtrReportDir:           getEnv("CTR_REPORT_DIR", "./reports/ctr"),
+			CtrPeriods:             strings.Split(getEnv("CTR_PERIODS", "daily,monthly"), ","),
+			CtrUTCOffsetHours:      getEnvAsInt("CTR_UTC_OFFSET_HOURS", 7),
+			CtrInterval:            time.Duration(getEnvAsInt("CTR_INTERVAL_MINUTES", 60)) * time.Minute,
+			AlertSuppressionWindow: time.Duration(getEnvAsInt("ALERT_SUPPRESSION_WINDOW_MINUTES", 60)) * time.Minute,
+			WatchlistInterval:      time.Duration(getEnvAsInt("WATCHLIST_INTERVAL_SECONDS", 60)) * time.Second,
+			WatchlistFetchTimeout:  time.Duration(getEnvAsInt("WATCHLIST_FETCH_TIMEOUT_SECONDS", 60)) * time.Second,
+			WatchlistLookback:      time.Duration(getEnvAsInt("WATCHLIST_LOOKBACK_DAYS", 365)) * 24 * time.Hour,
+		},
+		Signer: SignerConfig{
+			Type:           getEnv("SIGNER_TYPE", "keystore"),
+			KeystorePath:   getEnv("SIGNER_KEYSTORE_PATH", ""),
+			PassphraseFile: getEnv("SIGNER_PASSPHRASE_FILE", ""),
+			RemoteURL:      getEnv("SIGNER_REMOTE_URL", ""),
+			Address:        getEnv("SIGNER_ADDRESS", ""),
+			Timeout:        time.Duration(getEnvAsInt("SIGNER_TIMEOUT_SECONDS", 30)) * time.Second,
+			PrivateKey:     getEnv("BLACKLIST_PRIVATE_KEY", ""),
+			MaxPerMinute:   getEnvAsInt("SIGNER_MAX_PER_MINUTE", 60),
+		},
+		Notifier: NotifierConfig{
+			ChannelsFile: getEnv("NOTIFY_CHANNELS_FILE", ""),
+			MaxAttempts:  getEnvAsInt("NOTIFY_MAX_ATTEMPTS", 6),
+			RetryBackoff: time.Duration(getEnvAsInt("NOTIFY_RETRY_BACKOFF_SECONDS", 30)) * time.Second,
+			Timeout:      time.Duration(getEnvAsInt("NOTIFY_TIMEOUT_SECONDS", 10)) * time.Second,
+			Interval:     time.Duration(getEnvAsInt("NOTIFY_INTERVAL_SECONDS", 5)) * time.Second,
 		},
 	}
 
@@ -113,6 +191,15 @@ func Load() (*Config, error) {
 	}
 	config.Monitor.SuspiciousAddresses = cleanAddresses
 
+	// Remove empty addresses from the system contract list
+	var cleanContracts []string
+	for _, addr := range config.Monitor.SystemContracts {
+		if addr != "" {
+			cleanContracts = append(cleanContracts, addr)
+		}
+	}
+	config.Monitor.SystemContracts = cleanContracts
+
 	// Remove empty events from excluded list
 	var cleanExcluded []string
 	for _, event := range config.Monitor.ExcludedEvents {
@@ -156,6 +243,37 @@ func (c *Config) validate() error {
 	if c.Database.Password == "" {
 		return fmt.Errorf("DB_PASSWORD is required")
 	}
+	if c.Notifier.MaxAttempts < 1 {
+		return fmt.Errorf("NOTIFY_MAX_ATTEMPTS must be positive")
+	}
+	if c.Monitor.AlertSuppressionWindow < 0 {
+		return fmt.Errorf("ALERT_SUPPRESSION_WINDOW_MINUTES must not be negative")
+	}
+	return nil
+}
+
+// Validate checks the signer settings. Only the monitor signs, so they are checked when it
+// creates its signer rather than on every Load.
+func (c *SignerConfig) Validate() error {
+	switch c.Type {
+	case "keystore":
+		if c.KeystorePath == "" || c.PassphraseFile == "" {
+			return fmt.Errorf("SIGNER_KEYSTORE_PATH and SIGNER_PASSPHRASE_FILE are required for the keystore signer")
+		}
+	case "remote":
+		if c.RemoteURL == "" {
+			return fmt.Errorf("SIGNER_REMOTE_URL is required for the remote signer")
+		}
+	case "memory":
+		if c.PrivateKey == "" {
+			return fmt.Errorf("BLACKLIST_PRIVATE_KEY is required for the memory signer")
+		}
+	default:
+		return fmt.Errorf("SIGNER_TYPE must be one of keystore, remote, memory")
+	}
+	if c.MaxPerMinute < 1 {
+		return fmt.Errorf("SIGNER_MAX_PER_MINUTE must be positive")
+	}
 	return nil
 }
 
@@ -184,3 +302,12 @@ func getEnvAsFloat64(key string, defaultValue float64) float64 {
 	}
 	return defaultValue
 }
+
+func getEnvAsBool(key string, defaultValue bool) bool {
+	if value, exists := os.LookupEnv(key); exists {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
@@ -1,6 +1,7 @@
 package restrict
 
 import (
+	"math/big"
 	"strings"
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
@@ -56,36 +57,316 @@ func bindRestrict(address common.Address, caller bind.ContractCaller, transactor
 	return bind.NewBoundContract(address, parsed, caller, transactor, filterer), nil
 }
 
+// RestrictionInfo is the on-chain restriction of an address in one direction
+type RestrictionInfo struct {
+	IsRestricted bool
+	Reason       string
+	Timestamp    *big.Int
+	RestrictedBy common.Address
+}
+
 // Blacklist is a paid mutator transaction binding the contract method.
-func (r *RestrictTransactor) Blacklist(opts *bind.TransactOpts, accounts []common.Address) (*types.Transaction, error) {
-	return r.contract.Transact(opts, "blacklist", accounts)
+// It restricts the accounts from both sending and receiving.
+func (r *RestrictTransactor) Blacklist(opts *bind.TransactOpts, accounts []common.Address, reason string) (*types.Transaction, error) {
+	return r.contract.Transact(opts, "blacklist", accounts, reason)
+}
+
+// BlacklistFrom is a paid mutator transaction binding the contract method.
+// It restricts the accounts from sending.
+func (r *RestrictTransactor) BlacklistFrom(opts *bind.TransactOpts, accounts []common.Address, reason string) (*types.Transaction, error) {
+	return r.contract.Transact(opts, "blacklistFrom", accounts, reason)
+}
+
+// BlacklistTo is a paid mutator transaction binding the contract method.
+// It restricts the accounts from receiving.
+func (r *RestrictTransactor) BlacklistTo(opts *bind.TransactOpts, accounts []common.Address, reason string) (*types.Transaction, error) {
+	return r.contract.Transact(opts, "blacklistTo", accounts, reason)
+}
+
+// BlacklistWithReasons is a paid mutator transaction binding the contract method.
+// reasons must have one entry per account.
+func (r *RestrictTransactor) BlacklistWithReasons(opts *bind.TransactOpts, accounts []common.Address, reasons []string) (*types.Transaction, error) {
+	return r.contract.Transact(opts, "blacklistWithReasons", accounts, reasons)
+}
+
+// BlacklistFromWithReasons is a paid mutator transaction binding the contract method.
+// reasons must have one entry per account.
+func (r *RestrictTransactor) BlacklistFromWithReasons(opts *bind.TransactOpts, accounts []common.Address, reasons []string) (*types.Transaction, error) {
+	return r.contract.Transact(opts, "blacklistFromWithReasons", accounts, reasons)
+}
+
+// BlacklistToWithReasons is a paid mutator transaction binding the contract method.
+// reasons must have one entry per account.
+func (r *RestrictTransactor) BlacklistToWithReasons(opts *bind.TransactOpts, accounts []common.Address, reasons []string) (*types.Transaction, error) {
+	return r.contract.Transact(opts, "blacklistToWithReasons", accounts, reasons)
+}
+
+// Unblacklist is a paid mutator transaction binding the contract method.
+func (r *RestrictTransactor) Unblacklist(opts *bind.TransactOpts, accounts []common.Address, reason string) (*types.Transaction, error) {
+	return r.contract.Transact(opts, "unblacklist", accounts, reason)
+}
+
+// UnblacklistFrom is a paid mutator transaction binding the contract method.
+func (r *RestrictTransactor) UnblacklistFrom(opts *bind.TransactOpts, accounts []common.Address, reason string) (*types.Transaction, error) {
+	return r.contract.Transact(opts, "unblacklistFrom", accounts, reason)
+}
+
+// UnblacklistTo is a paid mutator transaction binding the contract method.
+func (r *RestrictTransactor) UnblacklistTo(opts *bind.TransactOpts, accounts []common.Address, reason string) (*types.Transaction, error) {
+	return r.contract.Transact(opts, "unblacklistTo", accounts, reason)
+}
+
+// IsBlacklistedFrom is a free data retrieval call binding the contract method.
+func (r *RestrictCaller) IsBlacklistedFrom(opts *bind.CallOpts, account common.Address) (bool, error) {
+	return r.callBool(opts, "isBlacklistedFrom", account)
+}
+
+// IsBlacklistedTo is a free data retrieval call binding the contract method.
+func (r *RestrictCaller) IsBlacklistedTo(opts *bind.CallOpts, account common.Address) (bool, error) {
+	return r.callBool(opts, "isBlacklistedTo", account)
+}
+
+// GetRestrictionInfoFrom is a free data retrieval call binding the contract method.
+func (r *RestrictCaller) GetRestrictionInfoFrom(opts *bind.CallOpts, account common.Address) (RestrictionInfo, error) {
+	return r.callRestrictionInfo(opts, "getRestrictionInfoFrom", account)
+}
+
+// GetRestrictionInfoTo is a free data retrieval call binding the contract method.
+func (r *RestrictCaller) GetRestrictionInfoTo(opts *bind.CallOpts, account common.Address) (RestrictionInfo, error) {
+	return r.callRestrictionInfo(opts, "getRestrictionInfoTo", account)
+}
+
+func (r *RestrictCaller) callBool(opts *bind.CallOpts, method string, account common.Address) (bool, error) {
+	var out []interface{}
+	if err := r.contract.Call(opts, &out, method, account); err != nil {
+		return false, err
+	}
+	return *abi.ConvertType(out[0], new(bool)).(*bool), nil
+}
+
+func (r *RestrictCaller) callRestrictionInfo(opts *bind.CallOpts, method string, account common.Address) (RestrictionInfo, error) {
+	var out []interface{}
+	if err := r.contract.Call(opts, &out, method, account); err != nil {
+		return RestrictionInfo{}, err
+	}
+	return *abi.ConvertType(out[0], new(RestrictionInfo)).(*RestrictionInfo), nil
 }
 
-// RestrictABI contains the ABI for the Restrict contract
+// RestrictABI contains the ABI for the Restrict contract (AddressRestrictionCompliance)
 const RestrictABI = `[
 	{
-		"inputs": [{"internalType": "address[]", "name": "accounts", "type": "address[]"}],
+		"inputs": [
+			{"internalType": "address[]", "name": "accounts", "type": "address[]"},
+			{"internalType": "string", "name": "reason", "type": "string"}
+		],
 		"name": "blacklist",
 		"outputs": [],
 		"stateMutability": "nonpayable",
 		"type": "function"
 	},
+	{
+		"inputs": [
+			{"internalType": "address[]", "name": "accounts", "type": "address[]"},
+			{"internalType": "string", "name": "reason", "type": "string"}
+		],
+		"name": "blacklistFrom",
+		"outputs": [],
+		"stateMutability": "nonpayable",
+		"type": "function"
+	},
+	{
+		"inputs": [
+			{"internalType": "address[]", "name": "accounts", "type": "address[]"},
+			{"internalType": "string", "name": "reason", "type": "string"}
+		],
+		"name": "blacklistTo",
+		"outputs": [],
+		"stateMutability": "nonpayable",
+		"type": "function"
+	},
+	{
+		"inputs": [
+			{"internalType": "address[]", "name": "accounts", "type": "address[]"},
+			{"internalType": "string", "name": "reason", "type": "string"}
+		],
+		"name": "unblacklist",
+		"outputs": [],
+		"stateMutability": "nonpayable",
+		"type": "function"
+	},
+	{
+		"inputs": [
+			{"internalType": "address[]", "name": "accounts", "type": "address[]"},
+			{"internalType": "string", "name": "reason", "type": "string"}
+		],
+		"name": "unblacklistFrom",
+		"outputs": [],
+		"stateMutability": "nonpayable",
+		"type": "function"
+	},
+	{
+		"inputs": [
+			{"internalType": "address[]", "name": "accounts", "type": "address[]"},
+			{"internalType": "string", "name": "reason", "type": "string"}
+		],
+		"name": "unblacklistTo",
+		"outputs": [],
+		"stateMutability": "nonpayable",
+		"type": "function"
+	},
+	{
+		"inputs": [
+			{"internalType": "address[]", "name": "accounts", "type": "address[]"},
+			{"internalType": "string[]", "name": "reasons", "type": "string[]"}
+		],
+		"name": "blacklistWithReasons",
+		"outputs": [],
+		"stateMutability": "nonpayable",
+		"type": "function"
+	},
+	{
+		"inputs": [
+			{"internalType": "address[]", "name": "accounts", "type": "address[]"},
+			{"internalType": "string[]", "name": "reasons", "type": "string[]"}
+		],
+		"name": "blacklistFromWithReasons",
+		"outputs": [],
+		"stateMutability": "nonpayable",
+		"type": "function"
+	},
+	{
+		"inputs": [
+			{"internalType": "address[]", "name": "accounts", "type": "address[]"},
+			{"internalType": "string[]", "name": "reasons", "type": "string[]"}
+		],
+		"name": "blacklistToWithReasons",
+		"outputs": [],
+		"stateMutability": "nonpayable",
+		"type": "function"
+	},
+	{
+		"inputs": [{"internalType": "address", "name": "account", "type": "address"}],
+		"name": "isBlacklisted",
+		"outputs": [{"internalType": "bool", "name": "", "type": "bool"}],
+		"stateMutability": "view",
+		"type": "function"
+	},
+	{
+		"inputs": [{"internalType": "address", "name": "account", "type": "address"}],
+		"name": "isBlacklistedFrom",
+		"outputs": [{"internalType": "bool", "name": "", "type": "bool"}],
+		"stateMutability": "view",
+		"type": "function"
+	},
+	{
+		"inputs": [{"internalType": "address", "name": "account", "type": "address"}],
+		"name": "isBlacklistedTo",
+		"outputs": [{"internalType": "bool", "name": "", "type": "bool"}],
+		"stateMutability": "view",
+		"type": "function"
+	},
+	{
+		"inputs": [{"internalType": "address", "name": "account", "type": "address"}],
+		"name": "getRestrictionInfoFrom",
+		"outputs": [
+			{
+				"components": [
+					{"internalType": "bool", "name": "isRestricted", "type": "bool"},
+					{"internalType": "string", "name": "reason", "type": "string"},
+					{"internalType": "uint256", "name": "timestamp", "type": "uint256"},
+					{"internalType": "address", "name": "restrictedBy", "type": "address"}
+				],
+				"internalType": "struct AddressRestrictionCompliance.RestrictionInfo",
+				"name": "",
+				"type": "tuple"
+			}
+		],
+		"stateMutability": "view",
+		"type": "function"
+	},
+	{
+		"inputs": [{"internalType": "address", "name": "account", "type": "address"}],
+		"name": "getRestrictionInfoTo",
+		"outputs": [
+			{
+				"components": [
+					{"internalType": "bool", "name": "isRestricted", "type": "bool"},
+					{"internalType": "string", "name": "reason", "type": "string"},
+					{"internalType": "uint256", "name": "timestamp", "type": "uint256"},
+					{"internalType": "address", "name": "restrictedBy", "type": "address"}
+				],
+				"internalType": "struct AddressRestrictionCompliance.RestrictionInfo",
+				"name": "",
+				"type": "tuple"
+			}
+		],
+		"stateMutability": "view",
+		"type": "function"
+	},
 	{
 		"anonymous": false,
-		"inputs": [{"indexed": true, "internalType": "address", "name": "account", "type": "address"}],
+		"inputs": [
+			{"indexed": true, "internalType": "address", "name": "account", "type": "address"},
+			{"indexed": false, "internalType": "string", "name": "reason", "type": "string"},
+			{"indexed": true, "internalType": "address", "name": "restrictedBy", "type": "address"},
+			{"indexed": false, "internalType": "uint256", "name": "timestamp", "type": "uint256"}
+		],
 		"name": "AddressBlacklisted",
 		"type": "event"
 	},
 	{
 		"anonymous": false,
-		"inputs": [{"indexed": true, "internalType": "address", "name": "account", "type": "address"}],
+		"inputs": [
+			{"indexed": true, "internalType": "address", "name": "account", "type": "address"},
+			{"indexed": false, "internalType": "string", "name": "reason", "type": "string"},
+			{"indexed": true, "internalType": "address", "name": "restrictedBy", "type": "address"},
+			{"indexed": false, "internalType": "uint256", "name": "timestamp", "type": "uint256"}
+		],
 		"name": "AddressBlacklistedFrom",
 		"type": "event"
 	},
 	{
 		"anonymous": false,
-		"inputs": [{"indexed": true, "internalType": "address", "name": "account", "type": "address"}],
+		"inputs": [
+			{"indexed": true, "internalType": "address", "name": "account", "type": "address"},
+			{"indexed": false, "internalType": "string", "name": "reason", "type": "string"},
+			{"indexed": true, "internalType": "address", "name": "restrictedBy", "type": "address"},
+			{"indexed": false, "internalType": "uint256", "name": "timestamp", "type": "uint256"}
+		],
 		"name": "AddressBlacklistedTo",
 		"type": "event"
+	},
+	{
+		"anonymous": false,
+		"inputs": [
+			{"indexed": true, "internalType": "address", "name": "account", "type": "address"},
+			{"indexed": false, "internalType": "string", "name": "reason", "type": "string"},
+			{"indexed": true, "internalType": "address", "name": "unrestrictedBy", "type": "address"},
+			{"indexed": false, "internalType": "uint256", "name": "timestamp", "type": "uint256"}
+		],
+		"name": "AddressUnblacklisted",
+		"type": "event"
+	},
+	{
+		"anonymous": false,
+		"inputs": [
+			{"indexed": true, "internalType": "address", "name": "account", "type": "address"},
+			{"indexed": false, "internalType": "string", "name": "reason", "type": "string"},
+			{"indexed": true, "internalType": "address", "name": "unrestrictedBy", "type": "address"},
+			{"indexed": false, "internalType": "uint256", "name": "timestamp", "type": "uint256"}
+		],
+		"name": "AddressUnblacklistedFrom",
+		"type": "event"
+	},
+	{
+		"anonymous": false,
+		"inputs": [
+			{"indexed": true, "internalType": "address", "name": "account", "type": "address"},
+			{"indexed": false, "internalType": "string", "name": "reason", "type": "string"},
+			{"indexed": true, "internalType": "address", "name": "unrestrictedBy", "type": "address"},
+			{"indexed": false, "internalType": "uint256", "name": "timestamp", "type": "uint256"}
+		],
+		"name": "AddressUnblacklistedTo",
+		"type": "event"
 	}
 ]`
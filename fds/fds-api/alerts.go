@@ -0,0 +1,242 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var alertIncidentList = listSpec{
+	Sorts: map[string]listSort{
+		"last_seen_at":     {"last_seen_at", "TIMESTAMPTZ"},
+		"first_seen_at":    {"first_seen_at", "TIMESTAMPTZ"},
+		"count":            {"count", "INTEGER"},
+		"suppressed_count": {"suppressed_count", "INTEGER"},
+	},
+	DefaultSort: "last_seen_at",
+	Filters: []listFilter{
+		inFilter("rule", "rule_name"),
+		inFilter("status", "status"),
+		inFilter("severity", "severity"),
+		addressFilter("address", "address"),
+		activeIncidentFilter,
+		timeRangeFilter("last_seen_at"),
+	},
+}
+
+// activeIncidentFilter restricts incidents to those still suppressing repeats (active=true)
+// or to the others (active=false)
+func activeIncidentFilter(c *gin.Context) (func(*gorm.DB) *gorm.DB, error) {
+	value := c.Query("active")
+	if value == "" {
+		return nil, nil
+	}
+	active, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, fmt.Errorf("active must be true or false")
+	}
+	return func(db *gorm.DB) *gorm.DB {
+		if active {
+			return db.Where("status = ? AND expires_at > ?", "open", time.Now())
+		}
+		return db.Where("NOT (status = ? AND expires_at > ?)", "open", time.Now())
+	}, nil
+}
+
+// getAlertIncidents returns a page of alert incidents
+func getAlertIncidents(c *gin.Context) {
+	var incidents []AlertIncident
+	page, err := listFrom(c).find(db.Model(&AlertIncident{}), &incidents)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, ListResponse{Data: incidents, Pagination: page})
+}
+
+// getAlertIncident returns an incident with the violations grouped into it
+func getAlertIncident(c *gin.Context) {
+	var incident AlertIncident
+	if err := db.First(&incident, c.Param("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "incident not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	var violations []RuleViolation
+	if err := db.Where("incident_id = ?", incident.ID).Order("created_at DESC").Limit(maxPageSize).Find(&violations).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"incident": incident, "violations": violations})
+}
+
+// resolveAlertIncident marks an incident resolved; the next violation of its rule by its
+// address opens a new incident and alerts again
+func resolveAlertIncident(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid incident id"})
+		return
+	}
+
+	var incident AlertIncident
+	status := http.StatusOK
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&incident, id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				status = http.StatusNotFound
+			}
+			return err
+		}
+		if incident.Status == "resolved" {
+			status = http.StatusConflict
+			return fmt.Errorf("incident is already resolved")
+		}
+		now := time.Now()
+		incident.Status = "resolved"
+		incident.ResolvedBy = currentUser(c)
+		incident.ResolvedAt = &now
+		return tx.Model(&incident).Updates(map[string]interface{}{
+			"status":      incident.Status,
+			"resolved_by": incident.ResolvedBy,
+			"resolved_at": now,
+		}).Error
+	})
+	if err != nil {
+		if status == http.StatusOK {
+			status = http.StatusInternalServerError
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, incident)
+}
+
+var alertSnoozeList = listSpec{
+	Sorts: map[string]listSort{
+		"created_at": {"created_at", "TIMESTAMPTZ"},
+		"until":      {"until", "TIMESTAMPTZ"},
+	},
+	DefaultSort: "created_at",
+	Filters: []listFilter{
+		inFilter("rule", "rule_name"),
+		addressFilter("address", "address"),
+		activeSnoozeFilter,
+	},
+}
+
+// activeSnoozeFilter restricts snoozes to those not yet expired (active=true) or to the
+// expired ones (active=false)
+func activeSnoozeFilter(c *gin.Context) (func(*gorm.DB) *gorm.DB, error) {
+	value := c.Query("active")
+	if value == "" {
+		return nil, nil
+	}
+	active, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, fmt.Errorf("active must be true or false")
+	}
+	return func(db *gorm.DB) *gorm.DB {
+		if active {
+			return db.Where("until > ?", time.Now())
+		}
+		return db.Where("until <= ?", time.Now())
+	}, nil
+}
+
+// getAlertSnoozes returns a page of alert snoozes
+func getAlertSnoozes(c *gin.Context) {
+	var snoozes []AlertSnooze
+	page, err := listFrom(c).find(db.Model(&AlertSnooze{}), &snoozes)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, ListResponse{Data: snoozes, Pagination: page})
+}
+
+// createAlertSnooze silences the alerts of an address, for one rule or all of them, for a
+// number of minutes or until a time. Violations are still recorded, as suppressed.
+func createAlertSnooze(c *gin.Context) {
+	var req struct {
+		Address string     `json:"address"`
+		Rule    string     `json:"rule"`
+		Minutes int        `json:"minutes"`
+		Until   *time.Time `json:"until"`
+		Reason  string     `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !common.IsHexAddress(req.Address) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid address"})
+		return
+	}
+	if strings.TrimSpace(req.Reason) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "reason required"})
+		return
+	}
+	var until time.Time
+	switch {
+	case req.Until != nil && req.Minutes == 0:
+		until = *req.Until
+	case req.Until == nil && req.Minutes > 0:
+		until = time.Now().Add(time.Duration(req.Minutes) * time.Minute)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "either minutes or until required"})
+		return
+	}
+	if !until.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "until must be in the future"})
+		return
+	}
+	if req.Rule != "" {
+		var rules int64
+		if err := db.Model(&Rule{}).Where("name = ?", req.Rule).Count(&rules).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if rules == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unknown rule %s", req.Rule)})
+			return
+		}
+	}
+
+	snooze := AlertSnooze{
+		Address:   common.HexToAddress(req.Address).Hex(),
+		RuleName:  req.Rule,
+		Until:     until,
+		Reason:    req.Reason,
+		CreatedBy: currentUser(c),
+	}
+	if err := db.Create(&snooze).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, snooze)
+}
+
+// deleteAlertSnooze lifts a snooze
+func deleteAlertSnooze(c *gin.Context) {
+	result := db.Delete(&AlertSnooze{}, c.Param("id"))
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "snooze not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "deleted"})
+}
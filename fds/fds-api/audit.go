@@ -0,0 +1,254 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"token-monitor/audit"
+	monitor "token-monitor/models"
+)
+
+// auditTxHashKey is the context key under which a handler leaves the on-chain transaction of its action
+const auditTxHashKey = "audit_tx_hash"
+
+// auditRedacted are the request fields never written to the audit log
+var auditRedacted = map[string]bool{"password": true}
+
+// auditGenesisHash is the previous hash of the first audit entry
+var auditGenesisHash = audit.GenesisHash
+
+// auditHash returns the hash of an audit entry as the monitor computes it
+func auditHash(entry *AuditLog) string {
+	return audit.Hash((*monitor.AuditLog)(entry))
+}
+
+// appendAudit links an entry to the last one of the audit log and appends it, stamped
+// with the current time
+func appendAudit(entry *AuditLog) error {
+	record := monitor.AuditLog(*entry)
+	record.CreatedAt = time.Now()
+	if err := audit.Append(db, &record); err != nil {
+		return err
+	}
+	*entry = AuditLog(record)
+	return nil
+}
+
+// auditWriter keeps the start of an error response so the audit log can record the error
+type auditWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *auditWriter) Write(data []byte) (int, error) {
+	if w.Status() >= http.StatusBadRequest && w.body.Len() < 4096 {
+		w.body.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// audited records a mutating request in the audit log. The request is refused when it
+// cannot be logged before it runs; its result is logged once the handler has finished.
+func audited(action string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "cannot read request body"})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		params, reason := auditParameters(c, body)
+		requestID := make([]byte, 16)
+		if _, err := rand.Read(requestID); err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		entry := AuditLog{
+			RequestID:  hex.EncodeToString(requestID),
+			Actor:      currentUser(c),
+			Action:     action,
+			Method:     c.Request.Method,
+			Path:       c.Request.URL.Path,
+			Parameters: params,
+			Reason:     reason,
+			Result:     "started",
+		}
+		if principal, ok := c.Get("principal"); ok {
+			entry.Role = principal.(*Principal).Role
+		}
+		if err := appendAudit(&entry); err != nil {
+			log.Printf("Error writing audit log, refusing %s by %s: %v", action, entry.Actor, err)
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "audit log unavailable"})
+			return
+		}
+
+		writer := &auditWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+
+		result := entry
+		result.ID = 0
+		result.Status = writer.Status()
+		result.TxHash = c.GetString(auditTxHashKey)
+		result.Result = "succeeded"
+		if result.Status >= http.StatusBadRequest {
+			result.Result = "failed"
+			var response struct {
+				Error string `json:"error"`
+			}
+			if json.Unmarshal(writer.body.Bytes(), &response) == nil {
+				result.Error = response.Error
+			}
+		}
+		if err := appendAudit(&result); err != nil {
+			log.Printf("Error writing audit log result of %s request %s: %v", action, entry.RequestID, err)
+		}
+	}
+}
+
+// auditParameters returns the path parameters and request body of a request as JSON, with
+// secrets redacted, and the reason or comment given with it
+func auditParameters(c *gin.Context, body []byte) (string, string) {
+	params := make(map[string]interface{})
+	for _, p := range c.Params {
+		params[p.Key] = p.Value
+	}
+	if query := c.Request.URL.RawQuery; query != "" {
+		params["query"] = query
+	}
+
+	reason := ""
+	if len(bytes.TrimSpace(body)) > 0 {
+		var fields map[string]interface{}
+		if err := json.Unmarshal(body, &fields); err == nil {
+			for key := range fields {
+				if auditRedacted[key] {
+					fields[key] = "[redacted]"
+				}
+			}
+			for _, key := range []string{"reason", "comment"} {
+				if value, ok := fields[key].(string); ok && value != "" && reason == "" {
+					reason = value
+				}
+			}
+			params["body"] = fields
+		} else {
+			params["body"] = string(body)
+		}
+	}
+
+	content, err := json.Marshal(params)
+	if err != nil {
+		return "{}", reason
+	}
+	return string(content), reason
+}
+
+// getAuditLog returns audit log entries, newest first, filtered by actor, action, result,
+// request, transaction and a from/to time range (RFC 3339 or YYYY-MM-DD)
+func getAuditLog(c *gin.Context) {
+	query := db.Model(&AuditLog{}).Order("seq DESC")
+	for param, column := range map[string]string{
+		"actor":      "actor",
+		"action":     "action",
+		"result":     "result",
+		"request_id": "request_id",
+		"tx_hash":    "tx_hash",
+	} {
+		if value := c.Query(param); value != "" {
+			query = query.Where(column+" = ?", value)
+		}
+	}
+	for param, op := range map[string]string{"from": ">=", "to": "<"} {
+		if value := c.Query(param); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				day, dayErr := time.Parse("2006-01-02", value)
+				if dayErr != nil {
+					c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid %s time", param)})
+					return
+				}
+				t = day
+				if param == "to" {
+					t = day.AddDate(0, 0, 1)
+				}
+			}
+			query = query.Where("created_at "+op+" ?", t)
+		}
+	}
+
+	limit := 200
+	if value := c.Query("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > 1000 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 1000"})
+			return
+		}
+		limit = n
+	}
+
+	var entries []AuditLog
+	if err := query.Limit(limit).Find(&entries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, entries)
+}
+
+// verifyAuditLog recomputes the hash chain of the whole audit log and reports the first
+// entry that does not match
+func verifyAuditLog(c *gin.Context) {
+	prevHash := auditGenesisHash
+	var seq uint64
+	for {
+		var batch []AuditLog
+		if err := db.Where("seq > ?", seq).Order("seq").Limit(1000).Find(&batch).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if len(batch) == 0 {
+			break
+		}
+		for i := range batch {
+			entry := &batch[i]
+			var problem string
+			switch {
+			case entry.Seq != seq+1:
+				problem = fmt.Sprintf("entries %d to %d are missing", seq+1, entry.Seq-1)
+			case entry.PrevHash != prevHash:
+				problem = "previous hash does not match the previous entry"
+			case auditHash(entry) != entry.Hash:
+				problem = "content does not match its hash"
+			}
+			if problem != "" {
+				log.Printf("Audit log verification failed at entry %d: %s", entry.Seq, problem)
+				c.JSON(http.StatusOK, gin.H{
+					"valid":       false,
+					"checked":     seq,
+					"invalid_seq": entry.Seq,
+					"error":       problem,
+				})
+				return
+			}
+			seq = entry.Seq
+			prevHash = entry.Hash
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"valid":     true,
+		"checked":   seq,
+		"head_hash": prevHash,
+	})
+}
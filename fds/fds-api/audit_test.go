@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestVerifyAuditLog(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cases := []struct {
+		name       string
+		entries    int
+		tamper     func()
+		valid      bool
+		invalidSeq uint64
+	}{
+		{name: "empty log", valid: true},
+		{name: "intact chain", entries: 5, valid: true},
+		{
+			name:    "changed reason",
+			entries: 5,
+			tamper: func() {
+				db.Model(&AuditLog{}).Where("seq = ?", 3).Update("reason", "nothing to see")
+			},
+			invalidSeq: 3,
+		},
+		{
+			name:    "changed and rehashed entry",
+			entries: 5,
+			tamper: func() {
+				var entry AuditLog
+				db.Where("seq = ?", 2).First(&entry)
+				entry.Actor = "someone else"
+				db.Model(&entry).Updates(map[string]interface{}{"actor": entry.Actor, "hash": auditHash(&entry)})
+			},
+			invalidSeq: 3,
+		},
+		{
+			name:    "deleted entry",
+			entries: 5,
+			tamper: func() {
+				db.Where("seq = ?", 4).Delete(&AuditLog{})
+			},
+			invalidSeq: 5,
+		},
+		{
+			name:    "deleted head",
+			entries: 5,
+			tamper: func() {
+				db.Where("seq = ?", 1).Delete(&AuditLog{})
+			},
+			invalidSeq: 2,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			useTestDB(t, &AuditLog{})
+			prevHash := auditGenesisHash
+			start := time.Now().UTC().Truncate(time.Microsecond)
+			for i := 1; i <= tc.entries; i++ {
+				entry := AuditLog{
+					Seq:       uint64(i),
+					CreatedAt: start.Add(time.Duration(i) * time.Second),
+					Actor:     "olga",
+					Action:    "blacklist",
+					Reason:    "sanctioned",
+					Result:    "started",
+					PrevHash:  prevHash,
+				}
+				entry.Hash = auditHash(&entry)
+				if err := db.Create(&entry).Error; err != nil {
+					t.Fatalf("appending entry %d: %v", i, err)
+				}
+				prevHash = entry.Hash
+			}
+			if tc.tamper != nil {
+				tc.tamper()
+			}
+
+			r := gin.New()
+			r.GET("/", verifyAuditLog)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+			var report struct {
+				Valid      bool   `json:"valid"`
+				InvalidSeq uint64 `json:"invalid_seq"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil || w.Code != http.StatusOK {
+				t.Fatalf("status %d: %s", w.Code, w.Body.String())
+			}
+			if report.Valid != tc.valid || report.InvalidSeq != tc.invalidSeq {
+				t.Errorf("valid %v at %d, want %v at %d: %s", report.Valid, report.InvalidSeq, tc.valid, tc.invalidSeq, w.Body.String())
+			}
+		})
+	}
+}
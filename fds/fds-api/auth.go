@@ -0,0 +1,520 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v4"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// Roles, each allowed everything the roles before it are
+const (
+	RoleViewer            = "viewer"             // Reads dashboards, cases and reports
+	RoleAnalyst           = "analyst"            // Works cases and the suspicious address list
+	RoleComplianceOfficer = "compliance_officer" // Blacklists, approves enforcement and files reports
+	RoleAdmin             = "admin"              // Manages rules and users
+)
+
+// roleRank orders roles by privilege; unknown roles rank below viewer
+func roleRank(role string) int {
+	switch role {
+	case RoleViewer:
+		return 1
+	case RoleAnalyst:
+		return 2
+	case RoleComplianceOfficer:
+		return 3
+	case RoleAdmin:
+		return 4
+	}
+	return 0
+}
+
+// localIssuer is the issuer of the tokens fds-api signs for its local users
+const localIssuer = "fds-api"
+
+var (
+	jwtSecret        []byte
+	tokenTTL         time.Duration
+	oidcIssuer       string
+	oidcAudience     string
+	oidcJWKSURL      string
+	oidcRolesClaim   string
+	oidcUserClaim    string
+	oidcRoleMap      map[string]string // Claim value -> role
+	corsAllowOrigins map[string]bool
+	oidcKeys         = &jwksCache{keys: make(map[string]*rsa.PublicKey)}
+)
+
+// loadAuthConfig reads the authentication settings from the environment
+func loadAuthConfig() {
+	jwtSecret = []byte(os.Getenv("AUTH_JWT_SECRET"))
+	if len(jwtSecret) == 0 {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			log.Fatalf("Failed to generate JWT secret: %v", err)
+		}
+		jwtSecret = secret
+		log.Println("AUTH_JWT_SECRET not set, local tokens will not survive a restart")
+	}
+	tokenTTL = time.Duration(getEnvInt("AUTH_TOKEN_TTL_MINUTES", 480)) * time.Minute
+
+	oidcIssuer = strings.TrimSuffix(os.Getenv("OIDC_ISSUER"), "/")
+	oidcAudience = os.Getenv("OIDC_AUDIENCE")
+	oidcJWKSURL = os.Getenv("OIDC_JWKS_URL")
+	if oidcJWKSURL == "" && oidcIssuer != "" {
+		oidcJWKSURL = discoverJWKSURL(oidcIssuer)
+	}
+	oidcRolesClaim = getEnvDefault("OIDC_ROLES_CLAIM", "roles")
+	oidcUserClaim = getEnvDefault("OIDC_USERNAME_CLAIM", "preferred_username")
+	oidcRoleMap = make(map[string]string)
+	for _, pair := range splitList(os.Getenv("OIDC_ROLE_MAP")) {
+		if value, role, ok := strings.Cut(pair, "="); ok && roleRank(role) > 0 {
+			oidcRoleMap[value] = role
+		}
+	}
+
+	corsAllowOrigins = make(map[string]bool)
+	for _, origin := range splitList(os.Getenv("CORS_ALLOWED_ORIGINS")) {
+		corsAllowOrigins[strings.TrimSuffix(origin, "/")] = true
+	}
+}
+
+// getEnvDefault returns an environment variable or a default value
+func getEnvDefault(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvInt returns an environment variable as an integer or a default value
+func getEnvInt(key string, defaultValue int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+// splitList splits a comma-separated list, dropping empty entries
+func splitList(value string) []string {
+	var result []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// corsMiddleware allows the configured origins only. Requests from other origins get no
+// CORS headers, so browsers keep them from reading the responses.
+func corsMiddleware(c *gin.Context) {
+	if origin := c.GetHeader("Origin"); origin != "" && corsAllowOrigins[origin] {
+		c.Writer.Header().Set("Access-Control-Allow-Origin", origin)
+		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
+		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
+	}
+	c.Writer.Header().Add("Vary", "Origin")
+
+	// Disable caching
+	c.Writer.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+	c.Writer.Header().Set("Pragma", "no-cache")
+	c.Writer.Header().Set("Expires", "0")
+
+	if c.Request.Method == "OPTIONS" {
+		c.AbortWithStatus(204)
+		return
+	}
+
+	c.Next()
+}
+
+// Principal is the authenticated caller of a request
+type Principal struct {
+	Username string `json:"username"`
+	Role     string `json:"role"`
+	Source   string `json:"source"` // "local" or "oidc"
+}
+
+// requireRole authenticates the request and lets it through when the caller has at least role
+func requireRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		raw, ok := strings.CutPrefix(header, "Bearer ")
+		if !ok || raw == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "authentication required"})
+			return
+		}
+		principal, err := authenticateToken(raw)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		if roleRank(principal.Role) < roleRank(role) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("requires the %s role", role)})
+			return
+		}
+		c.Set("principal", principal)
+		c.Next()
+	}
+}
+
+// currentUser returns the name of the authenticated caller, which handlers record as the
+// actor of a change
+func currentUser(c *gin.Context) string {
+	if principal, ok := c.Get("principal"); ok {
+		return principal.(*Principal).Username
+	}
+	return ""
+}
+
+// authenticateToken validates a bearer token signed either by fds-api for a local user or
+// by the configured OIDC provider
+func authenticateToken(raw string) (*Principal, error) {
+	claims := jwt.MapClaims{}
+	token, err := jwt.ParseWithClaims(raw, claims, func(token *jwt.Token) (interface{}, error) {
+		switch token.Method.(type) {
+		case *jwt.SigningMethodHMAC:
+			return jwtSecret, nil
+		case *jwt.SigningMethodRSA:
+			if oidcJWKSURL == "" {
+				return nil, fmt.Errorf("OIDC is not configured")
+			}
+			kid, _ := token.Header["kid"].(string)
+			return oidcKeys.key(kid)
+		}
+		return nil, fmt.Errorf("unexpected signing method %v", token.Header["alg"])
+	})
+	if err != nil || !token.Valid || !claims.VerifyExpiresAt(time.Now().Unix(), true) {
+		return nil, fmt.Errorf("invalid token")
+	}
+
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok {
+		if !claims.VerifyIssuer(localIssuer, true) {
+			return nil, fmt.Errorf("invalid token issuer")
+		}
+		username, _ := claims["sub"].(string)
+		var user User
+		if err := db.Where("username = ?", username).First(&user).Error; err != nil || user.Disabled {
+			return nil, fmt.Errorf("unknown or disabled user")
+		}
+		return &Principal{Username: user.Username, Role: user.Role, Source: "local"}, nil
+	}
+
+	if !claims.VerifyIssuer(oidcIssuer, true) || (oidcAudience != "" && !claims.VerifyAudience(oidcAudience, true)) {
+		return nil, fmt.Errorf("invalid token issuer or audience")
+	}
+	username, _ := claims[oidcUserClaim].(string)
+	if username == "" {
+		username, _ = claims["sub"].(string)
+	}
+	role := oidcRole(claims[oidcRolesClaim])
+	if role == "" {
+		return nil, fmt.Errorf("token grants no fds role")
+	}
+	return &Principal{Username: username, Role: role, Source: "oidc"}, nil
+}
+
+// oidcRole returns the highest role granted by the roles claim of an OIDC token, mapping
+// claim values through OIDC_ROLE_MAP; values naming a role grant it directly
+func oidcRole(claim interface{}) string {
+	var values []string
+	switch v := claim.(type) {
+	case string:
+		values = strings.Fields(v)
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+	}
+
+	best := ""
+	for _, value := range values {
+		role, ok := oidcRoleMap[value]
+		if !ok {
+			role = value
+		}
+		if roleRank(role) > roleRank(best) {
+			best = role
+		}
+	}
+	return best
+}
+
+// discoverJWKSURL reads the JWKS location from the issuer's OpenID configuration
+func discoverJWKSURL(issuer string) string {
+	resp, err := http.Get(issuer + "/.well-known/openid-configuration")
+	if err != nil {
+		log.Printf("Failed to fetch OpenID configuration of %s: %v", issuer, err)
+		return ""
+	}
+	defer resp.Body.Close()
+	var config struct {
+		JWKSURI string `json:"jwks_uri"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&config); err != nil {
+		log.Printf("Failed to decode OpenID configuration of %s: %v", issuer, err)
+		return ""
+	}
+	return config.JWKSURI
+}
+
+// jwksCache holds the RSA signing keys of the OIDC provider. Keys are refetched when a
+// token names an unknown key, at most once a minute.
+type jwksCache struct {
+	mu        sync.Mutex
+	keys      map[string]*rsa.PublicKey
+	fetchedAt time.Time
+}
+
+// key returns the signing key kid
+func (j *jwksCache) key(kid string) (*rsa.PublicKey, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if key, ok := j.keys[kid]; ok {
+		return key, nil
+	}
+	if time.Since(j.fetchedAt) > time.Minute {
+		j.fetchedAt = time.Now()
+		if err := j.refresh(); err != nil {
+			log.Printf("Failed to fetch OIDC signing keys: %v", err)
+		}
+	}
+	if key, ok := j.keys[kid]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// refresh fetches the key set from the JWKS endpoint
+func (j *jwksCache) refresh() error {
+	resp, err := http.Get(oidcJWKSURL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	var set struct {
+		Keys []struct {
+			Kid string `json:"kid"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return err
+	}
+
+	keys := make(map[string]*rsa.PublicKey)
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" {
+			continue
+		}
+		n, errN := base64.RawURLEncoding.DecodeString(k.N)
+		e, errE := base64.RawURLEncoding.DecodeString(k.E)
+		if errN != nil || errE != nil {
+			continue
+		}
+		keys[k.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	}
+	j.keys = keys
+	return nil
+}
+
+// bootstrapAdmin creates the admin of AUTH_ADMIN_USERNAME and AUTH_ADMIN_PASSWORD when
+// there are no local users yet
+func bootstrapAdmin() error {
+	username, password := os.Getenv("AUTH_ADMIN_USERNAME"), os.Getenv("AUTH_ADMIN_PASSWORD")
+	if username == "" || password == "" {
+		return nil
+	}
+	var count int64
+	if err := db.Model(&User{}).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	if err := db.Create(&User{Username: username, PasswordHash: string(hash), Role: RoleAdmin}).Error; err != nil {
+		return err
+	}
+	log.Printf("Created initial admin user %s", username)
+	return nil
+}
+
+// login exchanges a local user's credentials for a token
+func login(c *gin.Context) {
+	var req struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || req.Username == "" || req.Password == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "username and password required"})
+		return
+	}
+
+	var user User
+	if err := db.Where("username = ?", req.Username).First(&user).Error; err != nil || user.Disabled ||
+		bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)) != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid username or password"})
+		return
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(tokenTTL)
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"iss":  localIssuer,
+		"sub":  user.Username,
+		"role": user.Role,
+		"iat":  now.Unix(),
+		"exp":  expiresAt.Unix(),
+	}).SignedString(jwtSecret)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	db.Model(&user).Update("last_login_at", now)
+
+	c.JSON(http.StatusOK, gin.H{
+		"token":      token,
+		"expires_at": expiresAt,
+		"username":   user.Username,
+		"role":       user.Role,
+	})
+}
+
+// getCurrentUser returns the authenticated caller
+func getCurrentUser(c *gin.Context) {
+	principal, _ := c.Get("principal")
+	c.JSON(http.StatusOK, principal)
+}
+
+// getUsers returns the local users
+func getUsers(c *gin.Context) {
+	var users []User
+	if err := db.Order("username").Find(&users).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, users)
+}
+
+// UserRequest creates or updates a local user
+type UserRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Role     string `json:"role"`
+	Disabled *bool  `json:"disabled"`
+}
+
+// createUser adds a local user
+func createUser(c *gin.Context) {
+	var req UserRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Username == "" || len(req.Password) < 12 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "username and a password of at least 12 characters required"})
+		return
+	}
+	if roleRank(req.Role) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "role must be one of viewer, analyst, compliance_officer, admin"})
+		return
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	user := User{Username: req.Username, PasswordHash: string(hash), Role: req.Role}
+	if err := db.Create(&user).Error; err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	log.Printf("User %s created %s with role %s", currentUser(c), user.Username, user.Role)
+	c.JSON(http.StatusOK, user)
+}
+
+// updateUser changes the role, password or disabled flag of a local user
+func updateUser(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+	var req UserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var user User
+	if err := db.First(&user, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	updates := map[string]interface{}{}
+	if req.Role != "" {
+		if roleRank(req.Role) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "role must be one of viewer, analyst, compliance_officer, admin"})
+			return
+		}
+		updates["role"] = req.Role
+	}
+	if req.Password != "" {
+		if len(req.Password) < 12 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "password must have at least 12 characters"})
+			return
+		}
+		hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		updates["password_hash"] = string(hash)
+	}
+	if req.Disabled != nil {
+		if *req.Disabled && user.Username == currentUser(c) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "users cannot disable themselves"})
+			return
+		}
+		updates["disabled"] = *req.Disabled
+	}
+	if len(updates) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "nothing to update"})
+		return
+	}
+
+	if err := db.Model(&user).Updates(updates).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	log.Printf("User %s updated %s", currentUser(c), user.Username)
+	c.JSON(http.StatusOK, user)
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// signLocal returns a token fds-api would issue to a local user
+func signLocal(t *testing.T, username string, ttl time.Duration) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"iss": localIssuer,
+		"sub": username,
+		"exp": time.Now().Add(ttl).Unix(),
+	}).SignedString(jwtSecret)
+	if err != nil {
+		t.Fatalf("signing token: %v", err)
+	}
+	return token
+}
+
+// signOIDC returns a token of the OIDC provider signed with key
+func signOIDC(t *testing.T, key *rsa.PrivateKey, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = "test"
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("signing token: %v", err)
+	}
+	return signed
+}
+
+func TestRequireRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	useTestDB(t, &User{})
+	jwtSecret = []byte("test secret")
+	db.Create(&User{Username: "alice", Role: RoleAnalyst})
+	db.Create(&User{Username: "olga", Role: RoleComplianceOfficer})
+	db.Create(&User{Username: "mallory", Role: RoleAdmin, Disabled: true})
+
+	cases := []struct {
+		name   string
+		header string
+		role   string
+		status int
+	}{
+		{"no token", "", RoleViewer, http.StatusUnauthorized},
+		{"not a bearer token", "Basic " + signLocal(t, "alice", time.Hour), RoleViewer, http.StatusUnauthorized},
+		{"malformed token", "Bearer not-a-token", RoleViewer, http.StatusUnauthorized},
+		{"expired token", "Bearer " + signLocal(t, "alice", -time.Minute), RoleViewer, http.StatusUnauthorized},
+		{"unknown user", "Bearer " + signLocal(t, "eve", time.Hour), RoleViewer, http.StatusUnauthorized},
+		{"disabled user", "Bearer " + signLocal(t, "mallory", time.Hour), RoleViewer, http.StatusUnauthorized},
+		{"lower role", "Bearer " + signLocal(t, "alice", time.Hour), RoleComplianceOfficer, http.StatusForbidden},
+		{"same role", "Bearer " + signLocal(t, "alice", time.Hour), RoleAnalyst, http.StatusOK},
+		{"higher role", "Bearer " + signLocal(t, "olga", time.Hour), RoleAnalyst, http.StatusOK},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := gin.New()
+			r.GET("/", requireRole(tc.role), func(c *gin.Context) { c.String(http.StatusOK, currentUser(c)) })
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.header != "" {
+				req.Header.Set("Authorization", tc.header)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != tc.status {
+				t.Errorf("status %d, want %d: %s", w.Code, tc.status, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestOIDCClaimMapping(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+	oidcIssuer, oidcAudience, oidcJWKSURL = "https://idp.example.com", "fds", "https://idp.example.com/jwks"
+	oidcRolesClaim, oidcUserClaim = "groups", "preferred_username"
+	oidcRoleMap = map[string]string{"fds-officers": RoleComplianceOfficer, "fds-readers": RoleViewer}
+	oidcKeys = &jwksCache{keys: map[string]*rsa.PublicKey{"test": &key.PublicKey}, fetchedAt: time.Now()}
+	t.Cleanup(func() {
+		oidcIssuer, oidcAudience, oidcJWKSURL, oidcRoleMap = "", "", "", nil
+		oidcKeys = &jwksCache{keys: make(map[string]*rsa.PublicKey)}
+	})
+
+	claims := func(extra jwt.MapClaims) jwt.MapClaims {
+		c := jwt.MapClaims{"iss": oidcIssuer, "aud": "fds", "sub": "u-1", "exp": time.Now().Add(time.Hour).Unix()}
+		for k, v := range extra {
+			c[k] = v
+		}
+		return c
+	}
+	cases := []struct {
+		name     string
+		token    string
+		username string
+		role     string // Empty when the token is refused
+	}{
+		{
+			name:     "mapped group",
+			token:    signOIDC(t, key, claims(jwt.MapClaims{"preferred_username": "olga", "groups": []interface{}{"fds-officers"}})),
+			username: "olga", role: RoleComplianceOfficer,
+		},
+		{
+			name:     "highest of several groups",
+			token:    signOIDC(t, key, claims(jwt.MapClaims{"groups": []interface{}{"fds-readers", "admin", "other"}})),
+			username: "u-1", role: RoleAdmin,
+		},
+		{
+			name:     "space separated claim",
+			token:    signOIDC(t, key, claims(jwt.MapClaims{"groups": "other analyst"})),
+			username: "u-1", role: RoleAnalyst,
+		},
+		{
+			name:  "no fds role",
+			token: signOIDC(t, key, claims(jwt.MapClaims{"groups": []interface{}{"other"}})),
+		},
+		{
+			name:  "other issuer",
+			token: signOIDC(t, key, claims(jwt.MapClaims{"iss": "https://evil.example.com", "groups": "admin"})),
+		},
+		{
+			name:  "other audience",
+			token: signOIDC(t, key, claims(jwt.MapClaims{"aud": "another-app", "groups": "admin"})),
+		},
+		{
+			name:  "signed with another key",
+			token: signOIDC(t, other, claims(jwt.MapClaims{"groups": "admin"})),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			principal, err := authenticateToken(tc.token)
+			if tc.role == "" {
+				if err == nil {
+					t.Fatalf("token accepted as %+v", principal)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("authenticateToken: %v", err)
+			}
+			if principal.Username != tc.username || principal.Role != tc.role || principal.Source != "oidc" {
+				t.Errorf("principal %+v, want %s as %s from oidc", principal, tc.username, tc.role)
+			}
+		})
+	}
+}
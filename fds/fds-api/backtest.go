@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// backtestRequest is a backtest of candidate rule parameters over a block or date range,
+// as the monitor reads it
+type backtestRequest struct {
+	FromBlock  *uint64                           `json:"from_block,omitempty"`
+	ToBlock    *uint64                           `json:"to_block,omitempty"`
+	Since      *time.Time                        `json:"since,omitempty"`
+	Until      *time.Time                        `json:"until,omitempty"`
+	Parameters map[string]map[string]interface{} `json:"parameters"`
+}
+
+// backtestResponse is a backtest run with its request and, once done, its report
+type backtestResponse struct {
+	*BacktestRun
+	Request json.RawMessage `json:"request"`
+	Report  json.RawMessage `json:"report,omitempty"`
+}
+
+func newBacktestResponse(run *BacktestRun) backtestResponse {
+	resp := backtestResponse{BacktestRun: run, Request: json.RawMessage(run.Request)}
+	if run.Report != "" {
+		resp.Report = json.RawMessage(run.Report)
+	}
+	return resp
+}
+
+// validate checks the range and that the candidate parameters exist on their rules
+func (req *backtestRequest) validate() error {
+	if req.FromBlock == nil && req.ToBlock == nil && req.Since == nil && req.Until == nil {
+		return fmt.Errorf("a block or date range is required")
+	}
+	if req.FromBlock != nil && req.ToBlock != nil && *req.FromBlock > *req.ToBlock {
+		return fmt.Errorf("from_block is after to_block")
+	}
+	if req.Since != nil && req.Until != nil && !req.Since.Before(*req.Until) {
+		return fmt.Errorf("since must be before until")
+	}
+	for name, overrides := range req.Parameters {
+		var rule Rule
+		if err := db.Where("name = ?", name).First(&rule).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return fmt.Errorf("unknown rule %s", name)
+			}
+			return err
+		}
+		var params map[string]interface{}
+		if err := json.Unmarshal([]byte(rule.Parameters), &params); err != nil {
+			return fmt.Errorf("rule %s has invalid parameters", name)
+		}
+		for param := range overrides {
+			if _, ok := params[param]; !ok {
+				return fmt.Errorf("rule %s has no parameter %s", name, param)
+			}
+		}
+	}
+	return nil
+}
+
+// createBacktest queues a backtest replaying the stored transactions of a block or date
+// range with candidate rule parameters. The monitor runs it without touching live data;
+// poll GET /api/rules/backtest/:id for the report.
+func createBacktest(c *gin.Context) {
+	var req backtestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := req.validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	encoded, err := json.Marshal(req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	run := BacktestRun{Status: "pending", RequestedBy: currentUser(c), Request: string(encoded)}
+	if err := db.Create(&run).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusAccepted, newBacktestResponse(&run))
+}
+
+var backtestList = listSpec{
+	Sorts: map[string]listSort{
+		"created_at": {"created_at", "TIMESTAMPTZ"},
+	},
+	DefaultSort: "created_at",
+	Filters: []listFilter{
+		inFilter("status", "status"),
+		inFilter("requested_by", "requested_by"),
+	},
+}
+
+// getBacktests returns a page of backtest runs, without their reports
+func getBacktests(c *gin.Context) {
+	var runs []BacktestRun
+	page, err := listFrom(c).find(db.Model(&BacktestRun{}).Omit("report"), &runs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	data := make([]backtestResponse, len(runs))
+	for i := range runs {
+		data[i] = newBacktestResponse(&runs[i])
+	}
+	c.JSON(http.StatusOK, ListResponse{Data: data, Pagination: page})
+}
+
+// getBacktest returns a backtest run with its report once done
+func getBacktest(c *gin.Context) {
+	var run BacktestRun
+	if err := db.First(&run, c.Param("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "backtest not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, newBacktestResponse(&run))
+}
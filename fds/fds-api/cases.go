@@ -0,0 +1,346 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// caseStatuses are the statuses an officer may set on a case
+var caseStatuses = map[string]bool{
+	"open":                  true,
+	"investigating":         true,
+	"escalated":             true,
+	"closed-false-positive": true,
+	"closed-reported":       true,
+}
+
+// addCaseEvent appends an entry to a case's audit history
+func addCaseEvent(tx *gorm.DB, caseID uint, actor, action, from, to, details string) error {
+	return tx.Create(&CaseEvent{
+		CaseID:    caseID,
+		Actor:     actor,
+		Action:    action,
+		FromValue: from,
+		ToValue:   to,
+		Details:   details,
+	}).Error
+}
+
+// loadCase loads the case of the id path parameter, writing the error response if it fails
+func loadCase(c *gin.Context) (*Case, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid case id"})
+		return nil, false
+	}
+	var kase Case
+	if err := db.First(&kase, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "case not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return nil, false
+	}
+	return &kase, true
+}
+
+// getCases returns cases, optionally filtered by status, assignee or address
+func getCases(c *gin.Context) {
+	query := db.Model(&Case{}).Order("updated_at DESC").Limit(200)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	} else {
+		query = query.Where("status <> ?", "merged")
+	}
+	if assignee := c.Query("assignee"); assignee != "" {
+		query = query.Where("assignee = ?", assignee)
+	}
+	if address := c.Query("address"); address != "" {
+		query = query.Where("id IN (?)", db.Model(&CaseAddress{}).Select("case_id").Where("address = ?", common.HexToAddress(address).Hex()))
+	}
+
+	var cases []Case
+	if err := query.Find(&cases).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, cases)
+}
+
+// getCase returns a case with its addresses, suspicious transfers, violations, notes and attachments
+func getCase(c *gin.Context) {
+	kase, ok := loadCase(c)
+	if !ok {
+		return
+	}
+
+	var addresses []CaseAddress
+	var transfers []SuspiciousTransfer
+	var violations []RuleViolation
+	var notes []CaseNote
+	var attachments []CaseAttachment
+	queries := []*gorm.DB{
+		db.Where("case_id = ?", kase.ID).Order("created_at").Find(&addresses),
+		db.Where("id IN (?)", db.Model(&CaseTransfer{}).Select("suspicious_transfer_id").Where("case_id = ?", kase.ID)).
+			Order("block_number DESC").Find(&transfers),
+		db.Where("id IN (?)", db.Model(&CaseViolation{}).Select("rule_violation_id").Where("case_id = ?", kase.ID)).
+			Order("created_at DESC").Find(&violations),
+		db.Where("case_id = ?", kase.ID).Order("created_at").Find(&notes),
+		db.Where("case_id = ?", kase.ID).Order("created_at").Find(&attachments),
+	}
+	for _, q := range queries {
+		if q.Error != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": q.Error.Error()})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"case":        kase,
+		"addresses":   addresses,
+		"transfers":   transfers,
+		"violations":  violations,
+		"notes":       notes,
+		"attachments": attachments,
+	})
+}
+
+// createCase opens a case manually
+func createCase(c *gin.Context) {
+	var req struct {
+		Title     string   `json:"title"`
+		Summary   string   `json:"summary"`
+		Severity  string   `json:"severity"`
+		Assignee  string   `json:"assignee"`
+		Addresses []string `json:"addresses"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || req.Title == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "title required"})
+		return
+	}
+	actor := currentUser(c)
+	for _, addr := range req.Addresses {
+		if !common.IsHexAddress(addr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid address %s", addr)})
+			return
+		}
+	}
+
+	kase := Case{Title: req.Title, Summary: req.Summary, Severity: req.Severity, Assignee: req.Assignee, Status: "open"}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&kase).Error; err != nil {
+			return err
+		}
+		if err := addCaseEvent(tx, kase.ID, actor, "created", "", "open", "Opened manually"); err != nil {
+			return err
+		}
+		for _, addr := range req.Addresses {
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+				Create(&CaseAddress{CaseID: kase.ID, Address: common.HexToAddress(addr).Hex()}).Error; err != nil {
+				return err
+			}
+			if err := addCaseEvent(tx, kase.ID, actor, "address_added", "", common.HexToAddress(addr).Hex(), ""); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, kase)
+}
+
+// updateCase changes the status, assignee, severity, title or summary of a case, recording
+// each change in its history
+func updateCase(c *gin.Context) {
+	kase, ok := loadCase(c)
+	if !ok {
+		return
+	}
+	var req struct {
+		Status   *string `json:"status"`
+		Assignee *string `json:"assignee"`
+		Severity *string `json:"severity"`
+		Title    *string `json:"title"`
+		Summary  *string `json:"summary"`
+		Comment  string  `json:"comment"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	actor := currentUser(c)
+	if kase.Status == "merged" {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("case was merged into case %d", *kase.MergedIntoID)})
+		return
+	}
+	if req.Status != nil && !caseStatuses[*req.Status] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be one of open, investigating, escalated, closed-false-positive, closed-reported"})
+		return
+	}
+
+	updates := map[string]interface{}{}
+	type change struct{ action, field, from, to string }
+	var changes []change
+	for _, f := range []struct {
+		action, field, current string
+		value                  *string
+	}{
+		{"status_changed", "status", kase.Status, req.Status},
+		{"assigned", "assignee", kase.Assignee, req.Assignee},
+		{"severity_changed", "severity", kase.Severity, req.Severity},
+		{"title_changed", "title", kase.Title, req.Title},
+		{"summary_changed", "summary", kase.Summary, req.Summary},
+	} {
+		if f.value != nil && *f.value != f.current {
+			updates[f.field] = *f.value
+			changes = append(changes, change{f.action, f.field, f.current, *f.value})
+		}
+	}
+	if len(updates) == 0 {
+		c.JSON(http.StatusOK, kase)
+		return
+	}
+	if req.Status != nil {
+		if strings.HasPrefix(*req.Status, "closed-") {
+			now := time.Now()
+			updates["closed_at"] = &now
+		} else {
+			updates["closed_at"] = nil
+		}
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(kase).Updates(updates).Error; err != nil {
+			return err
+		}
+		for _, ch := range changes {
+			if err := addCaseEvent(tx, kase.ID, actor, ch.action, ch.from, ch.to, req.Comment); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, kase)
+}
+
+// addCaseNote adds an investigator's note to a case
+func addCaseNote(c *gin.Context) {
+	kase, ok := loadCase(c)
+	if !ok {
+		return
+	}
+	var req struct {
+		Body string `json:"body"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || req.Body == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "body required"})
+		return
+	}
+
+	note := CaseNote{CaseID: kase.ID, Author: currentUser(c), Body: req.Body}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&note).Error; err != nil {
+			return err
+		}
+		return addCaseEvent(tx, kase.ID, note.Author, "note_added", "", fmt.Sprintf("%d", note.ID), "")
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, note)
+}
+
+// addCaseAttachment records the metadata of a file attached to a case
+func addCaseAttachment(c *gin.Context) {
+	kase, ok := loadCase(c)
+	if !ok {
+		return
+	}
+	var req CaseAttachment
+	if err := c.ShouldBindJSON(&req); err != nil || req.FileName == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file_name required"})
+		return
+	}
+	req.UploadedBy = currentUser(c)
+
+	attachment := CaseAttachment{
+		CaseID:      kase.ID,
+		FileName:    req.FileName,
+		ContentType: req.ContentType,
+		Size:        req.Size,
+		StorageURI:  req.StorageURI,
+		SHA256:      req.SHA256,
+		UploadedBy:  req.UploadedBy,
+	}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&attachment).Error; err != nil {
+			return err
+		}
+		return addCaseEvent(tx, kase.ID, req.UploadedBy, "attachment_added", "", req.FileName, req.SHA256)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, attachment)
+}
+
+// addCaseAddress links an address to a case
+func addCaseAddress(c *gin.Context) {
+	kase, ok := loadCase(c)
+	if !ok {
+		return
+	}
+	var req struct {
+		Address string `json:"address"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || !common.IsHexAddress(req.Address) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "address required"})
+		return
+	}
+
+	address := common.HexToAddress(req.Address).Hex()
+	err := db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&CaseAddress{CaseID: kase.ID, Address: address})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return addCaseEvent(tx, kase.ID, currentUser(c), "address_added", "", address, "")
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "added", "case_id": kase.ID, "address": address})
+}
+
+// getCaseHistory returns the audit history of a case
+func getCaseHistory(c *gin.Context) {
+	kase, ok := loadCase(c)
+	if !ok {
+		return
+	}
+	var events []CaseEvent
+	if err := db.Where("case_id = ?", kase.ID).Order("created_at, id").Find(&events).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, events)
+}
@@ -29,9 +29,17 @@ var (
 	_ = abi.ConvertType
 )
 
+// AddressRestrictionComplianceRestrictionInfo is an auto generated low-level Go binding around an user-defined struct.
+type AddressRestrictionComplianceRestrictionInfo struct {
+	IsRestricted bool
+	Reason       string
+	Timestamp    *big.Int
+	RestrictedBy common.Address
+}
+
 // ContractsMetaData contains all meta data concerning the Contracts contract.
 var ContractsMetaData = &bind.MetaData{
-	ABI: "[{\"type\":\"constructor\",\"inputs\":[],\"stateMutability\":\"nonpayable\"},{\"type\":\"function\",\"name\":\"BLACKLIST_ADMIN_ROLE\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"bytes32\",\"internalType\":\"bytes32\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"DEFAULT_ADMIN_ROLE\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"bytes32\",\"internalType\":\"bytes32\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"blacklist\",\"inputs\":[{\"name\":\"accounts\",\"type\":\"address[]\",\"internalType\":\"address[]\"}],\"outputs\":[],\"stateMutability\":\"nonpayable\"},{\"type\":\"function\",\"name\":\"blacklistFrom\",\"inputs\":[{\"name\":\"accounts\",\"type\":\"address[]\",\"internalType\":\"address[]\"}],\"outputs\":[],\"stateMutability\":\"nonpayable\"},{\"type\":\"function\",\"name\":\"blacklistTo\",\"inputs\":[{\"name\":\"accounts\",\"type\":\"address[]\",\"internalType\":\"address[]\"}],\"outputs\":[],\"stateMutability\":\"nonpayable\"},{\"type\":\"function\",\"name\":\"canTransfer\",\"inputs\":[{\"name\":\"from\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"to\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"amount\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"outputs\":[{\"name\":\"\",\"type\":\"bool\",\"internalType\":\"bool\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"canTransferWithFailureReason\",\"inputs\":[{\"name\":\"from\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"to\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"outputs\":[{\"name\":\"\",\"type\":\"bool\",\"internalType\":\"bool\"},{\"name\":\"\",\"type\":\"string\",\"internalType\":\"string\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"getRoleAdmin\",\"inputs\":[{\"name\":\"role\",\"type\":\"bytes32\",\"internalType\":\"bytes32\"}],\"outputs\":[{\"name\":\"\",\"type\":\"bytes32\",\"internalType\":\"bytes32\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"grantRole\",\"inputs\":[{\"name\":\"role\",\"type\":\"bytes32\",\"internalType\":\"bytes32\"},{\"name\":\"account\",\"type\":\"address\",\"internalType\":\"address\"}],\"outputs\":[],\"stateMutability\":\"nonpayable\"},{\"type\":\"function\",\"name\":\"hasRole\",\"inputs\":[{\"name\":\"role\",\"type\":\"bytes32\",\"internalType\":\"bytes32\"},{\"name\":\"account\",\"type\":\"address\",\"internalType\":\"address\"}],\"outputs\":[{\"name\":\"\",\"type\":\"bool\",\"internalType\":\"bool\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"isBlacklisted\",\"inputs\":[{\"name\":\"account\",\"type\":\"address\",\"internalType\":\"address\"}],\"outputs\":[{\"name\":\"\",\"type\":\"bool\",\"internalType\":\"bool\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"isBlacklistedFrom\",\"inputs\":[{\"name\":\"account\",\"type\":\"address\",\"internalType\":\"address\"}],\"outputs\":[{\"name\":\"\",\"type\":\"bool\",\"internalType\":\"bool\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"isBlacklistedTo\",\"inputs\":[{\"name\":\"account\",\"type\":\"address\",\"internalType\":\"address\"}],\"outputs\":[{\"name\":\"\",\"type\":\"bool\",\"internalType\":\"bool\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"renounceRole\",\"inputs\":[{\"name\":\"role\",\"type\":\"bytes32\",\"internalType\":\"bytes32\"},{\"name\":\"callerConfirmation\",\"type\":\"address\",\"internalType\":\"address\"}],\"outputs\":[],\"stateMutability\":\"nonpayable\"},{\"type\":\"function\",\"name\":\"revokeRole\",\"inputs\":[{\"name\":\"role\",\"type\":\"bytes32\",\"internalType\":\"bytes32\"},{\"name\":\"account\",\"type\":\"address\",\"internalType\":\"address\"}],\"outputs\":[],\"stateMutability\":\"nonpayable\"},{\"type\":\"function\",\"name\":\"supportsInterface\",\"inputs\":[{\"name\":\"interfaceId\",\"type\":\"bytes4\",\"internalType\":\"bytes4\"}],\"outputs\":[{\"name\":\"\",\"type\":\"bool\",\"internalType\":\"bool\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"unblacklist\",\"inputs\":[{\"name\":\"accounts\",\"type\":\"address[]\",\"internalType\":\"address[]\"}],\"outputs\":[],\"stateMutability\":\"nonpayable\"},{\"type\":\"function\",\"name\":\"unblacklistFrom\",\"inputs\":[{\"name\":\"accounts\",\"type\":\"address[]\",\"internalType\":\"address[]\"}],\"outputs\":[],\"stateMutability\":\"nonpayable\"},{\"type\":\"function\",\"name\":\"unblacklistTo\",\"inputs\":[{\"name\":\"accounts\",\"type\":\"address[]\",\"internalType\":\"address[]\"}],\"outputs\":[],\"stateMutability\":\"nonpayable\"},{\"type\":\"event\",\"name\":\"AddressBlacklisted\",\"inputs\":[{\"name\":\"account\",\"type\":\"address\",\"indexed\":true,\"internalType\":\"address\"}],\"anonymous\":false},{\"type\":\"event\",\"name\":\"AddressBlacklistedFrom\",\"inputs\":[{\"name\":\"account\",\"type\":\"address\",\"indexed\":true,\"internalType\":\"address\"}],\"anonymous\":false},{\"type\":\"event\",\"name\":\"AddressBlacklistedTo\",\"inputs\":[{\"name\":\"account\",\"type\":\"address\",\"indexed\":true,\"internalType\":\"address\"}],\"anonymous\":false},{\"type\":\"event\",\"name\":\"AddressUnblacklisted\",\"inputs\":[{\"name\":\"account\",\"type\":\"address\",\"indexed\":true,\"internalType\":\"address\"}],\"anonymous\":false},{\"type\":\"event\",\"name\":\"AddressUnblacklistedFrom\",\"inputs\":[{\"name\":\"account\",\"type\":\"address\",\"indexed\":true,\"internalType\":\"address\"}],\"anonymous\":false},{\"type\":\"event\",\"name\":\"AddressUnblacklistedTo\",\"inputs\":[{\"name\":\"account\",\"type\":\"address\",\"indexed\":true,\"internalType\":\"address\"}],\"anonymous\":false},{\"type\":\"event\",\"name\":\"RoleAdminChanged\",\"inputs\":[{\"name\":\"role\",\"type\":\"bytes32\",\"indexed\":true,\"internalType\":\"bytes32\"},{\"name\":\"previousAdminRole\",\"type\":\"bytes32\",\"indexed\":true,\"internalType\":\"bytes32\"},{\"name\":\"newAdminRole\",\"type\":\"bytes32\",\"indexed\":true,\"internalType\":\"bytes32\"}],\"anonymous\":false},{\"type\":\"event\",\"name\":\"RoleGranted\",\"inputs\":[{\"name\":\"role\",\"type\":\"bytes32\",\"indexed\":true,\"internalType\":\"bytes32\"},{\"name\":\"account\",\"type\":\"address\",\"indexed\":true,\"internalType\":\"address\"},{\"name\":\"sender\",\"type\":\"address\",\"indexed\":true,\"internalType\":\"address\"}],\"anonymous\":false},{\"type\":\"event\",\"name\":\"RoleRevoked\",\"inputs\":[{\"name\":\"role\",\"type\":\"bytes32\",\"indexed\":true,\"internalType\":\"bytes32\"},{\"name\":\"account\",\"type\":\"address\",\"indexed\":true,\"internalType\":\"address\"},{\"name\":\"sender\",\"type\":\"address\",\"indexed\":true,\"internalType\":\"address\"}],\"anonymous\":false},{\"type\":\"error\",\"name\":\"AccessControlBadConfirmation\",\"inputs\":[]},{\"type\":\"error\",\"name\":\"AccessControlUnauthorizedAccount\",\"inputs\":[{\"name\":\"account\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"neededRole\",\"type\":\"bytes32\",\"internalType\":\"bytes32\"}]},{\"type\":\"error\",\"name\":\"AddressAlreadyBlacklistedFrom\",\"inputs\":[{\"name\":\"account\",\"type\":\"address\",\"internalType\":\"address\"}]},{\"type\":\"error\",\"name\":\"AddressAlreadyBlacklistedTo\",\"inputs\":[{\"name\":\"account\",\"type\":\"address\",\"internalType\":\"address\"}]},{\"type\":\"error\",\"name\":\"AddressNotBlacklistedFrom\",\"inputs\":[{\"name\":\"account\",\"type\":\"address\",\"internalType\":\"address\"}]},{\"type\":\"error\",\"name\":\"AddressNotBlacklistedTo\",\"inputs\":[{\"name\":\"account\",\"type\":\"address\",\"internalType\":\"address\"}]},{\"type\":\"error\",\"name\":\"EmptyAddressList\",\"inputs\":[]},{\"type\":\"error\",\"name\":\"ZeroAddress\",\"inputs\":[]}]",
+	ABI: "[{\"type\":\"constructor\",\"inputs\":[],\"stateMutability\":\"nonpayable\"},{\"type\":\"function\",\"name\":\"BLACKLIST_ADMIN_ROLE\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"bytes32\",\"internalType\":\"bytes32\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"DEFAULT_ADMIN_ROLE\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"bytes32\",\"internalType\":\"bytes32\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"blacklist\",\"inputs\":[{\"name\":\"accounts\",\"type\":\"address[]\",\"internalType\":\"address[]\"},{\"name\":\"reason\",\"type\":\"string\",\"internalType\":\"string\"}],\"outputs\":[],\"stateMutability\":\"nonpayable\"},{\"type\":\"function\",\"name\":\"blacklistFrom\",\"inputs\":[{\"name\":\"accounts\",\"type\":\"address[]\",\"internalType\":\"address[]\"},{\"name\":\"reason\",\"type\":\"string\",\"internalType\":\"string\"}],\"outputs\":[],\"stateMutability\":\"nonpayable\"},{\"type\":\"function\",\"name\":\"blacklistTo\",\"inputs\":[{\"name\":\"accounts\",\"type\":\"address[]\",\"internalType\":\"address[]\"},{\"name\":\"reason\",\"type\":\"string\",\"internalType\":\"string\"}],\"outputs\":[],\"stateMutability\":\"nonpayable\"},{\"type\":\"function\",\"name\":\"unblacklist\",\"inputs\":[{\"name\":\"accounts\",\"type\":\"address[]\",\"internalType\":\"address[]\"},{\"name\":\"reason\",\"type\":\"string\",\"internalType\":\"string\"}],\"outputs\":[],\"stateMutability\":\"nonpayable\"},{\"type\":\"function\",\"name\":\"unblacklistFrom\",\"inputs\":[{\"name\":\"accounts\",\"type\":\"address[]\",\"internalType\":\"address[]\"},{\"name\":\"reason\",\"type\":\"string\",\"internalType\":\"string\"}],\"outputs\":[],\"stateMutability\":\"nonpayable\"},{\"type\":\"function\",\"name\":\"unblacklistTo\",\"inputs\":[{\"name\":\"accounts\",\"type\":\"address[]\",\"internalType\":\"address[]\"},{\"name\":\"reason\",\"type\":\"string\",\"internalType\":\"string\"}],\"outputs\":[],\"stateMutability\":\"nonpayable\"},{\"type\":\"function\",\"name\":\"blacklistWithReasons\",\"inputs\":[{\"name\":\"accounts\",\"type\":\"address[]\",\"internalType\":\"address[]\"},{\"name\":\"reasons\",\"type\":\"string[]\",\"internalType\":\"string[]\"}],\"outputs\":[],\"stateMutability\":\"nonpayable\"},{\"type\":\"function\",\"name\":\"blacklistFromWithReasons\",\"inputs\":[{\"name\":\"accounts\",\"type\":\"address[]\",\"internalType\":\"address[]\"},{\"name\":\"reasons\",\"type\":\"string[]\",\"internalType\":\"string[]\"}],\"outputs\":[],\"stateMutability\":\"nonpayable\"},{\"type\":\"function\",\"name\":\"blacklistToWithReasons\",\"inputs\":[{\"name\":\"accounts\",\"type\":\"address[]\",\"internalType\":\"address[]\"},{\"name\":\"reasons\",\"type\":\"string[]\",\"internalType\":\"string[]\"}],\"outputs\":[],\"stateMutability\":\"nonpayable\"},{\"type\":\"function\",\"name\":\"canTransfer\",\"inputs\":[{\"name\":\"from\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"to\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"amount\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"outputs\":[{\"name\":\"\",\"type\":\"bool\",\"internalType\":\"bool\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"canTransferWithFailureReason\",\"inputs\":[{\"name\":\"from\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"to\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"outputs\":[{\"name\":\"\",\"type\":\"bool\",\"internalType\":\"bool\"},{\"name\":\"\",\"type\":\"string\",\"internalType\":\"string\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"canTransferWithType\",\"inputs\":[{\"name\":\"from\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"to\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"amount\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"txType\",\"type\":\"uint8\",\"internalType\":\"enumTxType\"}],\"outputs\":[{\"name\":\"\",\"type\":\"bool\",\"internalType\":\"bool\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"canTransferWithTypeAndFailureReason\",\"inputs\":[{\"name\":\"from\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"to\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"amount\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"txType\",\"type\":\"uint8\",\"internalType\":\"enumTxType\"}],\"outputs\":[{\"name\":\"\",\"type\":\"bool\",\"internalType\":\"bool\"},{\"name\":\"\",\"type\":\"string\",\"internalType\":\"string\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"getRestrictionInfoFrom\",\"inputs\":[{\"name\":\"account\",\"type\":\"address\",\"internalType\":\"address\"}],\"outputs\":[{\"name\":\"\",\"type\":\"tuple\",\"internalType\":\"structAddressRestrictionCompliance.RestrictionInfo\",\"components\":[{\"name\":\"isRestricted\",\"type\":\"bool\",\"internalType\":\"bool\"},{\"name\":\"reason\",\"type\":\"string\",\"internalType\":\"string\"},{\"name\":\"timestamp\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"restrictedBy\",\"type\":\"address\",\"internalType\":\"address\"}]}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"getRestrictionInfoTo\",\"inputs\":[{\"name\":\"account\",\"type\":\"address\",\"internalType\":\"address\"}],\"outputs\":[{\"name\":\"\",\"type\":\"tuple\",\"internalType\":\"structAddressRestrictionCompliance.RestrictionInfo\",\"components\":[{\"name\":\"isRestricted\",\"type\":\"bool\",\"internalType\":\"bool\"},{\"name\":\"reason\",\"type\":\"string\",\"internalType\":\"string\"},{\"name\":\"timestamp\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"restrictedBy\",\"type\":\"address\",\"internalType\":\"address\"}]}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"getRestrictionReasonFrom\",\"inputs\":[{\"name\":\"account\",\"type\":\"address\",\"internalType\":\"address\"}],\"outputs\":[{\"name\":\"\",\"type\":\"string\",\"internalType\":\"string\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"getRestrictionReasonTo\",\"inputs\":[{\"name\":\"account\",\"type\":\"address\",\"internalType\":\"address\"}],\"outputs\":[{\"name\":\"\",\"type\":\"string\",\"internalType\":\"string\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"getRoleAdmin\",\"inputs\":[{\"name\":\"role\",\"type\":\"bytes32\",\"internalType\":\"bytes32\"}],\"outputs\":[{\"name\":\"\",\"type\":\"bytes32\",\"internalType\":\"bytes32\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"grantRole\",\"inputs\":[{\"name\":\"role\",\"type\":\"bytes32\",\"internalType\":\"bytes32\"},{\"name\":\"account\",\"type\":\"address\",\"internalType\":\"address\"}],\"outputs\":[],\"stateMutability\":\"nonpayable\"},{\"type\":\"function\",\"name\":\"hasRole\",\"inputs\":[{\"name\":\"role\",\"type\":\"bytes32\",\"internalType\":\"bytes32\"},{\"name\":\"account\",\"type\":\"address\",\"internalType\":\"address\"}],\"outputs\":[{\"name\":\"\",\"type\":\"bool\",\"internalType\":\"bool\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"initialize\",\"inputs\":[],\"outputs\":[],\"stateMutability\":\"nonpayable\"},{\"type\":\"function\",\"name\":\"isBlacklisted\",\"inputs\":[{\"name\":\"account\",\"type\":\"address\",\"internalType\":\"address\"}],\"outputs\":[{\"name\":\"\",\"type\":\"bool\",\"internalType\":\"bool\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"isBlacklistedFrom\",\"inputs\":[{\"name\":\"account\",\"type\":\"address\",\"internalType\":\"address\"}],\"outputs\":[{\"name\":\"\",\"type\":\"bool\",\"internalType\":\"bool\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"isBlacklistedTo\",\"inputs\":[{\"name\":\"account\",\"type\":\"address\",\"internalType\":\"address\"}],\"outputs\":[{\"name\":\"\",\"type\":\"bool\",\"internalType\":\"bool\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"name\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"string\",\"internalType\":\"string\"}],\"stateMutability\":\"pure\"},{\"type\":\"function\",\"name\":\"renounceRole\",\"inputs\":[{\"name\":\"role\",\"type\":\"bytes32\",\"internalType\":\"bytes32\"},{\"name\":\"callerConfirmation\",\"type\":\"address\",\"internalType\":\"address\"}],\"outputs\":[],\"stateMutability\":\"nonpayable\"},{\"type\":\"function\",\"name\":\"revokeRole\",\"inputs\":[{\"name\":\"role\",\"type\":\"bytes32\",\"internalType\":\"bytes32\"},{\"name\":\"account\",\"type\":\"address\",\"internalType\":\"address\"}],\"outputs\":[],\"stateMutability\":\"nonpayable\"},{\"type\":\"function\",\"name\":\"supportsInterface\",\"inputs\":[{\"name\":\"interfaceId\",\"type\":\"bytes4\",\"internalType\":\"bytes4\"}],\"outputs\":[{\"name\":\"\",\"type\":\"bool\",\"internalType\":\"bool\"}],\"stateMutability\":\"view\"},{\"type\":\"event\",\"name\":\"AddressBlacklisted\",\"inputs\":[{\"name\":\"account\",\"type\":\"address\",\"internalType\":\"address\",\"indexed\":true},{\"name\":\"reason\",\"type\":\"string\",\"internalType\":\"string\",\"indexed\":false},{\"name\":\"restrictedBy\",\"type\":\"address\",\"internalType\":\"address\",\"indexed\":true},{\"name\":\"timestamp\",\"type\":\"uint256\",\"internalType\":\"uint256\",\"indexed\":false}],\"anonymous\":false},{\"type\":\"event\",\"name\":\"AddressBlacklistedFrom\",\"inputs\":[{\"name\":\"account\",\"type\":\"address\",\"internalType\":\"address\",\"indexed\":true},{\"name\":\"reason\",\"type\":\"string\",\"internalType\":\"string\",\"indexed\":false},{\"name\":\"restrictedBy\",\"type\":\"address\",\"internalType\":\"address\",\"indexed\":true},{\"name\":\"timestamp\",\"type\":\"uint256\",\"internalType\":\"uint256\",\"indexed\":false}],\"anonymous\":false},{\"type\":\"event\",\"name\":\"AddressBlacklistedTo\",\"inputs\":[{\"name\":\"account\",\"type\":\"address\",\"internalType\":\"address\",\"indexed\":true},{\"name\":\"reason\",\"type\":\"string\",\"internalType\":\"string\",\"indexed\":false},{\"name\":\"restrictedBy\",\"type\":\"address\",\"internalType\":\"address\",\"indexed\":true},{\"name\":\"timestamp\",\"type\":\"uint256\",\"internalType\":\"uint256\",\"indexed\":false}],\"anonymous\":false},{\"type\":\"event\",\"name\":\"AddressUnblacklisted\",\"inputs\":[{\"name\":\"account\",\"type\":\"address\",\"internalType\":\"address\",\"indexed\":true},{\"name\":\"reason\",\"type\":\"string\",\"internalType\":\"string\",\"indexed\":false},{\"name\":\"unrestrictedBy\",\"type\":\"address\",\"internalType\":\"address\",\"indexed\":true},{\"name\":\"timestamp\",\"type\":\"uint256\",\"internalType\":\"uint256\",\"indexed\":false}],\"anonymous\":false},{\"type\":\"event\",\"name\":\"AddressUnblacklistedFrom\",\"inputs\":[{\"name\":\"account\",\"type\":\"address\",\"internalType\":\"address\",\"indexed\":true},{\"name\":\"reason\",\"type\":\"string\",\"internalType\":\"string\",\"indexed\":false},{\"name\":\"unrestrictedBy\",\"type\":\"address\",\"internalType\":\"address\",\"indexed\":true},{\"name\":\"timestamp\",\"type\":\"uint256\",\"internalType\":\"uint256\",\"indexed\":false}],\"anonymous\":false},{\"type\":\"event\",\"name\":\"AddressUnblacklistedTo\",\"inputs\":[{\"name\":\"account\",\"type\":\"address\",\"internalType\":\"address\",\"indexed\":true},{\"name\":\"reason\",\"type\":\"string\",\"internalType\":\"string\",\"indexed\":false},{\"name\":\"unrestrictedBy\",\"type\":\"address\",\"internalType\":\"address\",\"indexed\":true},{\"name\":\"timestamp\",\"type\":\"uint256\",\"internalType\":\"uint256\",\"indexed\":false}],\"anonymous\":false},{\"type\":\"event\",\"name\":\"Initialized\",\"inputs\":[{\"name\":\"version\",\"type\":\"uint64\",\"internalType\":\"uint64\",\"indexed\":false}],\"anonymous\":false},{\"type\":\"event\",\"name\":\"RoleAdminChanged\",\"inputs\":[{\"name\":\"role\",\"type\":\"bytes32\",\"internalType\":\"bytes32\",\"indexed\":true},{\"name\":\"previousAdminRole\",\"type\":\"bytes32\",\"internalType\":\"bytes32\",\"indexed\":true},{\"name\":\"newAdminRole\",\"type\":\"bytes32\",\"internalType\":\"bytes32\",\"indexed\":true}],\"anonymous\":false},{\"type\":\"event\",\"name\":\"RoleGranted\",\"inputs\":[{\"name\":\"role\",\"type\":\"bytes32\",\"internalType\":\"bytes32\",\"indexed\":true},{\"name\":\"account\",\"type\":\"address\",\"internalType\":\"address\",\"indexed\":true},{\"name\":\"sender\",\"type\":\"address\",\"internalType\":\"address\",\"indexed\":true}],\"anonymous\":false},{\"type\":\"event\",\"name\":\"RoleRevoked\",\"inputs\":[{\"name\":\"role\",\"type\":\"bytes32\",\"internalType\":\"bytes32\",\"indexed\":true},{\"name\":\"account\",\"type\":\"address\",\"internalType\":\"address\",\"indexed\":true},{\"name\":\"sender\",\"type\":\"address\",\"internalType\":\"address\",\"indexed\":true}],\"anonymous\":false},{\"type\":\"error\",\"name\":\"AccessControlBadConfirmation\",\"inputs\":[]},{\"type\":\"error\",\"name\":\"AccessControlUnauthorizedAccount\",\"inputs\":[{\"name\":\"account\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"neededRole\",\"type\":\"bytes32\",\"internalType\":\"bytes32\"}]},{\"type\":\"error\",\"name\":\"AddressAlreadyBlacklistedFrom\",\"inputs\":[{\"name\":\"account\",\"type\":\"address\",\"internalType\":\"address\"}]},{\"type\":\"error\",\"name\":\"AddressAlreadyBlacklistedTo\",\"inputs\":[{\"name\":\"account\",\"type\":\"address\",\"internalType\":\"address\"}]},{\"type\":\"error\",\"name\":\"AddressNotBlacklistedFrom\",\"inputs\":[{\"name\":\"account\",\"type\":\"address\",\"internalType\":\"address\"}]},{\"type\":\"error\",\"name\":\"AddressNotBlacklistedTo\",\"inputs\":[{\"name\":\"account\",\"type\":\"address\",\"internalType\":\"address\"}]},{\"type\":\"error\",\"name\":\"ArrayLengthMismatch\",\"inputs\":[]},{\"type\":\"error\",\"name\":\"EmptyAddressList\",\"inputs\":[]},{\"type\":\"error\",\"name\":\"EmptyReason\",\"inputs\":[]},{\"type\":\"error\",\"name\":\"InvalidInitialization\",\"inputs\":[]},{\"type\":\"error\",\"name\":\"NotInitializing\",\"inputs\":[]},{\"type\":\"error\",\"name\":\"ZeroAddress\",\"inputs\":[]}]",
 }
 
 // ContractsABI is the input ABI used to generate the binding from.
@@ -305,6 +313,193 @@ func (_Contracts *ContractsCallerSession) CanTransferWithFailureReason(from comm
 	return _Contracts.Contract.CanTransferWithFailureReason(&_Contracts.CallOpts, from, to, arg2)
 }
 
+// CanTransferWithType is a free data retrieval call binding the contract method 0xb4f90e97.
+//
+// Solidity: function canTransferWithType(address from, address to, uint256 amount, uint8 txType) view returns(bool)
+func (_Contracts *ContractsCaller) CanTransferWithType(opts *bind.CallOpts, from common.Address, to common.Address, amount *big.Int, txType uint8) (bool, error) {
+	var out []interface{}
+	err := _Contracts.contract.Call(opts, &out, "canTransferWithType", from, to, amount, txType)
+
+	if err != nil {
+		return *new(bool), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(bool)).(*bool)
+
+	return out0, err
+
+}
+
+// CanTransferWithType is a free data retrieval call binding the contract method 0xb4f90e97.
+//
+// Solidity: function canTransferWithType(address from, address to, uint256 amount, uint8 txType) view returns(bool)
+func (_Contracts *ContractsSession) CanTransferWithType(from common.Address, to common.Address, amount *big.Int, txType uint8) (bool, error) {
+	return _Contracts.Contract.CanTransferWithType(&_Contracts.CallOpts, from, to, amount, txType)
+}
+
+// CanTransferWithType is a free data retrieval call binding the contract method 0xb4f90e97.
+//
+// Solidity: function canTransferWithType(address from, address to, uint256 amount, uint8 txType) view returns(bool)
+func (_Contracts *ContractsCallerSession) CanTransferWithType(from common.Address, to common.Address, amount *big.Int, txType uint8) (bool, error) {
+	return _Contracts.Contract.CanTransferWithType(&_Contracts.CallOpts, from, to, amount, txType)
+}
+
+// CanTransferWithTypeAndFailureReason is a free data retrieval call binding the contract method 0xaa245c76.
+//
+// Solidity: function canTransferWithTypeAndFailureReason(address from, address to, uint256 amount, uint8 txType) view returns(bool, string)
+func (_Contracts *ContractsCaller) CanTransferWithTypeAndFailureReason(opts *bind.CallOpts, from common.Address, to common.Address, amount *big.Int, txType uint8) (bool, string, error) {
+	var out []interface{}
+	err := _Contracts.contract.Call(opts, &out, "canTransferWithTypeAndFailureReason", from, to, amount, txType)
+
+	if err != nil {
+		return *new(bool), *new(string), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(bool)).(*bool)
+	out1 := *abi.ConvertType(out[1], new(string)).(*string)
+
+	return out0, out1, err
+
+}
+
+// CanTransferWithTypeAndFailureReason is a free data retrieval call binding the contract method 0xaa245c76.
+//
+// Solidity: function canTransferWithTypeAndFailureReason(address from, address to, uint256 amount, uint8 txType) view returns(bool, string)
+func (_Contracts *ContractsSession) CanTransferWithTypeAndFailureReason(from common.Address, to common.Address, amount *big.Int, txType uint8) (bool, string, error) {
+	return _Contracts.Contract.CanTransferWithTypeAndFailureReason(&_Contracts.CallOpts, from, to, amount, txType)
+}
+
+// CanTransferWithTypeAndFailureReason is a free data retrieval call binding the contract method 0xaa245c76.
+//
+// Solidity: function canTransferWithTypeAndFailureReason(address from, address to, uint256 amount, uint8 txType) view returns(bool, string)
+func (_Contracts *ContractsCallerSession) CanTransferWithTypeAndFailureReason(from common.Address, to common.Address, amount *big.Int, txType uint8) (bool, string, error) {
+	return _Contracts.Contract.CanTransferWithTypeAndFailureReason(&_Contracts.CallOpts, from, to, amount, txType)
+}
+
+// GetRestrictionInfoFrom is a free data retrieval call binding the contract method 0x9a5c9b97.
+//
+// Solidity: function getRestrictionInfoFrom(address account) view returns((bool,string,uint256,address))
+func (_Contracts *ContractsCaller) GetRestrictionInfoFrom(opts *bind.CallOpts, account common.Address) (AddressRestrictionComplianceRestrictionInfo, error) {
+	var out []interface{}
+	err := _Contracts.contract.Call(opts, &out, "getRestrictionInfoFrom", account)
+
+	if err != nil {
+		return *new(AddressRestrictionComplianceRestrictionInfo), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(AddressRestrictionComplianceRestrictionInfo)).(*AddressRestrictionComplianceRestrictionInfo)
+
+	return out0, err
+
+}
+
+// GetRestrictionInfoFrom is a free data retrieval call binding the contract method 0x9a5c9b97.
+//
+// Solidity: function getRestrictionInfoFrom(address account) view returns((bool,string,uint256,address))
+func (_Contracts *ContractsSession) GetRestrictionInfoFrom(account common.Address) (AddressRestrictionComplianceRestrictionInfo, error) {
+	return _Contracts.Contract.GetRestrictionInfoFrom(&_Contracts.CallOpts, account)
+}
+
+// GetRestrictionInfoFrom is a free data retrieval call binding the contract method 0x9a5c9b97.
+//
+// Solidity: function getRestrictionInfoFrom(address account) view returns((bool,string,uint256,address))
+func (_Contracts *ContractsCallerSession) GetRestrictionInfoFrom(account common.Address) (AddressRestrictionComplianceRestrictionInfo, error) {
+	return _Contracts.Contract.GetRestrictionInfoFrom(&_Contracts.CallOpts, account)
+}
+
+// GetRestrictionInfoTo is a free data retrieval call binding the contract method 0x7ac6a66d.
+//
+// Solidity: function getRestrictionInfoTo(address account) view returns((bool,string,uint256,address))
+func (_Contracts *ContractsCaller) GetRestrictionInfoTo(opts *bind.CallOpts, account common.Address) (AddressRestrictionComplianceRestrictionInfo, error) {
+	var out []interface{}
+	err := _Contracts.contract.Call(opts, &out, "getRestrictionInfoTo", account)
+
+	if err != nil {
+		return *new(AddressRestrictionComplianceRestrictionInfo), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(AddressRestrictionComplianceRestrictionInfo)).(*AddressRestrictionComplianceRestrictionInfo)
+
+	return out0, err
+
+}
+
+// GetRestrictionInfoTo is a free data retrieval call binding the contract method 0x7ac6a66d.
+//
+// Solidity: function getRestrictionInfoTo(address account) view returns((bool,string,uint256,address))
+func (_Contracts *ContractsSession) GetRestrictionInfoTo(account common.Address) (AddressRestrictionComplianceRestrictionInfo, error) {
+	return _Contracts.Contract.GetRestrictionInfoTo(&_Contracts.CallOpts, account)
+}
+
+// GetRestrictionInfoTo is a free data retrieval call binding the contract method 0x7ac6a66d.
+//
+// Solidity: function getRestrictionInfoTo(address account) view returns((bool,string,uint256,address))
+func (_Contracts *ContractsCallerSession) GetRestrictionInfoTo(account common.Address) (AddressRestrictionComplianceRestrictionInfo, error) {
+	return _Contracts.Contract.GetRestrictionInfoTo(&_Contracts.CallOpts, account)
+}
+
+// GetRestrictionReasonFrom is a free data retrieval call binding the contract method 0xc3ccad4a.
+//
+// Solidity: function getRestrictionReasonFrom(address account) view returns(string)
+func (_Contracts *ContractsCaller) GetRestrictionReasonFrom(opts *bind.CallOpts, account common.Address) (string, error) {
+	var out []interface{}
+	err := _Contracts.contract.Call(opts, &out, "getRestrictionReasonFrom", account)
+
+	if err != nil {
+		return *new(string), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(string)).(*string)
+
+	return out0, err
+
+}
+
+// GetRestrictionReasonFrom is a free data retrieval call binding the contract method 0xc3ccad4a.
+//
+// Solidity: function getRestrictionReasonFrom(address account) view returns(string)
+func (_Contracts *ContractsSession) GetRestrictionReasonFrom(account common.Address) (string, error) {
+	return _Contracts.Contract.GetRestrictionReasonFrom(&_Contracts.CallOpts, account)
+}
+
+// GetRestrictionReasonFrom is a free data retrieval call binding the contract method 0xc3ccad4a.
+//
+// Solidity: function getRestrictionReasonFrom(address account) view returns(string)
+func (_Contracts *ContractsCallerSession) GetRestrictionReasonFrom(account common.Address) (string, error) {
+	return _Contracts.Contract.GetRestrictionReasonFrom(&_Contracts.CallOpts, account)
+}
+
+// GetRestrictionReasonTo is a free data retrieval call binding the contract method 0x50307a0c.
+//
+// Solidity: function getRestrictionReasonTo(address account) view returns(string)
+func (_Contracts *ContractsCaller) GetRestrictionReasonTo(opts *bind.CallOpts, account common.Address) (string, error) {
+	var out []interface{}
+	err := _Contracts.contract.Call(opts, &out, "getRestrictionReasonTo", account)
+
+	if err != nil {
+		return *new(string), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(string)).(*string)
+
+	return out0, err
+
+}
+
+// GetRestrictionReasonTo is a free data retrieval call binding the contract method 0x50307a0c.
+//
+// Solidity: function getRestrictionReasonTo(address account) view returns(string)
+func (_Contracts *ContractsSession) GetRestrictionReasonTo(account common.Address) (string, error) {
+	return _Contracts.Contract.GetRestrictionReasonTo(&_Contracts.CallOpts, account)
+}
+
+// GetRestrictionReasonTo is a free data retrieval call binding the contract method 0x50307a0c.
+//
+// Solidity: function getRestrictionReasonTo(address account) view returns(string)
+func (_Contracts *ContractsCallerSession) GetRestrictionReasonTo(account common.Address) (string, error) {
+	return _Contracts.Contract.GetRestrictionReasonTo(&_Contracts.CallOpts, account)
+}
+
 // GetRoleAdmin is a free data retrieval call binding the contract method 0x248a9ca3.
 //
 // Solidity: function getRoleAdmin(bytes32 role) view returns(bytes32)
@@ -460,6 +655,37 @@ func (_Contracts *ContractsCallerSession) IsBlacklistedTo(account common.Address
 	return _Contracts.Contract.IsBlacklistedTo(&_Contracts.CallOpts, account)
 }
 
+// Name is a free data retrieval call binding the contract method 0x06fdde03.
+//
+// Solidity: function name() pure returns(string)
+func (_Contracts *ContractsCaller) Name(opts *bind.CallOpts) (string, error) {
+	var out []interface{}
+	err := _Contracts.contract.Call(opts, &out, "name")
+
+	if err != nil {
+		return *new(string), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(string)).(*string)
+
+	return out0, err
+
+}
+
+// Name is a free data retrieval call binding the contract method 0x06fdde03.
+//
+// Solidity: function name() pure returns(string)
+func (_Contracts *ContractsSession) Name() (string, error) {
+	return _Contracts.Contract.Name(&_Contracts.CallOpts)
+}
+
+// Name is a free data retrieval call binding the contract method 0x06fdde03.
+//
+// Solidity: function name() pure returns(string)
+func (_Contracts *ContractsCallerSession) Name() (string, error) {
+	return _Contracts.Contract.Name(&_Contracts.CallOpts)
+}
+
 // SupportsInterface is a free data retrieval call binding the contract method 0x01ffc9a7.
 //
 // Solidity: function supportsInterface(bytes4 interfaceId) view returns(bool)
@@ -491,67 +717,130 @@ func (_Contracts *ContractsCallerSession) SupportsInterface(interfaceId [4]byte)
 	return _Contracts.Contract.SupportsInterface(&_Contracts.CallOpts, interfaceId)
 }
 
-// Blacklist is a paid mutator transaction binding the contract method 0x041f173f.
+// Blacklist is a paid mutator transaction binding the contract method 0x54405db5.
+//
+// Solidity: function blacklist(address[] accounts, string reason) returns()
+func (_Contracts *ContractsTransactor) Blacklist(opts *bind.TransactOpts, accounts []common.Address, reason string) (*types.Transaction, error) {
+	return _Contracts.contract.Transact(opts, "blacklist", accounts, reason)
+}
+
+// Blacklist is a paid mutator transaction binding the contract method 0x54405db5.
+//
+// Solidity: function blacklist(address[] accounts, string reason) returns()
+func (_Contracts *ContractsSession) Blacklist(accounts []common.Address, reason string) (*types.Transaction, error) {
+	return _Contracts.Contract.Blacklist(&_Contracts.TransactOpts, accounts, reason)
+}
+
+// Blacklist is a paid mutator transaction binding the contract method 0x54405db5.
+//
+// Solidity: function blacklist(address[] accounts, string reason) returns()
+func (_Contracts *ContractsTransactorSession) Blacklist(accounts []common.Address, reason string) (*types.Transaction, error) {
+	return _Contracts.Contract.Blacklist(&_Contracts.TransactOpts, accounts, reason)
+}
+
+// BlacklistFrom is a paid mutator transaction binding the contract method 0xc163e299.
+//
+// Solidity: function blacklistFrom(address[] accounts, string reason) returns()
+func (_Contracts *ContractsTransactor) BlacklistFrom(opts *bind.TransactOpts, accounts []common.Address, reason string) (*types.Transaction, error) {
+	return _Contracts.contract.Transact(opts, "blacklistFrom", accounts, reason)
+}
+
+// BlacklistFrom is a paid mutator transaction binding the contract method 0xc163e299.
+//
+// Solidity: function blacklistFrom(address[] accounts, string reason) returns()
+func (_Contracts *ContractsSession) BlacklistFrom(accounts []common.Address, reason string) (*types.Transaction, error) {
+	return _Contracts.Contract.BlacklistFrom(&_Contracts.TransactOpts, accounts, reason)
+}
+
+// BlacklistFrom is a paid mutator transaction binding the contract method 0xc163e299.
+//
+// Solidity: function blacklistFrom(address[] accounts, string reason) returns()
+func (_Contracts *ContractsTransactorSession) BlacklistFrom(accounts []common.Address, reason string) (*types.Transaction, error) {
+	return _Contracts.Contract.BlacklistFrom(&_Contracts.TransactOpts, accounts, reason)
+}
+
+// BlacklistFromWithReasons is a paid mutator transaction binding the contract method 0x350f89d1.
+//
+// Solidity: function blacklistFromWithReasons(address[] accounts, string[] reasons) returns()
+func (_Contracts *ContractsTransactor) BlacklistFromWithReasons(opts *bind.TransactOpts, accounts []common.Address, reasons []string) (*types.Transaction, error) {
+	return _Contracts.contract.Transact(opts, "blacklistFromWithReasons", accounts, reasons)
+}
+
+// BlacklistFromWithReasons is a paid mutator transaction binding the contract method 0x350f89d1.
+//
+// Solidity: function blacklistFromWithReasons(address[] accounts, string[] reasons) returns()
+func (_Contracts *ContractsSession) BlacklistFromWithReasons(accounts []common.Address, reasons []string) (*types.Transaction, error) {
+	return _Contracts.Contract.BlacklistFromWithReasons(&_Contracts.TransactOpts, accounts, reasons)
+}
+
+// BlacklistFromWithReasons is a paid mutator transaction binding the contract method 0x350f89d1.
+//
+// Solidity: function blacklistFromWithReasons(address[] accounts, string[] reasons) returns()
+func (_Contracts *ContractsTransactorSession) BlacklistFromWithReasons(accounts []common.Address, reasons []string) (*types.Transaction, error) {
+	return _Contracts.Contract.BlacklistFromWithReasons(&_Contracts.TransactOpts, accounts, reasons)
+}
+
+// BlacklistTo is a paid mutator transaction binding the contract method 0x146c5870.
 //
-// Solidity: function blacklist(address[] accounts) returns()
-func (_Contracts *ContractsTransactor) Blacklist(opts *bind.TransactOpts, accounts []common.Address) (*types.Transaction, error) {
-	return _Contracts.contract.Transact(opts, "blacklist", accounts)
+// Solidity: function blacklistTo(address[] accounts, string reason) returns()
+func (_Contracts *ContractsTransactor) BlacklistTo(opts *bind.TransactOpts, accounts []common.Address, reason string) (*types.Transaction, error) {
+	return _Contracts.contract.Transact(opts, "blacklistTo", accounts, reason)
 }
 
-// Blacklist is a paid mutator transaction binding the contract method 0x041f173f.
+// BlacklistTo is a paid mutator transaction binding the contract method 0x146c5870.
 //
-// Solidity: function blacklist(address[] accounts) returns()
-func (_Contracts *ContractsSession) Blacklist(accounts []common.Address) (*types.Transaction, error) {
-	return _Contracts.Contract.Blacklist(&_Contracts.TransactOpts, accounts)
+// Solidity: function blacklistTo(address[] accounts, string reason) returns()
+func (_Contracts *ContractsSession) BlacklistTo(accounts []common.Address, reason string) (*types.Transaction, error) {
+	return _Contracts.Contract.BlacklistTo(&_Contracts.TransactOpts, accounts, reason)
 }
 
-// Blacklist is a paid mutator transaction binding the contract method 0x041f173f.
+// BlacklistTo is a paid mutator transaction binding the contract method 0x146c5870.
 //
-// Solidity: function blacklist(address[] accounts) returns()
-func (_Contracts *ContractsTransactorSession) Blacklist(accounts []common.Address) (*types.Transaction, error) {
-	return _Contracts.Contract.Blacklist(&_Contracts.TransactOpts, accounts)
+// Solidity: function blacklistTo(address[] accounts, string reason) returns()
+func (_Contracts *ContractsTransactorSession) BlacklistTo(accounts []common.Address, reason string) (*types.Transaction, error) {
+	return _Contracts.Contract.BlacklistTo(&_Contracts.TransactOpts, accounts, reason)
 }
 
-// BlacklistFrom is a paid mutator transaction binding the contract method 0x343dbc01.
+// BlacklistToWithReasons is a paid mutator transaction binding the contract method 0x096300e1.
 //
-// Solidity: function blacklistFrom(address[] accounts) returns()
-func (_Contracts *ContractsTransactor) BlacklistFrom(opts *bind.TransactOpts, accounts []common.Address) (*types.Transaction, error) {
-	return _Contracts.contract.Transact(opts, "blacklistFrom", accounts)
+// Solidity: function blacklistToWithReasons(address[] accounts, string[] reasons) returns()
+func (_Contracts *ContractsTransactor) BlacklistToWithReasons(opts *bind.TransactOpts, accounts []common.Address, reasons []string) (*types.Transaction, error) {
+	return _Contracts.contract.Transact(opts, "blacklistToWithReasons", accounts, reasons)
 }
 
-// BlacklistFrom is a paid mutator transaction binding the contract method 0x343dbc01.
+// BlacklistToWithReasons is a paid mutator transaction binding the contract method 0x096300e1.
 //
-// Solidity: function blacklistFrom(address[] accounts) returns()
-func (_Contracts *ContractsSession) BlacklistFrom(accounts []common.Address) (*types.Transaction, error) {
-	return _Contracts.Contract.BlacklistFrom(&_Contracts.TransactOpts, accounts)
+// Solidity: function blacklistToWithReasons(address[] accounts, string[] reasons) returns()
+func (_Contracts *ContractsSession) BlacklistToWithReasons(accounts []common.Address, reasons []string) (*types.Transaction, error) {
+	return _Contracts.Contract.BlacklistToWithReasons(&_Contracts.TransactOpts, accounts, reasons)
 }
 
-// BlacklistFrom is a paid mutator transaction binding the contract method 0x343dbc01.
+// BlacklistToWithReasons is a paid mutator transaction binding the contract method 0x096300e1.
 //
-// Solidity: function blacklistFrom(address[] accounts) returns()
-func (_Contracts *ContractsTransactorSession) BlacklistFrom(accounts []common.Address) (*types.Transaction, error) {
-	return _Contracts.Contract.BlacklistFrom(&_Contracts.TransactOpts, accounts)
+// Solidity: function blacklistToWithReasons(address[] accounts, string[] reasons) returns()
+func (_Contracts *ContractsTransactorSession) BlacklistToWithReasons(accounts []common.Address, reasons []string) (*types.Transaction, error) {
+	return _Contracts.Contract.BlacklistToWithReasons(&_Contracts.TransactOpts, accounts, reasons)
 }
 
-// BlacklistTo is a paid mutator transaction binding the contract method 0x6d907c47.
+// BlacklistWithReasons is a paid mutator transaction binding the contract method 0xe8fd9ea8.
 //
-// Solidity: function blacklistTo(address[] accounts) returns()
-func (_Contracts *ContractsTransactor) BlacklistTo(opts *bind.TransactOpts, accounts []common.Address) (*types.Transaction, error) {
-	return _Contracts.contract.Transact(opts, "blacklistTo", accounts)
+// Solidity: function blacklistWithReasons(address[] accounts, string[] reasons) returns()
+func (_Contracts *ContractsTransactor) BlacklistWithReasons(opts *bind.TransactOpts, accounts []common.Address, reasons []string) (*types.Transaction, error) {
+	return _Contracts.contract.Transact(opts, "blacklistWithReasons", accounts, reasons)
 }
 
-// BlacklistTo is a paid mutator transaction binding the contract method 0x6d907c47.
+// BlacklistWithReasons is a paid mutator transaction binding the contract method 0xe8fd9ea8.
 //
-// Solidity: function blacklistTo(address[] accounts) returns()
-func (_Contracts *ContractsSession) BlacklistTo(accounts []common.Address) (*types.Transaction, error) {
-	return _Contracts.Contract.BlacklistTo(&_Contracts.TransactOpts, accounts)
+// Solidity: function blacklistWithReasons(address[] accounts, string[] reasons) returns()
+func (_Contracts *ContractsSession) BlacklistWithReasons(accounts []common.Address, reasons []string) (*types.Transaction, error) {
+	return _Contracts.Contract.BlacklistWithReasons(&_Contracts.TransactOpts, accounts, reasons)
 }
 
-// BlacklistTo is a paid mutator transaction binding the contract method 0x6d907c47.
+// BlacklistWithReasons is a paid mutator transaction binding the contract method 0xe8fd9ea8.
 //
-// Solidity: function blacklistTo(address[] accounts) returns()
-func (_Contracts *ContractsTransactorSession) BlacklistTo(accounts []common.Address) (*types.Transaction, error) {
-	return _Contracts.Contract.BlacklistTo(&_Contracts.TransactOpts, accounts)
+// Solidity: function blacklistWithReasons(address[] accounts, string[] reasons) returns()
+func (_Contracts *ContractsTransactorSession) BlacklistWithReasons(accounts []common.Address, reasons []string) (*types.Transaction, error) {
+	return _Contracts.Contract.BlacklistWithReasons(&_Contracts.TransactOpts, accounts, reasons)
 }
 
 // GrantRole is a paid mutator transaction binding the contract method 0x2f2ff15d.
@@ -575,6 +864,27 @@ func (_Contracts *ContractsTransactorSession) GrantRole(role [32]byte, account c
 	return _Contracts.Contract.GrantRole(&_Contracts.TransactOpts, role, account)
 }
 
+// Initialize is a paid mutator transaction binding the contract method 0x8129fc1c.
+//
+// Solidity: function initialize() returns()
+func (_Contracts *ContractsTransactor) Initialize(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _Contracts.contract.Transact(opts, "initialize")
+}
+
+// Initialize is a paid mutator transaction binding the contract method 0x8129fc1c.
+//
+// Solidity: function initialize() returns()
+func (_Contracts *ContractsSession) Initialize() (*types.Transaction, error) {
+	return _Contracts.Contract.Initialize(&_Contracts.TransactOpts)
+}
+
+// Initialize is a paid mutator transaction binding the contract method 0x8129fc1c.
+//
+// Solidity: function initialize() returns()
+func (_Contracts *ContractsTransactorSession) Initialize() (*types.Transaction, error) {
+	return _Contracts.Contract.Initialize(&_Contracts.TransactOpts)
+}
+
 // RenounceRole is a paid mutator transaction binding the contract method 0x36568abe.
 //
 // Solidity: function renounceRole(bytes32 role, address callerConfirmation) returns()
@@ -617,67 +927,67 @@ func (_Contracts *ContractsTransactorSession) RevokeRole(role [32]byte, account
 	return _Contracts.Contract.RevokeRole(&_Contracts.TransactOpts, role, account)
 }
 
-// Unblacklist is a paid mutator transaction binding the contract method 0xbfab6535.
+// Unblacklist is a paid mutator transaction binding the contract method 0x082f4b4d.
 //
-// Solidity: function unblacklist(address[] accounts) returns()
-func (_Contracts *ContractsTransactor) Unblacklist(opts *bind.TransactOpts, accounts []common.Address) (*types.Transaction, error) {
-	return _Contracts.contract.Transact(opts, "unblacklist", accounts)
+// Solidity: function unblacklist(address[] accounts, string reason) returns()
+func (_Contracts *ContractsTransactor) Unblacklist(opts *bind.TransactOpts, accounts []common.Address, reason string) (*types.Transaction, error) {
+	return _Contracts.contract.Transact(opts, "unblacklist", accounts, reason)
 }
 
-// Unblacklist is a paid mutator transaction binding the contract method 0xbfab6535.
+// Unblacklist is a paid mutator transaction binding the contract method 0x082f4b4d.
 //
-// Solidity: function unblacklist(address[] accounts) returns()
-func (_Contracts *ContractsSession) Unblacklist(accounts []common.Address) (*types.Transaction, error) {
-	return _Contracts.Contract.Unblacklist(&_Contracts.TransactOpts, accounts)
+// Solidity: function unblacklist(address[] accounts, string reason) returns()
+func (_Contracts *ContractsSession) Unblacklist(accounts []common.Address, reason string) (*types.Transaction, error) {
+	return _Contracts.Contract.Unblacklist(&_Contracts.TransactOpts, accounts, reason)
 }
 
-// Unblacklist is a paid mutator transaction binding the contract method 0xbfab6535.
+// Unblacklist is a paid mutator transaction binding the contract method 0x082f4b4d.
 //
-// Solidity: function unblacklist(address[] accounts) returns()
-func (_Contracts *ContractsTransactorSession) Unblacklist(accounts []common.Address) (*types.Transaction, error) {
-	return _Contracts.Contract.Unblacklist(&_Contracts.TransactOpts, accounts)
+// Solidity: function unblacklist(address[] accounts, string reason) returns()
+func (_Contracts *ContractsTransactorSession) Unblacklist(accounts []common.Address, reason string) (*types.Transaction, error) {
+	return _Contracts.Contract.Unblacklist(&_Contracts.TransactOpts, accounts, reason)
 }
 
-// UnblacklistFrom is a paid mutator transaction binding the contract method 0x7a5425be.
+// UnblacklistFrom is a paid mutator transaction binding the contract method 0x80b67f87.
 //
-// Solidity: function unblacklistFrom(address[] accounts) returns()
-func (_Contracts *ContractsTransactor) UnblacklistFrom(opts *bind.TransactOpts, accounts []common.Address) (*types.Transaction, error) {
-	return _Contracts.contract.Transact(opts, "unblacklistFrom", accounts)
+// Solidity: function unblacklistFrom(address[] accounts, string reason) returns()
+func (_Contracts *ContractsTransactor) UnblacklistFrom(opts *bind.TransactOpts, accounts []common.Address, reason string) (*types.Transaction, error) {
+	return _Contracts.contract.Transact(opts, "unblacklistFrom", accounts, reason)
 }
 
-// UnblacklistFrom is a paid mutator transaction binding the contract method 0x7a5425be.
+// UnblacklistFrom is a paid mutator transaction binding the contract method 0x80b67f87.
 //
-// Solidity: function unblacklistFrom(address[] accounts) returns()
-func (_Contracts *ContractsSession) UnblacklistFrom(accounts []common.Address) (*types.Transaction, error) {
-	return _Contracts.Contract.UnblacklistFrom(&_Contracts.TransactOpts, accounts)
+// Solidity: function unblacklistFrom(address[] accounts, string reason) returns()
+func (_Contracts *ContractsSession) UnblacklistFrom(accounts []common.Address, reason string) (*types.Transaction, error) {
+	return _Contracts.Contract.UnblacklistFrom(&_Contracts.TransactOpts, accounts, reason)
 }
 
-// UnblacklistFrom is a paid mutator transaction binding the contract method 0x7a5425be.
+// UnblacklistFrom is a paid mutator transaction binding the contract method 0x80b67f87.
 //
-// Solidity: function unblacklistFrom(address[] accounts) returns()
-func (_Contracts *ContractsTransactorSession) UnblacklistFrom(accounts []common.Address) (*types.Transaction, error) {
-	return _Contracts.Contract.UnblacklistFrom(&_Contracts.TransactOpts, accounts)
+// Solidity: function unblacklistFrom(address[] accounts, string reason) returns()
+func (_Contracts *ContractsTransactorSession) UnblacklistFrom(accounts []common.Address, reason string) (*types.Transaction, error) {
+	return _Contracts.Contract.UnblacklistFrom(&_Contracts.TransactOpts, accounts, reason)
 }
 
-// UnblacklistTo is a paid mutator transaction binding the contract method 0xc2a20abf.
+// UnblacklistTo is a paid mutator transaction binding the contract method 0x8372edbe.
 //
-// Solidity: function unblacklistTo(address[] accounts) returns()
-func (_Contracts *ContractsTransactor) UnblacklistTo(opts *bind.TransactOpts, accounts []common.Address) (*types.Transaction, error) {
-	return _Contracts.contract.Transact(opts, "unblacklistTo", accounts)
+// Solidity: function unblacklistTo(address[] accounts, string reason) returns()
+func (_Contracts *ContractsTransactor) UnblacklistTo(opts *bind.TransactOpts, accounts []common.Address, reason string) (*types.Transaction, error) {
+	return _Contracts.contract.Transact(opts, "unblacklistTo", accounts, reason)
 }
 
-// UnblacklistTo is a paid mutator transaction binding the contract method 0xc2a20abf.
+// UnblacklistTo is a paid mutator transaction binding the contract method 0x8372edbe.
 //
-// Solidity: function unblacklistTo(address[] accounts) returns()
-func (_Contracts *ContractsSession) UnblacklistTo(accounts []common.Address) (*types.Transaction, error) {
-	return _Contracts.Contract.UnblacklistTo(&_Contracts.TransactOpts, accounts)
+// Solidity: function unblacklistTo(address[] accounts, string reason) returns()
+func (_Contracts *ContractsSession) UnblacklistTo(accounts []common.Address, reason string) (*types.Transaction, error) {
+	return _Contracts.Contract.UnblacklistTo(&_Contracts.TransactOpts, accounts, reason)
 }
 
-// UnblacklistTo is a paid mutator transaction binding the contract method 0xc2a20abf.
+// UnblacklistTo is a paid mutator transaction binding the contract method 0x8372edbe.
 //
-// Solidity: function unblacklistTo(address[] accounts) returns()
-func (_Contracts *ContractsTransactorSession) UnblacklistTo(accounts []common.Address) (*types.Transaction, error) {
-	return _Contracts.Contract.UnblacklistTo(&_Contracts.TransactOpts, accounts)
+// Solidity: function unblacklistTo(address[] accounts, string reason) returns()
+func (_Contracts *ContractsTransactorSession) UnblacklistTo(accounts []common.Address, reason string) (*types.Transaction, error) {
+	return _Contracts.Contract.UnblacklistTo(&_Contracts.TransactOpts, accounts, reason)
 }
 
 // ContractsAddressBlacklistedIterator is returned from FilterAddressBlacklisted and is used to iterate over the raw logs and unpacked data for AddressBlacklisted events raised by the Contracts contract.
@@ -749,38 +1059,51 @@ func (it *ContractsAddressBlacklistedIterator) Close() error {
 
 // ContractsAddressBlacklisted represents a AddressBlacklisted event raised by the Contracts contract.
 type ContractsAddressBlacklisted struct {
-	Account common.Address
-	Raw     types.Log // Blockchain specific contextual infos
+	Account      common.Address
+	Reason       string
+	RestrictedBy common.Address
+	Timestamp    *big.Int
+	Raw          types.Log // Blockchain specific contextual infos
 }
 
-// FilterAddressBlacklisted is a free log retrieval operation binding the contract event 0xdaf49ab9345b6cb75bcb5a7f726bff9183c34dcf5c098c385730f9fd893765f6.
+// FilterAddressBlacklisted is a free log retrieval operation binding the contract event 0x26caf0ea9f0c339f600ce6cb4e79abfeedf463bf1b6e9d42464d098ac6d5a0e7.
 //
-// Solidity: event AddressBlacklisted(address indexed account)
-func (_Contracts *ContractsFilterer) FilterAddressBlacklisted(opts *bind.FilterOpts, account []common.Address) (*ContractsAddressBlacklistedIterator, error) {
+// Solidity: event AddressBlacklisted(address indexed account, string reason, address indexed restrictedBy, uint256 timestamp)
+func (_Contracts *ContractsFilterer) FilterAddressBlacklisted(opts *bind.FilterOpts, account []common.Address, restrictedBy []common.Address) (*ContractsAddressBlacklistedIterator, error) {
 
 	var accountRule []interface{}
 	for _, accountItem := range account {
 		accountRule = append(accountRule, accountItem)
 	}
 
-	logs, sub, err := _Contracts.contract.FilterLogs(opts, "AddressBlacklisted", accountRule)
+	var restrictedByRule []interface{}
+	for _, restrictedByItem := range restrictedBy {
+		restrictedByRule = append(restrictedByRule, restrictedByItem)
+	}
+
+	logs, sub, err := _Contracts.contract.FilterLogs(opts, "AddressBlacklisted", accountRule, restrictedByRule)
 	if err != nil {
 		return nil, err
 	}
 	return &ContractsAddressBlacklistedIterator{contract: _Contracts.contract, event: "AddressBlacklisted", logs: logs, sub: sub}, nil
 }
 
-// WatchAddressBlacklisted is a free log subscription operation binding the contract event 0xdaf49ab9345b6cb75bcb5a7f726bff9183c34dcf5c098c385730f9fd893765f6.
+// WatchAddressBlacklisted is a free log subscription operation binding the contract event 0x26caf0ea9f0c339f600ce6cb4e79abfeedf463bf1b6e9d42464d098ac6d5a0e7.
 //
-// Solidity: event AddressBlacklisted(address indexed account)
-func (_Contracts *ContractsFilterer) WatchAddressBlacklisted(opts *bind.WatchOpts, sink chan<- *ContractsAddressBlacklisted, account []common.Address) (event.Subscription, error) {
+// Solidity: event AddressBlacklisted(address indexed account, string reason, address indexed restrictedBy, uint256 timestamp)
+func (_Contracts *ContractsFilterer) WatchAddressBlacklisted(opts *bind.WatchOpts, sink chan<- *ContractsAddressBlacklisted, account []common.Address, restrictedBy []common.Address) (event.Subscription, error) {
 
 	var accountRule []interface{}
 	for _, accountItem := range account {
 		accountRule = append(accountRule, accountItem)
 	}
 
-	logs, sub, err := _Contracts.contract.WatchLogs(opts, "AddressBlacklisted", accountRule)
+	var restrictedByRule []interface{}
+	for _, restrictedByItem := range restrictedBy {
+		restrictedByRule = append(restrictedByRule, restrictedByItem)
+	}
+
+	logs, sub, err := _Contracts.contract.WatchLogs(opts, "AddressBlacklisted", accountRule, restrictedByRule)
 	if err != nil {
 		return nil, err
 	}
@@ -812,9 +1135,9 @@ func (_Contracts *ContractsFilterer) WatchAddressBlacklisted(opts *bind.WatchOpt
 	}), nil
 }
 
-// ParseAddressBlacklisted is a log parse operation binding the contract event 0xdaf49ab9345b6cb75bcb5a7f726bff9183c34dcf5c098c385730f9fd893765f6.
+// ParseAddressBlacklisted is a log parse operation binding the contract event 0x26caf0ea9f0c339f600ce6cb4e79abfeedf463bf1b6e9d42464d098ac6d5a0e7.
 //
-// Solidity: event AddressBlacklisted(address indexed account)
+// Solidity: event AddressBlacklisted(address indexed account, string reason, address indexed restrictedBy, uint256 timestamp)
 func (_Contracts *ContractsFilterer) ParseAddressBlacklisted(log types.Log) (*ContractsAddressBlacklisted, error) {
 	event := new(ContractsAddressBlacklisted)
 	if err := _Contracts.contract.UnpackLog(event, "AddressBlacklisted", log); err != nil {
@@ -893,38 +1216,51 @@ func (it *ContractsAddressBlacklistedFromIterator) Close() error {
 
 // ContractsAddressBlacklistedFrom represents a AddressBlacklistedFrom event raised by the Contracts contract.
 type ContractsAddressBlacklistedFrom struct {
-	Account common.Address
-	Raw     types.Log // Blockchain specific contextual infos
+	Account      common.Address
+	Reason       string
+	RestrictedBy common.Address
+	Timestamp    *big.Int
+	Raw          types.Log // Blockchain specific contextual infos
 }
 
-// FilterAddressBlacklistedFrom is a free log retrieval operation binding the contract event 0x9dfdda91594e9fdc47d49655cb3e5be93cf9432fd57290eb605b597728b8b35f.
+// FilterAddressBlacklistedFrom is a free log retrieval operation binding the contract event 0xe6ba7d87073fff59a37496a39db4d954cb93b5cf24cbfbd7fa05a92c999ccce5.
 //
-// Solidity: event AddressBlacklistedFrom(address indexed account)
-func (_Contracts *ContractsFilterer) FilterAddressBlacklistedFrom(opts *bind.FilterOpts, account []common.Address) (*ContractsAddressBlacklistedFromIterator, error) {
+// Solidity: event AddressBlacklistedFrom(address indexed account, string reason, address indexed restrictedBy, uint256 timestamp)
+func (_Contracts *ContractsFilterer) FilterAddressBlacklistedFrom(opts *bind.FilterOpts, account []common.Address, restrictedBy []common.Address) (*ContractsAddressBlacklistedFromIterator, error) {
 
 	var accountRule []interface{}
 	for _, accountItem := range account {
 		accountRule = append(accountRule, accountItem)
 	}
 
-	logs, sub, err := _Contracts.contract.FilterLogs(opts, "AddressBlacklistedFrom", accountRule)
+	var restrictedByRule []interface{}
+	for _, restrictedByItem := range restrictedBy {
+		restrictedByRule = append(restrictedByRule, restrictedByItem)
+	}
+
+	logs, sub, err := _Contracts.contract.FilterLogs(opts, "AddressBlacklistedFrom", accountRule, restrictedByRule)
 	if err != nil {
 		return nil, err
 	}
 	return &ContractsAddressBlacklistedFromIterator{contract: _Contracts.contract, event: "AddressBlacklistedFrom", logs: logs, sub: sub}, nil
 }
 
-// WatchAddressBlacklistedFrom is a free log subscription operation binding the contract event 0x9dfdda91594e9fdc47d49655cb3e5be93cf9432fd57290eb605b597728b8b35f.
+// WatchAddressBlacklistedFrom is a free log subscription operation binding the contract event 0xe6ba7d87073fff59a37496a39db4d954cb93b5cf24cbfbd7fa05a92c999ccce5.
 //
-// Solidity: event AddressBlacklistedFrom(address indexed account)
-func (_Contracts *ContractsFilterer) WatchAddressBlacklistedFrom(opts *bind.WatchOpts, sink chan<- *ContractsAddressBlacklistedFrom, account []common.Address) (event.Subscription, error) {
+// Solidity: event AddressBlacklistedFrom(address indexed account, string reason, address indexed restrictedBy, uint256 timestamp)
+func (_Contracts *ContractsFilterer) WatchAddressBlacklistedFrom(opts *bind.WatchOpts, sink chan<- *ContractsAddressBlacklistedFrom, account []common.Address, restrictedBy []common.Address) (event.Subscription, error) {
 
 	var accountRule []interface{}
 	for _, accountItem := range account {
 		accountRule = append(accountRule, accountItem)
 	}
 
-	logs, sub, err := _Contracts.contract.WatchLogs(opts, "AddressBlacklistedFrom", accountRule)
+	var restrictedByRule []interface{}
+	for _, restrictedByItem := range restrictedBy {
+		restrictedByRule = append(restrictedByRule, restrictedByItem)
+	}
+
+	logs, sub, err := _Contracts.contract.WatchLogs(opts, "AddressBlacklistedFrom", accountRule, restrictedByRule)
 	if err != nil {
 		return nil, err
 	}
@@ -956,9 +1292,9 @@ func (_Contracts *ContractsFilterer) WatchAddressBlacklistedFrom(opts *bind.Watc
 	}), nil
 }
 
-// ParseAddressBlacklistedFrom is a log parse operation binding the contract event 0x9dfdda91594e9fdc47d49655cb3e5be93cf9432fd57290eb605b597728b8b35f.
+// ParseAddressBlacklistedFrom is a log parse operation binding the contract event 0xe6ba7d87073fff59a37496a39db4d954cb93b5cf24cbfbd7fa05a92c999ccce5.
 //
-// Solidity: event AddressBlacklistedFrom(address indexed account)
+// Solidity: event AddressBlacklistedFrom(address indexed account, string reason, address indexed restrictedBy, uint256 timestamp)
 func (_Contracts *ContractsFilterer) ParseAddressBlacklistedFrom(log types.Log) (*ContractsAddressBlacklistedFrom, error) {
 	event := new(ContractsAddressBlacklistedFrom)
 	if err := _Contracts.contract.UnpackLog(event, "AddressBlacklistedFrom", log); err != nil {
@@ -1037,38 +1373,51 @@ func (it *ContractsAddressBlacklistedToIterator) Close() error {
 
 // ContractsAddressBlacklistedTo represents a AddressBlacklistedTo event raised by the Contracts contract.
 type ContractsAddressBlacklistedTo struct {
-	Account common.Address
-	Raw     types.Log // Blockchain specific contextual infos
+	Account      common.Address
+	Reason       string
+	RestrictedBy common.Address
+	Timestamp    *big.Int
+	Raw          types.Log // Blockchain specific contextual infos
 }
 
-// FilterAddressBlacklistedTo is a free log retrieval operation binding the contract event 0x73a8a75339968fe050872be18a0d3551982e1a056b8c244c6d36de22b0ef571c.
+// FilterAddressBlacklistedTo is a free log retrieval operation binding the contract event 0x8742e0d00eb7b78f8e5c93f784824fcbcf299663dd15af20103d8cc70f25ce3a.
 //
-// Solidity: event AddressBlacklistedTo(address indexed account)
-func (_Contracts *ContractsFilterer) FilterAddressBlacklistedTo(opts *bind.FilterOpts, account []common.Address) (*ContractsAddressBlacklistedToIterator, error) {
+// Solidity: event AddressBlacklistedTo(address indexed account, string reason, address indexed restrictedBy, uint256 timestamp)
+func (_Contracts *ContractsFilterer) FilterAddressBlacklistedTo(opts *bind.FilterOpts, account []common.Address, restrictedBy []common.Address) (*ContractsAddressBlacklistedToIterator, error) {
 
 	var accountRule []interface{}
 	for _, accountItem := range account {
 		accountRule = append(accountRule, accountItem)
 	}
 
-	logs, sub, err := _Contracts.contract.FilterLogs(opts, "AddressBlacklistedTo", accountRule)
+	var restrictedByRule []interface{}
+	for _, restrictedByItem := range restrictedBy {
+		restrictedByRule = append(restrictedByRule, restrictedByItem)
+	}
+
+	logs, sub, err := _Contracts.contract.FilterLogs(opts, "AddressBlacklistedTo", accountRule, restrictedByRule)
 	if err != nil {
 		return nil, err
 	}
 	return &ContractsAddressBlacklistedToIterator{contract: _Contracts.contract, event: "AddressBlacklistedTo", logs: logs, sub: sub}, nil
 }
 
-// WatchAddressBlacklistedTo is a free log subscription operation binding the contract event 0x73a8a75339968fe050872be18a0d3551982e1a056b8c244c6d36de22b0ef571c.
+// WatchAddressBlacklistedTo is a free log subscription operation binding the contract event 0x8742e0d00eb7b78f8e5c93f784824fcbcf299663dd15af20103d8cc70f25ce3a.
 //
-// Solidity: event AddressBlacklistedTo(address indexed account)
-func (_Contracts *ContractsFilterer) WatchAddressBlacklistedTo(opts *bind.WatchOpts, sink chan<- *ContractsAddressBlacklistedTo, account []common.Address) (event.Subscription, error) {
+// Solidity: event AddressBlacklistedTo(address indexed account, string reason, address indexed restrictedBy, uint256 timestamp)
+func (_Contracts *ContractsFilterer) WatchAddressBlacklistedTo(opts *bind.WatchOpts, sink chan<- *ContractsAddressBlacklistedTo, account []common.Address, restrictedBy []common.Address) (event.Subscription, error) {
 
 	var accountRule []interface{}
 	for _, accountItem := range account {
 		accountRule = append(accountRule, accountItem)
 	}
 
-	logs, sub, err := _Contracts.contract.WatchLogs(opts, "AddressBlacklistedTo", accountRule)
+	var restrictedByRule []interface{}
+	for _, restrictedByItem := range restrictedBy {
+		restrictedByRule = append(restrictedByRule, restrictedByItem)
+	}
+
+	logs, sub, err := _Contracts.contract.WatchLogs(opts, "AddressBlacklistedTo", accountRule, restrictedByRule)
 	if err != nil {
 		return nil, err
 	}
@@ -1100,9 +1449,9 @@ func (_Contracts *ContractsFilterer) WatchAddressBlacklistedTo(opts *bind.WatchO
 	}), nil
 }
 
-// ParseAddressBlacklistedTo is a log parse operation binding the contract event 0x73a8a75339968fe050872be18a0d3551982e1a056b8c244c6d36de22b0ef571c.
+// ParseAddressBlacklistedTo is a log parse operation binding the contract event 0x8742e0d00eb7b78f8e5c93f784824fcbcf299663dd15af20103d8cc70f25ce3a.
 //
-// Solidity: event AddressBlacklistedTo(address indexed account)
+// Solidity: event AddressBlacklistedTo(address indexed account, string reason, address indexed restrictedBy, uint256 timestamp)
 func (_Contracts *ContractsFilterer) ParseAddressBlacklistedTo(log types.Log) (*ContractsAddressBlacklistedTo, error) {
 	event := new(ContractsAddressBlacklistedTo)
 	if err := _Contracts.contract.UnpackLog(event, "AddressBlacklistedTo", log); err != nil {
@@ -1181,38 +1530,51 @@ func (it *ContractsAddressUnblacklistedIterator) Close() error {
 
 // ContractsAddressUnblacklisted represents a AddressUnblacklisted event raised by the Contracts contract.
 type ContractsAddressUnblacklisted struct {
-	Account common.Address
-	Raw     types.Log // Blockchain specific contextual infos
+	Account        common.Address
+	Reason         string
+	UnrestrictedBy common.Address
+	Timestamp      *big.Int
+	Raw            types.Log // Blockchain specific contextual infos
 }
 
-// FilterAddressUnblacklisted is a free log retrieval operation binding the contract event 0xb2aa8f2cce614e8fceaca560dbaf2a8ed3083e4ab371b10bf6d02e359216767a.
+// FilterAddressUnblacklisted is a free log retrieval operation binding the contract event 0x715db7900ccd2329a39320054f28c49b9fbc15e5048192a08a7eb2255614eeef.
 //
-// Solidity: event AddressUnblacklisted(address indexed account)
-func (_Contracts *ContractsFilterer) FilterAddressUnblacklisted(opts *bind.FilterOpts, account []common.Address) (*ContractsAddressUnblacklistedIterator, error) {
+// Solidity: event AddressUnblacklisted(address indexed account, string reason, address indexed unrestrictedBy, uint256 timestamp)
+func (_Contracts *ContractsFilterer) FilterAddressUnblacklisted(opts *bind.FilterOpts, account []common.Address, unrestrictedBy []common.Address) (*ContractsAddressUnblacklistedIterator, error) {
 
 	var accountRule []interface{}
 	for _, accountItem := range account {
 		accountRule = append(accountRule, accountItem)
 	}
 
-	logs, sub, err := _Contracts.contract.FilterLogs(opts, "AddressUnblacklisted", accountRule)
+	var unrestrictedByRule []interface{}
+	for _, unrestrictedByItem := range unrestrictedBy {
+		unrestrictedByRule = append(unrestrictedByRule, unrestrictedByItem)
+	}
+
+	logs, sub, err := _Contracts.contract.FilterLogs(opts, "AddressUnblacklisted", accountRule, unrestrictedByRule)
 	if err != nil {
 		return nil, err
 	}
 	return &ContractsAddressUnblacklistedIterator{contract: _Contracts.contract, event: "AddressUnblacklisted", logs: logs, sub: sub}, nil
 }
 
-// WatchAddressUnblacklisted is a free log subscription operation binding the contract event 0xb2aa8f2cce614e8fceaca560dbaf2a8ed3083e4ab371b10bf6d02e359216767a.
+// WatchAddressUnblacklisted is a free log subscription operation binding the contract event 0x715db7900ccd2329a39320054f28c49b9fbc15e5048192a08a7eb2255614eeef.
 //
-// Solidity: event AddressUnblacklisted(address indexed account)
-func (_Contracts *ContractsFilterer) WatchAddressUnblacklisted(opts *bind.WatchOpts, sink chan<- *ContractsAddressUnblacklisted, account []common.Address) (event.Subscription, error) {
+// Solidity: event AddressUnblacklisted(address indexed account, string reason, address indexed unrestrictedBy, uint256 timestamp)
+func (_Contracts *ContractsFilterer) WatchAddressUnblacklisted(opts *bind.WatchOpts, sink chan<- *ContractsAddressUnblacklisted, account []common.Address, unrestrictedBy []common.Address) (event.Subscription, error) {
 
 	var accountRule []interface{}
 	for _, accountItem := range account {
 		accountRule = append(accountRule, accountItem)
 	}
 
-	logs, sub, err := _Contracts.contract.WatchLogs(opts, "AddressUnblacklisted", accountRule)
+	var unrestrictedByRule []interface{}
+	for _, unrestrictedByItem := range unrestrictedBy {
+		unrestrictedByRule = append(unrestrictedByRule, unrestrictedByItem)
+	}
+
+	logs, sub, err := _Contracts.contract.WatchLogs(opts, "AddressUnblacklisted", accountRule, unrestrictedByRule)
 	if err != nil {
 		return nil, err
 	}
@@ -1244,9 +1606,9 @@ func (_Contracts *ContractsFilterer) WatchAddressUnblacklisted(opts *bind.WatchO
 	}), nil
 }
 
-// ParseAddressUnblacklisted is a log parse operation binding the contract event 0xb2aa8f2cce614e8fceaca560dbaf2a8ed3083e4ab371b10bf6d02e359216767a.
+// ParseAddressUnblacklisted is a log parse operation binding the contract event 0x715db7900ccd2329a39320054f28c49b9fbc15e5048192a08a7eb2255614eeef.
 //
-// Solidity: event AddressUnblacklisted(address indexed account)
+// Solidity: event AddressUnblacklisted(address indexed account, string reason, address indexed unrestrictedBy, uint256 timestamp)
 func (_Contracts *ContractsFilterer) ParseAddressUnblacklisted(log types.Log) (*ContractsAddressUnblacklisted, error) {
 	event := new(ContractsAddressUnblacklisted)
 	if err := _Contracts.contract.UnpackLog(event, "AddressUnblacklisted", log); err != nil {
@@ -1325,38 +1687,51 @@ func (it *ContractsAddressUnblacklistedFromIterator) Close() error {
 
 // ContractsAddressUnblacklistedFrom represents a AddressUnblacklistedFrom event raised by the Contracts contract.
 type ContractsAddressUnblacklistedFrom struct {
-	Account common.Address
-	Raw     types.Log // Blockchain specific contextual infos
+	Account        common.Address
+	Reason         string
+	UnrestrictedBy common.Address
+	Timestamp      *big.Int
+	Raw            types.Log // Blockchain specific contextual infos
 }
 
-// FilterAddressUnblacklistedFrom is a free log retrieval operation binding the contract event 0x05ddbc1da122ce95119058bfb5e7156768d6a92bcf25629bc6608cc6d7627e15.
+// FilterAddressUnblacklistedFrom is a free log retrieval operation binding the contract event 0xd4a4e632f4c2c1f1fb6ff08ccd90c6176fb728075c2c1d4b23795422d3628980.
 //
-// Solidity: event AddressUnblacklistedFrom(address indexed account)
-func (_Contracts *ContractsFilterer) FilterAddressUnblacklistedFrom(opts *bind.FilterOpts, account []common.Address) (*ContractsAddressUnblacklistedFromIterator, error) {
+// Solidity: event AddressUnblacklistedFrom(address indexed account, string reason, address indexed unrestrictedBy, uint256 timestamp)
+func (_Contracts *ContractsFilterer) FilterAddressUnblacklistedFrom(opts *bind.FilterOpts, account []common.Address, unrestrictedBy []common.Address) (*ContractsAddressUnblacklistedFromIterator, error) {
 
 	var accountRule []interface{}
 	for _, accountItem := range account {
 		accountRule = append(accountRule, accountItem)
 	}
 
-	logs, sub, err := _Contracts.contract.FilterLogs(opts, "AddressUnblacklistedFrom", accountRule)
+	var unrestrictedByRule []interface{}
+	for _, unrestrictedByItem := range unrestrictedBy {
+		unrestrictedByRule = append(unrestrictedByRule, unrestrictedByItem)
+	}
+
+	logs, sub, err := _Contracts.contract.FilterLogs(opts, "AddressUnblacklistedFrom", accountRule, unrestrictedByRule)
 	if err != nil {
 		return nil, err
 	}
 	return &ContractsAddressUnblacklistedFromIterator{contract: _Contracts.contract, event: "AddressUnblacklistedFrom", logs: logs, sub: sub}, nil
 }
 
-// WatchAddressUnblacklistedFrom is a free log subscription operation binding the contract event 0x05ddbc1da122ce95119058bfb5e7156768d6a92bcf25629bc6608cc6d7627e15.
+// WatchAddressUnblacklistedFrom is a free log subscription operation binding the contract event 0xd4a4e632f4c2c1f1fb6ff08ccd90c6176fb728075c2c1d4b23795422d3628980.
 //
-// Solidity: event AddressUnblacklistedFrom(address indexed account)
-func (_Contracts *ContractsFilterer) WatchAddressUnblacklistedFrom(opts *bind.WatchOpts, sink chan<- *ContractsAddressUnblacklistedFrom, account []common.Address) (event.Subscription, error) {
+// Solidity: event AddressUnblacklistedFrom(address indexed account, string reason, address indexed unrestrictedBy, uint256 timestamp)
+func (_Contracts *ContractsFilterer) WatchAddressUnblacklistedFrom(opts *bind.WatchOpts, sink chan<- *ContractsAddressUnblacklistedFrom, account []common.Address, unrestrictedBy []common.Address) (event.Subscription, error) {
 
 	var accountRule []interface{}
 	for _, accountItem := range account {
 		accountRule = append(accountRule, accountItem)
 	}
 
-	logs, sub, err := _Contracts.contract.WatchLogs(opts, "AddressUnblacklistedFrom", accountRule)
+	var unrestrictedByRule []interface{}
+	for _, unrestrictedByItem := range unrestrictedBy {
+		unrestrictedByRule = append(unrestrictedByRule, unrestrictedByItem)
+	}
+
+	logs, sub, err := _Contracts.contract.WatchLogs(opts, "AddressUnblacklistedFrom", accountRule, unrestrictedByRule)
 	if err != nil {
 		return nil, err
 	}
@@ -1388,9 +1763,9 @@ func (_Contracts *ContractsFilterer) WatchAddressUnblacklistedFrom(opts *bind.Wa
 	}), nil
 }
 
-// ParseAddressUnblacklistedFrom is a log parse operation binding the contract event 0x05ddbc1da122ce95119058bfb5e7156768d6a92bcf25629bc6608cc6d7627e15.
+// ParseAddressUnblacklistedFrom is a log parse operation binding the contract event 0xd4a4e632f4c2c1f1fb6ff08ccd90c6176fb728075c2c1d4b23795422d3628980.
 //
-// Solidity: event AddressUnblacklistedFrom(address indexed account)
+// Solidity: event AddressUnblacklistedFrom(address indexed account, string reason, address indexed unrestrictedBy, uint256 timestamp)
 func (_Contracts *ContractsFilterer) ParseAddressUnblacklistedFrom(log types.Log) (*ContractsAddressUnblacklistedFrom, error) {
 	event := new(ContractsAddressUnblacklistedFrom)
 	if err := _Contracts.contract.UnpackLog(event, "AddressUnblacklistedFrom", log); err != nil {
@@ -1469,38 +1844,51 @@ func (it *ContractsAddressUnblacklistedToIterator) Close() error {
 
 // ContractsAddressUnblacklistedTo represents a AddressUnblacklistedTo event raised by the Contracts contract.
 type ContractsAddressUnblacklistedTo struct {
-	Account common.Address
-	Raw     types.Log // Blockchain specific contextual infos
+	Account        common.Address
+	Reason         string
+	UnrestrictedBy common.Address
+	Timestamp      *big.Int
+	Raw            types.Log // Blockchain specific contextual infos
 }
 
-// FilterAddressUnblacklistedTo is a free log retrieval operation binding the contract event 0x0f8b068a900ac843db847c348ef052decfaa2ef3f6c4dbf70c55a354e5abc0e2.
+// FilterAddressUnblacklistedTo is a free log retrieval operation binding the contract event 0x08a5359a294f89a1a74d1d1554de86685142656643fb40b622c236d5dec48a65.
 //
-// Solidity: event AddressUnblacklistedTo(address indexed account)
-func (_Contracts *ContractsFilterer) FilterAddressUnblacklistedTo(opts *bind.FilterOpts, account []common.Address) (*ContractsAddressUnblacklistedToIterator, error) {
+// Solidity: event AddressUnblacklistedTo(address indexed account, string reason, address indexed unrestrictedBy, uint256 timestamp)
+func (_Contracts *ContractsFilterer) FilterAddressUnblacklistedTo(opts *bind.FilterOpts, account []common.Address, unrestrictedBy []common.Address) (*ContractsAddressUnblacklistedToIterator, error) {
 
 	var accountRule []interface{}
 	for _, accountItem := range account {
 		accountRule = append(accountRule, accountItem)
 	}
 
-	logs, sub, err := _Contracts.contract.FilterLogs(opts, "AddressUnblacklistedTo", accountRule)
+	var unrestrictedByRule []interface{}
+	for _, unrestrictedByItem := range unrestrictedBy {
+		unrestrictedByRule = append(unrestrictedByRule, unrestrictedByItem)
+	}
+
+	logs, sub, err := _Contracts.contract.FilterLogs(opts, "AddressUnblacklistedTo", accountRule, unrestrictedByRule)
 	if err != nil {
 		return nil, err
 	}
 	return &ContractsAddressUnblacklistedToIterator{contract: _Contracts.contract, event: "AddressUnblacklistedTo", logs: logs, sub: sub}, nil
 }
 
-// WatchAddressUnblacklistedTo is a free log subscription operation binding the contract event 0x0f8b068a900ac843db847c348ef052decfaa2ef3f6c4dbf70c55a354e5abc0e2.
+// WatchAddressUnblacklistedTo is a free log subscription operation binding the contract event 0x08a5359a294f89a1a74d1d1554de86685142656643fb40b622c236d5dec48a65.
 //
-// Solidity: event AddressUnblacklistedTo(address indexed account)
-func (_Contracts *ContractsFilterer) WatchAddressUnblacklistedTo(opts *bind.WatchOpts, sink chan<- *ContractsAddressUnblacklistedTo, account []common.Address) (event.Subscription, error) {
+// Solidity: event AddressUnblacklistedTo(address indexed account, string reason, address indexed unrestrictedBy, uint256 timestamp)
+func (_Contracts *ContractsFilterer) WatchAddressUnblacklistedTo(opts *bind.WatchOpts, sink chan<- *ContractsAddressUnblacklistedTo, account []common.Address, unrestrictedBy []common.Address) (event.Subscription, error) {
 
 	var accountRule []interface{}
 	for _, accountItem := range account {
 		accountRule = append(accountRule, accountItem)
 	}
 
-	logs, sub, err := _Contracts.contract.WatchLogs(opts, "AddressUnblacklistedTo", accountRule)
+	var unrestrictedByRule []interface{}
+	for _, unrestrictedByItem := range unrestrictedBy {
+		unrestrictedByRule = append(unrestrictedByRule, unrestrictedByItem)
+	}
+
+	logs, sub, err := _Contracts.contract.WatchLogs(opts, "AddressUnblacklistedTo", accountRule, unrestrictedByRule)
 	if err != nil {
 		return nil, err
 	}
@@ -1532,9 +1920,9 @@ func (_Contracts *ContractsFilterer) WatchAddressUnblacklistedTo(opts *bind.Watc
 	}), nil
 }
 
-// ParseAddressUnblacklistedTo is a log parse operation binding the contract event 0x0f8b068a900ac843db847c348ef052decfaa2ef3f6c4dbf70c55a354e5abc0e2.
+// ParseAddressUnblacklistedTo is a log parse operation binding the contract event 0x08a5359a294f89a1a74d1d1554de86685142656643fb40b622c236d5dec48a65.
 //
-// Solidity: event AddressUnblacklistedTo(address indexed account)
+// Solidity: event AddressUnblacklistedTo(address indexed account, string reason, address indexed unrestrictedBy, uint256 timestamp)
 func (_Contracts *ContractsFilterer) ParseAddressUnblacklistedTo(log types.Log) (*ContractsAddressUnblacklistedTo, error) {
 	event := new(ContractsAddressUnblacklistedTo)
 	if err := _Contracts.contract.UnpackLog(event, "AddressUnblacklistedTo", log); err != nil {
@@ -1544,6 +1932,140 @@ func (_Contracts *ContractsFilterer) ParseAddressUnblacklistedTo(log types.Log)
 	return event, nil
 }
 
+// ContractsInitializedIterator is returned from FilterInitialized and is used to iterate over the raw logs and unpacked data for Initialized events raised by the Contracts contract.
+type ContractsInitializedIterator struct {
+	Event *ContractsInitialized // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *ContractsInitializedIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(ContractsInitialized)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(ContractsInitialized)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *ContractsInitializedIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *ContractsInitializedIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// ContractsInitialized represents a Initialized event raised by the Contracts contract.
+type ContractsInitialized struct {
+	Version uint64
+	Raw     types.Log // Blockchain specific contextual infos
+}
+
+// FilterInitialized is a free log retrieval operation binding the contract event 0xc7f505b2f371ae2175ee4913f4499e1f2633a7b5936321eed1cdaeb6115181d2.
+//
+// Solidity: event Initialized(uint64 version)
+func (_Contracts *ContractsFilterer) FilterInitialized(opts *bind.FilterOpts) (*ContractsInitializedIterator, error) {
+
+	logs, sub, err := _Contracts.contract.FilterLogs(opts, "Initialized")
+	if err != nil {
+		return nil, err
+	}
+	return &ContractsInitializedIterator{contract: _Contracts.contract, event: "Initialized", logs: logs, sub: sub}, nil
+}
+
+// WatchInitialized is a free log subscription operation binding the contract event 0xc7f505b2f371ae2175ee4913f4499e1f2633a7b5936321eed1cdaeb6115181d2.
+//
+// Solidity: event Initialized(uint64 version)
+func (_Contracts *ContractsFilterer) WatchInitialized(opts *bind.WatchOpts, sink chan<- *ContractsInitialized) (event.Subscription, error) {
+
+	logs, sub, err := _Contracts.contract.WatchLogs(opts, "Initialized")
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(ContractsInitialized)
+				if err := _Contracts.contract.UnpackLog(event, "Initialized", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseInitialized is a log parse operation binding the contract event 0xc7f505b2f371ae2175ee4913f4499e1f2633a7b5936321eed1cdaeb6115181d2.
+//
+// Solidity: event Initialized(uint64 version)
+func (_Contracts *ContractsFilterer) ParseInitialized(log types.Log) (*ContractsInitialized, error) {
+	event := new(ContractsInitialized)
+	if err := _Contracts.contract.UnpackLog(event, "Initialized", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
 // ContractsRoleAdminChangedIterator is returned from FilterRoleAdminChanged and is used to iterate over the raw logs and unpacked data for RoleAdminChanged events raised by the Contracts contract.
 type ContractsRoleAdminChangedIterator struct {
 	Event *ContractsRoleAdminChanged // Event containing the contract specifics and raw log
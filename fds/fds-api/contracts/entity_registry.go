@@ -0,0 +1,61 @@
+package contracts
+
+import (
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// EntityRegistryABI is the subset of the EntityRegistry ABI read by the API
+const EntityRegistryABI = `[{"type":"function","name":"getEntity","inputs":[{"name":"entityAddress","type":"address","internalType":"address"}],"outputs":[{"name":"","type":"tuple","internalType":"structEntity","components":[{"name":"entityAddress","type":"address","internalType":"address"},{"name":"entityType","type":"uint8","internalType":"EntityType"},{"name":"entityData","type":"bytes","internalType":"bytes"},{"name":"verifier","type":"address","internalType":"address"}]}],"stateMutability":"view"},{"type":"function","name":"isVerifiedEntity","inputs":[{"name":"entityAddress","type":"address","internalType":"address"}],"outputs":[{"name":"","type":"bool","internalType":"bool"}],"stateMutability":"view"},{"type":"function","name":"getEntityTypeMetadata","inputs":[{"name":"entityType","type":"uint8","internalType":"EntityType"}],"outputs":[{"name":"","type":"string","internalType":"string"}],"stateMutability":"view"}]`
+
+// Entity is an entity registered in the EntityRegistry
+type Entity struct {
+	EntityAddress common.Address
+	EntityType    uint8
+	EntityData    []byte
+	Verifier      common.Address
+}
+
+// EntityRegistryCaller is a read-only binding to the EntityRegistry contract
+type EntityRegistryCaller struct {
+	contract *bind.BoundContract
+}
+
+// NewEntityRegistryCaller creates a read-only instance of EntityRegistry, bound to a specific deployed contract
+func NewEntityRegistryCaller(address common.Address, caller bind.ContractCaller) (*EntityRegistryCaller, error) {
+	parsed, err := abi.JSON(strings.NewReader(EntityRegistryABI))
+	if err != nil {
+		return nil, err
+	}
+	return &EntityRegistryCaller{contract: bind.NewBoundContract(address, parsed, caller, nil, nil)}, nil
+}
+
+// GetEntity returns the entity registered for an address; the zero entity when none is
+func (r *EntityRegistryCaller) GetEntity(opts *bind.CallOpts, entityAddress common.Address) (Entity, error) {
+	var out []interface{}
+	if err := r.contract.Call(opts, &out, "getEntity", entityAddress); err != nil {
+		return Entity{}, err
+	}
+	return *abi.ConvertType(out[0], new(Entity)).(*Entity), nil
+}
+
+// IsVerifiedEntity reports whether an address is registered by a verifier allowed for its entity type
+func (r *EntityRegistryCaller) IsVerifiedEntity(opts *bind.CallOpts, entityAddress common.Address) (bool, error) {
+	var out []interface{}
+	if err := r.contract.Call(opts, &out, "isVerifiedEntity", entityAddress); err != nil {
+		return false, err
+	}
+	return *abi.ConvertType(out[0], new(bool)).(*bool), nil
+}
+
+// GetEntityTypeMetadata returns the metadata string of an entity type
+func (r *EntityRegistryCaller) GetEntityTypeMetadata(opts *bind.CallOpts, entityType uint8) (string, error) {
+	var out []interface{}
+	if err := r.contract.Call(opts, &out, "getEntityTypeMetadata", entityType); err != nil {
+		return "", err
+	}
+	return *abi.ConvertType(out[0], new(string)).(*string), nil
+}
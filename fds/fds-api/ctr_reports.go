@@ -0,0 +1,78 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// getCtrReports returns the large-value transaction reports, optionally filtered by period
+// and by a from/to date range (YYYY-MM-DD) on the period start
+func getCtrReports(c *gin.Context) {
+	query := db.Model(&CtrReport{}).Order("period_start DESC, period").Limit(500)
+	if period := c.Query("period"); period != "" {
+		query = query.Where("period = ?", period)
+	}
+	for param, op := range map[string]string{"from": ">=", "to": "<="} {
+		if value := c.Query(param); value != "" {
+			day, err := time.Parse("2006-01-02", value)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid %s date", param)})
+				return
+			}
+			query = query.Where("period_start::date "+op+" ?", day.Format("2006-01-02"))
+		}
+	}
+
+	var reports []CtrReport
+	if err := query.Find(&reports).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, reports)
+}
+
+// downloadCtrReport returns the file of a large-value transaction report after checking
+// it against its recorded checksum
+func downloadCtrReport(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid report id"})
+		return
+	}
+	var report CtrReport
+	if err := db.First(&report, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "report not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	content, err := os.ReadFile(filepath.Join(ctrReportDir, filepath.Base(report.FileName)))
+	if err != nil {
+		log.Printf("Error reading CTR report %s: %v", report.FileName, err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "report file not found"})
+		return
+	}
+	sum := sha256.Sum256(content)
+	if hex.EncodeToString(sum[:]) != report.SHA256 {
+		log.Printf("CTR report %s does not match its checksum", report.FileName)
+		c.JSON(http.StatusConflict, gin.H{"error": "report file does not match its checksum"})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", report.FileName))
+	c.Header("X-Content-SHA256", report.SHA256)
+	c.Data(http.StatusOK, "application/json", content)
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// useTestDB points the handlers at an in-memory database with tables for the given models
+func useTestDB(t *testing.T, tables ...interface{}) {
+	t.Helper()
+	test, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("opening test database: %v", err)
+	}
+	// Every connection to :memory: is a database of its own
+	sqlDB, err := test.DB()
+	if err != nil {
+		t.Fatalf("opening test database: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	if err := test.AutoMigrate(tables...); err != nil {
+		t.Fatalf("migrating test database: %v", err)
+	}
+	previous := db
+	db = test
+	t.Cleanup(func() {
+		db = previous
+		sqlDB.Close()
+	})
+}
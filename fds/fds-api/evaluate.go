@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ruleEvaluationWait is how long a dry run request waits for the monitor before answering
+// with the pending evaluation
+const ruleEvaluationWait = 10 * time.Second
+
+// evaluationRequest is a hypothetical transfer to run the rules on, as the monitor reads it
+type evaluationRequest struct {
+	From             string  `json:"from"`
+	To               string  `json:"to"`
+	Amount           string  `json:"amount"`                 // In base units
+	BlockNumber      *uint64 `json:"block_number,omitempty"` // Defaults to the latest stored block
+	Blocked          bool    `json:"blocked"`                // A pending transfer a compliance module blocked
+	ComplianceModule string  `json:"compliance_module,omitempty"`
+}
+
+// evaluationResponse is a dry run with its request and, once done, the outcome of each rule
+type evaluationResponse struct {
+	*RuleEvaluation
+	Request json.RawMessage `json:"request"`
+	Result  json.RawMessage `json:"result,omitempty"`
+}
+
+func newEvaluationResponse(evaluation *RuleEvaluation) evaluationResponse {
+	resp := evaluationResponse{RuleEvaluation: evaluation, Request: json.RawMessage(evaluation.Request)}
+	if evaluation.Result != "" {
+		resp.Result = json.RawMessage(evaluation.Result)
+	}
+	return resp
+}
+
+// evaluateRules runs every rule on a hypothetical transfer against the stored history and
+// returns each rule's outcome: whether it fired, why, the parameter values it read and the
+// evidence. Nothing is recorded or enforced. The monitor runs the dry run; if it takes longer
+// than ruleEvaluationWait the pending evaluation is returned, to poll at
+// GET /api/rules/evaluate/:id.
+func evaluateRules(c *gin.Context) {
+	var req evaluationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !common.IsHexAddress(req.From) || !common.IsHexAddress(req.To) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from and to must be addresses"})
+		return
+	}
+	if amount, ok := new(big.Int).SetString(req.Amount, 10); !ok || amount.Sign() < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "amount must be an integer amount in base units"})
+		return
+	}
+	encoded, err := json.Marshal(req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	evaluation := RuleEvaluation{Status: "pending", RequestedBy: currentUser(c), Request: string(encoded)}
+	if err := db.Create(&evaluation).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+	timeout := time.After(ruleEvaluationWait)
+	for evaluation.Status == "pending" {
+		select {
+		case <-ticker.C:
+			if err := db.First(&evaluation, evaluation.ID).Error; err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+		case <-timeout:
+			c.JSON(http.StatusAccepted, newEvaluationResponse(&evaluation))
+			return
+		case <-c.Request.Context().Done():
+			return
+		}
+	}
+	if evaluation.Status == "failed" {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": evaluation.Error})
+		return
+	}
+	c.JSON(http.StatusOK, newEvaluationResponse(&evaluation))
+}
+
+// getRuleEvaluation returns a dry run with its result once done
+func getRuleEvaluation(c *gin.Context) {
+	var evaluation RuleEvaluation
+	if err := db.First(&evaluation, c.Param("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "evaluation not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, newEvaluationResponse(&evaluation))
+}
@@ -1,17 +1,21 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"log"
 	"net/http"
 	"os"
 
 	"context"
-	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 
 	"fds-api/contracts"
+	"token-monitor/blacklist"
+	monitor "token-monitor/models"
 
 	"math/big"
 
@@ -21,66 +25,37 @@ import (
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 
-	"encoding/json"
+	"strconv"
+	"strings"
 	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 var (
 	rpcURL           string
 	contractAddress  string
-	privateKeyHex    string
 	evndTokenAddress string
+	// EntityRegistry read for the subjects of suspicious transaction reports
+	entityRegistryAddress string
+	// Name of the reporting entity on suspicious transaction reports
+	reportingEntity string
+	// Directory the monitor writes large-value transaction reports to
+	ctrReportDir string
 )
 
 func init() {
 	godotenv.Load()
 	rpcURL = os.Getenv("MAINNET_RPC_URL")
 	contractAddress = os.Getenv("RESTRICT_CONTRACT_ADDRESS")
-	privateKeyHex = os.Getenv("BLACKLIST_PRIVATE_KEY")
 	evndTokenAddress = os.Getenv("EVND_TOKEN_ADDRESS")
-}
-
-func getAuth(client *ethclient.Client) (*bind.TransactOpts, error) {
-	privateKey, _ := crypto.HexToECDSA(strings.TrimPrefix(privateKeyHex, "0x"))
-	chainID, _ := client.NetworkID(context.Background())
-	auth, _ := bind.NewKeyedTransactorWithChainID(privateKey, chainID)
-	return auth, nil
-}
-
-func CallBlacklistContract(addresses []string) (string, error) {
-	client, _ := ethclient.Dial(rpcURL)
-	defer client.Close()
-	auth, _ := getAuth(client)
-	contract, _ := contracts.NewContracts(common.HexToAddress(contractAddress), client)
-
-	var addrList []common.Address
-	for _, addr := range addresses {
-		addrList = append(addrList, common.HexToAddress(addr))
-	}
-
-	tx, err := contract.Blacklist(auth, addrList)
-	if err != nil {
-		return "", err
+	entityRegistryAddress = os.Getenv("ENTITY_REGISTRY_ADDRESS")
+	reportingEntity = os.Getenv("STR_REPORTING_ENTITY")
+	ctrReportDir = os.Getenv("CTR_REPORT_DIR")
+	if ctrReportDir == "" {
+		ctrReportDir = "./reports/ctr"
 	}
-	return tx.Hash().Hex(), nil
-}
-
-func CallUnblacklistContract(addresses []string) (string, error) {
-	client, _ := ethclient.Dial(rpcURL)
-	defer client.Close()
-	auth, _ := getAuth(client)
-	contract, _ := contracts.NewContracts(common.HexToAddress(contractAddress), client)
-
-	var addrList []common.Address
-	for _, addr := range addresses {
-		addrList = append(addrList, common.HexToAddress(addr))
-	}
-
-	tx, err := contract.Unblacklist(auth, addrList)
-	if err != nil {
-		return "", err
-	}
-	return tx.Hash().Hex(), nil
 }
 
 // getAddressTotals returns the total amount in and out for a given address
@@ -112,24 +87,149 @@ func getAddressTotals(c *gin.Context) {
 	})
 }
 
-// getSuspiciousTransactions returns a list of suspicious transactions
+// suspiciousList are the filters and sort columns of the suspicious transfer list
+var suspiciousList = listSpec{
+	Sorts: map[string]listSort{
+		"created_at":   {"created_at", "TIMESTAMPTZ"},
+		"timestamp":    {"timestamp", "TIMESTAMPTZ"},
+		"block_number": {"block_number", "BIGINT"},
+		"amount":       {"CAST(COALESCE(NULLIF(amount, ''), '0') AS NUMERIC)", "NUMERIC"},
+		"severity":     severitySort("severity"),
+	},
+	DefaultSort: "created_at",
+	Filters: []listFilter{
+		inFilter("severity", "severity"),
+		boolFilter("blacklisted", "is_blacklisted"),
+		addressFilter("address", "from_address", "to_address"),
+		addressFilter("from", "from_address"),
+		addressFilter("to", "to_address"),
+		ruleFilter("tx_hash"),
+		timeRangeFilter("timestamp"),
+		amountRangeFilter("amount"),
+		blockRangeFilter("block_number"),
+	},
+}
+
+// ruleFilter matches the transactions that violated any of the comma-separated rule names
+// of the rule parameter
+func ruleFilter(txHashColumn string) listFilter {
+	names := inFilter("rule", "rules.name")
+	return func(c *gin.Context) (func(*gorm.DB) *gorm.DB, error) {
+		scope, err := names(c)
+		if scope == nil || err != nil {
+			return nil, err
+		}
+		violating := db.Model(&RuleViolation{}).
+			Select("rule_violations.tx_hash").
+			Joins("JOIN rules ON rules.id = rule_violations.rule_id").
+			Scopes(scope)
+		return func(q *gorm.DB) *gorm.DB {
+			return q.Where(txHashColumn+" IN (?)", violating)
+		}, nil
+	}
+}
+
+// getSuspiciousTransactions returns a page of suspicious transactions
 func getSuspiciousTransactions(c *gin.Context) {
 	var transactions []SuspiciousTransfer
-	if err := db.Order("created_at DESC").Find(&transactions).Error; err != nil {
+	page, err := listFrom(c).find(db.Model(&SuspiciousTransfer{}), &transactions)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, transactions)
+	c.JSON(http.StatusOK, ListResponse{Data: transactions, Pagination: page})
+}
+
+// blacklistList are the filters and sort columns of the blacklist
+var blacklistList = listSpec{
+	Sorts: map[string]listSort{
+		"created_at":   {"created_at", "TIMESTAMPTZ"},
+		"block_number": {"block_number", "BIGINT"},
+		"address":      {"address", "TEXT"},
+		"severity":     severitySort("severity"),
+	},
+	DefaultSort: "created_at",
+	Filters: []listFilter{
+		inFilter("status", "status"),
+		inFilter("severity", "severity"),
+		inFilter("hold_type", "hold_type"),
+		inFilter("direction", "direction"),
+		addressFilter("address", "address"),
+		timeRangeFilter("created_at"),
+		blockRangeFilter("block_number"),
+	},
 }
 
-// getBlacklist returns a list of blacklisted addresses and their reasons
+// getBlacklist returns a page of blacklisted addresses and their reasons
 func getBlacklist(c *gin.Context) {
 	var addresses []BlacklistedAddress
-	if err := db.Order("created_at DESC").Find(&addresses).Error; err != nil {
+	page, err := listFrom(c).find(db.Model(&BlacklistedAddress{}), &addresses)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, addresses)
+
+	// Attach the restrictions recorded on-chain; the list is still served when the node is unreachable
+	entries := make([]BlacklistEntry, len(addresses))
+	for i, addr := range addresses {
+		entries[i].BlacklistedAddress = addr
+	}
+
+	client, err := ethclient.Dial(rpcURL)
+	if err != nil {
+		log.Printf("Failed to connect to Ethereum node for restriction info: %v", err)
+		c.JSON(http.StatusOK, ListResponse{Data: entries, Pagination: page})
+		return
+	}
+	defer client.Close()
+
+	contract, err := contracts.NewContracts(common.HexToAddress(contractAddress), client)
+	if err != nil {
+		log.Printf("Failed to bind restriction contract: %v", err)
+		c.JSON(http.StatusOK, ListResponse{Data: entries, Pagination: page})
+		return
+	}
+
+	opts := &bind.CallOpts{Context: c.Request.Context()}
+	for i := range entries {
+		account := common.HexToAddress(entries[i].Address)
+		if info, err := contract.GetRestrictionInfoFrom(opts, account); err == nil {
+			entries[i].RestrictionFrom = newOnChainRestriction(info)
+		}
+		if info, err := contract.GetRestrictionInfoTo(opts, account); err == nil {
+			entries[i].RestrictionTo = newOnChainRestriction(info)
+		}
+	}
+
+	c.JSON(http.StatusOK, ListResponse{Data: entries, Pagination: page})
+}
+
+// BlacklistEntry is a blacklisted address together with its on-chain restrictions
+type BlacklistEntry struct {
+	BlacklistedAddress
+	RestrictionFrom *OnChainRestriction `json:"RestrictionFrom,omitempty"` // Restriction from sending
+	RestrictionTo   *OnChainRestriction `json:"RestrictionTo,omitempty"`   // Restriction from receiving
+}
+
+// OnChainRestriction is the restriction info stored by AddressRestrictionCompliance
+type OnChainRestriction struct {
+	IsRestricted bool      `json:"is_restricted"`
+	Reason       string    `json:"reason"`
+	Timestamp    time.Time `json:"timestamp"`
+	RestrictedBy string    `json:"restricted_by"`
+}
+
+// newOnChainRestriction converts restriction info read from the contract
+func newOnChainRestriction(info contracts.AddressRestrictionComplianceRestrictionInfo) *OnChainRestriction {
+	restriction := &OnChainRestriction{
+		IsRestricted: info.IsRestricted,
+		Reason:       info.Reason,
+	}
+	if info.IsRestricted {
+		restriction.Timestamp = time.Unix(info.Timestamp.Int64(), 0)
+		restriction.RestrictedBy = info.RestrictedBy.Hex()
+	}
+	return restriction
 }
 
 // getRelatedAddresses returns addresses that have sent or received tokens from/to the given address
@@ -191,7 +291,25 @@ func getRelatedTransactionsOfSuspicious(c *gin.Context) {
 	c.JSON(http.StatusOK, relatedTxs)
 }
 
-// getTransactionsByAddress returns all transactions where the address is sender or receiver
+// addressTransactionList are the filters and sort columns of the transactions of an address
+var addressTransactionList = listSpec{
+	Sorts: map[string]listSort{
+		"block_number": {"block_number", "BIGINT"},
+		"timestamp":    {"timestamp", "TIMESTAMPTZ"},
+		"amount":       {"CAST(COALESCE(NULLIF(value, ''), '0') AS NUMERIC)", "NUMERIC"},
+	},
+	DefaultSort: "block_number",
+	Filters: []listFilter{
+		inFilter("status", "status"),
+		inFilter("tx_type", "tx_type"),
+		addressFilter("counterparty", "from_address", "to_address"),
+		timeRangeFilter("timestamp"),
+		amountRangeFilter("value"),
+		blockRangeFilter("block_number"),
+	},
+}
+
+// getTransactionsByAddress returns a page of the transactions where the address is sender or receiver
 func getTransactionsByAddress(c *gin.Context) {
 	address := c.Query("address")
 	if address == "" {
@@ -200,17 +318,48 @@ func getTransactionsByAddress(c *gin.Context) {
 	}
 
 	var txs []Transaction
-	if err := db.Unscoped().Where("from_address = ? OR to_address = ?", address, address).
-		Order("block_number DESC").
-		Find(&txs).Error; err != nil {
+	query := db.Unscoped().Model(&Transaction{}).Where("(from_address = ? OR to_address = ?)", address, address)
+	page, err := listFrom(c).find(query, &txs)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, txs)
+	c.JSON(http.StatusOK, ListResponse{Data: txs, Pagination: page})
 }
 
 type BlacklistRequest struct {
-	Addresses []string `json:"addresses"`
+	Addresses      []string `json:"addresses"`
+	Direction      string   `json:"direction"`       // "both" (default), "from" (sending) or "to" (receiving)
+	Reason         string   `json:"reason"`          // Reason written to the contract
+	HoldType       string   `json:"hold_type"`       // "manual" (default) or "law_enforcement"
+	LegalReference string   `json:"legal_reference"` // Order or case reference, required for law-enforcement holds
+	RequestedBy    string   `json:"-"`               // Officer requesting the change, the authenticated user
+}
+
+// normalize validates the direction and hold type and fills in defaults
+func (r *BlacklistRequest) normalize(defaultReason string) error {
+	switch r.Direction {
+	case "":
+		r.Direction = "both"
+	case "both", "from", "to":
+	default:
+		return fmt.Errorf("direction must be one of both, from, to")
+	}
+	switch r.HoldType {
+	case "":
+		r.HoldType = "manual"
+	case "manual":
+	case "law_enforcement":
+		if r.LegalReference == "" {
+			return fmt.Errorf("legal_reference is required for law-enforcement holds")
+		}
+	default:
+		return fmt.Errorf("hold_type must be one of manual, law_enforcement")
+	}
+	if r.Reason == "" {
+		r.Reason = defaultReason
+	}
+	return nil
 }
 
 func blacklistAddresses(c *gin.Context) {
@@ -220,14 +369,118 @@ func blacklistAddresses(c *gin.Context) {
 		return
 	}
 
-	// Call contract
-	txHash, err := CallBlacklistContract(req.Addresses)
-	if err != nil {
+	req.RequestedBy = currentUser(c)
+	if err := req.normalize("Manual blacklist"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	for _, addr := range req.Addresses {
+		if !common.IsHexAddress(addr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid address %s", addr)})
+			return
+		}
+	}
+
+	// Queue the blacklist; the monitor's enforcement executor batches and sends it
+	var queued, skipped []string
+	for _, addr := range req.Addresses {
+		ok, err := blacklist.Enqueue(db, monitor.EnforcementAction{
+			Address:        addr,
+			Direction:      req.Direction,
+			Source:         "api",
+			Reason:         req.Reason,
+			OnChainReason:  req.Reason,
+			Severity:       "high",
+			HoldType:       req.HoldType,
+			LegalReference: req.LegalReference,
+			RequestedBy:    req.RequestedBy,
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if ok {
+			queued = append(queued, addr)
+		} else {
+			skipped = append(skipped, addr)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "queued", "direction": req.Direction, "queued": queued, "skipped": skipped})
+}
+
+// getEnforcementActions returns queued enforcement actions, optionally filtered by status
+func getEnforcementActions(c *gin.Context) {
+	query := db.Order("created_at DESC").Limit(200)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if address := c.Query("address"); address != "" {
+		query = query.Where("address = ?", address)
+	}
+
+	var actions []EnforcementAction
+	if err := query.Find(&actions).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	c.JSON(http.StatusOK, actions)
+}
 
-	c.JSON(http.StatusOK, gin.H{"status": "blacklisted", "addresses": req.Addresses, "txHash": txHash})
+// getBlacklistDrift reports discrepancies between the blacklist table and the restriction contract
+func getBlacklistDrift(c *gin.Context) {
+	var open []BlacklistDrift
+	if err := db.Where("resolved_at IS NULL").Order("last_seen_at DESC").Find(&open).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var resolved []BlacklistDrift
+	if err := db.Where("resolved_at IS NOT NULL").Order("resolved_at DESC").Limit(50).Find(&resolved).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var counts []struct {
+		Kind  string `json:"kind"`
+		Count int64  `json:"count"`
+	}
+	if err := db.Model(&BlacklistDrift{}).
+		Select("kind, COUNT(*) as count").
+		Where("resolved_at IS NULL").
+		Group("kind").
+		Scan(&counts).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var lastBlock uint64
+	if err := db.Model(&RestrictionEvent{}).Select("COALESCE(MAX(block_number), 0)").Scan(&lastBlock).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"open":              open,
+		"by_kind":           counts,
+		"recently_resolved": resolved,
+		"last_event_block":  lastBlock,
+	})
+}
+
+// getRestrictionEvents returns the restriction contract events, optionally for one address
+func getRestrictionEvents(c *gin.Context) {
+	query := db.Order("block_number DESC, log_index DESC").Limit(200)
+	if address := c.Query("address"); address != "" {
+		query = query.Where("address = ?", common.HexToAddress(address).Hex())
+	}
+
+	var events []RestrictionEvent
+	if err := query.Find(&events).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, events)
 }
 
 func unblacklistAddresses(c *gin.Context) {
@@ -237,14 +490,308 @@ func unblacklistAddresses(c *gin.Context) {
 		return
 	}
 
-	// Call contract
-	txHash, err := CallUnblacklistContract(req.Addresses)
+	req.RequestedBy = currentUser(c)
+	if err := req.normalize("Manual unblacklist"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	for _, addr := range req.Addresses {
+		if !common.IsHexAddress(addr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid address %s", addr)})
+			return
+		}
+	}
+
+	// Unblacklisting needs two distinct approvers before the monitor sends it
+	var queued, skipped []string
+	for _, addr := range req.Addresses {
+		ok, err := enqueueUnblacklist(common.HexToAddress(addr).Hex(), req)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if ok {
+			queued = append(queued, addr)
+		} else {
+			skipped = append(skipped, addr)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "awaiting_approval", "direction": req.Direction, "queued": queued, "skipped": skipped})
+}
+
+// enqueueUnblacklist queues an unblacklist that awaits four-eyes approval, unless one is
+// already open for the address and direction. It reports whether a new action was queued.
+func enqueueUnblacklist(address string, req BlacklistRequest) (bool, error) {
+	var count int64
+	if err := db.Model(&EnforcementAction{}).
+		Where("address = ? AND action = ? AND direction IN ? AND status IN ?",
+			address, "unblacklist", []string{req.Direction, "both"}, []string{"awaiting_approval", "pending", "submitted"}).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	if count > 0 {
+		return false, nil
+	}
+
+	action := &EnforcementAction{
+		Address:           address,
+		Action:            "unblacklist",
+		Direction:         req.Direction,
+		Status:            "awaiting_approval",
+		Source:            "api",
+		Reason:            req.Reason,
+		OnChainReason:     req.Reason,
+		RequestedBy:       req.RequestedBy,
+		RequiredApprovals: 2,
+		NextAttemptAt:     time.Now(),
+	}
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(action)
+	return result.RowsAffected > 0, result.Error
+}
+
+// getPendingActions returns the enforcement actions awaiting approval with the approvals so far
+func getPendingActions(c *gin.Context) {
+	var actions []EnforcementAction
+	if err := db.Where("status = ?", "awaiting_approval").Order("created_at").Find(&actions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	type pendingAction struct {
+		EnforcementAction
+		Approvals []ActionApproval `json:"approvals"`
+	}
+	result := make([]pendingAction, len(actions))
+	for i, action := range actions {
+		result[i].EnforcementAction = action
+		if err := db.Where("enforcement_action_id = ?", action.ID).Order("created_at").Find(&result[i].Approvals).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// ActionDecisionRequest is an officer's approval or rejection of an enforcement action
+type ActionDecisionRequest struct {
+	Approver string `json:"-"` // The authenticated user
+	Comment  string `json:"comment"`
+}
+
+// approveAction records an approval; the action is released to the executor once it has
+// the required number of distinct approvers, none of whom may be the requester
+func approveAction(c *gin.Context) {
+	decideAction(c, "approve")
+}
+
+// rejectAction rejects an action awaiting approval
+func rejectAction(c *gin.Context) {
+	decideAction(c, "reject")
+}
+
+func decideAction(c *gin.Context, decision string) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid action id"})
+		return
+	}
+	var req ActionDecisionRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Approver = currentUser(c)
+
+	var action EnforcementAction
+	status := http.StatusOK
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&action, id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				status = http.StatusNotFound
+			}
+			return err
+		}
+		if action.Status != "awaiting_approval" {
+			status = http.StatusConflict
+			return fmt.Errorf("action is %s, not awaiting approval", action.Status)
+		}
+		if action.RequestedBy != "" && action.RequestedBy == req.Approver {
+			status = http.StatusForbidden
+			return fmt.Errorf("the requester cannot decide on their own action")
+		}
+
+		var decided int64
+		if err := tx.Model(&ActionApproval{}).
+			Where("enforcement_action_id = ? AND approver = ?", action.ID, req.Approver).
+			Count(&decided).Error; err != nil {
+			return err
+		}
+		if decided > 0 {
+			status = http.StatusConflict
+			return fmt.Errorf("%s has already decided on this action", req.Approver)
+		}
+
+		if err := tx.Create(&ActionApproval{
+			EnforcementActionID: action.ID,
+			Approver:            req.Approver,
+			Decision:            decision,
+			Comment:             req.Comment,
+		}).Error; err != nil {
+			return err
+		}
+
+		if decision == "reject" {
+			now := time.Now()
+			action.Status = "rejected"
+			return tx.Model(&action).Updates(map[string]interface{}{
+				"status":       "rejected",
+				"last_error":   fmt.Sprintf("rejected by %s: %s", req.Approver, req.Comment),
+				"completed_at": &now,
+			}).Error
+		}
+
+		var approvals int64
+		if err := tx.Model(&ActionApproval{}).
+			Where("enforcement_action_id = ? AND decision = ?", action.ID, "approve").
+			Count(&approvals).Error; err != nil {
+			return err
+		}
+		required := int64(action.RequiredApprovals)
+		if required < 1 {
+			required = 1
+		}
+		if approvals < required {
+			return nil
+		}
+
+		action.Status = "pending"
+		return tx.Model(&action).Updates(map[string]interface{}{
+			"status":          "pending",
+			"next_attempt_at": time.Now(),
+		}).Error
+	})
 	if err != nil {
+		if status == http.StatusOK {
+			status = http.StatusInternalServerError
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": action.Status, "id": action.ID})
+}
+
+// confirmBlacklistEntry confirms an entry, or renews the review of a confirmed one, and
+// cancels a pending expiry. reviewAt may be nil to let the monitor schedule the review.
+func confirmBlacklistEntry(tx *gorm.DB, entry *BlacklistedAddress, officer, reason string, reviewAt *time.Time) error {
+	var submitted int64
+	if err := tx.Model(&EnforcementAction{}).
+		Where("address = ? AND action = ? AND source = ? AND status = ?", entry.Address, "unblacklist", "expiry", "submitted").
+		Count(&submitted).Error; err != nil {
+		return err
+	}
+	if submitted > 0 {
+		return fmt.Errorf("expiry of %s is already on-chain", entry.Address)
+	}
+	if err := tx.Model(&EnforcementAction{}).
+		Where("address = ? AND action = ? AND source = ? AND status = ?", entry.Address, "unblacklist", "expiry", "pending").
+		Updates(map[string]interface{}{"status": "cancelled", "last_error": "cancelled by confirmation"}).Error; err != nil {
+		return err
+	}
+
+	now := time.Now()
+	from := entry.Status
+	if err := tx.Model(entry).Updates(map[string]interface{}{
+		"status":       "confirmed",
+		"expires_at":   nil,
+		"review_at":    reviewAt,
+		"confirmed_by": officer,
+		"confirmed_at": &now,
+	}).Error; err != nil {
+		return err
+	}
+
+	return tx.Create(&BlacklistStatusChange{
+		Address:    entry.Address,
+		FromStatus: from,
+		ToStatus:   "confirmed",
+		Actor:      officer,
+		Reason:     reason,
+	}).Error
+}
+
+// confirmBlacklist confirms a provisional blacklist so that it does not expire, or renews
+// the review of a confirmed one
+func confirmBlacklist(c *gin.Context) {
+	var req struct {
+		Address  string     `json:"address"`
+		Reason   string     `json:"reason"`
+		ReviewAt *time.Time `json:"review_at"` // Optional; the monitor schedules the review otherwise
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || !common.IsHexAddress(req.Address) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "address required"})
+		return
+	}
+
+	var entry BlacklistedAddress
+	if err := db.Where("address = ?", common.HexToAddress(req.Address).Hex()).First(&entry).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "address is not blacklisted"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		c.Set(auditTxHashKey, entry.TxHash)
+		return confirmBlacklistEntry(tx, &entry, currentUser(c), req.Reason, req.ReviewAt)
+	}); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "confirmed", "address": entry.Address})
+}
+
+// getBlacklistReview returns provisional freezes expiring within a day and confirmed
+// entries due for review
+func getBlacklistReview(c *gin.Context) {
+	now := time.Now()
+
+	var expiring []BlacklistedAddress
+	if err := db.Where("status = ? AND expires_at <= ?", "provisional", now.Add(24*time.Hour)).
+		Order("expires_at").
+		Find(&expiring).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var due []BlacklistedAddress
+	if err := db.Where("status = ? AND review_at <= ?", "confirmed", now).
+		Order("review_at").
+		Find(&due).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"status": "unblacklisted", "addresses": req.Addresses, "txHash": txHash})
+	c.JSON(http.StatusOK, gin.H{"expiring": expiring, "review_due": due})
+}
+
+// getBlacklistHistory returns the lifecycle transitions of blacklist entries, optionally for one address
+func getBlacklistHistory(c *gin.Context) {
+	query := db.Order("created_at DESC").Limit(200)
+	if address := c.Query("address"); address != "" {
+		query = query.Where("address = ?", common.HexToAddress(address).Hex())
+	}
+
+	var changes []BlacklistStatusChange
+	if err := query.Find(&changes).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, changes)
 }
 
 func deleteBlacklistAddress(c *gin.Context) {
@@ -256,6 +803,11 @@ func deleteBlacklistAddress(c *gin.Context) {
 		return
 	}
 
+	// The row goes but the on-chain restriction stays; the audit log keeps its transaction
+	var entry BlacklistedAddress
+	if err := db.Where("address = ?", req.Address).First(&entry).Error; err == nil {
+		c.Set(auditTxHashKey, entry.TxHash)
+	}
 	if err := db.Where("address = ?", req.Address).Delete(&BlacklistedAddress{}).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -378,28 +930,75 @@ func getRules(c *gin.Context) {
 	c.JSON(http.StatusOK, rules)
 }
 
-// getRuleViolations returns all rules with their violation counts in the last 24h
+// ruleViolationList are the filters and sort columns of the rule violation counts
+var ruleViolationList = listSpec{
+	IDColumn: "rules.id",
+	Sorts: map[string]listSort{
+		"id":         {"rules.id", "BIGINT"},
+		"name":       {"rules.name", "TEXT"},
+		"violations": {"COALESCE(v.count, 0)", "BIGINT"},
+		"suppressed": {"COALESCE(v.suppressed, 0)", "BIGINT"},
+		"incidents":  {"COALESCE(v.incidents, 0)", "BIGINT"},
+	},
+	DefaultSort: "violations",
+	Filters: []listFilter{
+		inFilter("rule", "rules.name"),
+		inFilter("status", "rules.status"),
+	},
+}
+
+// RuleViolationCount is a rule with the number of its violations in a time window, of
+// those the suppressed repeats, and the incidents they were grouped into
+type RuleViolationCount struct {
+	ID         uint   `json:"id"`
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	Violations int64  `json:"violations"`
+	Suppressed int64  `json:"suppressed"`
+	Incidents  int64  `json:"incidents"`
+}
+
+// getRuleViolations returns a page of rules with their violation counts, by default in the
+// last 24h; since/until (RFC 3339 or YYYY-MM-DD) set another window and from_block/to_block
+// restrict it to a block range
 func getRuleViolations(c *gin.Context) {
-	var rules []Rule
-	if err := db.Order("id").Find(&rules).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	window, err := timeRangeFilter("rule_violations.created_at")(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if window == nil {
+		cutoff := time.Now().Add(-24 * time.Hour)
+		window = func(q *gorm.DB) *gorm.DB { return q.Where("rule_violations.created_at >= ?", cutoff) }
+	}
+	blocks, err := blockRangeFilter("rule_violations.block_number")(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	counts := db.Model(&RuleViolation{}).
+		Select("rule_violations.rule_id, COUNT(*) AS count, " +
+			"COUNT(*) FILTER (WHERE rule_violations.suppressed) AS suppressed, " +
+			"COUNT(DISTINCT rule_violations.incident_id) AS incidents").
+		Scopes(window).
+		Group("rule_violations.rule_id")
+	if blocks != nil {
+		counts = counts.Scopes(blocks)
+	}
 
-	// For each rule, count violations in the last 24h
-	var result []gin.H
-	cutoff := time.Now().Add(-24 * time.Hour)
-	for _, rule := range rules {
-		var count int64
-		db.Model(&RuleViolation{}).Where("rule_id = ? AND created_at >= ?", rule.ID, cutoff).Count(&count)
-		result = append(result, gin.H{
-			"id":         rule.ID,
-			"name":       rule.Name,
-			"status":     rule.Status,
-			"violations": count,
-		})
+	query := db.Table("rules").
+		Select("rules.id, rules.name, rules.status, COALESCE(v.count, 0) AS violations, "+
+			"COALESCE(v.suppressed, 0) AS suppressed, COALESCE(v.incidents, 0) AS incidents").
+		Joins("LEFT JOIN (?) v ON v.rule_id = rules.id", counts).
+		Where("rules.deleted_at IS NULL")
+
+	var result []RuleViolationCount
+	page, err := listFrom(c).find(query, &result)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
 	}
-	c.JSON(http.StatusOK, result)
+	c.JSON(http.StatusOK, ListResponse{Data: result, Pagination: page})
 }
 
 // Add address to suspicious_addresses
@@ -439,19 +1038,58 @@ func removeSuspiciousAddress(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"status": "deleted", "address": req.Address})
 }
 
-// Add address to whitelist_addresses
+// Add address to whitelist_addresses. The entry may be scoped to rule types, a direction
+// and a counterparty; it needs an expiry and takes effect once another officer approves it.
 func addWhitelistAddress(c *gin.Context) {
 	var req struct {
-		Address string `json:"address"`
-		Reason  string `json:"reason"`
+		Address      string     `json:"address"`
+		Reason       string     `json:"reason"`
+		Rules        []string   `json:"rules"`
+		Direction    string     `json:"direction"`
+		Counterparty string     `json:"counterparty"`
+		ExpiresAt    *time.Time `json:"expires_at"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil || req.Address == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "address required"})
 		return
 	}
+	if !common.IsHexAddress(req.Address) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid address"})
+		return
+	}
+	if req.Counterparty != "" && !common.IsHexAddress(req.Counterparty) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid counterparty"})
+		return
+	}
+	for _, rule := range req.Rules {
+		if _, ok := ruleSchemas[rule]; !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unknown rule type %s", rule)})
+			return
+		}
+	}
+	if req.Direction == "" {
+		req.Direction = "both"
+	}
+	if req.Direction != "both" && req.Direction != "from" && req.Direction != "to" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "direction must be both, from or to"})
+		return
+	}
+	if req.ExpiresAt == nil || !req.ExpiresAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "expires_at must be a future time"})
+		return
+	}
+
 	addr := WhitelistAddress{
-		Address: req.Address,
-		Reason:  req.Reason,
+		Address:     common.HexToAddress(req.Address).Hex(),
+		Reason:      req.Reason,
+		Rules:       strings.Join(req.Rules, ","),
+		Direction:   req.Direction,
+		Status:      "pending",
+		ExpiresAt:   req.ExpiresAt,
+		RequestedBy: currentUser(c),
+	}
+	if req.Counterparty != "" {
+		addr.Counterparty = common.HexToAddress(req.Counterparty).Hex()
 	}
 	if err := db.Create(&addr).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -460,20 +1098,83 @@ func addWhitelistAddress(c *gin.Context) {
 	c.JSON(http.StatusOK, addr)
 }
 
-// Remove address from whitelist_addresses
+// approveWhitelistAddress puts a pending whitelist entry into effect; the officer who
+// requested it cannot approve it
+func approveWhitelistAddress(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid whitelist entry id"})
+		return
+	}
+
+	var addr WhitelistAddress
+	status := http.StatusOK
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&addr, id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				status = http.StatusNotFound
+			}
+			return err
+		}
+		if addr.Status != "pending" {
+			status = http.StatusConflict
+			return fmt.Errorf("whitelist entry is %s, not pending", addr.Status)
+		}
+		if addr.ExpiresAt != nil && !addr.ExpiresAt.After(time.Now()) {
+			status = http.StatusConflict
+			return fmt.Errorf("whitelist entry has expired")
+		}
+		approver := currentUser(c)
+		if addr.RequestedBy != "" && addr.RequestedBy == approver {
+			status = http.StatusForbidden
+			return fmt.Errorf("the requester cannot approve their own whitelist entry")
+		}
+		now := time.Now()
+		addr.Status, addr.ApprovedBy, addr.ApprovedAt = "active", approver, &now
+		return tx.Model(&addr).Updates(map[string]interface{}{
+			"status":      addr.Status,
+			"approved_by": approver,
+			"approved_at": now,
+		}).Error
+	})
+	if err != nil {
+		if status == http.StatusOK {
+			status = http.StatusInternalServerError
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, addr)
+}
+
+// Remove address from whitelist_addresses: revokes its entries, or the one with id, which
+// are kept for the audit of past exemptions
 func removeWhitelistAddress(c *gin.Context) {
 	var req struct {
 		Address string `json:"address"`
+		ID      uint   `json:"id"`
 	}
-	if err := c.ShouldBindJSON(&req); err != nil || req.Address == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "address required"})
+	if err := c.ShouldBindJSON(&req); err != nil || req.Address == "" && req.ID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "address or id required"})
 		return
 	}
-	if err := db.Where("address = ?", req.Address).Delete(&WhitelistAddress{}).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	query := db.Model(&WhitelistAddress{}).Where("status <> ?", "revoked")
+	if req.ID != 0 {
+		query = query.Where("id = ?", req.ID)
+	}
+	if req.Address != "" {
+		query = query.Where("LOWER(address) = LOWER(?)", req.Address)
+	}
+	result := query.Updates(map[string]interface{}{
+		"status":     "revoked",
+		"revoked_by": currentUser(c),
+		"revoked_at": time.Now(),
+	})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"status": "deleted", "address": req.Address})
+	c.JSON(http.StatusOK, gin.H{"status": "revoked", "address": req.Address, "id": req.ID, "revoked": result.RowsAffected})
 }
 
 // Get all suspicious addresses
@@ -486,52 +1187,128 @@ func getSuspiciousAddresses(c *gin.Context) {
 	c.JSON(http.StatusOK, addresses)
 }
 
-// Get all whitelist addresses
+// Get the pending and active whitelist entries, or those with the comma-separated statuses
+// of status
 func getWhitelistAddresses(c *gin.Context) {
+	statuses := splitList(c.Query("status"))
+	if len(statuses) == 0 {
+		statuses = []string{"pending", "active"}
+	}
+	query := db.Order("created_at DESC").Where("status IN ?", statuses)
 	var addresses []WhitelistAddress
-	if err := db.Order("created_at DESC").Find(&addresses).Error; err != nil {
+	if err := query.Find(&addresses).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, addresses)
 }
 
-// UpdateRule updates a rule's parameters
+var whitelistExemptionList = listSpec{
+	Sorts: map[string]listSort{
+		"created_at":   {"created_at", "TIMESTAMPTZ"},
+		"block_number": {"block_number", "BIGINT"},
+	},
+	DefaultSort: "created_at",
+	Filters: []listFilter{
+		addressFilter("address", "address"),
+		inFilter("whitelist_id", "whitelist_id"),
+		inFilter("check", "check_name"),
+		inFilter("tx_hash", "tx_hash"),
+		boolFilter("fired", "fired"),
+		timeRangeFilter("created_at"),
+		blockRangeFilter("block_number"),
+	},
+}
+
+// getWhitelistExemptions returns a page of the rule checks the whitelist exempted
+// transactions from; fired=true keeps those that would have flagged them
+func getWhitelistExemptions(c *gin.Context) {
+	var exemptions []WhitelistExemption
+	page, err := listFrom(c).find(db.Model(&WhitelistExemption{}), &exemptions)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, ListResponse{Data: exemptions, Pagination: page})
+}
+
+// UpdateRule updates a rule's parameters, checked against the schema of its type, and
+// records the change as a new version. Severity and actions are kept unless given.
 func updateRule(c *gin.Context) {
 	var req struct {
-		Name        string `json:"name"`
-		Description string `json:"description"`
-		Status      string `json:"status"`
-		Parameters  string `json:"parameters"`
+		Name        string  `json:"name"`
+		Description string  `json:"description"`
+		Status      string  `json:"status"`
+		Parameters  string  `json:"parameters"`
+		Severity    string  `json:"severity"`
+		Actions     *string `json:"actions"`
+		Comment     string  `json:"comment"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Validate parameters JSON
-	var params map[string]interface{}
-	if err := json.Unmarshal([]byte(req.Parameters), &params); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid parameters JSON"})
+	if !ruleStatuses[req.Status] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be active, inactive or shadow"})
 		return
 	}
-
-	// Update rule
-	result := db.Model(&Rule{}).Where("name = ?", req.Name).Updates(map[string]interface{}{
-		"description": req.Description,
-		"status":      req.Status,
-		"parameters":  req.Parameters,
-	})
-	if result.Error != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+	if req.Severity != "" && !ruleSeverities[req.Severity] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "severity must be low, medium or high"})
 		return
 	}
-	if result.RowsAffected == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "rule not found"})
+
+	var rule Rule
+	status := http.StatusOK
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("name = ?", req.Name).First(&rule).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				status = http.StatusNotFound
+				return fmt.Errorf("rule not found")
+			}
+			return err
+		}
+		rule.Description = req.Description
+		rule.Status = req.Status
+		rule.Parameters = req.Parameters
+		if req.Severity != "" {
+			rule.Severity = req.Severity
+		}
+		if req.Actions != nil {
+			rule.Actions = *req.Actions
+		}
+
+		// Validate parameters and actions
+		if err := validateRule(ruleType(&rule), rule.Parameters, rule.Actions); err != nil {
+			status = http.StatusBadRequest
+			return err
+		}
+		if code, err := checkActivation(tx, &rule); err != nil {
+			status = code
+			return err
+		}
+
+		// Update rule
+		if err := tx.Model(&rule).Updates(map[string]interface{}{
+			"description": rule.Description,
+			"status":      rule.Status,
+			"parameters":  rule.Parameters,
+			"severity":    rule.Severity,
+			"actions":     rule.Actions,
+		}).Error; err != nil {
+			return err
+		}
+		return saveRuleVersion(tx, &rule, "update", req.Comment, currentUser(c))
+	})
+	if err != nil {
+		if status == http.StatusOK {
+			status = http.StatusInternalServerError
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"status": "updated", "rule": req.Name})
+	c.JSON(http.StatusOK, gin.H{"status": "updated", "rule": req.Name, "version": rule.Version})
 }
 
 // getTransactionStats returns transaction statistics for charts
@@ -595,3 +1372,119 @@ func getTransactionStats(c *gin.Context) {
 		"hourly_stats":            hourlyStats,
 	})
 }
+
+// getBlockedAttemptStats returns which compliance modules block the most simulated
+// transfers and which addresses keep retrying blocked transfers
+func getBlockedAttemptStats(c *gin.Context) {
+	days, err := strconv.Atoi(c.DefaultQuery("days", "7"))
+	if err != nil || days <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "days must be a positive integer"})
+		return
+	}
+	cutoff := time.Now().Add(-time.Duration(days) * 24 * time.Hour)
+
+	blocked := db.Unscoped().Model(&PendingTransaction{}).
+		Where("status = ? AND compliance_module <> '' AND created_at >= ?", "revert", cutoff)
+
+	// Blocked attempts per compliance module
+	var byModule []struct {
+		ComplianceModule string `json:"compliance_module"`
+		Count            int64  `json:"count"`
+	}
+	if err := blocked.Session(&gorm.Session{}).
+		Select("compliance_module, COUNT(*) AS count").
+		Group("compliance_module").
+		Order("count DESC").
+		Scan(&byModule).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Blocked attempts per failure reason
+	var byReason []struct {
+		ComplianceModule string `json:"compliance_module"`
+		RevertReason     string `json:"revert_reason"`
+		Count            int64  `json:"count"`
+	}
+	if err := blocked.Session(&gorm.Session{}).
+		Select("compliance_module, revert_reason, COUNT(*) AS count").
+		Group("compliance_module, revert_reason").
+		Order("count DESC").
+		Limit(20).
+		Scan(&byReason).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Addresses with the most blocked attempts
+	var topAddresses []struct {
+		Address     string    `json:"address"`
+		Count       int64     `json:"count"`
+		LastAttempt time.Time `json:"last_attempt"`
+		ModuleCount int64     `json:"module_count"`
+	}
+	if err := blocked.Session(&gorm.Session{}).
+		Select("from_address AS address, COUNT(*) AS count, MAX(created_at) AS last_attempt, COUNT(DISTINCT compliance_module) AS module_count").
+		Group("from_address").
+		Order("count DESC").
+		Limit(20).
+		Scan(&topAddresses).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"days":          days,
+		"by_module":     byModule,
+		"by_reason":     byReason,
+		"top_addresses": topAddresses,
+	})
+}
+
+// getPreemptionStats returns how often pre-emptive blacklist transactions landed before
+// the suspicious transactions they targeted
+func getPreemptionStats(c *gin.Context) {
+	var byOutcome []struct {
+		Outcome string `json:"outcome"`
+		Count   int64  `json:"count"`
+	}
+	if err := db.Model(&PreemptiveBlacklist{}).
+		Select("COALESCE(NULLIF(outcome, ''), 'pending') AS outcome, COUNT(*) AS count").
+		Group("COALESCE(NULLIF(outcome, ''), 'pending')").
+		Scan(&byOutcome).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	counts := make(map[string]int64)
+	for _, o := range byOutcome {
+		counts[o.Outcome] = o.Count
+	}
+
+	// Only races that were decided count towards the success rate
+	successRate := 0.0
+	if decided := counts["preceded"] + counts["too_late"]; decided > 0 {
+		successRate = float64(counts["preceded"]) / float64(decided)
+	}
+
+	var avgReplacements float64
+	if err := db.Model(&PreemptiveBlacklist{}).
+		Select("COALESCE(AVG(replacements), 0)").
+		Scan(&avgReplacements).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var recent []PreemptiveBlacklist
+	if err := db.Order("created_at DESC").Limit(20).Find(&recent).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"by_outcome":       counts,
+		"success_rate":     successRate,
+		"avg_replacements": avgReplacements,
+		"recent":           recent,
+	})
+}
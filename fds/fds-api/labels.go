@@ -0,0 +1,495 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var alertLabels = map[string]bool{"true_positive": true, "false_positive": true, "needs_info": true}
+
+// effectiveLabel is the label of a violation in labeledViolations: its own, or that of the
+// suspicious transfer of its transaction
+const effectiveLabel = "COALESCE(vl.label, tl.label)"
+
+// labeledViolations selects the rule violations, as v, joined with their labels
+func labeledViolations() *gorm.DB {
+	return db.Table("rule_violations v").
+		Joins("LEFT JOIN alert_labels vl ON vl.target_type = 'rule_violation' AND vl.target_id = v.id AND vl.deleted_at IS NULL").
+		Joins("LEFT JOIN suspicious_transfers st ON st.tx_hash = v.tx_hash AND st.deleted_at IS NULL").
+		Joins("LEFT JOIN alert_labels tl ON tl.target_type = 'suspicious_transfer' AND tl.target_id = st.id AND tl.deleted_at IS NULL").
+		Where("v.deleted_at IS NULL")
+}
+
+// labelAlert labels the suspicious transfer or rule violation (targetType) with id as a true
+// positive, false positive or as needing more information, replacing any earlier label
+func labelAlert(targetType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+			return
+		}
+		var req struct {
+			Label string `json:"label"`
+			Note  string `json:"note"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil || !alertLabels[req.Label] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "label must be true_positive, false_positive or needs_info"})
+			return
+		}
+
+		label := AlertLabel{TargetType: targetType, TargetID: uint(id), Label: req.Label, Note: req.Note, LabeledBy: currentUser(c)}
+		status := http.StatusOK
+		err = db.Transaction(func(tx *gorm.DB) error {
+			switch targetType {
+			case "rule_violation":
+				var violation RuleViolation
+				if err := tx.First(&violation, id).Error; err != nil {
+					if err == gorm.ErrRecordNotFound {
+						status = http.StatusNotFound
+						return fmt.Errorf("violation not found")
+					}
+					return err
+				}
+				label.TxHash, label.RuleID = violation.TxHash, violation.RuleID
+			default:
+				var transfer SuspiciousTransfer
+				if err := tx.First(&transfer, id).Error; err != nil {
+					if err == gorm.ErrRecordNotFound {
+						status = http.StatusNotFound
+						return fmt.Errorf("suspicious transfer not found")
+					}
+					return err
+				}
+				label.TxHash = transfer.TxHash
+			}
+			return tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "target_type"}, {Name: "target_id"}},
+				DoUpdates: clause.AssignmentColumns([]string{"label", "note", "labeled_by", "updated_at"}),
+			}).Create(&label).Error
+		})
+		if err != nil {
+			if status == http.StatusOK {
+				status = http.StatusInternalServerError
+			}
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, label)
+	}
+}
+
+var alertLabelList = listSpec{
+	Sorts: map[string]listSort{
+		"updated_at": {"updated_at", "TIMESTAMPTZ"},
+		"created_at": {"created_at", "TIMESTAMPTZ"},
+	},
+	DefaultSort: "updated_at",
+	Filters: []listFilter{
+		inFilter("target_type", "target_type"),
+		inFilter("label", "label"),
+		inFilter("labeled_by", "labeled_by"),
+		inFilter("tx_hash", "tx_hash"),
+		labelRuleFilter,
+		timeRangeFilter("updated_at"),
+	},
+}
+
+// labelRuleFilter restricts labels to the violations of the comma-separated rule names of
+// the rule parameter
+func labelRuleFilter(c *gin.Context) (func(*gorm.DB) *gorm.DB, error) {
+	names := splitList(c.Query("rule"))
+	if len(names) == 0 {
+		return nil, nil
+	}
+	return func(q *gorm.DB) *gorm.DB {
+		return q.Where("rule_id IN (?)", db.Unscoped().Model(&Rule{}).Select("id").Where("name IN ?", names))
+	}, nil
+}
+
+// getAlertLabels returns a page of alert labels
+func getAlertLabels(c *gin.Context) {
+	var labels []AlertLabel
+	page, err := listFrom(c).find(db.Model(&AlertLabel{}), &labels)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, ListResponse{Data: labels, Pagination: page})
+}
+
+// labelCounts are the alerts of a period and the analysts' verdicts on them
+type labelCounts struct {
+	Start         *time.Time `json:"start,omitempty"`
+	Alerts        int64      `json:"alerts"`     // Violations that alerted
+	Suppressed    int64      `json:"suppressed"` // Repeats grouped into an incident
+	TruePositive  int64      `json:"true_positive"`
+	FalsePositive int64      `json:"false_positive"`
+	NeedsInfo     int64      `json:"needs_info"`
+	Precision     *float64   `json:"precision"` // Of the true and false positives; null without either
+}
+
+func (l *labelCounts) add(o labelCounts) {
+	l.Alerts += o.Alerts
+	l.Suppressed += o.Suppressed
+	l.TruePositive += o.TruePositive
+	l.FalsePositive += o.FalsePositive
+	l.NeedsInfo += o.NeedsInfo
+}
+
+// precision returns the share of true positives among tp and fp, or nil without either
+func precision(tp, fp int64) *float64 {
+	if tp+fp == 0 {
+		return nil
+	}
+	p := float64(tp) / float64(tp+fp)
+	return &p
+}
+
+// ruleQuality is the precision and alert volume of a rule over time
+type ruleQuality struct {
+	Rule   string        `json:"rule"`
+	Type   string        `json:"type"`
+	Status string        `json:"status"`
+	Total  labelCounts   `json:"total"`
+	Series []labelCounts `json:"series"`
+}
+
+// getRuleQuality reports the alert volume and precision of the rules (or those named in
+// rule) per day or week (bucket) between since and until, by default over the last 30 days,
+// along with the labels of the suspicious transfers. Violations are counted in the period
+// they were raised in, whenever they were labeled.
+func getRuleQuality(c *gin.Context) {
+	bucket := c.DefaultQuery("bucket", "day")
+	step := 24 * time.Hour
+	switch bucket {
+	case "day":
+	case "week":
+		step = 7 * 24 * time.Hour
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "bucket must be day or week"})
+		return
+	}
+	since, err := timeParam(c, "since")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	until, err := timeParam(c, "until")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if until == nil {
+		now := time.Now()
+		until = &now
+	}
+	if since == nil {
+		start := until.AddDate(0, 0, -30)
+		since = &start
+	}
+	if !since.Before(*until) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "since must be before until"})
+		return
+	}
+	if until.Sub(*since)/step > 366 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "too many buckets; use a shorter range or weekly buckets"})
+		return
+	}
+
+	query := db.Where("status <> ?", "shadow")
+	if names := splitList(c.Query("rule")); len(names) > 0 {
+		query = db.Where("name IN ?", names)
+	}
+	var rules []Rule
+	if err := query.Order("name").Find(&rules).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var rows []struct {
+		RuleID uint
+		Bucket time.Time
+		labelCounts
+	}
+	if err := labeledViolations().
+		Select(fmt.Sprintf("v.rule_id, date_trunc('%s', v.created_at AT TIME ZONE 'UTC') AS bucket, ", bucket)+
+			"COUNT(*) FILTER (WHERE v.suppressed IS NOT TRUE) AS alerts, "+
+			"COUNT(*) FILTER (WHERE v.suppressed) AS suppressed, "+
+			"COUNT(*) FILTER (WHERE "+effectiveLabel+" = 'true_positive') AS true_positive, "+
+			"COUNT(*) FILTER (WHERE "+effectiveLabel+" = 'false_positive') AS false_positive, "+
+			"COUNT(*) FILTER (WHERE "+effectiveLabel+" = 'needs_info') AS needs_info").
+		Where("v.created_at >= ? AND v.created_at < ?", *since, *until).
+		Group("v.rule_id, bucket").
+		Scan(&rows).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	counts := map[uint]map[time.Time]labelCounts{}
+	for _, row := range rows {
+		if counts[row.RuleID] == nil {
+			counts[row.RuleID] = map[time.Time]labelCounts{}
+		}
+		counts[row.RuleID][row.Bucket.UTC()] = row.labelCounts
+	}
+
+	qualities := []ruleQuality{}
+	for _, rule := range rules {
+		q := ruleQuality{Rule: rule.Name, Type: ruleType(&rule), Status: rule.Status, Series: []labelCounts{}}
+		for start := since.UTC().Truncate(step); start.Before(*until); start = start.Add(step) {
+			b := counts[rule.ID][start]
+			b.Start = &start
+			b.Precision = precision(b.TruePositive, b.FalsePositive)
+			q.Total.add(b)
+			q.Series = append(q.Series, b)
+		}
+		q.Total.Precision = precision(q.Total.TruePositive, q.Total.FalsePositive)
+		qualities = append(qualities, q)
+	}
+
+	var transfers labelCounts
+	if err := db.Table("suspicious_transfers st").
+		Select("COUNT(*) AS alerts, "+
+			"COUNT(*) FILTER (WHERE l.label = 'true_positive') AS true_positive, "+
+			"COUNT(*) FILTER (WHERE l.label = 'false_positive') AS false_positive, "+
+			"COUNT(*) FILTER (WHERE l.label = 'needs_info') AS needs_info").
+		Joins("LEFT JOIN alert_labels l ON l.target_type = 'suspicious_transfer' AND l.target_id = st.id AND l.deleted_at IS NULL").
+		Where("st.deleted_at IS NULL AND st.created_at >= ? AND st.created_at < ?", *since, *until).
+		Scan(&transfers).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	transfers.Precision = precision(transfers.TruePositive, transfers.FalsePositive)
+
+	c.JSON(http.StatusOK, gin.H{"since": since, "until": until, "bucket": bucket, "rules": qualities, "transfers": transfers})
+}
+
+// ruleTuning describes how to recompute, for a labeled violation, the figure a rule type
+// compares with its tunable parameter
+type ruleTuning struct {
+	Parameter string
+	Strict    bool   // Fires when the figure exceeds the parameter rather than reaches it
+	Join      string // Joins the violation's transaction as t
+	Metric    string // SQL figure of t; ? is the rule's block_range when Windowed
+	Windowed  bool
+}
+
+// ruleTunings are the rule types with a parameter worth tuning, figured as the analyzer does
+var ruleTunings = map[string]ruleTuning{
+	"large_transfer": {
+		Parameter: "threshold",
+		Strict:    true,
+		Join:      "JOIN transactions t ON t.hash = v.tx_hash",
+		Metric:    "CAST(COALESCE(NULLIF(t.value, ''), '0') AS NUMERIC)",
+	},
+	"multiple_incoming_transfers": {
+		Parameter: "threshold",
+		Strict:    true,
+		Join:      "JOIN transactions t ON t.hash = v.tx_hash",
+		// The analyzer adds the transfer to a total that already includes it
+		Metric: "CAST(COALESCE(NULLIF(t.value, ''), '0') AS NUMERIC) + (SELECT COALESCE(SUM(CAST(COALESCE(NULLIF(r.value, ''), '0') AS NUMERIC)), 0) " +
+			"FROM transactions r WHERE r.to_address = t.to_address AND r.block_number BETWEEN t.block_number - ? AND t.block_number)",
+		Windowed: true,
+	},
+	"multiple_transfers": {
+		Parameter: "min_transfers",
+		Join:      "JOIN transactions t ON t.hash = v.tx_hash",
+		Metric:    "(SELECT COUNT(*) FROM transactions r WHERE r.from_address = t.from_address AND r.block_number BETWEEN t.block_number - ? AND t.block_number)",
+		Windowed:  true,
+	},
+	"repeated_blocked_attempts": {
+		Parameter: "min_attempts",
+		Join:      "JOIN pending_transactions t ON t.hash = v.tx_hash",
+		Metric: "(SELECT COUNT(*) FROM pending_transactions r WHERE r.from_address = t.from_address AND r.status = 'revert' " +
+			"AND r.compliance_module <> '' AND r.block_number BETWEEN t.block_number - ? AND t.block_number)",
+		Windowed: true,
+	},
+}
+
+// maxTuningSamples caps the labeled violations a suggestion is derived from, newest first
+const maxTuningSamples = 10000
+
+// parameterSuggestion is a value of a rule parameter and how it would have changed the
+// labeled violations
+type parameterSuggestion struct {
+	Target                 float64  `json:"target"` // Share of false positives asked to drop
+	Value                  string   `json:"value"`
+	FalsePositivesRemoved  int      `json:"false_positives_removed"`
+	FalsePositiveReduction float64  `json:"false_positive_reduction"`
+	TruePositivesLost      int      `json:"true_positives_lost"`
+	Precision              *float64 `json:"precision"`
+}
+
+// suggestValues returns, for each target share of the false positives, the lowest parameter
+// value that would have dropped at least that share of them. A value drops the figures up to
+// it when the rule fires above its parameter (strict), and those below it otherwise.
+func suggestValues(strict bool, tps, fps []*big.Int, targets []float64) []parameterSuggestion {
+	sort.Slice(fps, func(i, j int) bool { return fps[i].Cmp(fps[j]) < 0 })
+	upTo := func(figures []*big.Int, limit *big.Int) int {
+		n := 0
+		for _, f := range figures {
+			if f.Cmp(limit) <= 0 {
+				n++
+			}
+		}
+		return n
+	}
+
+	suggestions := []parameterSuggestion{}
+	if len(fps) == 0 {
+		return suggestions
+	}
+	for _, target := range targets {
+		k := int(math.Ceil(target * float64(len(fps))))
+		if k < 1 {
+			k = 1
+		}
+		pivot := fps[k-1]
+		value := new(big.Int).Set(pivot)
+		if !strict {
+			value.Add(value, big.NewInt(1))
+		}
+		removed, lost := upTo(fps, pivot), upTo(tps, pivot)
+		suggestions = append(suggestions, parameterSuggestion{
+			Target:                 target,
+			Value:                  value.String(),
+			FalsePositivesRemoved:  removed,
+			FalsePositiveReduction: float64(removed) / float64(len(fps)),
+			TruePositivesLost:      lost,
+			Precision:              precision(int64(len(tps)-lost), int64(len(fps)-removed)),
+		})
+	}
+	return suggestions
+}
+
+// parameterValue returns a rule parameter, stored as a JSON string or number, as a string
+func parameterValue(parameters map[string]interface{}, name string) string {
+	switch v := parameters[name].(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return ""
+}
+
+// getRuleSuggestions suggests values of a rule's tunable parameter from its labeled
+// violations (in since/until when given): for each share of false positives in targets
+// (comma-separated, by default 0.25,0.5,0.75,0.9,1), the value that would have dropped them
+// and the true positives it would have lost. Only violations the current parameters would
+// still raise count, each figured again from the stored transactions.
+func getRuleSuggestions(c *gin.Context) {
+	var rule Rule
+	if err := db.Where("name = ?", c.Param("name")).First(&rule).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "rule not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	tuning, ok := ruleTunings[ruleType(&rule)]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("rules of type %s have no tunable parameter", ruleType(&rule))})
+		return
+	}
+
+	targets := []float64{0.25, 0.5, 0.75, 0.9, 1}
+	if value := c.Query("targets"); value != "" {
+		targets = nil
+		for _, s := range splitList(value) {
+			target, err := strconv.ParseFloat(s, 64)
+			if err != nil || target <= 0 || target > 1 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "targets must be shares between 0 and 1"})
+				return
+			}
+			targets = append(targets, target)
+		}
+		sort.Float64s(targets)
+	}
+	window, err := timeRangeFilter("v.created_at")(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var parameters map[string]interface{}
+	if err := json.Unmarshal([]byte(rule.Parameters), &parameters); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid rule parameters"})
+		return
+	}
+	current, ok := new(big.Int).SetString(parameterValue(parameters, tuning.Parameter), 10)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("invalid rule parameter %s", tuning.Parameter)})
+		return
+	}
+	var args []interface{}
+	if tuning.Windowed {
+		blockRange, err := strconv.ParseInt(parameterValue(parameters, "block_range"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid rule parameter block_range"})
+			return
+		}
+		args = append(args, blockRange)
+	}
+
+	var rows []struct {
+		Label  string
+		Figure string
+	}
+	query := labeledViolations().
+		Select(effectiveLabel+" AS label, ("+tuning.Metric+")::TEXT AS figure", args...).
+		Joins(tuning.Join).
+		Where("v.rule_id = ?", rule.ID).
+		Where(effectiveLabel+" IN ?", []string{"true_positive", "false_positive"}).
+		Order("v.id DESC").
+		Limit(maxTuningSamples)
+	if window != nil {
+		query = query.Scopes(window)
+	}
+	if err := query.Scan(&rows).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var tps, fps []*big.Int
+	stale := 0
+	for _, row := range rows {
+		figure, ok := new(big.Int).SetString(row.Figure, 10)
+		if !ok {
+			continue
+		}
+		// Raised under earlier parameters; the current ones already drop it
+		if cmp := figure.Cmp(current); cmp < 0 || cmp == 0 && tuning.Strict {
+			stale++
+			continue
+		}
+		if row.Label == "true_positive" {
+			tps = append(tps, figure)
+		} else {
+			fps = append(fps, figure)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"rule":            rule.Name,
+		"type":            ruleType(&rule),
+		"parameter":       tuning.Parameter,
+		"current":         current.String(),
+		"true_positives":  len(tps),
+		"false_positives": len(fps),
+		"stale":           stale,
+		"precision":       precision(int64(len(tps)), int64(len(fps))),
+		"suggestions":     suggestValues(tuning.Strict, tps, fps, targets),
+	})
+}
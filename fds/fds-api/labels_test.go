@@ -0,0 +1,97 @@
+package main
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestSuggestValues(t *testing.T) {
+	figures := func(values ...int64) []*big.Int {
+		result := make([]*big.Int, len(values))
+		for i, v := range values {
+			result[i] = big.NewInt(v)
+		}
+		return result
+	}
+	ratio := func(p float64) *float64 { return &p }
+
+	cases := []struct {
+		name    string
+		strict  bool
+		tps     []*big.Int
+		fps     []*big.Int
+		targets []float64
+		want    []parameterSuggestion
+	}{
+		{
+			name:    "strict",
+			strict:  true,
+			tps:     figures(25, 50),
+			fps:     figures(40, 10, 30, 20),
+			targets: []float64{0.5, 1},
+			want: []parameterSuggestion{
+				{Target: 0.5, Value: "20", FalsePositivesRemoved: 2, FalsePositiveReduction: 0.5, Precision: ratio(0.5)},
+				{Target: 1, Value: "40", FalsePositivesRemoved: 4, FalsePositiveReduction: 1, TruePositivesLost: 1, Precision: ratio(1)},
+			},
+		},
+		{
+			name:    "non-strict",
+			tps:     figures(25, 50),
+			fps:     figures(40, 10, 30, 20),
+			targets: []float64{0.5, 1},
+			want: []parameterSuggestion{
+				{Target: 0.5, Value: "21", FalsePositivesRemoved: 2, FalsePositiveReduction: 0.5, Precision: ratio(0.5)},
+				{Target: 1, Value: "41", FalsePositivesRemoved: 4, FalsePositiveReduction: 1, TruePositivesLost: 1, Precision: ratio(1)},
+			},
+		},
+		{
+			name:    "ties drop together",
+			strict:  true,
+			tps:     figures(20),
+			fps:     figures(20, 20, 30),
+			targets: []float64{0.25},
+			want: []parameterSuggestion{
+				{Target: 0.25, Value: "20", FalsePositivesRemoved: 2, FalsePositiveReduction: 2.0 / 3, TruePositivesLost: 1, Precision: ratio(0)},
+			},
+		},
+		{
+			name:    "only false positives",
+			fps:     figures(7),
+			targets: []float64{0, 1},
+			want: []parameterSuggestion{
+				{Target: 0, Value: "8", FalsePositivesRemoved: 1, FalsePositiveReduction: 1},
+				{Target: 1, Value: "8", FalsePositivesRemoved: 1, FalsePositiveReduction: 1},
+			},
+		},
+		{
+			name:    "no false positives",
+			strict:  true,
+			tps:     figures(25),
+			targets: []float64{0.5},
+			want:    []parameterSuggestion{},
+		},
+		{
+			name:    "no labeled history",
+			targets: []float64{0.25, 0.5},
+			want:    []parameterSuggestion{},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := suggestValues(tc.strict, tc.tps, tc.fps, tc.targets)
+			if got == nil || len(got) != len(tc.want) {
+				t.Fatalf("got %d suggestions (%v), want %d", len(got), got, len(tc.want))
+			}
+			for i, want := range tc.want {
+				g := got[i]
+				if g.Target != want.Target || g.Value != want.Value || g.FalsePositivesRemoved != want.FalsePositivesRemoved ||
+					g.FalsePositiveReduction != want.FalsePositiveReduction || g.TruePositivesLost != want.TruePositivesLost {
+					t.Errorf("suggestion %d = %+v, want %+v", i, g, want)
+				}
+				if (g.Precision == nil) != (want.Precision == nil) || g.Precision != nil && *g.Precision != *want.Precision {
+					t.Errorf("suggestion %d precision = %v, want %v", i, g.Precision, want.Precision)
+				}
+			}
+		})
+	}
+}
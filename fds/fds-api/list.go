@@ -0,0 +1,364 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Page sizes of list endpoints
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+)
+
+// listSort is a sortable column of a list endpoint
+type listSort struct {
+	Expr string // SQL expression sorted on
+	Type string // Postgres type of Expr, used to compare it with the cursor
+}
+
+// severitySort sorts on the rank of a severity column rather than its text, so low comes
+// before medium and medium before high
+func severitySort(column string) listSort {
+	return listSort{fmt.Sprintf("CASE %s WHEN 'high' THEN 3 WHEN 'medium' THEN 2 WHEN 'low' THEN 1 ELSE 0 END", column), "INTEGER"}
+}
+
+// listFilter turns query parameters into a condition on the list query; it returns nil
+// when its parameters are absent
+type listFilter func(c *gin.Context) (func(*gorm.DB) *gorm.DB, error)
+
+// listSpec declares the filters and sortable columns a list endpoint accepts
+type listSpec struct {
+	IDColumn    string // Unique column breaking ties between equal sort values; "id" if empty
+	Sorts       map[string]listSort
+	DefaultSort string
+	Filters     []listFilter
+}
+
+// listQuery is a validated list request
+type listQuery struct {
+	spec   listSpec
+	scopes []func(*gorm.DB) *gorm.DB
+	sort   string
+	desc   bool
+	limit  int
+	cursor *listCursor
+}
+
+// listCursor is the position after the last row of a page
+type listCursor struct {
+	Sort  string `json:"s"`
+	Desc  bool   `json:"d"`
+	Value string `json:"v"`
+	ID    uint   `json:"id"`
+}
+
+// Pagination describes a page in a list response
+type Pagination struct {
+	Total      int64  `json:"total"` // Rows matching the filters
+	Limit      int    `json:"limit"`
+	Sort       string `json:"sort"`
+	Order      string `json:"order"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	HasMore    bool   `json:"has_more"`
+}
+
+// ListResponse is the envelope of every list response
+type ListResponse struct {
+	Data       interface{} `json:"data"`
+	Pagination Pagination  `json:"pagination"`
+}
+
+// paginated parses the limit, cursor, sort, order and filter parameters of a list request,
+// rejecting invalid ones, and leaves the query for the handler
+func paginated(spec listSpec) gin.HandlerFunc {
+	if spec.IDColumn == "" {
+		spec.IDColumn = "id"
+	}
+	return func(c *gin.Context) {
+		q := &listQuery{spec: spec, sort: spec.DefaultSort, desc: true, limit: defaultPageSize}
+
+		if value := c.Query("limit"); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 || n > maxPageSize {
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("limit must be between 1 and %d", maxPageSize)})
+				return
+			}
+			q.limit = n
+		}
+		if value := c.Query("sort"); value != "" {
+			if _, ok := spec.Sorts[value]; !ok {
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("cannot sort by %s", value)})
+				return
+			}
+			q.sort = value
+		}
+		switch c.DefaultQuery("order", "desc") {
+		case "desc":
+		case "asc":
+			q.desc = false
+		default:
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "order must be asc or desc"})
+			return
+		}
+		if value := c.Query("cursor"); value != "" {
+			cursor, err := decodeCursor(value)
+			if err != nil || cursor.Sort != q.sort || cursor.Desc != q.desc {
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid cursor for this sort order"})
+				return
+			}
+			q.cursor = cursor
+		}
+
+		for _, filter := range spec.Filters {
+			scope, err := filter(c)
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			if scope != nil {
+				q.scopes = append(q.scopes, scope)
+			}
+		}
+
+		c.Set("list_query", q)
+		c.Next()
+	}
+}
+
+// listFrom returns the list query parsed by paginated
+func listFrom(c *gin.Context) *listQuery {
+	return c.MustGet("list_query").(*listQuery)
+}
+
+func decodeCursor(value string) (*listCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	var cursor listCursor
+	if err := json.Unmarshal(raw, &cursor); err != nil {
+		return nil, err
+	}
+	return &cursor, nil
+}
+
+func (cur listCursor) encode() string {
+	raw, _ := json.Marshal(cur)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// find loads the page of query selected by the request into dest, which must be a pointer
+// to a slice of the rows of query
+func (q *listQuery) find(query *gorm.DB, dest interface{}) (Pagination, error) {
+	sort := q.spec.Sorts[q.sort]
+	page := Pagination{Limit: q.limit, Sort: q.sort, Order: "desc"}
+	if !q.desc {
+		page.Order = "asc"
+	}
+	dir, cmp := "DESC", "<"
+	if !q.desc {
+		dir, cmp = "ASC", ">"
+	}
+	orderBy := fmt.Sprintf("%s %s, %s %s", sort.Expr, dir, q.spec.IDColumn, dir)
+
+	filtered := query.Session(&gorm.Session{}).Scopes(q.scopes...)
+	if err := filtered.Session(&gorm.Session{}).Select("COUNT(*)").Scan(&page.Total).Error; err != nil {
+		return page, err
+	}
+
+	keysQuery := filtered.Session(&gorm.Session{})
+	if q.cursor != nil {
+		keysQuery = keysQuery.Where(
+			fmt.Sprintf("(%s, %s) %s (CAST(? AS %s), ?)", sort.Expr, q.spec.IDColumn, cmp, sort.Type),
+			q.cursor.Value, q.cursor.ID)
+	}
+	var keys []struct {
+		ID      uint
+		SortKey string
+	}
+	if err := keysQuery.
+		Select(fmt.Sprintf("%s AS id, CAST(%s AS TEXT) AS sort_key", q.spec.IDColumn, sort.Expr)).
+		Order(orderBy).
+		Limit(q.limit + 1).
+		Scan(&keys).Error; err != nil {
+		return page, err
+	}
+
+	if len(keys) > q.limit {
+		keys = keys[:q.limit]
+		last := keys[len(keys)-1]
+		page.HasMore = true
+		page.NextCursor = listCursor{Sort: q.sort, Desc: q.desc, Value: last.SortKey, ID: last.ID}.encode()
+	}
+
+	ids := make([]uint, len(keys))
+	for i, key := range keys {
+		ids[i] = key.ID
+	}
+	if len(ids) == 0 {
+		// Keeps dest an empty slice rather than null
+		return page, query.Session(&gorm.Session{}).Where("1 = 0").Find(dest).Error
+	}
+	return page, query.Session(&gorm.Session{}).Where(q.spec.IDColumn+" IN ?", ids).Order(orderBy).Find(dest).Error
+}
+
+// inFilter matches column against the comma-separated values of param
+func inFilter(param, column string) listFilter {
+	return func(c *gin.Context) (func(*gorm.DB) *gorm.DB, error) {
+		value := c.Query(param)
+		if value == "" {
+			return nil, nil
+		}
+		var values []string
+		for _, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+		return func(db *gorm.DB) *gorm.DB {
+			return db.Where(column+" IN ?", values)
+		}, nil
+	}
+}
+
+// boolFilter matches a boolean column against param
+func boolFilter(param, column string) listFilter {
+	return func(c *gin.Context) (func(*gorm.DB) *gorm.DB, error) {
+		value := c.Query(param)
+		if value == "" {
+			return nil, nil
+		}
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be true or false", param)
+		}
+		return func(db *gorm.DB) *gorm.DB {
+			return db.Where(column+" = ?", b)
+		}, nil
+	}
+}
+
+// addressFilter matches the address in param against any of columns
+func addressFilter(param string, columns ...string) listFilter {
+	return func(c *gin.Context) (func(*gorm.DB) *gorm.DB, error) {
+		value := c.Query(param)
+		if value == "" {
+			return nil, nil
+		}
+		if !common.IsHexAddress(value) {
+			return nil, fmt.Errorf("invalid %s", param)
+		}
+		address := common.HexToAddress(value).Hex()
+		conditions := make([]string, len(columns))
+		args := make([]interface{}, len(columns))
+		for i, column := range columns {
+			conditions[i] = column + " = ?"
+			args[i] = address
+		}
+		return func(db *gorm.DB) *gorm.DB {
+			return db.Where("("+strings.Join(conditions, " OR ")+")", args...)
+		}, nil
+	}
+}
+
+// timeRangeFilter restricts column to the since/until range; both take RFC 3339 times or
+// YYYY-MM-DD dates, until being exclusive and covering the whole of a date
+func timeRangeFilter(column string) listFilter {
+	return func(c *gin.Context) (func(*gorm.DB) *gorm.DB, error) {
+		var bounds []func(*gorm.DB) *gorm.DB
+		for param, op := range map[string]string{"since": ">=", "until": "<"} {
+			t, err := timeParam(c, param)
+			if err != nil {
+				return nil, err
+			}
+			if t == nil {
+				continue
+			}
+			cond := column + " " + op + " ?"
+			bounds = append(bounds, func(db *gorm.DB) *gorm.DB { return db.Where(cond, *t) })
+		}
+		return chainScopes(bounds), nil
+	}
+}
+
+// timeParam parses the since or until parameter, an RFC 3339 time or a YYYY-MM-DD date; an
+// until date covers the whole day. It returns nil when the parameter is absent.
+func timeParam(c *gin.Context, param string) (*time.Time, error) {
+	value := c.Query(param)
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		day, dayErr := time.Parse("2006-01-02", value)
+		if dayErr != nil {
+			return nil, fmt.Errorf("%s must be an RFC 3339 time or a YYYY-MM-DD date", param)
+		}
+		t = day
+		if param == "until" {
+			t = day.AddDate(0, 0, 1)
+		}
+	}
+	return &t, nil
+}
+
+// amountRangeFilter restricts a numeric text column to the min_amount/max_amount range, in
+// token base units
+func amountRangeFilter(column string) listFilter {
+	return func(c *gin.Context) (func(*gorm.DB) *gorm.DB, error) {
+		var bounds []func(*gorm.DB) *gorm.DB
+		for param, op := range map[string]string{"min_amount": ">=", "max_amount": "<="} {
+			value := c.Query(param)
+			if value == "" {
+				continue
+			}
+			if _, ok := new(big.Int).SetString(value, 10); !ok {
+				return nil, fmt.Errorf("%s must be an integer amount in base units", param)
+			}
+			cond := fmt.Sprintf("%s ~ '^[0-9]+$' AND CAST(%s AS NUMERIC) %s CAST(? AS NUMERIC)", column, column, op)
+			bounds = append(bounds, func(db *gorm.DB) *gorm.DB { return db.Where(cond, value) })
+		}
+		return chainScopes(bounds), nil
+	}
+}
+
+// blockRangeFilter restricts column to the inclusive from_block/to_block range
+func blockRangeFilter(column string) listFilter {
+	return func(c *gin.Context) (func(*gorm.DB) *gorm.DB, error) {
+		var bounds []func(*gorm.DB) *gorm.DB
+		for param, op := range map[string]string{"from_block": ">=", "to_block": "<="} {
+			value := c.Query(param)
+			if value == "" {
+				continue
+			}
+			block, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%s must be a block number", param)
+			}
+			cond := column + " " + op + " ?"
+			bounds = append(bounds, func(db *gorm.DB) *gorm.DB { return db.Where(cond, block) })
+		}
+		return chainScopes(bounds), nil
+	}
+}
+
+// chainScopes combines scopes into one, or nil if there are none
+func chainScopes(scopes []func(*gorm.DB) *gorm.DB) func(*gorm.DB) *gorm.DB {
+	if len(scopes) == 0 {
+		return nil
+	}
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Scopes(scopes...)
+	}
+}
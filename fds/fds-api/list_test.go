@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// listPage requests a page of suspicious transfers and returns the status, IDs and pagination
+func listPage(t *testing.T, params url.Values) (int, []uint, Pagination) {
+	t.Helper()
+	r := gin.New()
+	r.GET("/", paginated(suspiciousList), getSuspiciousTransactions)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/?"+params.Encode(), nil))
+	if w.Code != http.StatusOK {
+		return w.Code, nil, Pagination{}
+	}
+	var response struct {
+		Data       []SuspiciousTransfer `json:"data"`
+		Pagination Pagination           `json:"pagination"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if response.Data == nil {
+		t.Fatalf("data is null: %s", w.Body.String())
+	}
+	ids := make([]uint, len(response.Data))
+	for i, transfer := range response.Data {
+		ids[i] = transfer.ID
+	}
+	return w.Code, ids, response.Pagination
+}
+
+func TestListPagination(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	useTestDB(t, &SuspiciousTransfer{})
+	// Blocks repeat so ties are broken by ID
+	severities := []string{"low", "high", "medium", "high", "low"}
+	for i, severity := range severities {
+		db.Create(&SuspiciousTransfer{TxHash: fmt.Sprintf("0x%02d", i+1), BlockNumber: uint64(100 + i/2), Severity: severity, Amount: "1"})
+	}
+
+	cases := []struct {
+		name   string
+		params url.Values
+		pages  [][]uint
+	}{
+		{"one page of all", url.Values{"sort": {"block_number"}, "limit": {"5"}}, [][]uint{{5, 4, 3, 2, 1}}},
+		{"more than all", url.Values{"sort": {"block_number"}, "limit": {"500"}}, [][]uint{{5, 4, 3, 2, 1}}},
+		{"one short", url.Values{"sort": {"block_number"}, "limit": {"4"}}, [][]uint{{5, 4, 3, 2}, {1}}},
+		{"page boundary in a tie", url.Values{"sort": {"block_number"}, "limit": {"2"}}, [][]uint{{5, 4}, {3, 2}, {1}}},
+		{"ascending", url.Values{"sort": {"block_number"}, "order": {"asc"}, "limit": {"3"}}, [][]uint{{1, 2, 3}, {4, 5}}},
+		{"severity by rank", url.Values{"sort": {"severity"}, "limit": {"2"}}, [][]uint{{4, 2}, {3, 5}, {1}}},
+		{"severity ascending", url.Values{"sort": {"severity"}, "order": {"asc"}, "limit": {"5"}}, [][]uint{{1, 5, 3, 2, 4}}},
+		{"nothing matches", url.Values{"severity": {"critical"}}, [][]uint{{}}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			params := url.Values{}
+			for k, v := range tc.params {
+				params[k] = v
+			}
+			for i, want := range tc.pages {
+				status, ids, page := listPage(t, params)
+				if status != http.StatusOK {
+					t.Fatalf("page %d: status %d", i+1, status)
+				}
+				if fmt.Sprint(ids) != fmt.Sprint(want) {
+					t.Errorf("page %d: %v, want %v", i+1, ids, want)
+				}
+				last := i == len(tc.pages)-1
+				if page.HasMore == last || (page.NextCursor == "") != last {
+					t.Errorf("page %d: has_more %v, cursor %q, last page %v", i+1, page.HasMore, page.NextCursor, last)
+				}
+				if want := int64(len(severities)); tc.params.Get("severity") == "" && page.Total != want {
+					t.Errorf("page %d: total %d, want %d", i+1, page.Total, want)
+				}
+				params.Set("cursor", page.NextCursor)
+			}
+		})
+	}
+
+	_, _, page := listPage(t, url.Values{"sort": {"block_number"}, "limit": {"1"}})
+	for _, params := range []url.Values{
+		{"limit": {"0"}},
+		{"limit": {"501"}},
+		{"sort": {"reason"}},
+		{"order": {"sideways"}},
+		{"cursor": {"not base64!"}},
+		{"sort": {"severity"}, "limit": {"1"}, "cursor": {page.NextCursor}},
+		{"sort": {"block_number"}, "order": {"asc"}, "cursor": {page.NextCursor}},
+	} {
+		if status, _, _ := listPage(t, params); status != http.StatusBadRequest {
+			t.Errorf("%v: status %d, want %d", params, status, http.StatusBadRequest)
+		}
+	}
+}
@@ -1,9 +1,11 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
+	"time"
 
 	"github.com/joho/godotenv"
 
@@ -42,13 +44,25 @@ func initializeDefaultRules() error {
 			Status:      "active",
 			Parameters:  `{"check_blocks": "10"}`,
 		},
+		{
+			Name:        "repeated_blocked_attempts",
+			Description: "Detect addresses repeatedly attempting transfers blocked by compliance modules",
+			Status:      "active",
+			Parameters:  `{"min_attempts": "3", "block_range": "100"}`,
+		},
 	}
 
 	for _, rule := range defaultRules {
 		var existingRule Rule
 		if err := db.Where("name = ?", rule.Name).First(&existingRule).Error; err != nil {
 			if err == gorm.ErrRecordNotFound {
-				if err := db.Create(&rule).Error; err != nil {
+				rule.Type = rule.Name
+				if err := db.Transaction(func(tx *gorm.DB) error {
+					if err := tx.Create(&rule).Error; err != nil {
+						return err
+					}
+					return saveRuleVersion(tx, &rule, "create", "Initial version", "system")
+				}); err != nil {
 					return fmt.Errorf("failed to create rule %s: %w", rule.Name, err)
 				}
 				log.Printf("Created default rule: %s", rule.Name)
@@ -68,6 +82,7 @@ func migrateDatabase() error {
 		&SuspiciousTransfer{},
 		&BlacklistedAddress{},
 		&Rule{},
+		&RuleVersion{},
 		&RuleViolation{},
 		&SuspiciousAddress{},
 		&WhitelistAddress{},
@@ -112,60 +127,145 @@ func main() {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 
+	if err := loadStrSchema(os.Getenv("STR_SCHEMA_PATH")); err != nil {
+		log.Fatalf("Failed to load STR schema: %v", err)
+	}
+
+	loadAuthConfig()
+	if err := bootstrapAdmin(); err != nil {
+		log.Printf("Warning: Failed to create initial admin user: %v", err)
+	}
+
 	// Initialize default rules
 	if err := initializeDefaultRules(); err != nil {
 		log.Printf("Warning: Failed to initialize default rules: %v", err)
 	}
 
+	// Push new alerts to stream clients
+	go stream.run(context.Background(), dsn, time.Duration(getEnvInt("STREAM_RETENTION_HOURS", 168))*time.Hour)
+
 	// Set up Gin router
 	r := gin.Default()
 
-	// Add CORS middleware
-	r.Use(func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
-
-		// Disable caching
-		c.Writer.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
-		c.Writer.Header().Set("Pragma", "no-cache")
-		c.Writer.Header().Set("Expires", "0")
-
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
-			return
-		}
+	// Add CORS middleware; only the configured origins may call the API from a browser
+	r.Use(corsMiddleware)
 
-		c.Next()
-	})
+	viewer := requireRole(RoleViewer)
+	analyst := requireRole(RoleAnalyst)
+	officer := requireRole(RoleComplianceOfficer)
+	admin := requireRole(RoleAdmin)
+
+	// Authentication and local users
+	r.POST("/api/auth/login", login)
+	r.GET("/api/auth/me", viewer, getCurrentUser)
+	r.GET("/api/auth/users", admin, getUsers)
+	r.POST("/api/auth/users", admin, audited("create_user"), createUser)
+	r.PUT("/api/auth/users/:id", admin, audited("update_user"), updateUser)
 
 	// Register API endpoints
-	r.GET("/api/address/totals", getAddressTotals)
-	r.GET("/api/suspicious", getSuspiciousTransactions)
-	r.GET("/api/blacklist", getBlacklist)
-	r.GET("/api/address/related", getRelatedAddresses)
-	r.GET("/api/address/transactions", getTransactionsByAddress)
-	r.GET("/api/suspicious/related", getRelatedTransactionsOfSuspicious)
-	r.GET("/api/balance/eth", getETHBalance)
-	r.GET("/api/balance/evnd", getEVNDBalance)
-	r.POST("/api/blacklist", blacklistAddresses)
-	r.POST("/api/unblacklist", unblacklistAddresses)
-	r.DELETE("/api/blacklist", deleteBlacklistAddress)
+	r.GET("/api/address/totals", viewer, getAddressTotals)
+	r.GET("/api/suspicious", viewer, paginated(suspiciousList), getSuspiciousTransactions)
+	r.GET("/api/blacklist", viewer, paginated(blacklistList), getBlacklist)
+	r.GET("/api/address/related", viewer, getRelatedAddresses)
+	r.GET("/api/address/transactions", viewer, paginated(addressTransactionList), getTransactionsByAddress)
+	r.GET("/api/suspicious/related", viewer, getRelatedTransactionsOfSuspicious)
+	r.GET("/api/stream", viewer, streamEvents)
+	r.GET("/api/balance/eth", viewer, getETHBalance)
+	r.GET("/api/balance/evnd", viewer, getEVNDBalance)
+	r.POST("/api/blacklist", officer, audited("blacklist"), blacklistAddresses)
+	r.POST("/api/unblacklist", officer, audited("unblacklist"), unblacklistAddresses)
+	r.DELETE("/api/blacklist", admin, audited("delete_blacklist_row"), deleteBlacklistAddress)
 	// New endpoints for rules
-	r.GET("/api/rules", getRules)
-	r.GET("/api/rules/violations", getRuleViolations)
-	r.PUT("/api/rules", updateRule)
+	r.GET("/api/rules", viewer, getRules)
+	r.GET("/api/rules/violations", viewer, paginated(ruleViolationList), getRuleViolations)
+	r.PUT("/api/rules", admin, audited("update_rule"), updateRule)
+	r.POST("/api/rules", admin, audited("create_rule"), createRule)
+	r.GET("/api/rules/schemas", viewer, getRuleSchemas)
+	r.POST("/api/rules/:name/clone", admin, audited("clone_rule"), cloneRule)
+	r.DELETE("/api/rules/:name", admin, audited("delete_rule"), deleteRule)
+	r.GET("/api/rules/:name/versions", viewer, getRuleVersions)
+	r.POST("/api/rules/:name/rollback", admin, audited("rollback_rule"), rollbackRule)
+	r.POST("/api/rules/evaluate", analyst, audited("evaluate_rules"), evaluateRules)
+	r.GET("/api/rules/evaluate/:id", viewer, getRuleEvaluation)
+	r.POST("/api/rules/backtest", analyst, audited("backtest_rules"), createBacktest)
+	r.GET("/api/rules/backtest", viewer, paginated(backtestList), getBacktests)
+	r.GET("/api/rules/backtest/:id", viewer, getBacktest)
+	r.POST("/api/rules/shadow", admin, audited("create_shadow_rule"), createShadowRule)
+	r.POST("/api/rules/shadow/:name/promote", admin, audited("promote_shadow_rule"), promoteShadowRule)
+	r.GET("/api/rules/shadow/report", viewer, getShadowReport)
+	r.GET("/api/rules/quality", viewer, getRuleQuality)
+	r.GET("/api/rules/:name/suggestions", viewer, getRuleSuggestions)
+
+	r.GET("/api/labels", viewer, paginated(alertLabelList), getAlertLabels)
+	r.POST("/api/suspicious/:id/label", analyst, audited("label_alert"), labelAlert("suspicious_transfer"))
+	r.POST("/api/rules/violations/:id/label", analyst, audited("label_alert"), labelAlert("rule_violation"))
 	// Transaction statistics endpoint
-	r.GET("/api/transactions/stats", getTransactionStats)
+	r.GET("/api/transactions/stats", viewer, getTransactionStats)
+	// Compliance-blocked attempts analytics
+	r.GET("/api/compliance/blocked", viewer, getBlockedAttemptStats)
+	// Enforcement queue
+	r.GET("/api/enforcement/actions", viewer, getEnforcementActions)
+	// Enforcement actions awaiting approval
+	r.GET("/api/actions/pending", viewer, getPendingActions)
+	r.POST("/api/actions/:id/approve", officer, audited("approve_action"), approveAction)
+	r.POST("/api/actions/:id/reject", officer, audited("reject_action"), rejectAction)
+	// Case management
+	r.GET("/api/cases", viewer, getCases)
+	r.POST("/api/cases", analyst, audited("create_case"), createCase)
+	r.GET("/api/cases/:id", viewer, getCase)
+	r.PUT("/api/cases/:id", analyst, audited("update_case"), updateCase)
+	r.POST("/api/cases/:id/notes", analyst, audited("add_case_note"), addCaseNote)
+	r.POST("/api/cases/:id/attachments", analyst, audited("add_case_attachment"), addCaseAttachment)
+	r.POST("/api/cases/:id/addresses", analyst, audited("add_case_address"), addCaseAddress)
+	r.GET("/api/cases/:id/history", viewer, getCaseHistory)
+	// Suspicious Transaction Reports
+	r.POST("/api/reports/str", officer, audited("file_str_report"), createStrReport)
+	r.GET("/api/reports", viewer, getStrReports)
+	r.GET("/api/reports/:id/download", officer, downloadStrReport)
+	// Large-value transaction reports
+	r.GET("/api/reports/ctr", viewer, getCtrReports)
+	r.GET("/api/reports/ctr/:id/download", officer, downloadCtrReport)
+	// Notification delivery log
+	r.GET("/api/notifications/deliveries", viewer, paginated(notificationDeliveryList), getNotificationDeliveries)
+	r.POST("/api/notifications/deliveries/:id/retry", officer, audited("retry_notification"), retryNotificationDelivery)
+	// Alert incidents and snoozes
+	r.GET("/api/alerts/incidents", viewer, paginated(alertIncidentList), getAlertIncidents)
+	r.GET("/api/alerts/incidents/:id", viewer, getAlertIncident)
+	r.POST("/api/alerts/incidents/:id/resolve", analyst, audited("resolve_incident"), resolveAlertIncident)
+	r.GET("/api/alerts/snoozes", viewer, paginated(alertSnoozeList), getAlertSnoozes)
+	r.POST("/api/alerts/snoozes", analyst, audited("snooze_alerts"), createAlertSnooze)
+	r.DELETE("/api/alerts/snoozes/:id", analyst, audited("delete_snooze"), deleteAlertSnooze)
+	// Audit log of operator and enforcement actions
+	r.GET("/api/audit", officer, getAuditLog)
+	r.GET("/api/audit/verify", officer, verifyAuditLog)
+	// Blacklist reconciliation with the restriction contract
+	r.GET("/api/blacklist/drift", viewer, getBlacklistDrift)
+	r.GET("/api/blacklist/events", viewer, getRestrictionEvents)
+	// Blacklist lifecycle
+	r.POST("/api/blacklist/confirm", officer, audited("confirm_blacklist"), confirmBlacklist)
+	r.GET("/api/blacklist/review", viewer, getBlacklistReview)
+	r.GET("/api/blacklist/history", viewer, getBlacklistHistory)
+	// Pre-emptive blacklist outcomes
+	r.GET("/api/preemption/stats", viewer, getPreemptionStats)
 	// New endpoints for suspicious/whitelist/blacklist management
-	r.GET("/api/suspicious-addresses", getSuspiciousAddresses)
-	r.GET("/api/whitelist-addresses", getWhitelistAddresses)
+	r.GET("/api/suspicious-addresses", viewer, getSuspiciousAddresses)
+	r.GET("/api/whitelist-addresses", viewer, getWhitelistAddresses)
+	r.GET("/api/whitelist-addresses/exemptions", viewer, paginated(whitelistExemptionList), getWhitelistExemptions)
 
-	r.POST("/api/suspicious-addresses/add", addSuspiciousAddress)
-	r.POST("/api/suspicious-addresses/remove", removeSuspiciousAddress)
-	r.POST("/api/whitelist-addresses/add", addWhitelistAddress)
-	r.POST("/api/whitelist-addresses/remove", removeWhitelistAddress)
+	r.POST("/api/suspicious-addresses/add", analyst, audited("add_suspicious_address"), addSuspiciousAddress)
+	r.POST("/api/suspicious-addresses/remove", analyst, audited("remove_suspicious_address"), removeSuspiciousAddress)
+	r.POST("/api/whitelist-addresses/add", officer, audited("add_whitelist_address"), addWhitelistAddress)
+	r.POST("/api/whitelist-addresses/remove", officer, audited("remove_whitelist_address"), removeWhitelistAddress)
+	r.POST("/api/whitelist-addresses/:id/approve", officer, audited("approve_whitelist_address"), approveWhitelistAddress)
+	// Watchlists imported by the monitor
+	r.GET("/api/watchlists/sources", viewer, getWatchlistSources)
+	r.POST("/api/watchlists/sources", admin, audited("create_watchlist_source"), createWatchlistSource)
+	r.PUT("/api/watchlists/sources/:id", admin, audited("update_watchlist_source"), updateWatchlistSource)
+	r.POST("/api/watchlists/sources/:id/sync", admin, audited("sync_watchlist"), syncWatchlistSource)
+	r.GET("/api/watchlists/sources/:id/versions", viewer, getWatchlistVersions)
+	r.GET("/api/watchlists/entries", viewer, paginated(watchlistEntryList), getWatchlistEntries)
+	r.GET("/api/watchlists/changes", viewer, paginated(watchlistChangeList), getWatchlistChanges)
+	r.GET("/api/watchlists/matches", viewer, paginated(watchlistMatchList), getWatchlistMatches)
 	// Start the server
 	port := os.Getenv("PORT")
 	if port == "" {
@@ -18,6 +18,116 @@ type Transaction struct {
 	IsAnalyzed  bool
 	IsPending   bool   `gorm:"default:false"`
 	Status      string `gorm:"default:'confirmed'"`
+	TxType      string
+}
+
+// PendingTransaction represents a simulated transaction from the mempool
+type PendingTransaction struct {
+	gorm.Model
+	Hash             string `gorm:"uniqueIndex"`
+	From             string `gorm:"column:from_address"`
+	To               string `gorm:"column:to_address"`
+	Value            string
+	BlockNumber      uint64
+	Timestamp        time.Time
+	IsAnalyzed       bool
+	Status           string
+	Effects          string
+	RevertReason     string
+	RevertError      string
+	RevertData       string
+	ComplianceModule string
+	GasTipCap        string
+	GasFeeCap        string
+}
+
+// EnforcementAction represents a queued on-chain enforcement action, executed by the monitor
+type EnforcementAction struct {
+	gorm.Model
+	Address           string     `json:"address"`
+	Action            string     `json:"action"`
+	Direction         string     `json:"direction"`
+	Status            string     `json:"status"`
+	Source            string     `json:"source"`
+	TriggerTxHash     string     `json:"trigger_tx_hash"`
+	Reason            string     `json:"reason"`
+	OnChainReason     string     `json:"on_chain_reason"`
+	Severity          string     `json:"severity"`
+	Details           string     `json:"details"`
+	HoldType          string     `json:"hold_type"`
+	LegalReference    string     `json:"legal_reference"`
+	RequestedBy       string     `json:"requested_by"`
+	RequiredApprovals int        `json:"required_approvals"`
+	TxHash            string     `json:"tx_hash"`
+	Nonce             uint64     `json:"nonce"`
+	BlockNumber       uint64     `json:"block_number"`
+	Attempts          int        `json:"attempts"`
+	LastError         string     `json:"last_error"`
+	NextAttemptAt     time.Time  `json:"next_attempt_at"`
+	SubmittedAt       *time.Time `json:"submitted_at"`
+	CompletedAt       *time.Time `json:"completed_at"`
+}
+
+// ActionApproval represents an officer's decision on an enforcement action awaiting approval
+type ActionApproval struct {
+	gorm.Model
+	EnforcementActionID uint   `json:"enforcement_action_id"`
+	Approver            string `json:"approver"`
+	Decision            string `json:"decision"`
+	Comment             string `json:"comment"`
+}
+
+// PreemptiveBlacklist represents a blacklist transaction sent to front-run a suspicious pending transaction
+type PreemptiveBlacklist struct {
+	gorm.Model
+	Address           string    `json:"address"`
+	Direction         string    `json:"direction"`
+	Reason            string    `json:"reason"`
+	TargetTxHash      string    `json:"target_tx_hash"`
+	TargetGasTipCap   string    `json:"target_gas_tip_cap"`
+	TargetGasFeeCap   string    `json:"target_gas_fee_cap"`
+	TxHash            string    `json:"tx_hash"`
+	ReplacedTxHashes  string    `json:"replaced_tx_hashes"`
+	Nonce             uint64    `json:"nonce"`
+	GasTipCap         string    `json:"gas_tip_cap"`
+	GasFeeCap         string    `json:"gas_fee_cap"`
+	Replacements      int       `json:"replacements"`
+	LastSentAt        time.Time `json:"last_sent_at"`
+	Status            string    `json:"status"`
+	BlockNumber       uint64    `json:"block_number"`
+	TxIndex           uint      `json:"tx_index"`
+	TargetStatus      string    `json:"target_status"`
+	TargetBlockNumber uint64    `json:"target_block_number"`
+	TargetTxIndex     uint      `json:"target_tx_index"`
+	Outcome           string    `json:"outcome"`
+}
+
+// RestrictionEvent represents a blacklist or unblacklist event emitted by the restriction contract
+type RestrictionEvent struct {
+	gorm.Model
+	Address     string    `json:"address"`
+	Event       string    `json:"event"`
+	Direction   string    `json:"direction"`
+	Restricted  bool      `json:"restricted"`
+	Reason      string    `json:"reason"`
+	Actor       string    `json:"actor"`
+	Timestamp   time.Time `json:"timestamp"`
+	TxHash      string    `json:"tx_hash"`
+	LogIndex    uint      `json:"log_index"`
+	BlockNumber uint64    `json:"block_number"`
+}
+
+// BlacklistDrift represents a discrepancy between the blacklist table and the restriction contract
+type BlacklistDrift struct {
+	gorm.Model
+	Address        string     `json:"address"`
+	Kind           string     `json:"kind"`
+	DBDirection    string     `json:"db_direction"`
+	ChainDirection string     `json:"chain_direction"`
+	Repaired       bool       `json:"repaired"`
+	RepairAction   string     `json:"repair_action"`
+	LastSeenAt     time.Time  `json:"last_seen_at"`
+	ResolvedAt     *time.Time `json:"resolved_at"`
 }
 
 // SuspiciousTransfer represents a suspicious token transfer event
@@ -41,9 +151,31 @@ type BlacklistedAddress struct {
 	Address     string `gorm:"uniqueIndex;not null"`
 	TxHash      string `gorm:"index;not null"`
 	BlockNumber uint64 `gorm:"index;not null"`
+	Direction   string
 	Reason      string
 	Severity    string
 	Details     string
+
+	Status         string     `gorm:"default:'confirmed'"` // "provisional", "confirmed" or "lifted"
+	HoldType       string     `gorm:"default:'manual'"`    // "automated", "manual" or "law_enforcement"
+	LegalReference string     // Order or case reference of a law-enforcement hold
+	ExpiresAt      *time.Time // When a provisional entry is lifted automatically
+	ReviewAt       *time.Time // When a confirmed entry is due for review
+	ConfirmedBy    string
+	ConfirmedAt    *time.Time
+	LiftedAt       *time.Time
+	LiftReason     string
+}
+
+// BlacklistStatusChange represents an audit record of a blacklist lifecycle transition
+type BlacklistStatusChange struct {
+	gorm.Model
+	Address             string `json:"address"`
+	FromStatus          string `json:"from_status"`
+	ToStatus            string `json:"to_status"`
+	Actor               string `json:"actor"`
+	Reason              string `json:"reason"`
+	EnforcementActionID uint   `json:"enforcement_action_id"`
 }
 
 // Rule represents a compliance rule
@@ -57,6 +189,10 @@ type Rule struct {
 	Actions         string         `gorm:"type:jsonb;not null;default:'{}'" json:"actions"`
 	Violations      uint64         `gorm:"default:0" json:"violations"`
 	LastViolationAt *time.Time     `gorm:"type:timestamp with time zone" json:"last_violation_at"`
+	ShadowOf        string         `gorm:"index" json:"shadow_of,omitempty"`
+	Type            string         `gorm:"index" json:"type"`
+	Version         int            `gorm:"default:0" json:"version"`
+	VersionID       uint           `json:"version_id"`
 	CreatedAt       time.Time      `json:"created_at"`
 	UpdatedAt       time.Time      `json:"updated_at"`
 	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
@@ -64,15 +200,18 @@ type Rule struct {
 
 // RuleViolation represents a record of when a rule was violated
 type RuleViolation struct {
-	ID          uint           `gorm:"primaryKey" json:"id"`
-	RuleID      uint           `gorm:"index;not null" json:"rule_id"`
-	TxHash      string         `gorm:"index;not null" json:"tx_hash"`
-	BlockNumber uint64         `gorm:"index;not null" json:"block_number"`
-	Details     string         `gorm:"type:jsonb;not null;default:'{}'" json:"details"`
-	ActionTaken string         `gorm:"type:varchar(255);default:''" json:"action_taken"`
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+	ID            uint           `gorm:"primaryKey" json:"id"`
+	RuleID        uint           `gorm:"index;not null" json:"rule_id"`
+	TxHash        string         `gorm:"index;not null" json:"tx_hash"`
+	BlockNumber   uint64         `gorm:"index;not null" json:"block_number"`
+	Details       string         `gorm:"type:jsonb;not null;default:'{}'" json:"details"`
+	ActionTaken   string         `gorm:"type:varchar(255);default:''" json:"action_taken"`
+	IncidentID    uint           `gorm:"index" json:"incident_id"`
+	Suppressed    bool           `gorm:"default:false" json:"suppressed"`
+	RuleVersionID uint           `gorm:"index" json:"rule_version_id"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
 // SuspiciousAddress represents an address flagged as suspicious
@@ -83,10 +222,356 @@ type SuspiciousAddress struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
-// WhitelistAddress represents an address that is whitelisted
+// WhitelistAddress exempts transfers of an address from rule checks, once approved and
+// until it expires or is revoked
 type WhitelistAddress struct {
-	ID        uint      `gorm:"primaryKey" json:"id"`
-	Address   string    `gorm:"uniqueIndex;not null" json:"address"`
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	Address      string     `gorm:"index;not null" json:"address"`
+	Reason       string     `json:"reason"`
+	Rules        string     `json:"rules"`        // Comma-separated rule types; empty for every rule
+	Direction    string     `json:"direction"`    // both, from or to
+	Counterparty string     `json:"counterparty"` // Only transfers with this address, if set
+	Status       string     `json:"status"`       // pending, active or revoked
+	ExpiresAt    *time.Time `json:"expires_at"`
+	RequestedBy  string     `json:"requested_by"`
+	ApprovedBy   string     `json:"approved_by"`
+	ApprovedAt   *time.Time `json:"approved_at"`
+	RevokedBy    string     `json:"revoked_by,omitempty"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// WhitelistExemption is a rule check a whitelist entry exempted a transaction from
+type WhitelistExemption struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	WhitelistID uint      `json:"whitelist_id"`
+	Address     string    `json:"address"`
+	TxHash      string    `json:"tx_hash"`
+	BlockNumber uint64    `json:"block_number"`
+	CheckName   string    `json:"check_name"`
+	Fired       bool      `json:"fired"` // Whether the check would have flagged the transaction
+	Details     string    `json:"details"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Case represents an investigation grouping suspicious transfers, violations and addresses
+type Case struct {
+	gorm.Model
+	Title        string     `json:"title"`
+	Status       string     `json:"status"`
+	Severity     string     `json:"severity"`
+	Assignee     string     `json:"assignee"`
+	Summary      string     `json:"summary"`
+	MergedIntoID *uint      `json:"merged_into_id"`
+	ClosedAt     *time.Time `json:"closed_at"`
+}
+
+// CaseAddress links an address to a case
+type CaseAddress struct {
+	gorm.Model
+	CaseID  uint   `json:"case_id"`
+	Address string `json:"address"`
+}
+
+// CaseTransfer links a suspicious transfer to a case
+type CaseTransfer struct {
+	gorm.Model
+	CaseID               uint   `json:"case_id"`
+	SuspiciousTransferID uint   `json:"suspicious_transfer_id"`
+	TxHash               string `json:"tx_hash"`
+}
+
+// CaseViolation links a rule violation to a case
+type CaseViolation struct {
+	gorm.Model
+	CaseID          uint `json:"case_id"`
+	RuleViolationID uint `json:"rule_violation_id"`
+}
+
+// CaseNote represents an investigator's note on a case
+type CaseNote struct {
+	gorm.Model
+	CaseID uint   `json:"case_id"`
+	Author string `json:"author"`
+	Body   string `json:"body"`
+}
+
+// CaseAttachment represents the metadata of a file attached to a case
+type CaseAttachment struct {
+	gorm.Model
+	CaseID      uint   `json:"case_id"`
+	FileName    string `json:"file_name"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	StorageURI  string `json:"storage_uri"`
+	SHA256      string `json:"sha256"`
+	UploadedBy  string `json:"uploaded_by"`
+}
+
+// CaseEvent represents an entry of a case's audit history
+type CaseEvent struct {
+	gorm.Model
+	CaseID    uint   `json:"case_id"`
+	Actor     string `json:"actor"`
+	Action    string `json:"action"`
+	FromValue string `json:"from_value"`
+	ToValue   string `json:"to_value"`
+	Details   string `json:"details"`
+}
+
+// StrReport represents a Suspicious Transaction Report filed with the State Bank
+type StrReport struct {
+	gorm.Model
+	ReportNumber        string `json:"report_number"`
+	CaseID              *uint  `json:"case_id"`
+	TransferIDs         string `json:"transfer_ids"`
+	Format              string `json:"format"`
+	SchemaVersion       string `json:"schema_version"`
+	FileName            string `json:"file_name"`
+	ContentType         string `json:"content_type"`
+	Content             []byte `json:"-"`
+	SHA256              string `json:"sha256"`
+	FiledBy             string `json:"filed_by"`
+	SubmissionReference string `json:"submission_reference"`
+}
+
+// CtrReport represents the large-value transaction report of one reporting period
+type CtrReport struct {
+	gorm.Model
+	Period        string    `json:"period"`
+	PeriodStart   time.Time `json:"period_start"`
+	PeriodEnd     time.Time `json:"period_end"`
+	Threshold     string    `json:"threshold"`
+	TransferCount int       `json:"transfer_count"`
+	EntityCount   int       `json:"entity_count"`
+	TotalAmount   string    `json:"total_amount"`
+	FileName      string    `json:"file_name"`
+	Size          int64     `json:"size"`
+	SHA256        string    `json:"sha256"`
+}
+
+// User represents a local user of the API, used when no OIDC provider signs in the user
+type User struct {
+	gorm.Model
+	Username     string     `json:"username"`
+	PasswordHash string     `json:"-"`
+	Role         string     `json:"role"`
+	Disabled     bool       `json:"disabled"`
+	LastLoginAt  *time.Time `json:"last_login_at"`
+}
+
+// AuditLog represents an entry of the append-only, hash-chained audit log
+type AuditLog struct {
+	ID         uint      `gorm:"primarykey" json:"id"`
+	Seq        uint64    `json:"seq"`
+	CreatedAt  time.Time `json:"created_at"`
+	RequestID  string    `json:"request_id"`
+	Actor      string    `json:"actor"`
+	Role       string    `json:"role"`
+	Action     string    `json:"action"`
+	Method     string    `json:"method"`
+	Path       string    `json:"path"`
+	Parameters string    `json:"parameters"`
+	Reason     string    `json:"reason"`
+	TxHash     string    `json:"tx_hash"`
+	Result     string    `json:"result"`
+	Status     int       `json:"status"`
+	Error      string    `json:"error"`
+	PrevHash   string    `json:"prev_hash"`
+	Hash       string    `json:"hash"`
+}
+
+// StreamEvent represents an alert published on the event stream
+type StreamEvent struct {
+	ID          uint64    `gorm:"primarykey" json:"id"`
+	CreatedAt   time.Time `json:"created_at"`
+	Type        string    `json:"type"`
+	Severity    string    `json:"severity"`
+	Rule        string    `json:"rule,omitempty"`
+	FromAddress string    `json:"from_address,omitempty"`
+	ToAddress   string    `json:"to_address,omitempty"`
+	Payload     string    `gorm:"type:jsonb" json:"-"`
+}
+
+// NotificationDelivery represents an alert queued for a notification channel and its delivery log
+type NotificationDelivery struct {
+	gorm.Model
+	Channel         string     `json:"channel"`
+	ChannelType     string     `json:"channel_type"`
+	RuleName        string     `json:"rule_name"`
+	RuleViolationID uint       `json:"rule_violation_id"`
+	Severity        string     `json:"severity"`
+	TxHash          string     `json:"tx_hash"`
+	Payload         string     `json:"payload"`
+	Status          string     `json:"status"`
+	Attempts        int        `json:"attempts"`
+	LastError       string     `json:"last_error"`
+	ResponseStatus  int        `json:"response_status"`
+	NextAttemptAt   time.Time  `json:"next_attempt_at"`
+	SentAt          *time.Time `json:"sent_at"`
+}
+
+// AlertIncident groups the violations of one rule by one address
+type AlertIncident struct {
+	gorm.Model
+	Fingerprint     string     `json:"fingerprint"`
+	RuleID          uint       `json:"rule_id"`
+	RuleName        string     `json:"rule_name"`
+	Address         string     `json:"address"`
+	Severity        string     `json:"severity"`
+	Status          string     `json:"status"`
+	FirstSeenAt     time.Time  `json:"first_seen_at"`
+	LastSeenAt      time.Time  `json:"last_seen_at"`
+	ExpiresAt       time.Time  `json:"expires_at"`
+	Count           int        `json:"count"`
+	SuppressedCount int        `json:"suppressed_count"`
+	FirstTxHash     string     `json:"first_tx_hash"`
+	LastTxHash      string     `json:"last_tx_hash"`
+	ResolvedBy      string     `json:"resolved_by"`
+	ResolvedAt      *time.Time `json:"resolved_at"`
+}
+
+// AlertSnooze silences alerts on an address until a time
+type AlertSnooze struct {
+	gorm.Model
+	Address   string    `json:"address"`
+	RuleName  string    `json:"rule_name"`
+	Until     time.Time `json:"until"`
 	Reason    string    `json:"reason"`
+	CreatedBy string    `json:"created_by"`
+}
+
+// BacktestRun is a backtest of candidate rule parameters, run by the monitor
+type BacktestRun struct {
+	gorm.Model
+	Status      string     `json:"status"`
+	RequestedBy string     `json:"requested_by"`
+	Request     string     `json:"-"`
+	Report      string     `json:"-"`
+	Error       string     `json:"error,omitempty"`
+	StartedAt   *time.Time `json:"started_at"`
+	FinishedAt  *time.Time `json:"finished_at"`
+}
+
+// ShadowViolation is a violation a shadow rule would have recorded
+type ShadowViolation struct {
+	gorm.Model
+	RuleID        uint   `json:"rule_id"`
+	RuleVersionID uint   `json:"rule_version_id"`
+	RuleName      string `json:"rule_name"`
+	CheckName     string `json:"check_name"`
+	TxHash        string `json:"tx_hash"`
+	BlockNumber   uint64 `json:"block_number"`
+	Address       string `json:"address"`
+	Details       string `gorm:"type:jsonb" json:"details"`
+}
+
+// RuleVersion is an immutable snapshot of a rule, written on every change of the rule
+type RuleVersion struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	RuleID      uint      `gorm:"not null;uniqueIndex:idx_rule_versions_rule_version" json:"rule_id"`
+	Version     int       `gorm:"not null;uniqueIndex:idx_rule_versions_rule_version" json:"version"`
+	Name        string    `gorm:"not null" json:"name"`
+	Type        string    `gorm:"not null" json:"type"`
+	Description string    `gorm:"type:text" json:"description"`
+	Status      string    `gorm:"not null" json:"status"`
+	Severity    string    `gorm:"not null" json:"severity"`
+	Parameters  string    `gorm:"type:jsonb" json:"parameters"`
+	Actions     string    `gorm:"type:jsonb" json:"actions"`
+	ShadowOf    string    `json:"shadow_of,omitempty"`
+	Change      string    `gorm:"not null" json:"change"` // create, update, clone, rollback, promote or delete
+	Comment     string    `gorm:"type:text" json:"comment,omitempty"`
+	ChangedBy   string    `json:"changed_by"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// RuleEvaluation is a dry run of the rules on a hypothetical transfer, run by the monitor
+type RuleEvaluation struct {
+	gorm.Model
+	Status      string     `json:"status"`
+	RequestedBy string     `json:"requested_by"`
+	Request     string     `json:"-"`
+	Result      string     `json:"-"`
+	Error       string     `json:"error,omitempty"`
+	FinishedAt  *time.Time `json:"finished_at"`
+}
+
+// AlertLabel is an analyst's verdict on a suspicious transfer or a rule violation
+type AlertLabel struct {
+	gorm.Model
+	TargetType string `json:"target_type"` // suspicious_transfer or rule_violation
+	TargetID   uint   `json:"target_id"`
+	TxHash     string `json:"tx_hash"`
+	RuleID     uint   `json:"rule_id,omitempty"`
+	Label      string `json:"label"` // true_positive, false_positive or needs_info
+	Note       string `json:"note"`
+	LabeledBy  string `json:"labeled_by"`
+}
+
+// WatchlistSource is a sanctions or watchlist list the monitor imports on a schedule
+type WatchlistSource struct {
+	gorm.Model
+	Name          string     `json:"name"`
+	URL           string     `json:"url"`
+	Format        string     `json:"format"` // csv, json or ofac_xml
+	Enabled       bool       `json:"enabled"`
+	SyncInterval  int        `json:"sync_interval_minutes"`
+	NextSyncAt    *time.Time `json:"next_sync_at"`
+	LastSyncAt    *time.Time `json:"last_sync_at"`
+	LastVersionID uint       `json:"last_version_id"`
+	LastChecksum  string     `json:"last_checksum"`
+	LastError     string     `json:"last_error,omitempty"`
+	CreatedBy     string     `json:"created_by"`
+}
+
+// WatchlistVersion is a distinct content of a source's list
+type WatchlistVersion struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	SourceID  uint      `json:"source_id"`
+	Version   string    `json:"version"`
+	Checksum  string    `json:"checksum"`
+	Entries   int       `json:"entries"`
+	Added     int       `json:"added"`
+	Removed   int       `json:"removed"`
+	Skipped   int       `json:"skipped"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WatchlistEntry is an address a source lists
+type WatchlistEntry struct {
+	gorm.Model
+	SourceID         uint       `json:"source_id"`
+	Address          string     `json:"address"`
+	Name             string     `json:"name"`
+	Reference        string     `json:"reference"`
+	Status           string     `json:"status"` // active or removed
+	AddedVersionID   uint       `json:"added_version_id"`
+	RemovedVersionID uint       `json:"removed_version_id,omitempty"`
+	ScreenedAt       *time.Time `json:"screened_at"`
+}
+
+// WatchlistChange is an address a version of a source's list added or removed
+type WatchlistChange struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	SourceID  uint      `json:"source_id"`
+	VersionID uint      `json:"version_id"`
+	Address   string    `json:"address"`
+	Change    string    `json:"change"` // added or removed
+	Name      string    `json:"name"`
 	CreatedAt time.Time `json:"created_at"`
 }
+
+// WatchlistMatch is a past transaction of a newly listed address
+type WatchlistMatch struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	EntryID     uint      `json:"entry_id"`
+	SourceID    uint      `json:"source_id"`
+	VersionID   uint      `json:"version_id"`
+	Address     string    `json:"address"`
+	TxHash      string    `json:"tx_hash"`
+	BlockNumber uint64    `json:"block_number"`
+	Direction   string    `json:"direction"`
+	Amount      string    `json:"amount"`
+	TxTimestamp time.Time `json:"tx_timestamp"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var notificationDeliveryList = listSpec{
+	Sorts: map[string]listSort{
+		"created_at":      {"created_at", "TIMESTAMPTZ"},
+		"next_attempt_at": {"next_attempt_at", "TIMESTAMPTZ"},
+		"attempts":        {"attempts", "INTEGER"},
+	},
+	DefaultSort: "created_at",
+	Filters: []listFilter{
+		inFilter("status", "status"),
+		inFilter("channel", "channel"),
+		inFilter("channel_type", "channel_type"),
+		inFilter("rule", "rule_name"),
+		inFilter("severity", "severity"),
+		inFilter("tx_hash", "tx_hash"),
+		timeRangeFilter("created_at"),
+	},
+}
+
+// getNotificationDeliveries returns a page of the notification delivery log
+func getNotificationDeliveries(c *gin.Context) {
+	var deliveries []NotificationDelivery
+	page, err := listFrom(c).find(db.Model(&NotificationDelivery{}), &deliveries)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, ListResponse{Data: deliveries, Pagination: page})
+}
+
+// retryNotificationDelivery queues a failed delivery again with a fresh set of attempts
+func retryNotificationDelivery(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid delivery id"})
+		return
+	}
+
+	var delivery NotificationDelivery
+	status := http.StatusOK
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&delivery, id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				status = http.StatusNotFound
+			}
+			return err
+		}
+		if delivery.Status != "failed" {
+			status = http.StatusConflict
+			return fmt.Errorf("delivery is %s, only failed deliveries can be retried", delivery.Status)
+		}
+		return tx.Model(&delivery).Updates(map[string]interface{}{
+			"status":          "pending",
+			"attempts":        0,
+			"next_attempt_at": time.Now(),
+		}).Error
+	})
+	if err != nil {
+		if status == http.StatusOK {
+			status = http.StatusInternalServerError
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, delivery)
+}
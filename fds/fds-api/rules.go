@@ -0,0 +1,387 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Rule names are used in URLs, alerts and on-chain reasons
+var ruleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{2,63}$`)
+
+var ruleSeverities = map[string]bool{"low": true, "medium": true, "high": true}
+
+// ruleType returns the check a rule configures; rules from before rule types run the check
+// named like them
+func ruleType(rule *Rule) string {
+	if rule.Type != "" {
+		return rule.Type
+	}
+	return rule.Name
+}
+
+// saveRuleVersion records the rule as it now is as its next version. It must run in the
+// transaction changing the rule, with the rule locked.
+func saveRuleVersion(tx *gorm.DB, rule *Rule, change, comment, actor string) error {
+	version := RuleVersion{
+		RuleID:      rule.ID,
+		Version:     rule.Version + 1,
+		Name:        rule.Name,
+		Type:        ruleType(rule),
+		Description: rule.Description,
+		Status:      rule.Status,
+		Severity:    rule.Severity,
+		Parameters:  rule.Parameters,
+		Actions:     rule.Actions,
+		ShadowOf:    rule.ShadowOf,
+		Change:      change,
+		Comment:     comment,
+		ChangedBy:   actor,
+	}
+	if version.Parameters == "" {
+		version.Parameters = "{}"
+	}
+	if version.Actions == "" {
+		version.Actions = "{}"
+	}
+	if err := tx.Create(&version).Error; err != nil {
+		return fmt.Errorf("error recording rule version: %w", err)
+	}
+	rule.Version = version.Version
+	rule.VersionID = version.ID
+	return tx.Model(rule).Updates(map[string]interface{}{"version": rule.Version, "version_id": rule.VersionID}).Error
+}
+
+// checkActivation refuses to make a shadow rule active, or a second rule configuring the
+// same check; the monitor runs one active rule per check
+func checkActivation(tx *gorm.DB, rule *Rule) (int, error) {
+	if rule.Status != "active" {
+		return http.StatusOK, nil
+	}
+	if rule.ShadowOf != "" {
+		return http.StatusBadRequest, fmt.Errorf("%s shadows another rule; promote it instead", rule.Name)
+	}
+	var active Rule
+	err := tx.Where("status = ? AND COALESCE(NULLIF(type, ''), name) = ? AND id <> ?", "active", ruleType(rule), rule.ID).
+		Limit(1).Find(&active).Error
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	if active.ID != 0 {
+		return http.StatusConflict, fmt.Errorf("%s is already active for the %s check; deactivate it first", active.Name, ruleType(rule))
+	}
+	return http.StatusOK, nil
+}
+
+// ruleJSON returns a JSON object from a request as a string, or def when absent
+func ruleJSON(raw json.RawMessage, def string) string {
+	if len(raw) == 0 || string(raw) == "null" {
+		return def
+	}
+	return string(raw)
+}
+
+// getRuleSchemas returns the JSON Schema of the parameters of each rule type and of actions
+func getRuleSchemas(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"types": ruleSchemaJSON, "actions": actionsJSON})
+}
+
+// createRule creates a rule configuring one of the monitor's checks (type, by default its
+// name). Rules are created inactive unless status says otherwise.
+func createRule(c *gin.Context) {
+	var req struct {
+		Name        string          `json:"name"`
+		Type        string          `json:"type"`
+		Description string          `json:"description"`
+		Status      string          `json:"status"`
+		Severity    string          `json:"severity"`
+		Parameters  json.RawMessage `json:"parameters"`
+		Actions     json.RawMessage `json:"actions"`
+		Comment     string          `json:"comment"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !ruleNamePattern.MatchString(req.Name) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name must be 3-64 lowercase letters, digits or underscores"})
+		return
+	}
+	if strings.TrimSpace(req.Description) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "description required"})
+		return
+	}
+	rule := Rule{
+		Name:        req.Name,
+		Type:        req.Type,
+		Description: req.Description,
+		Status:      req.Status,
+		Severity:    req.Severity,
+		Parameters:  ruleJSON(req.Parameters, "{}"),
+		Actions:     ruleJSON(req.Actions, "{}"),
+	}
+	if rule.Type == "" {
+		rule.Type = rule.Name
+	}
+	if rule.Status == "" {
+		rule.Status = "inactive"
+	}
+	if rule.Severity == "" {
+		rule.Severity = "medium"
+	}
+	if !ruleStatuses[rule.Status] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be active, inactive or shadow"})
+		return
+	}
+	if !ruleSeverities[rule.Severity] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "severity must be low, medium or high"})
+		return
+	}
+	if err := validateRule(rule.Type, rule.Parameters, rule.Actions); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	status := http.StatusOK
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var taken int64
+		if err := tx.Unscoped().Model(&Rule{}).Where("name = ?", rule.Name).Count(&taken).Error; err != nil {
+			return err
+		}
+		if taken > 0 {
+			status = http.StatusConflict
+			return fmt.Errorf("rule %s already exists", rule.Name)
+		}
+		if code, err := checkActivation(tx, &rule); err != nil {
+			status = code
+			return err
+		}
+		if err := tx.Create(&rule).Error; err != nil {
+			return err
+		}
+		return saveRuleVersion(tx, &rule, "create", req.Comment, currentUser(c))
+	})
+	if err != nil {
+		if status == http.StatusOK {
+			status = http.StatusInternalServerError
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, rule)
+}
+
+// cloneRule copies a rule under a new name, inactive, to be changed and activated in its place
+func cloneRule(c *gin.Context) {
+	var req struct {
+		Name        string `json:"name"`
+		Description string `json:"description"` // Defaults to the source rule's
+		Comment     string `json:"comment"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !ruleNamePattern.MatchString(req.Name) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name must be 3-64 lowercase letters, digits or underscores"})
+		return
+	}
+
+	var clone Rule
+	status := http.StatusOK
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var source Rule
+		if err := tx.Where("name = ?", c.Param("name")).First(&source).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				status = http.StatusNotFound
+			}
+			return err
+		}
+		var taken int64
+		if err := tx.Unscoped().Model(&Rule{}).Where("name = ?", req.Name).Count(&taken).Error; err != nil {
+			return err
+		}
+		if taken > 0 {
+			status = http.StatusConflict
+			return fmt.Errorf("rule %s already exists", req.Name)
+		}
+
+		clone = Rule{
+			Name:        req.Name,
+			Type:        ruleType(&source),
+			Description: source.Description,
+			Status:      "inactive",
+			Severity:    source.Severity,
+			Parameters:  source.Parameters,
+			Actions:     source.Actions,
+		}
+		if req.Description != "" {
+			clone.Description = req.Description
+		}
+		if req.Comment == "" {
+			req.Comment = fmt.Sprintf("Cloned from %s version %d", source.Name, source.Version)
+		}
+		if err := tx.Create(&clone).Error; err != nil {
+			return err
+		}
+		return saveRuleVersion(tx, &clone, "clone", req.Comment, currentUser(c))
+	})
+	if err != nil {
+		if status == http.StatusOK {
+			status = http.StatusInternalServerError
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, clone)
+}
+
+// deleteRule deletes an inactive rule. Its versions are kept, as are the violations
+// referencing them.
+func deleteRule(c *gin.Context) {
+	status := http.StatusOK
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var rule Rule
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("name = ?", c.Param("name")).First(&rule).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				status = http.StatusNotFound
+			}
+			return err
+		}
+		if rule.Status == "active" {
+			status = http.StatusConflict
+			return fmt.Errorf("rule %s is active; deactivate it first", rule.Name)
+		}
+		var shadows int64
+		if err := tx.Model(&Rule{}).Where("shadow_of = ? AND status = ?", rule.Name, "shadow").Count(&shadows).Error; err != nil {
+			return err
+		}
+		if shadows > 0 {
+			status = http.StatusConflict
+			return fmt.Errorf("rule %s has shadow rules", rule.Name)
+		}
+		if err := saveRuleVersion(tx, &rule, "delete", c.Query("comment"), currentUser(c)); err != nil {
+			return err
+		}
+		return tx.Delete(&rule).Error
+	})
+	if err != nil {
+		if status == http.StatusOK {
+			status = http.StatusInternalServerError
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "deleted"})
+}
+
+// getRuleVersions returns the versions of a rule, newest first, including those of a
+// deleted rule
+func getRuleVersions(c *gin.Context) {
+	var rule Rule
+	if err := db.Unscoped().Where("name = ?", c.Param("name")).First(&rule).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "rule not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	var versions []RuleVersion
+	if err := db.Where("rule_id = ?", rule.ID).Order("version DESC").Find(&versions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"rule": rule, "versions": versions})
+}
+
+// rollbackRule restores the description, status, severity, parameters, actions and shadowed
+// rule of an earlier version of a rule as its next version. A shadow version is refused if
+// the rule it shadowed is no longer active.
+func rollbackRule(c *gin.Context) {
+	var req struct {
+		Version int    `json:"version"`
+		Comment string `json:"comment"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || req.Version < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "version required"})
+		return
+	}
+
+	var rule Rule
+	status := http.StatusOK
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("name = ?", c.Param("name")).First(&rule).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				status = http.StatusNotFound
+			}
+			return err
+		}
+		var target RuleVersion
+		if err := tx.Where("rule_id = ? AND version = ?", rule.ID, req.Version).First(&target).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				status = http.StatusNotFound
+				return fmt.Errorf("rule %s has no version %d", rule.Name, req.Version)
+			}
+			return err
+		}
+		if target.Version == rule.Version {
+			status = http.StatusConflict
+			return fmt.Errorf("rule %s is at version %d", rule.Name, rule.Version)
+		}
+
+		rule.Description = target.Description
+		rule.Status = target.Status
+		rule.Severity = target.Severity
+		rule.Parameters = target.Parameters
+		rule.Actions = target.Actions
+		rule.ShadowOf = target.ShadowOf
+		if rule.Status == "shadow" && rule.ShadowOf != "" {
+			var shadowed int64
+			if err := tx.Model(&Rule{}).Where("name = ? AND status = ?", rule.ShadowOf, "active").Count(&shadowed).Error; err != nil {
+				return err
+			}
+			if shadowed == 0 {
+				status = http.StatusConflict
+				return fmt.Errorf("version %d shadows %s, which is no longer active", target.Version, rule.ShadowOf)
+			}
+		}
+		// Schemas may have changed since
+		if err := validateRule(ruleType(&rule), rule.Parameters, rule.Actions); err != nil {
+			status = http.StatusConflict
+			return fmt.Errorf("version %d no longer validates: %w", target.Version, err)
+		}
+		if code, err := checkActivation(tx, &rule); err != nil {
+			status = code
+			return err
+		}
+		if err := tx.Model(&rule).Updates(map[string]interface{}{
+			"description": rule.Description,
+			"status":      rule.Status,
+			"severity":    rule.Severity,
+			"parameters":  rule.Parameters,
+			"actions":     rule.Actions,
+			"shadow_of":   rule.ShadowOf,
+		}).Error; err != nil {
+			return err
+		}
+		if req.Comment == "" {
+			req.Comment = fmt.Sprintf("Rolled back to version %d", target.Version)
+		}
+		return saveRuleVersion(tx, &rule, "rollback", req.Comment, currentUser(c))
+	})
+	if err != nil {
+		if status == http.StatusOK {
+			status = http.StatusInternalServerError
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, rule)
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"path"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// The JSON Schema of each rule type's parameters, named after the type, and of rule actions
+//
+//go:embed ruleschemas/*.json
+var ruleSchemaFiles embed.FS
+
+var (
+	ruleSchemas    = map[string]*jsonSchema{}     // Parameters, by rule type
+	ruleSchemaJSON = map[string]json.RawMessage{} // As served, by rule type
+	actionsSchema  *jsonSchema
+	actionsJSON    json.RawMessage
+)
+
+func init() {
+	files, err := ruleSchemaFiles.ReadDir("ruleschemas")
+	if err != nil {
+		log.Fatalf("Failed to read rule schemas: %v", err)
+	}
+	for _, file := range files {
+		raw, err := ruleSchemaFiles.ReadFile("ruleschemas/" + file.Name())
+		if err != nil {
+			log.Fatalf("Failed to read rule schema %s: %v", file.Name(), err)
+		}
+		schema, err := parseSchema(raw)
+		if err != nil {
+			log.Fatalf("Invalid rule schema %s: %v", file.Name(), err)
+		}
+		name := strings.TrimSuffix(file.Name(), path.Ext(file.Name()))
+		if name == "actions" {
+			actionsSchema, actionsJSON = schema, raw
+			continue
+		}
+		ruleSchemas[name], ruleSchemaJSON[name] = schema, raw
+	}
+}
+
+// schemaTypes is the "type" keyword: one type or a list of them
+type schemaTypes []string
+
+func (t *schemaTypes) UnmarshalJSON(data []byte) error {
+	var one string
+	if err := json.Unmarshal(data, &one); err == nil {
+		*t = schemaTypes{one}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(t))
+}
+
+// allows reports whether value, as decoded by encoding/json, has one of the types
+func (t schemaTypes) allows(value interface{}) bool {
+	for _, name := range t {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			if name == "object" {
+				return true
+			}
+		case []interface{}:
+			if name == "array" {
+				return true
+			}
+		case string:
+			if name == "string" {
+				return true
+			}
+		case float64:
+			if name == "number" || name == "integer" && v == math.Trunc(v) {
+				return true
+			}
+		case bool:
+			if name == "boolean" {
+				return true
+			}
+		case nil:
+			if name == "null" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// jsonSchema is the subset of JSON Schema the rule schemas use
+type jsonSchema struct {
+	Type                 schemaTypes            `json:"type"`
+	Properties           map[string]*jsonSchema `json:"properties"`
+	Required             []string               `json:"required"`
+	AdditionalProperties *bool                  `json:"additionalProperties"`
+	Items                *jsonSchema            `json:"items"`
+	Enum                 []interface{}          `json:"enum"`
+	Pattern              string                 `json:"pattern"`
+	MinLength            *int                   `json:"minLength"`
+	Minimum              *float64               `json:"minimum"`
+	Maximum              *float64               `json:"maximum"`
+
+	pattern *regexp.Regexp
+}
+
+// parseSchema parses a schema and compiles its patterns
+func parseSchema(raw []byte) (*jsonSchema, error) {
+	var schema jsonSchema
+	if err := json.Unmarshal(raw, &schema); err != nil {
+		return nil, err
+	}
+	return &schema, schema.compile()
+}
+
+func (s *jsonSchema) compile() error {
+	if s.Pattern != "" {
+		pattern, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return err
+		}
+		s.pattern = pattern
+	}
+	for _, property := range s.Properties {
+		if err := property.compile(); err != nil {
+			return err
+		}
+	}
+	if s.Items != nil {
+		return s.Items.compile()
+	}
+	return nil
+}
+
+// validate returns what is wrong with value, each problem prefixed with its path
+func (s *jsonSchema) validate(at string, value interface{}) []string {
+	if len(s.Type) > 0 && !s.Type.allows(value) {
+		return []string{fmt.Sprintf("%s must be %s", at, strings.Join(s.Type, " or "))}
+	}
+	if len(s.Enum) > 0 {
+		allowed := false
+		for _, option := range s.Enum {
+			allowed = allowed || reflect.DeepEqual(option, value)
+		}
+		if !allowed {
+			return []string{fmt.Sprintf("%s must be one of %v", at, s.Enum)}
+		}
+	}
+
+	var problems []string
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				problems = append(problems, fmt.Sprintf("%s.%s is required", at, name))
+			}
+		}
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if property, ok := s.Properties[name]; ok {
+				problems = append(problems, property.validate(at+"."+name, v[name])...)
+			} else if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+				problems = append(problems, fmt.Sprintf("%s.%s is not allowed (allowed: %s)", at, name, strings.Join(s.propertyNames(), ", ")))
+			}
+		}
+	case []interface{}:
+		if s.Items != nil {
+			for i, item := range v {
+				problems = append(problems, s.Items.validate(fmt.Sprintf("%s[%d]", at, i), item)...)
+			}
+		}
+	case string:
+		if s.MinLength != nil && len(v) < *s.MinLength {
+			problems = append(problems, fmt.Sprintf("%s must be at least %d characters", at, *s.MinLength))
+		}
+		if s.pattern != nil && !s.pattern.MatchString(v) {
+			problems = append(problems, fmt.Sprintf("%s must match %s", at, s.Pattern))
+		}
+	case float64:
+		if s.Minimum != nil && v < *s.Minimum {
+			problems = append(problems, fmt.Sprintf("%s must be at least %v", at, *s.Minimum))
+		}
+		if s.Maximum != nil && v > *s.Maximum {
+			problems = append(problems, fmt.Sprintf("%s must be at most %v", at, *s.Maximum))
+		}
+	}
+	return problems
+}
+
+func (s *jsonSchema) propertyNames() []string {
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// validateRule checks a rule's parameters against the schema of its type and its actions
+// against the actions schema
+func validateRule(ruleType, parameters, actions string) error {
+	schema, ok := ruleSchemas[ruleType]
+	if !ok {
+		return fmt.Errorf("unknown rule type %s", ruleType)
+	}
+	var problems []string
+	for _, doc := range []struct {
+		name, raw string
+		schema    *jsonSchema
+	}{
+		{"parameters", parameters, schema},
+		{"actions", actions, actionsSchema},
+	} {
+		if doc.raw == "" {
+			doc.raw = "{}"
+		}
+		var value interface{}
+		if err := json.Unmarshal([]byte(doc.raw), &value); err != nil {
+			return fmt.Errorf("invalid %s JSON", doc.name)
+		}
+		problems = append(problems, doc.schema.validate(doc.name, value)...)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,332 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Rule statuses; shadow rules record would-be violations only
+var ruleStatuses = map[string]bool{"active": true, "inactive": true, "shadow": true}
+
+// createShadowRule deploys changed parameters of an active rule as a shadow rule next to it.
+// The shadow rule runs the active rule's check on live traffic and records what it would
+// have hit in shadow_violations; the monitor picks it up when it starts.
+func createShadowRule(c *gin.Context) {
+	var req struct {
+		Of          string                 `json:"of"`   // The active rule
+		Name        string                 `json:"name"` // Defaults to <of>_shadow
+		Description string                 `json:"description"`
+		Parameters  map[string]interface{} `json:"parameters"` // Over the active rule's parameters
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || req.Of == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "of required"})
+		return
+	}
+
+	var active Rule
+	if err := db.Where("name = ? AND status = ?", req.Of, "active").First(&active).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("no active rule %s", req.Of)})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if active.ShadowOf != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot shadow a shadow rule"})
+		return
+	}
+	params := make(map[string]interface{})
+	if err := json.Unmarshal([]byte(active.Parameters), &params); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "active rule has invalid parameters"})
+		return
+	}
+	for param, value := range req.Parameters {
+		if _, ok := params[param]; !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("rule %s has no parameter %s", req.Of, param)})
+			return
+		}
+		params[param] = value
+	}
+	merged, _ := json.Marshal(params)
+	if err := validateRule(ruleType(&active), string(merged), active.Actions); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Name == "" {
+		req.Name = req.Of + "_shadow"
+	}
+	if req.Description == "" {
+		req.Description = active.Description
+	}
+	shadow := Rule{
+		Name:        req.Name,
+		Type:        ruleType(&active),
+		Description: req.Description,
+		Status:      "shadow",
+		Severity:    active.Severity,
+		Parameters:  string(merged),
+		Actions:     active.Actions,
+		ShadowOf:    active.Name,
+	}
+	status := http.StatusOK
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var taken int64
+		if err := tx.Unscoped().Model(&Rule{}).Where("name = ?", req.Name).Count(&taken).Error; err != nil {
+			return err
+		}
+		if taken > 0 {
+			status = http.StatusConflict
+			return fmt.Errorf("rule %s already exists", req.Name)
+		}
+		if err := tx.Create(&shadow).Error; err != nil {
+			return err
+		}
+		return saveRuleVersion(tx, &shadow, "create", fmt.Sprintf("Shadow of %s version %d", active.Name, active.Version), currentUser(c))
+	})
+	if err != nil {
+		if status == http.StatusOK {
+			status = http.StatusInternalServerError
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, shadow)
+}
+
+// promoteShadowRule makes a shadow rule live: the parameters of a shadow of an active rule
+// replace the active rule's and the shadow is retired; a new rule in shadow becomes active
+func promoteShadowRule(c *gin.Context) {
+	var shadow Rule
+	status := http.StatusOK
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("name = ?", c.Param("name")).First(&shadow).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				status = http.StatusNotFound
+			}
+			return err
+		}
+		if shadow.Status != "shadow" {
+			status = http.StatusConflict
+			return fmt.Errorf("rule %s is %s, not in shadow", shadow.Name, shadow.Status)
+		}
+		comment := fmt.Sprintf("Promoted from shadow rule %s version %d", shadow.Name, shadow.Version)
+		if shadow.ShadowOf == "" {
+			shadow.Status = "active"
+			if code, err := checkActivation(tx, &shadow); err != nil {
+				status = code
+				return err
+			}
+			if err := tx.Model(&shadow).Update("status", shadow.Status).Error; err != nil {
+				return err
+			}
+			return saveRuleVersion(tx, &shadow, "promote", comment, currentUser(c))
+		}
+
+		var active Rule
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("name = ? AND status = ?", shadow.ShadowOf, "active").First(&active).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				status = http.StatusConflict
+				return fmt.Errorf("rule %s is no longer active", shadow.ShadowOf)
+			}
+			return err
+		}
+		active.Parameters = shadow.Parameters
+		if err := tx.Model(&active).Update("parameters", active.Parameters).Error; err != nil {
+			return err
+		}
+		if err := saveRuleVersion(tx, &active, "promote", comment, currentUser(c)); err != nil {
+			return err
+		}
+		shadow.Status = "inactive"
+		if err := tx.Model(&shadow).Update("status", shadow.Status).Error; err != nil {
+			return err
+		}
+		return saveRuleVersion(tx, &shadow, "promote", fmt.Sprintf("Promoted into %s version %d", active.Name, active.Version), currentUser(c))
+	})
+	if err != nil {
+		if status == http.StatusOK {
+			status = http.StatusInternalServerError
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "promoted", "rule": shadow})
+}
+
+// shadowBucket is one period of a shadow comparison
+type shadowBucket struct {
+	Start        time.Time `json:"start"`
+	Transactions int64     `json:"transactions"`
+	ShadowHits   int64     `json:"shadow_hits"`
+	ActiveHits   int64     `json:"active_hits"`
+	ShadowRate   float64   `json:"shadow_rate"` // Hits per transaction
+	ActiveRate   float64   `json:"active_rate"`
+}
+
+// shadowComparison compares a shadow rule's would-be violations with the violations of the
+// rule whose check it runs
+type shadowComparison struct {
+	Rule         string         `json:"rule"`
+	Check        string         `json:"check"`
+	Parameters   string         `json:"parameters"`
+	Active       *Rule          `json:"active,omitempty"` // Nil for a new rule in shadow
+	Transactions int64          `json:"transactions"`
+	ShadowHits   int64          `json:"shadow_hits"`
+	ActiveHits   int64          `json:"active_hits"`
+	ShadowRate   float64        `json:"shadow_rate"`
+	ActiveRate   float64        `json:"active_rate"`
+	Overlap      int64          `json:"overlap"`     // Transactions both hit
+	ShadowOnly   int64          `json:"shadow_only"` // Hits the rule would add
+	ActiveOnly   int64          `json:"active_only"` // Hits the rule would drop
+	Series       []shadowBucket `json:"series"`
+}
+
+// rate returns hits per transaction
+func rate(hits, transactions int64) float64 {
+	if transactions == 0 {
+		return 0
+	}
+	return float64(hits) / float64(transactions)
+}
+
+// bucketCounts counts the rows of query per period of column
+func bucketCounts(query *gorm.DB, bucket, column string) (map[time.Time]int64, error) {
+	var rows []struct {
+		Bucket time.Time
+		Count  int64
+	}
+	if err := query.Select(fmt.Sprintf("date_trunc('%s', %s AT TIME ZONE 'UTC') AS bucket, COUNT(*) AS count", bucket, column)).
+		Group("bucket").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	counts := make(map[time.Time]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Bucket.UTC()] = row.Count
+	}
+	return counts, nil
+}
+
+// getShadowReport compares the hit rates of the shadow rules (or those named in rule) with
+// the rules they shadow, per day or hour (bucket) between since and until, by default over
+// the last 7 days
+func getShadowReport(c *gin.Context) {
+	bucket := c.DefaultQuery("bucket", "day")
+	step := 24 * time.Hour
+	switch bucket {
+	case "day":
+	case "hour":
+		step = time.Hour
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "bucket must be day or hour"})
+		return
+	}
+	since, err := timeParam(c, "since")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	until, err := timeParam(c, "until")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if until == nil {
+		now := time.Now()
+		until = &now
+	}
+	if since == nil {
+		start := until.AddDate(0, 0, -7)
+		since = &start
+	}
+	if !since.Before(*until) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "since must be before until"})
+		return
+	}
+	if until.Sub(*since)/step > 24*92 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "too many buckets; use a shorter range or daily buckets"})
+		return
+	}
+
+	query := db.Where("status = ?", "shadow")
+	if names := splitList(c.Query("rule")); len(names) > 0 {
+		query = db.Where("name IN ?", names)
+	}
+	var shadows []Rule
+	if err := query.Order("name").Find(&shadows).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	window := func(column string) func(*gorm.DB) *gorm.DB {
+		return func(q *gorm.DB) *gorm.DB {
+			return q.Where(column+" >= ? AND "+column+" < ?", *since, *until)
+		}
+	}
+	transactions, err := bucketCounts(db.Unscoped().Model(&Transaction{}).Scopes(window("created_at")), bucket, "created_at")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	comparisons := []shadowComparison{}
+	for i := range shadows {
+		shadow := &shadows[i]
+		cmp := shadowComparison{Rule: shadow.Name, Check: shadow.Name, Parameters: shadow.Parameters, Series: []shadowBucket{}}
+		if shadow.ShadowOf != "" {
+			cmp.Check = shadow.ShadowOf
+		}
+
+		shadowHits := db.Model(&ShadowViolation{}).Where("rule_id = ?", shadow.ID).Scopes(window("created_at"))
+		shadowCounts, err := bucketCounts(shadowHits.Session(&gorm.Session{}), bucket, "created_at")
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		activeCounts := map[time.Time]int64{}
+		var active Rule
+		if err := db.Where("name = ?", cmp.Check).First(&active).Error; err == nil && active.ID != shadow.ID {
+			cmp.Active = &active
+			activeHits := db.Model(&RuleViolation{}).Where("rule_id = ?", active.ID).Scopes(window("created_at"))
+			if activeCounts, err = bucketCounts(activeHits.Session(&gorm.Session{}), bucket, "created_at"); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			if err := shadowHits.Session(&gorm.Session{}).
+				Where("tx_hash IN (?)", activeHits.Session(&gorm.Session{}).Select("tx_hash")).
+				Count(&cmp.Overlap).Error; err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+		}
+
+		for start := since.UTC().Truncate(step); start.Before(*until); start = start.Add(step) {
+			b := shadowBucket{
+				Start:        start,
+				Transactions: transactions[start],
+				ShadowHits:   shadowCounts[start],
+				ActiveHits:   activeCounts[start],
+			}
+			b.ShadowRate = rate(b.ShadowHits, b.Transactions)
+			b.ActiveRate = rate(b.ActiveHits, b.Transactions)
+			cmp.Transactions += b.Transactions
+			cmp.ShadowHits += b.ShadowHits
+			cmp.ActiveHits += b.ActiveHits
+			cmp.Series = append(cmp.Series, b)
+		}
+		cmp.ShadowRate = rate(cmp.ShadowHits, cmp.Transactions)
+		cmp.ActiveRate = rate(cmp.ActiveHits, cmp.Transactions)
+		cmp.ShadowOnly = cmp.ShadowHits - cmp.Overlap
+		cmp.ActiveOnly = cmp.ActiveHits - cmp.Overlap
+		comparisons = append(comparisons, cmp)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"since": since, "until": until, "bucket": bucket, "rules": comparisons})
+}
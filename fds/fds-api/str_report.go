@@ -0,0 +1,938 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"fds-api/contracts"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/text/unicode/norm"
+	"gorm.io/gorm"
+)
+
+// legalReference ties a rule to the provision of the Anti-Money Laundering Law
+// (Luật Phòng, chống rửa tiền số 14/2022/QH15) it detects, as listed in RULES.md
+type legalReference struct {
+	Code      string
+	Reference string
+}
+
+var ruleLegalReferences = map[string]legalReference{
+	"large_transfer":              {"R001", "Khoản 1 Điều 34 Luật Phòng, chống rửa tiền số 14/2022/QH15"},
+	"multiple_transfers":          {"R002", "Khoản 3 Điều 29 Luật Phòng, chống rửa tiền số 14/2022/QH15"},
+	"multiple_incoming_transfers": {"R003", "Khoản 3 Điều 29 Luật Phòng, chống rửa tiền số 14/2022/QH15"},
+	"suspicious_address":          {"R004", "Khoản 5, 6 Điều 29 Luật Phòng, chống rửa tiền số 14/2022/QH15"},
+}
+
+// ruleLegalReference returns the legal reference of the check a rule configures, so clones
+// and renamed rules keep it. The "rule_code" and "legal_reference" keys of the rule's
+// actions override the references of RULES.md.
+func ruleLegalReference(rule Rule) legalReference {
+	ref := ruleLegalReferences[ruleType(&rule)]
+	var actions map[string]interface{}
+	if err := json.Unmarshal([]byte(rule.Actions), &actions); err == nil {
+		if code, ok := actions["rule_code"].(string); ok && code != "" {
+			ref.Code = code
+		}
+		if reference, ok := actions["legal_reference"].(string); ok && reference != "" {
+			ref.Reference = reference
+		}
+	}
+	return ref
+}
+
+// StrSchema configures the element names of XML and JSON reports so they follow the
+// layout the State Bank expects. Fields without an entry keep their default names.
+type StrSchema struct {
+	Version  string            `json:"version"`
+	Root     string            `json:"root"`
+	Elements map[string]string `json:"elements"` // Default field name -> element name
+}
+
+// strSchema is the schema in use, loaded from STR_SCHEMA_PATH
+var strSchema = StrSchema{Version: "1.0", Root: "str_report"}
+
+// loadStrSchema reads the report schema from path; the default schema is kept when path is empty
+func loadStrSchema(path string) error {
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading STR schema: %w", err)
+	}
+	var schema StrSchema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return fmt.Errorf("error parsing STR schema %s: %w", path, err)
+	}
+	if schema.Version == "" || schema.Root == "" {
+		return fmt.Errorf("STR schema %s needs a version and a root", path)
+	}
+	strSchema = schema
+	return nil
+}
+
+// name returns the element name of a field
+func (s StrSchema) name(field string) string {
+	if name, ok := s.Elements[field]; ok && name != "" {
+		return name
+	}
+	return field
+}
+
+// StrDocument is the content of a Suspicious Transaction Report
+type StrDocument struct {
+	ReportNumber    string
+	SchemaVersion   string
+	GeneratedAt     time.Time
+	ReportingEntity string
+	Officer         string
+	Case            *StrCase
+	Subjects        []StrSubject
+	Transactions    []StrTransaction
+	TriggeredRules  []StrRule
+	Notes           []StrNote
+}
+
+// StrCase is the case a report was generated from
+type StrCase struct {
+	ID       uint
+	Title    string
+	Status   string
+	Severity string
+	Summary  string
+}
+
+// StrSubject is an address involved in the reported transactions, with its EntityRegistry record
+type StrSubject struct {
+	Address            string
+	Registered         bool
+	Verified           bool
+	EntityType         string
+	EntityTypeMetadata string
+	Name               string
+	DataRoot           string // Merkle root of the identity fields kept by the verifier
+	Verifier           string
+	BlacklistStatus    string
+}
+
+// StrTransaction is a reported suspicious transfer
+type StrTransaction struct {
+	TxHash      string
+	BlockNumber uint64
+	Timestamp   time.Time
+	From        string
+	To          string
+	Amount      string
+	Reason      string
+	Severity    string
+}
+
+// StrRule is a rule triggered by the reported transactions
+type StrRule struct {
+	Rule           string
+	Code           string
+	LegalReference string
+	Severity       string
+	TxHashes       []string
+}
+
+// StrNote is an officer's note on the reported case
+type StrNote struct {
+	Author    string
+	CreatedAt time.Time
+	Body      string
+}
+
+// buildStrDocument collects the content of a report on a case or, without a case, on a
+// set of suspicious transfers. It returns the document and the IDs of the reported transfers.
+func buildStrDocument(ctx context.Context, caseID *uint, transferIDs []uint) (StrDocument, []uint, error) {
+	var doc StrDocument
+	var transfers []SuspiciousTransfer
+	var violations []RuleViolation
+	var addresses []string
+
+	if caseID != nil {
+		var kase Case
+		if err := db.First(&kase, *caseID).Error; err != nil {
+			return doc, nil, fmt.Errorf("error loading case %d: %w", *caseID, err)
+		}
+		doc.Case = &StrCase{ID: kase.ID, Title: kase.Title, Status: kase.Status, Severity: kase.Severity, Summary: kase.Summary}
+
+		var notes []CaseNote
+		queries := []*gorm.DB{
+			db.Where("id IN (?)", db.Model(&CaseTransfer{}).Select("suspicious_transfer_id").Where("case_id = ?", kase.ID)).Find(&transfers),
+			db.Where("id IN (?)", db.Model(&CaseViolation{}).Select("rule_violation_id").Where("case_id = ?", kase.ID)).Find(&violations),
+			db.Model(&CaseAddress{}).Where("case_id = ?", kase.ID).Pluck("address", &addresses),
+			db.Where("case_id = ?", kase.ID).Order("created_at, id").Find(&notes),
+		}
+		for _, q := range queries {
+			if q.Error != nil {
+				return doc, nil, fmt.Errorf("error loading case %d: %w", kase.ID, q.Error)
+			}
+		}
+		for _, note := range notes {
+			doc.Notes = append(doc.Notes, StrNote{Author: note.Author, CreatedAt: note.CreatedAt.UTC(), Body: note.Body})
+		}
+	} else {
+		if err := db.Where("id IN ?", transferIDs).Find(&transfers).Error; err != nil {
+			return doc, nil, fmt.Errorf("error loading suspicious transfers: %w", err)
+		}
+		if len(transfers) != len(transferIDs) {
+			return doc, nil, fmt.Errorf("found %d of %d suspicious transfers", len(transfers), len(transferIDs))
+		}
+		hashes := make([]string, len(transfers))
+		for i, t := range transfers {
+			hashes[i] = t.TxHash
+			addresses = append(addresses, t.From, t.To)
+		}
+		if err := db.Where("tx_hash IN ?", hashes).Find(&violations).Error; err != nil {
+			return doc, nil, fmt.Errorf("error loading rule violations: %w", err)
+		}
+	}
+	if len(transfers) == 0 {
+		return doc, nil, fmt.Errorf("no suspicious transfers to report")
+	}
+
+	sort.Slice(transfers, func(i, j int) bool {
+		if transfers[i].BlockNumber != transfers[j].BlockNumber {
+			return transfers[i].BlockNumber < transfers[j].BlockNumber
+		}
+		return transfers[i].TxHash < transfers[j].TxHash
+	})
+	ids := make([]uint, len(transfers))
+	for i, t := range transfers {
+		ids[i] = t.ID
+		doc.Transactions = append(doc.Transactions, StrTransaction{
+			TxHash:      t.TxHash,
+			BlockNumber: t.BlockNumber,
+			Timestamp:   t.Timestamp.UTC(),
+			From:        t.From,
+			To:          t.To,
+			Amount:      t.Amount,
+			Reason:      t.Reason,
+			Severity:    t.Severity,
+		})
+	}
+
+	rules, err := strTriggeredRules(violations)
+	if err != nil {
+		return doc, nil, err
+	}
+	doc.TriggeredRules = rules
+
+	subjects, err := strSubjects(ctx, addresses)
+	if err != nil {
+		return doc, nil, err
+	}
+	doc.Subjects = subjects
+	return doc, ids, nil
+}
+
+// strTriggeredRules groups rule violations by rule, with the rule's legal reference
+func strTriggeredRules(violations []RuleViolation) ([]StrRule, error) {
+	hashesByRule := make(map[uint][]string)
+	for _, v := range violations {
+		hashesByRule[v.RuleID] = append(hashesByRule[v.RuleID], v.TxHash)
+	}
+	if len(hashesByRule) == 0 {
+		return nil, nil
+	}
+	ruleIDs := make([]uint, 0, len(hashesByRule))
+	for id := range hashesByRule {
+		ruleIDs = append(ruleIDs, id)
+	}
+
+	var rules []Rule
+	if err := db.Unscoped().Where("id IN ?", ruleIDs).Find(&rules).Error; err != nil {
+		return nil, fmt.Errorf("error loading rules: %w", err)
+	}
+
+	result := make([]StrRule, 0, len(rules))
+	for _, rule := range rules {
+		ref := ruleLegalReference(rule)
+		hashes := hashesByRule[rule.ID]
+		sort.Strings(hashes)
+		result = append(result, StrRule{
+			Rule:           rule.Name,
+			Code:           ref.Code,
+			LegalReference: ref.Reference,
+			Severity:       rule.Severity,
+			TxHashes:       hashes,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Code != result[j].Code {
+			return result[i].Code < result[j].Code
+		}
+		return result[i].Rule < result[j].Rule
+	})
+	return result, nil
+}
+
+// strSubjects describes the addresses of a report with their EntityRegistry records and
+// blacklist status. The report is still generated when the registry cannot be read; the
+// subjects are then reported as unregistered.
+func strSubjects(ctx context.Context, addresses []string) ([]StrSubject, error) {
+	seen := make(map[string]bool)
+	var subjects []StrSubject
+	for _, addr := range addresses {
+		if !common.IsHexAddress(addr) {
+			continue
+		}
+		addr = common.HexToAddress(addr).Hex()
+		if seen[addr] || addr == (common.Address{}).Hex() {
+			continue
+		}
+		seen[addr] = true
+		subjects = append(subjects, StrSubject{Address: addr})
+	}
+	sort.Slice(subjects, func(i, j int) bool { return subjects[i].Address < subjects[j].Address })
+
+	var registry *contracts.EntityRegistryCaller
+	if entityRegistryAddress != "" {
+		client, err := ethclient.Dial(rpcURL)
+		if err != nil {
+			log.Printf("Failed to connect to Ethereum node for entity info: %v", err)
+		} else {
+			defer client.Close()
+			if registry, err = contracts.NewEntityRegistryCaller(common.HexToAddress(entityRegistryAddress), client); err != nil {
+				log.Printf("Failed to bind entity registry: %v", err)
+			}
+		}
+	}
+
+	opts := &bind.CallOpts{Context: ctx}
+	typeMetadata := make(map[uint8]string)
+	for i := range subjects {
+		var entry BlacklistedAddress
+		if err := db.Unscoped().Where("address = ?", subjects[i].Address).First(&entry).Error; err == nil {
+			subjects[i].BlacklistStatus = entry.Status
+		} else if err != gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("error loading blacklist status of %s: %w", subjects[i].Address, err)
+		}
+
+		if registry == nil {
+			continue
+		}
+		entity, err := registry.GetEntity(opts, common.HexToAddress(subjects[i].Address))
+		if err != nil {
+			log.Printf("Error reading entity of %s: %v", subjects[i].Address, err)
+			continue
+		}
+		if entity.EntityAddress == (common.Address{}) {
+			continue
+		}
+		subjects[i].Registered = true
+		subjects[i].EntityType = strconv.Itoa(int(entity.EntityType))
+		subjects[i].Verifier = entity.Verifier.Hex()
+		if name, root, err := decodeEntityData(entity.EntityData); err == nil {
+			subjects[i].Name, subjects[i].DataRoot = name, root
+		} else {
+			log.Printf("Error decoding entity data of %s: %v", subjects[i].Address, err)
+		}
+		if verified, err := registry.IsVerifiedEntity(opts, common.HexToAddress(subjects[i].Address)); err == nil {
+			subjects[i].Verified = verified
+		}
+		if _, ok := typeMetadata[entity.EntityType]; !ok {
+			metadata, err := registry.GetEntityTypeMetadata(opts, entity.EntityType)
+			if err != nil {
+				log.Printf("Error reading metadata of entity type %d: %v", entity.EntityType, err)
+			}
+			typeMetadata[entity.EntityType] = metadata
+		}
+		subjects[i].EntityTypeMetadata = typeMetadata[entity.EntityType]
+	}
+	return subjects, nil
+}
+
+// decodeEntityData decodes the entity data verifiers register: the ABI encoding of
+// tuple(string name, bytes32 root), root being the Merkle root of the identity fields
+func decodeEntityData(data []byte) (string, string, error) {
+	tupleType, err := abi.NewType("tuple", "", []abi.ArgumentMarshaling{
+		{Name: "name", Type: "string"},
+		{Name: "root", Type: "bytes32"},
+	})
+	if err != nil {
+		return "", "", err
+	}
+	values, err := abi.Arguments{{Type: tupleType}}.Unpack(data)
+	if err != nil {
+		return "", "", err
+	}
+	entity := *abi.ConvertType(values[0], new(struct {
+		Name string
+		Root [32]byte
+	})).(*struct {
+		Name string
+		Root [32]byte
+	})
+	return entity.Name, common.Hash(entity.Root).Hex(), nil
+}
+
+// strNode is an element of a report laid out by a schema. The children of a list are
+// repeated elements; a node without children holds a value.
+type strNode struct {
+	Name     string
+	Value    string
+	Children []strNode
+	List     bool
+}
+
+// tree lays the document out with the element names of schema
+func (d StrDocument) tree(schema StrSchema) strNode {
+	leaf := func(field, value string) strNode { return strNode{Name: schema.name(field), Value: value} }
+	group := func(field string, children ...strNode) strNode {
+		return strNode{Name: schema.name(field), Children: children}
+	}
+	list := func(field string, items []strNode) strNode {
+		return strNode{Name: schema.name(field), Children: items, List: true}
+	}
+	timestamp := func(t time.Time) string { return t.UTC().Format(time.RFC3339) }
+
+	root := group("",
+		leaf("report_number", d.ReportNumber),
+		leaf("schema_version", d.SchemaVersion),
+		leaf("generated_at", timestamp(d.GeneratedAt)),
+		leaf("reporting_entity", d.ReportingEntity),
+		leaf("officer", d.Officer),
+	)
+	root.Name = schema.Root
+
+	if d.Case != nil {
+		root.Children = append(root.Children, group("case",
+			leaf("case_id", strconv.FormatUint(uint64(d.Case.ID), 10)),
+			leaf("title", d.Case.Title),
+			leaf("status", d.Case.Status),
+			leaf("severity", d.Case.Severity),
+			leaf("summary", d.Case.Summary),
+		))
+	}
+
+	subjects := make([]strNode, len(d.Subjects))
+	for i, s := range d.Subjects {
+		subjects[i] = group("subject",
+			leaf("address", s.Address),
+			leaf("registered", strconv.FormatBool(s.Registered)),
+			leaf("verified", strconv.FormatBool(s.Verified)),
+			leaf("entity_type", s.EntityType),
+			leaf("entity_type_metadata", s.EntityTypeMetadata),
+			leaf("name", s.Name),
+			leaf("data_root", s.DataRoot),
+			leaf("verifier", s.Verifier),
+			leaf("blacklist_status", s.BlacklistStatus),
+		)
+	}
+
+	transactions := make([]strNode, len(d.Transactions))
+	for i, t := range d.Transactions {
+		transactions[i] = group("transaction",
+			leaf("tx_hash", t.TxHash),
+			leaf("block_number", strconv.FormatUint(t.BlockNumber, 10)),
+			leaf("timestamp", timestamp(t.Timestamp)),
+			leaf("from", t.From),
+			leaf("to", t.To),
+			leaf("amount", t.Amount),
+			leaf("reason", t.Reason),
+			leaf("severity", t.Severity),
+		)
+	}
+
+	rules := make([]strNode, len(d.TriggeredRules))
+	for i, r := range d.TriggeredRules {
+		hashes := make([]strNode, len(r.TxHashes))
+		for j, hash := range r.TxHashes {
+			hashes[j] = leaf("tx_hash", hash)
+		}
+		rules[i] = group("rule",
+			leaf("rule_name", r.Rule),
+			leaf("code", r.Code),
+			leaf("legal_reference", r.LegalReference),
+			leaf("severity", r.Severity),
+			list("tx_hashes", hashes),
+		)
+	}
+
+	notes := make([]strNode, len(d.Notes))
+	for i, n := range d.Notes {
+		notes[i] = group("note",
+			leaf("author", n.Author),
+			leaf("created_at", timestamp(n.CreatedAt)),
+			leaf("body", n.Body),
+		)
+	}
+
+	root.Children = append(root.Children,
+		list("subjects", subjects),
+		list("transactions", transactions),
+		list("triggered_rules", rules),
+		list("notes", notes),
+	)
+	return root
+}
+
+// Report formats and their content types
+var strContentTypes = map[string]string{
+	"xml":  "application/xml",
+	"json": "application/json",
+	"html": "text/html; charset=utf-8",
+	"pdf":  "application/pdf",
+}
+
+// renderStr renders a report in format. The output only depends on the document, so a
+// filed report can be regenerated byte for byte.
+func renderStr(doc StrDocument, format string, schema StrSchema) ([]byte, error) {
+	switch format {
+	case "xml":
+		return strXML(doc.tree(schema))
+	case "json":
+		return strJSON(doc.tree(schema))
+	case "html":
+		var buf bytes.Buffer
+		if err := strHTMLTemplate.Execute(&buf, doc); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case "pdf":
+		return strPDF(strTextLines(doc)), nil
+	}
+	return nil, fmt.Errorf("unsupported report format %q", format)
+}
+
+// strXML encodes a report tree as an XML document
+func strXML(root strNode) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+
+	var encode func(n strNode) error
+	encode = func(n strNode) error {
+		start := xml.StartElement{Name: xml.Name{Local: n.Name}}
+		if err := enc.EncodeToken(start); err != nil {
+			return err
+		}
+		if len(n.Children) == 0 && !n.List {
+			if err := enc.EncodeToken(xml.CharData(n.Value)); err != nil {
+				return err
+			}
+		}
+		for _, child := range n.Children {
+			if err := encode(child); err != nil {
+				return err
+			}
+		}
+		return enc.EncodeToken(start.End())
+	}
+	if err := encode(root); err != nil {
+		return nil, err
+	}
+	if err := enc.Flush(); err != nil {
+		return nil, err
+	}
+	buf.WriteString("\n")
+	return buf.Bytes(), nil
+}
+
+// strJSON encodes a report tree as a JSON object under the root name. Lists become arrays
+// of their elements' content; the element names of list items are dropped.
+func strJSON(root strNode) ([]byte, error) {
+	var buf bytes.Buffer
+	var encode func(n strNode)
+	encode = func(n strNode) {
+		switch {
+		case n.List:
+			buf.WriteByte('[')
+			for i, child := range n.Children {
+				if i > 0 {
+					buf.WriteByte(',')
+				}
+				encode(child)
+			}
+			buf.WriteByte(']')
+		case len(n.Children) == 0:
+			value, _ := json.Marshal(n.Value)
+			buf.Write(value)
+		default:
+			buf.WriteByte('{')
+			for i, child := range n.Children {
+				if i > 0 {
+					buf.WriteByte(',')
+				}
+				name, _ := json.Marshal(child.Name)
+				buf.Write(name)
+				buf.WriteByte(':')
+				encode(child)
+			}
+			buf.WriteByte('}')
+		}
+	}
+	encode(strNode{Children: []strNode{root}})
+
+	var out bytes.Buffer
+	if err := json.Indent(&out, buf.Bytes(), "", "  "); err != nil {
+		return nil, err
+	}
+	out.WriteString("\n")
+	return out.Bytes(), nil
+}
+
+var strHTMLTemplate = template.Must(template.New("str").Funcs(template.FuncMap{
+	"time": func(t time.Time) string { return t.UTC().Format(time.RFC3339) },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Suspicious Transaction Report {{.ReportNumber}}</title>
+<style>
+body { font-family: sans-serif; font-size: 12px; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 16px; }
+th, td { border: 1px solid #999; padding: 4px; text-align: left; vertical-align: top; }
+</style>
+</head>
+<body>
+<h1>Suspicious Transaction Report</h1>
+<table>
+<tr><th>Report number</th><td>{{.ReportNumber}}</td></tr>
+<tr><th>Generated at</th><td>{{time .GeneratedAt}}</td></tr>
+<tr><th>Reporting entity</th><td>{{.ReportingEntity}}</td></tr>
+<tr><th>Compliance officer</th><td>{{.Officer}}</td></tr>
+{{- with .Case}}
+<tr><th>Case</th><td>#{{.ID}} {{.Title}} ({{.Status}}, severity {{.Severity}})</td></tr>
+<tr><th>Summary</th><td>{{.Summary}}</td></tr>
+{{- end}}
+</table>
+<h2>Subjects</h2>
+<table>
+<tr><th>Address</th><th>Name</th><th>Entity type</th><th>Verified</th><th>Verifier</th><th>Data root</th><th>Blacklist</th></tr>
+{{- range .Subjects}}
+<tr><td>{{.Address}}</td><td>{{if .Registered}}{{.Name}}{{else}}Not registered{{end}}</td><td>{{.EntityType}} {{.EntityTypeMetadata}}</td><td>{{.Verified}}</td><td>{{.Verifier}}</td><td>{{.DataRoot}}</td><td>{{.BlacklistStatus}}</td></tr>
+{{- end}}
+</table>
+<h2>Transactions</h2>
+<table>
+<tr><th>Tx hash</th><th>Block</th><th>Time</th><th>From</th><th>To</th><th>Amount</th><th>Reason</th><th>Severity</th></tr>
+{{- range .Transactions}}
+<tr><td>{{.TxHash}}</td><td>{{.BlockNumber}}</td><td>{{time .Timestamp}}</td><td>{{.From}}</td><td>{{.To}}</td><td>{{.Amount}}</td><td>{{.Reason}}</td><td>{{.Severity}}</td></tr>
+{{- end}}
+</table>
+<h2>Triggered rules</h2>
+<table>
+<tr><th>Code</th><th>Rule</th><th>Legal reference</th><th>Severity</th><th>Transactions</th></tr>
+{{- range .TriggeredRules}}
+<tr><td>{{.Code}}</td><td>{{.Rule}}</td><td>{{.LegalReference}}</td><td>{{.Severity}}</td><td>{{range .TxHashes}}{{.}}<br>{{end}}</td></tr>
+{{- end}}
+</table>
+{{- if .Notes}}
+<h2>Officer notes</h2>
+{{- range .Notes}}
+<p><b>{{.Author}}</b>, {{time .CreatedAt}}<br>{{.Body}}</p>
+{{- end}}
+{{- end}}
+</body>
+</html>
+`))
+
+// strTextLines lays a report out as lines of text for the PDF rendering
+func strTextLines(d StrDocument) []string {
+	timestamp := func(t time.Time) string { return t.UTC().Format(time.RFC3339) }
+	lines := []string{
+		"SUSPICIOUS TRANSACTION REPORT",
+		"",
+		"Report number: " + d.ReportNumber,
+		"Generated at: " + timestamp(d.GeneratedAt),
+		"Reporting entity: " + d.ReportingEntity,
+		"Compliance officer: " + d.Officer,
+	}
+	if d.Case != nil {
+		lines = append(lines,
+			fmt.Sprintf("Case: #%d %s (%s, severity %s)", d.Case.ID, d.Case.Title, d.Case.Status, d.Case.Severity),
+			"Summary: "+d.Case.Summary,
+		)
+	}
+
+	lines = append(lines, "", "SUBJECTS")
+	for _, s := range d.Subjects {
+		lines = append(lines, "- "+s.Address)
+		if s.Registered {
+			lines = append(lines,
+				fmt.Sprintf("  Name: %s, entity type %s %s, verified: %t", s.Name, s.EntityType, s.EntityTypeMetadata, s.Verified),
+				"  Verifier: "+s.Verifier+", data root: "+s.DataRoot,
+			)
+		} else {
+			lines = append(lines, "  Not registered in the EntityRegistry")
+		}
+		if s.BlacklistStatus != "" {
+			lines = append(lines, "  Blacklist status: "+s.BlacklistStatus)
+		}
+	}
+
+	lines = append(lines, "", "TRANSACTIONS")
+	for _, t := range d.Transactions {
+		lines = append(lines,
+			"- "+t.TxHash,
+			fmt.Sprintf("  Block %d at %s, severity %s", t.BlockNumber, timestamp(t.Timestamp), t.Severity),
+			"  From "+t.From+" to "+t.To,
+			"  Amount: "+t.Amount,
+			"  Reason: "+t.Reason,
+		)
+	}
+
+	lines = append(lines, "", "TRIGGERED RULES")
+	for _, r := range d.TriggeredRules {
+		lines = append(lines,
+			fmt.Sprintf("- %s %s (severity %s)", r.Code, r.Rule, r.Severity),
+			"  Legal reference: "+r.LegalReference,
+		)
+		for _, hash := range r.TxHashes {
+			lines = append(lines, "  "+hash)
+		}
+	}
+
+	if len(d.Notes) > 0 {
+		lines = append(lines, "", "OFFICER NOTES")
+		for _, n := range d.Notes {
+			lines = append(lines, fmt.Sprintf("- %s, %s:", n.Author, timestamp(n.CreatedAt)))
+			for _, line := range strings.Split(n.Body, "\n") {
+				lines = append(lines, "  "+line)
+			}
+		}
+	}
+	return lines
+}
+
+// pdfText folds text to the ASCII the standard PDF fonts can show: Vietnamese letters lose
+// their diacritics and other characters are replaced
+func pdfText(s string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(s) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+		case r == 'đ':
+			b.WriteByte('d')
+		case r == 'Đ':
+			b.WriteByte('D')
+		case r >= ' ' && r <= '~':
+			b.WriteRune(r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+const (
+	pdfLineWidth    = 95 // Characters per line at 9pt Courier within the A4 margins
+	pdfLinesPerPage = 62
+)
+
+// strPDF writes lines of text as an A4 PDF document in Courier
+func strPDF(lines []string) []byte {
+	var wrapped []string
+	for _, line := range lines {
+		line = pdfText(line)
+		for len(line) > pdfLineWidth {
+			wrapped = append(wrapped, line[:pdfLineWidth])
+			line = "    " + line[pdfLineWidth:]
+		}
+		wrapped = append(wrapped, line)
+	}
+
+	var pages [][]string
+	for len(wrapped) > pdfLinesPerPage {
+		pages = append(pages, wrapped[:pdfLinesPerPage])
+		wrapped = wrapped[pdfLinesPerPage:]
+	}
+	pages = append(pages, wrapped)
+
+	// Objects: 1 catalog, 2 page tree, 3 font, then a page and its content stream per page
+	var buf bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n")
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 4+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+
+	escaper := strings.NewReplacer(`\`, `\\`, "(", `\(`, ")", `\)`)
+	for i, page := range pages {
+		var content strings.Builder
+		content.WriteString("BT\n/F1 9 Tf\n12 TL\n40 800 Td\n")
+		for _, line := range page {
+			fmt.Fprintf(&content, "(%s) '\n", escaper.Replace(line))
+		}
+		content.WriteString("ET")
+
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 595 842] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>", 5+2*i))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", content.Len(), content.String()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return buf.Bytes()
+}
+
+// StrRequest asks for a Suspicious Transaction Report on a case or on suspicious transfers
+type StrRequest struct {
+	CaseID              *uint  `json:"case_id"`
+	TransferIDs         []uint `json:"transfer_ids"`
+	Format              string `json:"format"`
+	Officer             string `json:"-"` // The authenticated user
+	SubmissionReference string `json:"submission_reference"`
+}
+
+// createStrReport generates a Suspicious Transaction Report and records it as filed
+func createStrReport(c *gin.Context) {
+	var req StrRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Officer = currentUser(c)
+	if (req.CaseID == nil) == (len(req.TransferIDs) == 0) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "either case_id or transfer_ids required"})
+		return
+	}
+	req.Format = strings.ToLower(req.Format)
+	contentType, ok := strContentTypes[req.Format]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be xml, json, html or pdf"})
+		return
+	}
+
+	seen := make(map[uint]bool)
+	var transferIDs []uint
+	for _, id := range req.TransferIDs {
+		if !seen[id] {
+			seen[id] = true
+			transferIDs = append(transferIDs, id)
+		}
+	}
+
+	doc, ids, err := buildStrDocument(c.Request.Context(), req.CaseID, transferIDs)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	doc.SchemaVersion = strSchema.Version
+	doc.ReportingEntity = reportingEntity
+	doc.Officer = req.Officer
+	idsJSON, _ := json.Marshal(ids)
+
+	report := StrReport{
+		CaseID:              req.CaseID,
+		TransferIDs:         string(idsJSON),
+		Format:              req.Format,
+		ContentType:         contentType,
+		FiledBy:             req.Officer,
+		SubmissionReference: req.SubmissionReference,
+	}
+	if req.Format == "xml" || req.Format == "json" {
+		report.SchemaVersion = strSchema.Version
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		// The report number is derived from the record, so the record is created first
+		report.FileName = "pending"
+		if err := tx.Create(&report).Error; err != nil {
+			return err
+		}
+		doc.ReportNumber = fmt.Sprintf("STR-%s-%06d", report.CreatedAt.UTC().Format("20060102"), report.ID)
+		doc.GeneratedAt = report.CreatedAt
+
+		content, err := renderStr(doc, req.Format, strSchema)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(content)
+		report.ReportNumber = doc.ReportNumber
+		report.FileName = doc.ReportNumber + "." + req.Format
+		report.Content = content
+		report.SHA256 = hex.EncodeToString(sum[:])
+		if err := tx.Save(&report).Error; err != nil {
+			return err
+		}
+
+		if req.CaseID != nil {
+			return addCaseEvent(tx, *req.CaseID, req.Officer, "report_filed", "", report.ReportNumber, report.SHA256)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Error filing suspicious transaction report: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	log.Printf("Filed suspicious transaction report %s (%s) by %s", report.ReportNumber, report.Format, report.FiledBy)
+	c.JSON(http.StatusOK, report)
+}
+
+// getStrReports returns the filed reports, optionally filtered by case or officer
+func getStrReports(c *gin.Context) {
+	query := db.Model(&StrReport{}).Omit("content").Order("created_at DESC").Limit(200)
+	if caseID := c.Query("case_id"); caseID != "" {
+		query = query.Where("case_id = ?", caseID)
+	}
+	if officer := c.Query("filed_by"); officer != "" {
+		query = query.Where("filed_by = ?", officer)
+	}
+
+	var reports []StrReport
+	if err := query.Find(&reports).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, reports)
+}
+
+// downloadStrReport returns the document of a filed report as it was filed
+func downloadStrReport(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid report id"})
+		return
+	}
+	var report StrReport
+	if err := db.First(&report, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "report not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", report.FileName))
+	c.Header("X-Content-SHA256", report.SHA256)
+	c.Data(http.StatusOK, report.ContentType, report.Content)
+}
@@ -0,0 +1,38 @@
+package main
+
+import "testing"
+
+func TestRuleLegalReference(t *testing.T) {
+	cases := []struct {
+		name string
+		rule Rule
+		want legalReference
+	}{
+		{
+			name: "named after its check",
+			rule: Rule{Name: "large_transfer"},
+			want: ruleLegalReferences["large_transfer"],
+		},
+		{
+			name: "clone of a check",
+			rule: Rule{Name: "large_transfer_vip", Type: "large_transfer"},
+			want: ruleLegalReferences["large_transfer"],
+		},
+		{
+			name: "actions override",
+			rule: Rule{Name: "incoming", Type: "multiple_incoming_transfers", Actions: `{"rule_code": "R103"}`},
+			want: legalReference{"R103", ruleLegalReferences["multiple_incoming_transfers"].Reference},
+		},
+		{
+			name: "check without a reference",
+			rule: Rule{Name: "blocked", Type: "repeated_blocked_attempts"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := ruleLegalReference(tc.rule); got != tc.want {
+				t.Errorf("ruleLegalReference() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
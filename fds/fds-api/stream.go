@@ -0,0 +1,356 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"gorm.io/gorm"
+)
+
+// streamChannel is the notification channel the stream_events triggers announce events on
+const streamChannel = "fds_stream"
+
+// Stream tuning
+const (
+	streamBuffer      = 256              // Events queued for a slow client before it is disconnected
+	streamReplayBatch = 500              // Events loaded at once when a client resumes
+	streamKeepAlive   = 15 * time.Second // Comment sent to idle clients so proxies keep the connection
+	// Events are read again this many IDs below the last one seen, since a transaction
+	// that took an ID earlier may commit after one that took a later ID
+	streamReorderWindow = 100
+)
+
+// streamFilter selects the events a client subscribed to; empty sets match everything
+type streamFilter struct {
+	types      map[string]bool
+	severities map[string]bool
+	rules      map[string]bool
+	address    string
+}
+
+// parseStreamFilter reads the type, severity, rule and address parameters of a stream request
+func parseStreamFilter(c *gin.Context) (*streamFilter, error) {
+	f := &streamFilter{
+		types:      make(map[string]bool),
+		severities: make(map[string]bool),
+		rules:      make(map[string]bool),
+	}
+	for param, set := range map[string]map[string]bool{"type": f.types, "severity": f.severities, "rule": f.rules} {
+		for _, value := range splitList(c.Query(param)) {
+			set[value] = true
+		}
+	}
+	for t := range f.types {
+		if t != "suspicious_transfer" && t != "rule_violation" && t != "blacklist" {
+			return nil, fmt.Errorf("unknown event type %s", t)
+		}
+	}
+	if value := c.Query("address"); value != "" {
+		if !common.IsHexAddress(value) {
+			return nil, fmt.Errorf("invalid address")
+		}
+		f.address = common.HexToAddress(value).Hex()
+	}
+	return f, nil
+}
+
+func (f *streamFilter) matches(e *StreamEvent) bool {
+	if len(f.types) > 0 && !f.types[e.Type] {
+		return false
+	}
+	if len(f.severities) > 0 && !f.severities[e.Severity] {
+		return false
+	}
+	if len(f.rules) > 0 && !f.rules[e.Rule] {
+		return false
+	}
+	if f.address != "" && !strings.EqualFold(e.FromAddress, f.address) && !strings.EqualFold(e.ToAddress, f.address) {
+		return false
+	}
+	return true
+}
+
+// scope applies the filter to a query on stream_events
+func (f *streamFilter) scope(query *gorm.DB) *gorm.DB {
+	for column, set := range map[string]map[string]bool{"type": f.types, "severity": f.severities, "rule": f.rules} {
+		if len(set) > 0 {
+			values := make([]string, 0, len(set))
+			for value := range set {
+				values = append(values, value)
+			}
+			query = query.Where(column+" IN ?", values)
+		}
+	}
+	if f.address != "" {
+		query = query.Where("(LOWER(from_address) = LOWER(?) OR LOWER(to_address) = LOWER(?))", f.address, f.address)
+	}
+	return query
+}
+
+// streamSubscriber is a connected stream client
+type streamSubscriber struct {
+	filter *streamFilter
+	events chan *StreamEvent
+}
+
+// streamHub listens for new events and fans them out to the connected clients
+type streamHub struct {
+	mu          sync.Mutex
+	subscribers map[*streamSubscriber]struct{}
+	lastID      uint64          // Highest event broadcast
+	broadcasted map[uint64]bool // Events broadcast within the reorder window below lastID
+	started     bool
+}
+
+var stream = &streamHub{subscribers: make(map[*streamSubscriber]struct{}), broadcasted: make(map[uint64]bool)}
+
+// windowStart returns the ID after which events are read again to catch late commits
+func windowStart(lastID uint64) uint64 {
+	if lastID < streamReorderWindow {
+		return 0
+	}
+	return lastID - streamReorderWindow
+}
+
+func (h *streamHub) subscribe(filter *streamFilter) *streamSubscriber {
+	sub := &streamSubscriber{filter: filter, events: make(chan *StreamEvent, streamBuffer)}
+	h.mu.Lock()
+	h.subscribers[sub] = struct{}{}
+	h.mu.Unlock()
+	return sub
+}
+
+func (h *streamHub) unsubscribe(sub *streamSubscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.subscribers[sub]; ok {
+		delete(h.subscribers, sub)
+		close(sub.events)
+	}
+}
+
+// broadcast hands an event to every client subscribed to it. A client whose queue is full
+// is disconnected; it resumes from its last event ID when it reconnects.
+func (h *streamHub) broadcast(e *StreamEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for sub := range h.subscribers {
+		if !sub.filter.matches(e) {
+			continue
+		}
+		select {
+		case sub.events <- e:
+		default:
+			log.Printf("Stream client too slow, disconnecting it at event %d", e.ID)
+			delete(h.subscribers, sub)
+			close(sub.events)
+		}
+	}
+}
+
+// run keeps the hub listening for events until ctx is done, reconnecting on errors, and
+// deletes events older than retention
+func (h *streamHub) run(ctx context.Context, dsn string, retention time.Duration) {
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for {
+			if err := db.Where("created_at < ?", time.Now().Add(-retention)).Delete(&StreamEvent{}).Error; err != nil {
+				log.Printf("Error pruning stream events: %v", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	for {
+		err := h.listen(ctx, dsn)
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("Error listening for stream events, reconnecting: %v", err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(5 * time.Second):
+		}
+	}
+}
+
+// listen subscribes to the stream channel and broadcasts each announced event. Events
+// written while the hub was disconnected are broadcast once it is listening again.
+func (h *streamHub) listen(ctx context.Context, dsn string) error {
+	conn, err := pgx.Connect(ctx, dsn)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+streamChannel); err != nil {
+		return err
+	}
+	if !h.started {
+		// Clients get history by resuming; the hub only broadcasts what is new
+		if err := db.Model(&StreamEvent{}).Select("COALESCE(MAX(id), 0)").Scan(&h.lastID).Error; err != nil {
+			return err
+		}
+		var recent []uint64
+		if err := db.Model(&StreamEvent{}).Where("id > ?", windowStart(h.lastID)).Pluck("id", &recent).Error; err != nil {
+			return err
+		}
+		for _, id := range recent {
+			h.broadcasted[id] = true
+		}
+		h.started = true
+	}
+
+	for {
+		if err := h.publishNew(); err != nil {
+			return err
+		}
+		if _, err := conn.WaitForNotification(ctx); err != nil {
+			return err
+		}
+	}
+}
+
+// publishNew broadcasts the events not broadcast yet, including those committed late within
+// the reorder window below the last one broadcast
+func (h *streamHub) publishNew() error {
+	after := windowStart(h.lastID)
+	for {
+		var events []StreamEvent
+		if err := db.Where("id > ?", after).Order("id").Limit(streamReplayBatch).Find(&events).Error; err != nil {
+			return err
+		}
+		for i := range events {
+			after = events[i].ID
+			if h.broadcasted[after] {
+				continue
+			}
+			h.broadcast(&events[i])
+			h.broadcasted[after] = true
+			if after > h.lastID {
+				h.lastID = after
+			}
+		}
+		if len(events) < streamReplayBatch {
+			break
+		}
+	}
+	for id := range h.broadcasted {
+		if id <= windowStart(h.lastID) {
+			delete(h.broadcasted, id)
+		}
+	}
+	return nil
+}
+
+// streamMessage is the data of a stream event
+type streamMessage struct {
+	*StreamEvent
+	Payload json.RawMessage `json:"payload"`
+}
+
+// streamEvents streams new suspicious transfers, rule violations and blacklist changes as
+// server-sent events, filtered by type, severity, rule and address. A client reconnecting
+// with Last-Event-ID (or last_event_id) first receives the events it missed, from the
+// reorder window below that ID on; it skips the ones it already has by ID.
+func streamEvents(c *gin.Context) {
+	filter, err := parseStreamFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+	var resumeID uint64
+	if lastEventID != "" {
+		if resumeID, err = strconv.ParseUint(lastEventID, 10, 64); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid last event ID"})
+			return
+		}
+	}
+
+	// Subscribe before replaying so nothing committed in between is lost; the replayed
+	// events that are also queued are skipped by ID
+	sub := stream.subscribe(filter)
+	defer stream.unsubscribe(sub)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	send := func(e *StreamEvent) {
+		c.Render(-1, sse.Event{
+			Id:    strconv.FormatUint(e.ID, 10),
+			Event: e.Type,
+			Data:  streamMessage{StreamEvent: e, Payload: json.RawMessage(e.Payload)},
+		})
+		c.Writer.Flush()
+	}
+
+	// Events replayed may also be queued, among the first streamBuffer received afterwards
+	var replayed map[uint64]bool
+	received := 0
+	if lastEventID != "" {
+		replayed = make(map[uint64]bool)
+		after := windowStart(resumeID)
+		for {
+			var events []StreamEvent
+			if err := filter.scope(db.Where("id > ?", after)).Order("id").Limit(streamReplayBatch).Find(&events).Error; err != nil {
+				log.Printf("Error replaying stream events after %d: %v", after, err)
+				return
+			}
+			for i := range events {
+				send(&events[i])
+				replayed[events[i].ID] = true
+				after = events[i].ID
+			}
+			if len(events) < streamReplayBatch {
+				break
+			}
+		}
+	}
+
+	keepAlive := time.NewTicker(streamKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case e, ok := <-sub.events:
+			if !ok {
+				return
+			}
+			if !replayed[e.ID] {
+				send(e)
+			}
+			if received++; received >= streamBuffer {
+				replayed = nil
+			}
+		case <-keepAlive.C:
+			c.Writer.WriteString(": keep-alive\n\n")
+			c.Writer.Flush()
+		}
+	}
+}
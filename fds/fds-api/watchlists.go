@@ -0,0 +1,282 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// watchlistFormats are the list formats the monitor can import
+var watchlistFormats = map[string]bool{"csv": true, "json": true, "ofac_xml": true}
+
+// watchlistSourceRequest is the body of a watchlist source create or update; absent fields
+// are kept on update
+type watchlistSourceRequest struct {
+	Name         string `json:"name"`
+	URL          string `json:"url"`
+	Format       string `json:"format"`
+	Enabled      *bool  `json:"enabled"`
+	SyncInterval *int   `json:"sync_interval_minutes"`
+}
+
+// apply validates the request and copies it onto source
+func (req *watchlistSourceRequest) apply(source *WatchlistSource) error {
+	if req.Name != "" {
+		source.Name = strings.TrimSpace(req.Name)
+	}
+	if req.URL != "" {
+		u, err := url.Parse(req.URL)
+		if err != nil || u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "file" {
+			return fmt.Errorf("url must be an http, https or file URL")
+		}
+		source.URL = req.URL
+	}
+	if req.Format != "" {
+		if !watchlistFormats[req.Format] {
+			return fmt.Errorf("format must be csv, json or ofac_xml")
+		}
+		source.Format = req.Format
+	}
+	if req.Enabled != nil {
+		source.Enabled = *req.Enabled
+	}
+	if req.SyncInterval != nil {
+		if *req.SyncInterval < 1 {
+			return fmt.Errorf("sync_interval_minutes must be positive")
+		}
+		source.SyncInterval = *req.SyncInterval
+	}
+	if source.Name == "" || source.URL == "" || source.Format == "" {
+		return fmt.Errorf("name, url and format are required")
+	}
+	return nil
+}
+
+// getWatchlistSources returns the watchlist sources with their sync state and the number
+// of addresses each currently lists
+func getWatchlistSources(c *gin.Context) {
+	var sources []WatchlistSource
+	if err := db.Order("name").Find(&sources).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	var counts []struct {
+		SourceID uint
+		Count    int64
+	}
+	if err := db.Model(&WatchlistEntry{}).Select("source_id, COUNT(*) AS count").
+		Where("status = ?", "active").Group("source_id").Scan(&counts).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	active := make(map[uint]int64)
+	for _, count := range counts {
+		active[count.SourceID] = count.Count
+	}
+	results := make([]gin.H, 0, len(sources))
+	for _, source := range sources {
+		results = append(results, gin.H{"source": source, "active_entries": active[source.ID]})
+	}
+	c.JSON(http.StatusOK, results)
+}
+
+// createWatchlistSource adds a watchlist source; the monitor imports it on its next pass
+func createWatchlistSource(c *gin.Context) {
+	var req watchlistSourceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	source := WatchlistSource{Enabled: true, SyncInterval: 1440, CreatedBy: currentUser(c)}
+	if err := req.apply(&source); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var existing int64
+	db.Model(&WatchlistSource{}).Where("name = ?", source.Name).Count(&existing)
+	if existing > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "watchlist source already exists"})
+		return
+	}
+	if err := db.Create(&source).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, source)
+}
+
+// updateWatchlistSource changes a watchlist source. A new URL or format is imported on the
+// monitor's next pass.
+func updateWatchlistSource(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid watchlist source id"})
+		return
+	}
+	var req watchlistSourceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var source WatchlistSource
+	status := http.StatusOK
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&source, id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				status = http.StatusNotFound
+			}
+			return err
+		}
+		oldURL, oldFormat := source.URL, source.Format
+		if err := req.apply(&source); err != nil {
+			status = http.StatusBadRequest
+			return err
+		}
+		if source.URL != oldURL || source.Format != oldFormat {
+			source.NextSyncAt = nil
+		}
+		return tx.Select("name", "url", "format", "enabled", "sync_interval", "next_sync_at").Save(&source).Error
+	})
+	if err != nil {
+		if status == http.StatusOK {
+			status = http.StatusInternalServerError
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, source)
+}
+
+// syncWatchlistSource makes a watchlist source due, so the monitor imports it on its next pass
+func syncWatchlistSource(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid watchlist source id"})
+		return
+	}
+	result := db.Model(&WatchlistSource{}).Where("id = ?", id).Update("next_sync_at", time.Now())
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "watchlist source not found"})
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"message": "watchlist sync scheduled"})
+}
+
+// getWatchlistVersions returns the versions imported from a watchlist source, newest first
+func getWatchlistVersions(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid watchlist source id"})
+		return
+	}
+	var versions []WatchlistVersion
+	if err := db.Where("source_id = ?", id).Order("id DESC").Find(&versions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, versions)
+}
+
+// watchlistSourceFilter keeps rows of the sources named in the source parameter
+func watchlistSourceFilter(c *gin.Context) (func(*gorm.DB) *gorm.DB, error) {
+	names := splitList(c.Query("source"))
+	if len(names) == 0 {
+		return nil, nil
+	}
+	return func(q *gorm.DB) *gorm.DB {
+		return q.Where("source_id IN (?)", db.Unscoped().Model(&WatchlistSource{}).Select("id").Where("name IN ?", names))
+	}, nil
+}
+
+var watchlistEntryList = listSpec{
+	Sorts: map[string]listSort{
+		"updated_at": {"updated_at", "TIMESTAMPTZ"},
+		"created_at": {"created_at", "TIMESTAMPTZ"},
+	},
+	DefaultSort: "updated_at",
+	Filters: []listFilter{
+		watchlistSourceFilter,
+		addressFilter("address", "address"),
+		inFilter("status", "status"),
+		inFilter("version_id", "added_version_id"),
+		timeRangeFilter("updated_at"),
+	},
+}
+
+// getWatchlistEntries returns a page of watchlisted addresses with the source and version
+// that listed them
+func getWatchlistEntries(c *gin.Context) {
+	var entries []WatchlistEntry
+	page, err := listFrom(c).find(db.Model(&WatchlistEntry{}), &entries)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, ListResponse{Data: entries, Pagination: page})
+}
+
+var watchlistChangeList = listSpec{
+	Sorts: map[string]listSort{
+		"created_at": {"created_at", "TIMESTAMPTZ"},
+	},
+	DefaultSort: "created_at",
+	Filters: []listFilter{
+		watchlistSourceFilter,
+		addressFilter("address", "address"),
+		inFilter("change", "change"),
+		inFilter("version_id", "version_id"),
+		timeRangeFilter("created_at"),
+	},
+}
+
+// getWatchlistChanges returns a page of the additions and removals between list versions
+func getWatchlistChanges(c *gin.Context) {
+	var changes []WatchlistChange
+	page, err := listFrom(c).find(db.Model(&WatchlistChange{}), &changes)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, ListResponse{Data: changes, Pagination: page})
+}
+
+var watchlistMatchList = listSpec{
+	Sorts: map[string]listSort{
+		"created_at":   {"created_at", "TIMESTAMPTZ"},
+		"tx_timestamp": {"tx_timestamp", "TIMESTAMPTZ"},
+		"block_number": {"block_number", "BIGINT"},
+	},
+	DefaultSort: "created_at",
+	Filters: []listFilter{
+		watchlistSourceFilter,
+		addressFilter("address", "address"),
+		inFilter("tx_hash", "tx_hash"),
+		inFilter("version_id", "version_id"),
+		timeRangeFilter("created_at"),
+		blockRangeFilter("block_number"),
+	},
+}
+
+// getWatchlistMatches returns a page of past transactions retroactive screening found for
+// newly listed addresses
+func getWatchlistMatches(c *gin.Context) {
+	var matches []WatchlistMatch
+	page, err := listFrom(c).find(db.Model(&WatchlistMatch{}), &matches)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, ListResponse{Data: matches, Pagination: page})
+}
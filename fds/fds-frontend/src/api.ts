@@ -1,4 +1,5 @@
 import axios from 'axios';
+import { getToken, withAuth } from './auth';
 import { AddressTotals, SuspiciousTransfer, BlacklistedAddress, RelatedAddresses, Rule, TransactionStats } from './types';
 
 // Create axios instance with base URL from environment variable
@@ -9,6 +10,8 @@ const api = axios.create({
   },
 });
 
+withAuth(api);
+
 // Add response interceptor for debugging
 api.interceptors.response.use(
   (response) => {
@@ -35,9 +38,9 @@ export const getAddressTotals = async (address: string): Promise<AddressTotals>
 
 export const getSuspiciousTransactions = async (): Promise<SuspiciousTransfer[]> => {
   try {
-    const response = await axios.get('/api/suspicious');
+    const response = await axios.get('/api/suspicious', { params: { limit: 500 } });
     // Map PascalCase to camelCase
-    return response.data.map((item: any) => ({
+    return response.data.data.map((item: any) => ({
       id: item.ID,
       from_address: item.From,
       to_address: item.To,
@@ -60,15 +63,22 @@ export const getSuspiciousTransactions = async (): Promise<SuspiciousTransfer[]>
 
 export const getBlacklist = async (): Promise<BlacklistedAddress[]> => {
   try {
-    const response = await axios.get('/api/blacklist');
+    const response = await axios.get('/api/blacklist', { params: { limit: 500 } });
     // Map PascalCase to camelCase
-    return response.data.map((item: any) => ({
+    return response.data.data.map((item: any) => ({
       id: item.ID,
       address: item.Address,
       reason: item.Reason,
       severity: item.Severity,
       blockNumber: item.BlockNumber,
       txHash: item.TxHash,
+      direction: item.Direction,
+      onChainReasonFrom: item.RestrictionFrom?.is_restricted ? item.RestrictionFrom.reason : undefined,
+      onChainReasonTo: item.RestrictionTo?.is_restricted ? item.RestrictionTo.reason : undefined,
+      status: item.Status,
+      holdType: item.HoldType,
+      expiresAt: item.ExpiresAt,
+      reviewAt: item.ReviewAt,
       createdAt: item.CreatedAt,
       updatedAt: item.UpdatedAt,
     }));
@@ -92,9 +102,9 @@ export const getRelatedAddresses = async (address: string): Promise<RelatedAddre
 
 export const getTransactionsByAddress = async (address: string) => {
   try {
-    const response = await axios.get('/api/address/transactions', { params: { address } });
+    const response = await axios.get('/api/address/transactions', { params: { address, limit: 500 } });
     // Map PascalCase to camelCase for frontend
-    return response.data.map((item: any) => ({
+    return response.data.data.map((item: any) => ({
       id: item.ID,
       txHash: item.Hash,
       from_address: item.From,
@@ -145,6 +155,7 @@ export const blacklistAddress = async (address: string) => {
 };
 
 export const unblacklistAddress = async (address: string) => {
+  // The request is recorded under the signed-in user
   const res = await axios.post('/api/unblacklist', { addresses: [address] });
   return res.data;
 };
@@ -212,4 +223,79 @@ export const getTransactionStats = async (): Promise<TransactionStats> => {
 function formatAmount(amount: string | number): string {
   // Convert to number, divide by 1e18, and show up to 6 decimals
   return (Number(amount) / 1e18).toLocaleString(undefined, { maximumFractionDigits: 6 });
-} 
\ No newline at end of file
+} 
+export interface StreamEvent {
+  id: number;
+  type: 'suspicious_transfer' | 'rule_violation' | 'blacklist';
+  severity: string;
+  rule?: string;
+  from_address?: string;
+  to_address?: string;
+  created_at: string;
+  payload: any;
+}
+
+// subscribeStream delivers new alerts matching filters as they happen. After a dropped
+// connection it reconnects and resumes from the last event received; the server replays a
+// few events before it, which are skipped if already delivered. Returns a function closing
+// the stream.
+export const subscribeStream = (
+  filters: { type?: string; severity?: string; rule?: string; address?: string },
+  onEvent: (event: StreamEvent) => void,
+): (() => void) => {
+  const controller = new AbortController();
+  let lastEventId = 0;
+  // Events can commit out of ID order, so recent IDs are remembered to skip repeats
+  const delivered = new Set<number>();
+
+  const connect = async () => {
+    while (!controller.signal.aborted) {
+      try {
+        const headers: Record<string, string> = { Accept: 'text/event-stream' };
+        const token = getToken();
+        if (token) headers.Authorization = `Bearer ${token}`;
+        if (lastEventId) headers['Last-Event-ID'] = String(lastEventId);
+        const params = Object.fromEntries(Object.entries(filters).filter(([, v]) => v));
+        const res = await fetch(api.getUri({ url: '/api/stream', params }), { headers, signal: controller.signal });
+        if (!res.ok || !res.body) throw new Error(`stream responded ${res.status}`);
+
+        const reader = res.body.pipeThrough(new TextDecoderStream()).getReader();
+        let buffer = '';
+        for (;;) {
+          const { value, done } = await reader.read();
+          if (done) break;
+          buffer += value;
+          let end;
+          while ((end = buffer.indexOf('\n\n')) >= 0) {
+            const message = buffer.slice(0, end);
+            buffer = buffer.slice(end + 2);
+            let id = 0;
+            let data = '';
+            for (const line of message.split('\n')) {
+              if (line.startsWith('id:')) id = Number(line.slice(3).trim());
+              else if (line.startsWith('data:')) data += line.slice(5);
+            }
+            if (!data) continue;
+            if (id) {
+              if (delivered.has(id)) continue;
+              delivered.add(id);
+              lastEventId = Math.max(lastEventId, id);
+              if (delivered.size > 1000) {
+                for (const old of delivered) {
+                  if (old < lastEventId - 500) delivered.delete(old);
+                }
+              }
+            }
+            onEvent(JSON.parse(data));
+          }
+        }
+      } catch (error) {
+        if (controller.signal.aborted) return;
+        console.error('Alert stream error:', error);
+      }
+      await new Promise((resolve) => setTimeout(resolve, 5000));
+    }
+  };
+  connect();
+  return () => controller.abort();
+};
@@ -0,0 +1,64 @@
+import axios, { AxiosError, AxiosInstance, InternalAxiosRequestConfig } from 'axios';
+
+const TOKEN_KEY = 'fds_token';
+
+let pendingLogin: Promise<string | null> | null = null;
+
+export const getToken = () => localStorage.getItem(TOKEN_KEY);
+
+export const logout = () => localStorage.removeItem(TOKEN_KEY);
+
+// login asks for credentials once, however many requests are waiting for them
+const login = (client: AxiosInstance): Promise<string | null> => {
+  if (!pendingLogin) {
+    pendingLogin = (async () => {
+      const username = window.prompt('Username');
+      const password = username ? window.prompt('Password') : null;
+      if (!username || !password) {
+        return null;
+      }
+      try {
+        const res = await client.post('/api/auth/login', { username, password });
+        localStorage.setItem(TOKEN_KEY, res.data.token);
+        return res.data.token as string;
+      } catch {
+        window.alert('Login failed');
+        return null;
+      }
+    })().finally(() => {
+      pendingLogin = null;
+    });
+  }
+  return pendingLogin;
+};
+
+// withAuth sends the stored token on every request of client, and signs in again when the
+// API answers 401
+export const withAuth = (client: AxiosInstance) => {
+  client.interceptors.request.use((config: InternalAxiosRequestConfig) => {
+    const token = getToken();
+    if (token && config.url !== '/api/auth/login') {
+      config.headers.Authorization = `Bearer ${token}`;
+    }
+    return config;
+  });
+
+  client.interceptors.response.use(undefined, async (error: AxiosError) => {
+    const config = error.config as (InternalAxiosRequestConfig & { _retried?: boolean }) | undefined;
+    if (error.response?.status !== 401 || !config || config._retried || config.url === '/api/auth/login') {
+      return Promise.reject(error);
+    }
+    logout();
+    const token = await login(client);
+    if (!token) {
+      return Promise.reject(error);
+    }
+    config._retried = true;
+    config.headers.Authorization = `Bearer ${token}`;
+    return client(config);
+  });
+
+  return client;
+};
+
+withAuth(axios);
@@ -15,7 +15,7 @@ import {
   Snackbar,
   Alert as MuiAlert,
 } from '@mui/material';
-import { getBlacklist, unblacklistAddress, deleteBlacklistAddress } from '../../api';
+import { getBlacklist, unblacklistAddress } from '../../api';
 import { BlacklistedAddress } from '../../types';
 
 export const BlacklistView = () => {
@@ -42,13 +42,13 @@ export const BlacklistView = () => {
   };
 
   const handleUnblacklist = async (address: string) => {
+    if (!window.confirm('Unblacklisting needs two other approvers. Request it?')) {
+      return;
+    }
     try {
-      // First call unblacklist endpoint
+      // The entry is lifted by the monitor once two approvers have approved the request
       await unblacklistAddress(address);
-      // Then call delete endpoint
-      await deleteBlacklistAddress(address);
-      setSnackbar({ open: true, message: 'Address unblacklisted successfully', severity: 'success' });
-      // Refresh the blacklist after successful unblacklist
+      setSnackbar({ open: true, message: 'Unblacklist requested, awaiting approval', severity: 'success' });
       await fetchBlacklist();
     } catch (err) {
       setSnackbar({ open: true, message: 'Failed to unblacklist address', severity: 'error' });
@@ -78,6 +78,9 @@ export const BlacklistView = () => {
             <TableRow>
               <TableCell>Address</TableCell>
               <TableCell>Reason</TableCell>
+              <TableCell>Direction</TableCell>
+              <TableCell>On-chain Reason</TableCell>
+              <TableCell>Status</TableCell>
               <TableCell>Severity</TableCell>
               <TableCell>Block Number</TableCell>
               <TableCell>Action</TableCell>
@@ -86,7 +89,7 @@ export const BlacklistView = () => {
           <TableBody>
             {blacklist.length === 0 ? (
               <TableRow>
-                <TableCell colSpan={5} align="center">
+                <TableCell colSpan={8} align="center">
                   No blacklisted addresses found.
                 </TableCell>
               </TableRow>
@@ -95,6 +98,21 @@ export const BlacklistView = () => {
                 <TableRow key={item.id}>
                   <TableCell>{item.address}</TableCell>
                   <TableCell>{item.reason}</TableCell>
+                  <TableCell>{item.direction || 'both'}</TableCell>
+                  <TableCell>
+                    {item.onChainReasonFrom && <div>Sending: {item.onChainReasonFrom}</div>}
+                    {item.onChainReasonTo && <div>Receiving: {item.onChainReasonTo}</div>}
+                    {!item.onChainReasonFrom && !item.onChainReasonTo && 'N/A'}
+                  </TableCell>
+                  <TableCell>
+                    <div>{item.status || 'confirmed'}{item.holdType === 'law_enforcement' && ' (law enforcement)'}</div>
+                    {item.status === 'provisional' && item.expiresAt && (
+                      <div>Expires {new Date(item.expiresAt).toLocaleString()}</div>
+                    )}
+                    {item.status === 'confirmed' && item.reviewAt && (
+                      <div>Review {new Date(item.reviewAt).toLocaleString()}</div>
+                    )}
+                  </TableCell>
                   <TableCell>{item.severity}</TableCell>
                   <TableCell>{item.blockNumber}</TableCell>
                   <TableCell>
@@ -92,7 +92,7 @@ export const ComplianceRulesTable = ({ onClick }: ComplianceRulesTableProps) =>
       try {
         setLoading(true);
         const res = await axios.get('/api/rules/violations');
-        setRules(res.data);
+        setRules(res.data.data);
       } catch (err) {
         setError('Failed to fetch compliance rules');
       } finally {
@@ -17,7 +17,7 @@ import {
   Alert,
   Chip,
 } from '@mui/material';
-import { getSuspiciousTransactions, getRelatedTransactionsOfSuspicious } from '../../api';
+import { getSuspiciousTransactions, getRelatedTransactionsOfSuspicious, subscribeStream } from '../../api';
 import { SuspiciousTransfer } from '../../types';
 import { formatTokenAmount } from '../../utils/format';
 
@@ -50,6 +50,27 @@ export const SuspiciousTransactionsList = () => {
     fetchTransactions();
   }, []);
 
+  // Prepend suspicious transfers as the monitor records them
+  useEffect(() => subscribeStream({ type: 'suspicious_transfer' }, (event) => {
+    const item = event.payload;
+    const tx: SuspiciousTransfer = {
+      id: item.id,
+      from_address: item.from_address,
+      to_address: item.to_address,
+      amount: item.amount,
+      txHash: item.tx_hash,
+      blockNumber: item.block_number,
+      timestamp: item.timestamp,
+      reason: item.reason,
+      severity: item.severity,
+      details: item.details,
+      isBlacklisted: item.is_blacklisted,
+      createdAt: item.created_at,
+      updatedAt: item.updated_at,
+    };
+    setTransactions((current) => (current.some((t) => t.id === tx.id) ? current : [tx, ...current]));
+  }), []);
+
   const fetchTransactions = async () => {
     try {
       setLoading(true);
@@ -28,6 +28,13 @@ export interface BlacklistedAddress {
   reason: string;
   severity: string;
   details: string;
+  direction?: string;
+  onChainReasonFrom?: string;
+  onChainReasonTo?: string;
+  status?: string;
+  holdType?: string;
+  expiresAt?: string;
+  reviewAt?: string;
   createdAt: string;
   updatedAt: string;
 }
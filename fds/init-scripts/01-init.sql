@@ -15,7 +15,8 @@ CREATE TABLE IF NOT EXISTS transactions (
     timestamp TIMESTAMP WITH TIME ZONE NOT NULL,
     is_analyzed BOOLEAN DEFAULT FALSE,
     is_pending BOOLEAN DEFAULT FALSE,
-    status VARCHAR(20) DEFAULT 'confirmed'
+    status VARCHAR(20) DEFAULT 'confirmed',
+    tx_type VARCHAR(16)
 );
 
 CREATE TABLE IF NOT EXISTS pending_transactions (
@@ -30,7 +31,14 @@ CREATE TABLE IF NOT EXISTS pending_transactions (
     block_number BIGINT NOT NULL,
     timestamp TIMESTAMP WITH TIME ZONE NOT NULL,
     is_analyzed BOOLEAN DEFAULT FALSE,
-    status VARCHAR(20) DEFAULT 'pending'
+    status VARCHAR(20) DEFAULT 'pending',
+    effects JSON,
+    revert_reason TEXT,
+    revert_error VARCHAR(64),
+    revert_data TEXT,
+    compliance_module VARCHAR(64),
+    gas_tip_cap TEXT,
+    gas_fee_cap TEXT
 );
 
 CREATE TABLE IF NOT EXISTS token_transfers (
@@ -58,9 +66,33 @@ CREATE TABLE IF NOT EXISTS blacklisted_addresses (
     address VARCHAR(42) UNIQUE NOT NULL,
     tx_hash VARCHAR(66) NOT NULL,
     block_number BIGINT NOT NULL,
+    direction VARCHAR(8) DEFAULT 'both',
     reason TEXT,
     severity VARCHAR(10),
-    details TEXT
+    details TEXT,
+    status VARCHAR(16) DEFAULT 'confirmed',
+    hold_type VARCHAR(16) DEFAULT 'manual',
+    legal_reference TEXT,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    review_at TIMESTAMP WITH TIME ZONE,
+    confirmed_by VARCHAR(128),
+    confirmed_at TIMESTAMP WITH TIME ZONE,
+    lifted_at TIMESTAMP WITH TIME ZONE,
+    lift_reason TEXT
+);
+
+-- Audit trail of blacklist lifecycle transitions
+CREATE TABLE IF NOT EXISTS blacklist_status_changes (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    address VARCHAR(42) NOT NULL,
+    from_status VARCHAR(16),
+    to_status VARCHAR(16) NOT NULL,
+    actor VARCHAR(128),
+    reason TEXT,
+    enforcement_action_id INTEGER
 );
 
 CREATE TABLE IF NOT EXISTS suspicious_transfers (
@@ -90,6 +122,110 @@ CREATE TABLE IF NOT EXISTS suspicious_transfer_related_txs (
     deleted_at TIMESTAMP WITH TIME ZONE
 );
 
+-- Queued on-chain enforcement actions (pending -> submitted -> mined/failed)
+CREATE TABLE IF NOT EXISTS enforcement_actions (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    address VARCHAR(42) NOT NULL,
+    action VARCHAR(32) NOT NULL DEFAULT 'blacklist',
+    direction VARCHAR(8) NOT NULL DEFAULT 'both',
+    status VARCHAR(20) DEFAULT 'pending',
+    source VARCHAR(64),
+    trigger_tx_hash VARCHAR(66),
+    reason TEXT,
+    on_chain_reason TEXT,
+    severity VARCHAR(10),
+    details TEXT,
+    hold_type VARCHAR(16),
+    legal_reference TEXT,
+    requested_by VARCHAR(128),
+    required_approvals INTEGER DEFAULT 0,
+    tx_hash VARCHAR(66),
+    nonce BIGINT,
+    block_number BIGINT,
+    attempts INTEGER DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    submitted_at TIMESTAMP WITH TIME ZONE,
+    completed_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Officer decisions on enforcement actions awaiting approval
+CREATE TABLE IF NOT EXISTS action_approvals (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    enforcement_action_id INTEGER NOT NULL REFERENCES enforcement_actions(id),
+    approver VARCHAR(128) NOT NULL,
+    decision VARCHAR(16) NOT NULL,
+    comment TEXT
+);
+
+-- Blacklist transactions sent to front-run suspicious pending transactions
+CREATE TABLE IF NOT EXISTS preemptive_blacklists (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    address VARCHAR(42) NOT NULL,
+    direction VARCHAR(8) DEFAULT 'both',
+    reason TEXT,
+    target_tx_hash VARCHAR(66) NOT NULL,
+    target_gas_tip_cap TEXT,
+    target_gas_fee_cap TEXT,
+    tx_hash VARCHAR(66),
+    replaced_tx_hashes TEXT,
+    nonce BIGINT,
+    gas_tip_cap TEXT,
+    gas_fee_cap TEXT,
+    replacements INTEGER DEFAULT 0,
+    last_sent_at TIMESTAMP WITH TIME ZONE,
+    status VARCHAR(20) DEFAULT 'submitted',
+    block_number BIGINT,
+    tx_index INTEGER,
+    target_status VARCHAR(20),
+    target_block_number BIGINT,
+    target_tx_index INTEGER,
+    outcome VARCHAR(20)
+);
+
+-- Blacklist and unblacklist events emitted by the restriction contract
+CREATE TABLE IF NOT EXISTS restriction_events (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    address VARCHAR(42) NOT NULL,
+    event VARCHAR(32) NOT NULL,
+    direction VARCHAR(8) NOT NULL,
+    restricted BOOLEAN,
+    reason TEXT,
+    actor VARCHAR(42),
+    timestamp TIMESTAMP WITH TIME ZONE,
+    tx_hash VARCHAR(66) NOT NULL,
+    log_index INTEGER,
+    block_number BIGINT NOT NULL
+);
+
+-- Discrepancies between blacklisted_addresses and the restriction contract
+CREATE TABLE IF NOT EXISTS blacklist_drifts (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    address VARCHAR(42) NOT NULL,
+    kind VARCHAR(32) NOT NULL,
+    db_direction VARCHAR(8),
+    chain_direction VARCHAR(8),
+    repaired BOOLEAN DEFAULT FALSE,
+    repair_action TEXT,
+    last_seen_at TIMESTAMP WITH TIME ZONE,
+    resolved_at TIMESTAMP WITH TIME ZONE
+);
+
 -- Compliance rules table
 CREATE TABLE IF NOT EXISTS rules (
     id SERIAL PRIMARY KEY,
@@ -101,11 +237,32 @@ CREATE TABLE IF NOT EXISTS rules (
     actions JSONB NOT NULL DEFAULT '{}',
     violations BIGINT DEFAULT 0,
     last_violation_at TIMESTAMP WITH TIME ZONE,
+    shadow_of VARCHAR(128) DEFAULT '',
+    type VARCHAR(64) DEFAULT '',
+    version INTEGER DEFAULT 0,
+    version_id INTEGER,
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     deleted_at TIMESTAMP WITH TIME ZONE
 );
 
+-- Would-be violations of shadow rules, kept apart from rule_violations
+CREATE TABLE IF NOT EXISTS shadow_violations (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    rule_id INTEGER NOT NULL REFERENCES rules(id),
+    rule_version_id INTEGER,
+    rule_name VARCHAR(128),
+    check_name VARCHAR(128),
+    tx_hash VARCHAR(66) NOT NULL,
+    block_number BIGINT,
+    address VARCHAR(42),
+    details JSONB,
+    UNIQUE (rule_id, tx_hash)
+);
+
 -- Rule violations table
 CREATE TABLE IF NOT EXISTS rule_violations (
     id SERIAL PRIMARY KEY,
@@ -114,11 +271,459 @@ CREATE TABLE IF NOT EXISTS rule_violations (
     block_number BIGINT NOT NULL,
     details JSONB NOT NULL DEFAULT '{}',
     action_taken VARCHAR(255) DEFAULT '',
+    incident_id INTEGER,
+    suppressed BOOLEAN DEFAULT FALSE,
+    rule_version_id INTEGER,
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     deleted_at TIMESTAMP WITH TIME ZONE
 );
 
+-- Investigation cases grouping suspicious transfers, violations and addresses
+CREATE TABLE IF NOT EXISTS cases (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    title TEXT NOT NULL,
+    status VARCHAR(32) NOT NULL DEFAULT 'open',
+    severity VARCHAR(10),
+    assignee VARCHAR(128),
+    summary TEXT,
+    merged_into_id INTEGER REFERENCES cases(id),
+    closed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE TABLE IF NOT EXISTS case_addresses (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    case_id INTEGER NOT NULL REFERENCES cases(id),
+    address VARCHAR(42) NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS case_transfers (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    case_id INTEGER NOT NULL REFERENCES cases(id),
+    suspicious_transfer_id INTEGER NOT NULL REFERENCES suspicious_transfers(id),
+    tx_hash VARCHAR(66)
+);
+
+CREATE TABLE IF NOT EXISTS case_violations (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    case_id INTEGER NOT NULL REFERENCES cases(id),
+    rule_violation_id INTEGER NOT NULL REFERENCES rule_violations(id)
+);
+
+CREATE TABLE IF NOT EXISTS case_notes (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    case_id INTEGER NOT NULL REFERENCES cases(id),
+    author VARCHAR(128) NOT NULL,
+    body TEXT NOT NULL
+);
+
+-- Metadata of files attached to cases; the files are stored elsewhere
+CREATE TABLE IF NOT EXISTS case_attachments (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    case_id INTEGER NOT NULL REFERENCES cases(id),
+    file_name TEXT NOT NULL,
+    content_type VARCHAR(128),
+    size BIGINT,
+    storage_uri TEXT,
+    sha256 VARCHAR(64),
+    uploaded_by VARCHAR(128)
+);
+
+-- Audit history of cases
+CREATE TABLE IF NOT EXISTS case_events (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    case_id INTEGER NOT NULL REFERENCES cases(id),
+    actor VARCHAR(128),
+    action VARCHAR(32) NOT NULL,
+    from_value TEXT,
+    to_value TEXT,
+    details TEXT
+);
+
+-- Suspicious Transaction Reports filed with the State Bank
+CREATE TABLE IF NOT EXISTS str_reports (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    report_number VARCHAR(64),
+    case_id INTEGER REFERENCES cases(id),
+    transfer_ids TEXT,
+    format VARCHAR(8) NOT NULL,
+    schema_version VARCHAR(32),
+    file_name TEXT NOT NULL,
+    content_type VARCHAR(128),
+    content BYTEA,
+    sha256 VARCHAR(64),
+    filed_by VARCHAR(128) NOT NULL,
+    submission_reference TEXT
+);
+
+-- Large-value transaction reports generated for each reporting period
+CREATE TABLE IF NOT EXISTS ctr_reports (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    period VARCHAR(16) NOT NULL,
+    period_start TIMESTAMP WITH TIME ZONE NOT NULL,
+    period_end TIMESTAMP WITH TIME ZONE NOT NULL,
+    threshold TEXT NOT NULL,
+    transfer_count INTEGER,
+    entity_count INTEGER,
+    total_amount TEXT,
+    file_name TEXT NOT NULL,
+    size BIGINT,
+    sha256 VARCHAR(64)
+);
+
+-- Table for API users signing in with a password
+CREATE TABLE IF NOT EXISTS users (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    username VARCHAR(64) UNIQUE NOT NULL,
+    password_hash TEXT NOT NULL,
+    role VARCHAR(32) NOT NULL DEFAULT 'viewer',
+    disabled BOOLEAN DEFAULT FALSE,
+    last_login_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Append-only, hash-chained log of operator and enforcement actions
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id SERIAL PRIMARY KEY,
+    seq BIGINT UNIQUE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    request_id VARCHAR(32),
+    actor TEXT,
+    role VARCHAR(32),
+    action VARCHAR(64) NOT NULL,
+    method VARCHAR(8),
+    path TEXT,
+    parameters TEXT,
+    reason TEXT,
+    tx_hash VARCHAR(66),
+    result VARCHAR(16) NOT NULL,
+    status INTEGER,
+    error TEXT,
+    prev_hash VARCHAR(64) NOT NULL,
+    hash VARCHAR(64) UNIQUE NOT NULL
+);
+
+CREATE OR REPLACE FUNCTION audit_logs_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_logs is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_logs_no_modify ON audit_logs;
+CREATE TRIGGER audit_logs_no_modify BEFORE UPDATE OR DELETE ON audit_logs
+    FOR EACH ROW EXECUTE FUNCTION audit_logs_append_only();
+DROP TRIGGER IF EXISTS audit_logs_no_truncate ON audit_logs;
+CREATE TRIGGER audit_logs_no_truncate BEFORE TRUNCATE ON audit_logs
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_logs_append_only();
+
+-- Alerts queued for notification channels and the log of their delivery
+CREATE TABLE IF NOT EXISTS notification_deliveries (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    channel VARCHAR(128) NOT NULL,
+    channel_type VARCHAR(16),
+    rule_name VARCHAR(128),
+    rule_violation_id INTEGER,
+    severity VARCHAR(16),
+    tx_hash VARCHAR(66),
+    payload TEXT,
+    status VARCHAR(16) DEFAULT 'pending',
+    attempts INTEGER DEFAULT 0,
+    last_error TEXT,
+    response_status INTEGER,
+    next_attempt_at TIMESTAMP WITH TIME ZONE,
+    sent_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Violations of one rule by one address grouped into an incident; repeats within the
+-- rule's suppression window join the open incident without alerting again
+CREATE TABLE IF NOT EXISTS alert_incidents (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    fingerprint VARCHAR(64) NOT NULL,
+    rule_id INTEGER REFERENCES rules(id),
+    rule_name VARCHAR(128),
+    address VARCHAR(42),
+    severity VARCHAR(10),
+    status VARCHAR(16) DEFAULT 'open',
+    first_seen_at TIMESTAMP WITH TIME ZONE,
+    last_seen_at TIMESTAMP WITH TIME ZONE,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    count INTEGER DEFAULT 0,
+    suppressed_count INTEGER DEFAULT 0,
+    first_tx_hash VARCHAR(66),
+    last_tx_hash VARCHAR(66),
+    resolved_by VARCHAR(128),
+    resolved_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Addresses whose alerts are silenced until a time, for one rule or all of them
+CREATE TABLE IF NOT EXISTS alert_snoozes (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    address VARCHAR(42) NOT NULL,
+    rule_name VARCHAR(128) DEFAULT '',
+    until TIMESTAMP WITH TIME ZONE NOT NULL,
+    reason TEXT,
+    created_by VARCHAR(128)
+);
+
+-- Backtests of candidate rule parameters, queued by fds-api and run by the monitor
+CREATE TABLE IF NOT EXISTS backtest_runs (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    status VARCHAR(16) DEFAULT 'pending',
+    requested_by VARCHAR(128),
+    request TEXT,
+    report TEXT,
+    error TEXT,
+    started_at TIMESTAMP WITH TIME ZONE,
+    finished_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Immutable snapshots of rules, one per change, written by fds-api
+CREATE TABLE IF NOT EXISTS rule_versions (
+    id SERIAL PRIMARY KEY,
+    rule_id INTEGER NOT NULL REFERENCES rules(id),
+    version INTEGER NOT NULL,
+    name VARCHAR(128) NOT NULL,
+    type VARCHAR(64) NOT NULL,
+    description TEXT,
+    status VARCHAR(16) NOT NULL,
+    severity VARCHAR(16) NOT NULL,
+    parameters JSONB,
+    actions JSONB,
+    shadow_of VARCHAR(128),
+    change VARCHAR(16) NOT NULL,
+    comment TEXT,
+    changed_by VARCHAR(128),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (rule_id, version)
+);
+
+CREATE OR REPLACE FUNCTION rule_versions_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'rule_versions is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS rule_versions_no_modify ON rule_versions;
+CREATE TRIGGER rule_versions_no_modify BEFORE UPDATE OR DELETE ON rule_versions
+    FOR EACH ROW EXECUTE FUNCTION rule_versions_append_only();
+
+-- Dry runs of the rules on hypothetical transfers, queued by fds-api and run by the monitor
+CREATE TABLE IF NOT EXISTS rule_evaluations (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    status VARCHAR(16) DEFAULT 'pending',
+    requested_by VARCHAR(128),
+    request TEXT,
+    result TEXT,
+    error TEXT,
+    finished_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Analyst verdicts on suspicious transfers and rule violations, one per alert
+CREATE TABLE IF NOT EXISTS alert_labels (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    target_type VARCHAR(32) NOT NULL,
+    target_id INTEGER NOT NULL,
+    tx_hash VARCHAR(66),
+    rule_id INTEGER,
+    label VARCHAR(32) NOT NULL,
+    note TEXT,
+    labeled_by VARCHAR(128),
+    UNIQUE(target_type, target_id)
+);
+
+-- Sanctions and watchlist lists the monitor imports on a schedule
+CREATE TABLE IF NOT EXISTS watchlist_sources (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    name VARCHAR(64) UNIQUE NOT NULL,
+    url TEXT NOT NULL,
+    format VARCHAR(16) NOT NULL,
+    enabled BOOLEAN DEFAULT TRUE,
+    sync_interval INTEGER DEFAULT 1440,
+    next_sync_at TIMESTAMP WITH TIME ZONE,
+    last_sync_at TIMESTAMP WITH TIME ZONE,
+    last_version_id INTEGER,
+    last_checksum VARCHAR(64),
+    last_error TEXT,
+    created_by VARCHAR(128)
+);
+
+-- Distinct contents of the watchlists, with what each added and removed
+CREATE TABLE IF NOT EXISTS watchlist_versions (
+    id SERIAL PRIMARY KEY,
+    source_id INTEGER NOT NULL,
+    version VARCHAR(128),
+    checksum VARCHAR(64),
+    entries INTEGER DEFAULT 0,
+    added INTEGER DEFAULT 0,
+    removed INTEGER DEFAULT 0,
+    skipped INTEGER DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS watchlist_entries (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    source_id INTEGER NOT NULL,
+    address VARCHAR(42) NOT NULL,
+    name TEXT,
+    reference VARCHAR(128),
+    status VARCHAR(16) DEFAULT 'active',
+    added_version_id INTEGER,
+    removed_version_id INTEGER,
+    screened_at TIMESTAMP WITH TIME ZONE,
+    UNIQUE(source_id, address)
+);
+
+CREATE TABLE IF NOT EXISTS watchlist_changes (
+    id SERIAL PRIMARY KEY,
+    source_id INTEGER NOT NULL,
+    version_id INTEGER NOT NULL,
+    address VARCHAR(42) NOT NULL,
+    change VARCHAR(16) NOT NULL,
+    name TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Past transactions of newly listed addresses, found by retroactive screening
+CREATE TABLE IF NOT EXISTS watchlist_matches (
+    id SERIAL PRIMARY KEY,
+    entry_id INTEGER NOT NULL,
+    source_id INTEGER,
+    version_id INTEGER,
+    address VARCHAR(42),
+    tx_hash VARCHAR(66) NOT NULL,
+    block_number BIGINT,
+    direction VARCHAR(8),
+    amount TEXT,
+    tx_timestamp TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(entry_id, tx_hash)
+);
+
+-- Alerts published to fds-api stream subscribers, written by the triggers below
+CREATE TABLE IF NOT EXISTS stream_events (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    type VARCHAR(32) NOT NULL,
+    severity VARCHAR(16),
+    rule VARCHAR(128),
+    from_address VARCHAR(42),
+    to_address VARCHAR(42),
+    payload JSONB NOT NULL DEFAULT '{}'
+);
+
+-- Publishes new suspicious transfers and rule violations, and blacklist changes, to
+-- stream_events and announces them on the fds_stream channel
+CREATE OR REPLACE FUNCTION publish_stream_event() RETURNS trigger AS $$
+DECLARE
+    r RECORD;
+    ev stream_events%ROWTYPE;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        r := OLD;
+    ELSE
+        r := NEW;
+    END IF;
+    -- Only blacklist entries are updated; other updates are not alerts
+    IF TG_OP = 'UPDATE' THEN
+        IF NEW.status IS NOT DISTINCT FROM OLD.status
+            AND NEW.direction IS NOT DISTINCT FROM OLD.direction
+            AND NEW.deleted_at IS NOT DISTINCT FROM OLD.deleted_at THEN
+            RETURN NULL;
+        END IF;
+    END IF;
+
+    -- Event IDs must follow commit order so a client resuming after an ID misses nothing
+    PERFORM pg_advisory_xact_lock(7410041);
+
+    ev.payload := to_jsonb(r);
+    IF TG_TABLE_NAME = 'suspicious_transfers' THEN
+        ev.type := 'suspicious_transfer';
+        ev.severity := r.severity;
+        ev.from_address := r.from_address;
+        ev.to_address := r.to_address;
+    ELSIF TG_TABLE_NAME = 'rule_violations' THEN
+        ev.type := 'rule_violation';
+        SELECT name, severity INTO ev.rule, ev.severity FROM rules WHERE id = r.rule_id;
+        SELECT from_address, to_address INTO ev.from_address, ev.to_address FROM transactions WHERE hash = r.tx_hash;
+    ELSE
+        ev.type := 'blacklist';
+        ev.severity := r.severity;
+        ev.from_address := r.address;
+        ev.payload := ev.payload || jsonb_build_object('operation', lower(TG_OP));
+    END IF;
+
+    INSERT INTO stream_events (created_at, type, severity, rule, from_address, to_address, payload)
+    VALUES (CURRENT_TIMESTAMP, ev.type, ev.severity, ev.rule, ev.from_address, ev.to_address, ev.payload)
+    RETURNING id INTO ev.id;
+    PERFORM pg_notify('fds_stream', ev.id::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS suspicious_transfers_stream ON suspicious_transfers;
+CREATE TRIGGER suspicious_transfers_stream AFTER INSERT ON suspicious_transfers
+    FOR EACH ROW EXECUTE FUNCTION publish_stream_event();
+DROP TRIGGER IF EXISTS rule_violations_stream ON rule_violations;
+-- Suppressed repeats of an alert are not published
+CREATE TRIGGER rule_violations_stream AFTER INSERT ON rule_violations
+    FOR EACH ROW WHEN (NEW.suppressed IS NOT TRUE) EXECUTE FUNCTION publish_stream_event();
+DROP TRIGGER IF EXISTS blacklisted_addresses_stream ON blacklisted_addresses;
+CREATE TRIGGER blacklisted_addresses_stream AFTER INSERT OR UPDATE OR DELETE ON blacklisted_addresses
+    FOR EACH ROW EXECUTE FUNCTION publish_stream_event();
+
 -- Table for suspicious addresses
 CREATE TABLE IF NOT EXISTS suspicious_addresses (
     id SERIAL PRIMARY KEY,
@@ -127,14 +732,39 @@ CREATE TABLE IF NOT EXISTS suspicious_addresses (
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
 );
 
--- Table for whitelist addresses
+-- Table for whitelist addresses: exemptions from rule checks, optionally scoped to rule
+-- types, a direction and a counterparty, effective once approved and until they expire
 CREATE TABLE IF NOT EXISTS whitelist_addresses (
     id SERIAL PRIMARY KEY,
-    address VARCHAR(42) UNIQUE NOT NULL,
+    address VARCHAR(42) NOT NULL,
     reason TEXT,
+    rules TEXT,
+    direction VARCHAR(8) DEFAULT 'both',
+    counterparty VARCHAR(42),
+    status VARCHAR(16) DEFAULT 'active',
+    expires_at TIMESTAMP WITH TIME ZONE,
+    requested_by VARCHAR(128),
+    approved_by VARCHAR(128),
+    approved_at TIMESTAMP WITH TIME ZONE,
+    revoked_by VARCHAR(128),
+    revoked_at TIMESTAMP WITH TIME ZONE,
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
 );
 
+-- Rule checks the whitelist exempted transactions from, and what they would have flagged
+CREATE TABLE IF NOT EXISTS whitelist_exemptions (
+    id SERIAL PRIMARY KEY,
+    whitelist_id INTEGER,
+    address VARCHAR(42),
+    tx_hash VARCHAR(66) NOT NULL,
+    block_number BIGINT,
+    check_name VARCHAR(64) NOT NULL,
+    fired BOOLEAN DEFAULT FALSE,
+    details JSONB,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(whitelist_id, tx_hash, check_name)
+);
+
 -- Insert default rules
 INSERT INTO rules (name, description, status, severity, parameters, actions)
 VALUES
@@ -144,7 +774,7 @@ VALUES
         'active',
         'high',
         '{"threshold": "1000000000000000000000", "description": "Transfer amount threshold in wei"}',
-        '{"action": "record_violation", "description": "Record violation when transfer amount exceeds threshold"}'
+        '{"action": "record_violation", "blacklist_direction": "both", "enforcement": "auto", "description": "Record violation when transfer amount exceeds threshold"}'
     ),
     (
         'multiple_transfers',
@@ -160,7 +790,7 @@ VALUES
         'active',
         'high',
         '{"threshold": "1000000000000000000000", "block_range": 10, "description": "Total amount threshold in wei and block range to check"}',
-        '{"action": "record_violation", "description": "Record violation when address receives multiple transfers exceeding threshold"}'
+        '{"action": "record_violation", "blacklist_direction": "to", "enforcement": "auto", "description": "Record violation when address receives multiple transfers exceeding threshold"}'
     ),
     (
         'suspicious_address',
@@ -168,7 +798,7 @@ VALUES
         'active',
         'high',
         '{"addresses": [], "description": "List of known suspicious addresses to monitor"}',
-        '{"action": "record_violation", "description": "Record violation when transaction involves suspicious address"}'
+        '{"action": "record_violation", "blacklist_direction": "both", "enforcement": "auto", "description": "Record violation when transaction involves suspicious address"}'
     ),
     (
         'insufficient_balance',
@@ -177,17 +807,37 @@ VALUES
         'high',
         '{"description": "Check if sender has sufficient balance before transfer", "check_blocks": 5}',
         '{"action": "record_violation", "description": "Record violation when transfer amount exceeds sender''s previous balance"}'
+    ),
+    (
+        'repeated_blocked_attempts',
+        'Detects addresses repeatedly attempting transfers that are blocked by compliance modules',
+        'active',
+        'medium',
+        '{"min_attempts": 3, "block_range": 100, "description": "Minimum number of blocked attempts and block range to check"}',
+        '{"action": "record_violation", "description": "Record violation when an address keeps retrying blocked transfers"}'
     )
 ON CONFLICT (name) DO NOTHING;
 
+-- Rules from before rule types run the check named like them, or that of the rule they shadow
+UPDATE rules SET type = COALESCE(NULLIF(shadow_of, ''), name) WHERE type IS NULL OR type = '';
+
+-- Every rule starts at version 1
+INSERT INTO rule_versions (rule_id, version, name, type, description, status, severity, parameters, actions, shadow_of, change, comment, changed_by)
+SELECT id, 1, name, type, description, status, severity, parameters, actions, COALESCE(shadow_of, ''), 'create', 'Initial version', 'system'
+FROM rules
+WHERE NOT EXISTS (SELECT 1 FROM rule_versions WHERE rule_versions.rule_id = rules.id);
+UPDATE rules SET version = rule_versions.version, version_id = rule_versions.id
+FROM rule_versions
+WHERE rule_versions.rule_id = rules.id AND rule_versions.version = 1 AND rules.version_id IS NULL;
+
 -- Insert initial whitelist address
-INSERT INTO whitelist_addresses (address, reason)
-VALUES ('0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92261', 'deployer')
-ON CONFLICT (address) DO NOTHING;
+INSERT INTO whitelist_addresses (address, reason, approved_by)
+SELECT '0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92261', 'deployer', 'system'
+WHERE NOT EXISTS (SELECT 1 FROM whitelist_addresses WHERE address = '0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92261');
 
-INSERT INTO whitelist_addresses (address, reason)
-VALUES ('0x439Fd6e51aad88F6F4ce6aB8827279cffFb92261', 'reviewer')
-ON CONFLICT (address) DO NOTHING;
+INSERT INTO whitelist_addresses (address, reason, approved_by)
+SELECT '0x439Fd6e51aad88F6F4ce6aB8827279cffFb92261', 'reviewer', 'system'
+WHERE NOT EXISTS (SELECT 1 FROM whitelist_addresses WHERE address = '0x439Fd6e51aad88F6F4ce6aB8827279cffFb92261');
 
 -- Insert initial suspicious address
 INSERT INTO suspicious_addresses (address, reason)
@@ -202,12 +852,114 @@ CREATE INDEX IF NOT EXISTS idx_transactions_from_block ON transactions(from_addr
 CREATE INDEX IF NOT EXISTS idx_transactions_to_block ON transactions(to_address, block_number);
 CREATE INDEX IF NOT EXISTS idx_pending_transactions_from_block ON pending_transactions(from_address, block_number);
 CREATE INDEX IF NOT EXISTS idx_pending_transactions_to_block ON pending_transactions(to_address, block_number);
+CREATE INDEX IF NOT EXISTS idx_pending_transactions_compliance_module ON pending_transactions(compliance_module);
+CREATE INDEX IF NOT EXISTS idx_enforcement_actions_address ON enforcement_actions(address);
+CREATE INDEX IF NOT EXISTS idx_enforcement_actions_status ON enforcement_actions(status, next_attempt_at);
+CREATE INDEX IF NOT EXISTS idx_enforcement_actions_tx_hash ON enforcement_actions(tx_hash);
+-- At most one open (awaiting approval, pending or submitted) action per address, action type and direction
+CREATE UNIQUE INDEX IF NOT EXISTS idx_enforcement_actions_open ON enforcement_actions(address, action, direction) WHERE status IN ('awaiting_approval', 'pending', 'submitted') AND deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_action_approvals_action ON action_approvals(enforcement_action_id);
+CREATE INDEX IF NOT EXISTS idx_cases_status ON cases(status);
+CREATE INDEX IF NOT EXISTS idx_cases_assignee ON cases(assignee);
+CREATE INDEX IF NOT EXISTS idx_cases_merged_into_id ON cases(merged_into_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_case_addresses_case_address ON case_addresses(case_id, address);
+CREATE INDEX IF NOT EXISTS idx_case_addresses_address ON case_addresses(address);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_case_transfers_case_transfer ON case_transfers(case_id, suspicious_transfer_id);
+CREATE INDEX IF NOT EXISTS idx_case_transfers_tx_hash ON case_transfers(tx_hash);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_case_violations_case_violation ON case_violations(case_id, rule_violation_id);
+CREATE INDEX IF NOT EXISTS idx_case_notes_case_id ON case_notes(case_id);
+CREATE INDEX IF NOT EXISTS idx_case_attachments_case_id ON case_attachments(case_id);
+CREATE INDEX IF NOT EXISTS idx_case_events_case_id ON case_events(case_id);
+CREATE INDEX IF NOT EXISTS idx_str_reports_report_number ON str_reports(report_number);
+CREATE INDEX IF NOT EXISTS idx_str_reports_case_id ON str_reports(case_id);
+CREATE INDEX IF NOT EXISTS idx_str_reports_filed_by ON str_reports(filed_by);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ctr_reports_period ON ctr_reports(period, period_start);
+CREATE INDEX IF NOT EXISTS idx_notification_deliveries_channel ON notification_deliveries(channel);
+CREATE INDEX IF NOT EXISTS idx_notification_deliveries_rule_name ON notification_deliveries(rule_name);
+CREATE INDEX IF NOT EXISTS idx_notification_deliveries_rule_violation_id ON notification_deliveries(rule_violation_id);
+CREATE INDEX IF NOT EXISTS idx_notification_deliveries_severity ON notification_deliveries(severity);
+CREATE INDEX IF NOT EXISTS idx_notification_deliveries_tx_hash ON notification_deliveries(tx_hash);
+CREATE INDEX IF NOT EXISTS idx_notification_deliveries_status ON notification_deliveries(status);
+CREATE INDEX IF NOT EXISTS idx_notification_deliveries_next_attempt_at ON notification_deliveries(next_attempt_at);
+CREATE INDEX IF NOT EXISTS idx_notification_deliveries_deleted_at ON notification_deliveries(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_stream_events_created_at ON stream_events(created_at);
+CREATE INDEX IF NOT EXISTS idx_stream_events_from_address ON stream_events(from_address);
+CREATE INDEX IF NOT EXISTS idx_stream_events_to_address ON stream_events(to_address);
+CREATE INDEX IF NOT EXISTS idx_rule_violations_incident_id ON rule_violations(incident_id);
+CREATE INDEX IF NOT EXISTS idx_alert_incidents_fingerprint ON alert_incidents(fingerprint);
+CREATE INDEX IF NOT EXISTS idx_alert_incidents_rule_id ON alert_incidents(rule_id);
+CREATE INDEX IF NOT EXISTS idx_alert_incidents_rule_name ON alert_incidents(rule_name);
+CREATE INDEX IF NOT EXISTS idx_alert_incidents_address ON alert_incidents(address);
+CREATE INDEX IF NOT EXISTS idx_alert_incidents_status ON alert_incidents(status);
+CREATE INDEX IF NOT EXISTS idx_alert_incidents_expires_at ON alert_incidents(expires_at);
+CREATE INDEX IF NOT EXISTS idx_alert_incidents_deleted_at ON alert_incidents(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_alert_snoozes_address ON alert_snoozes(address);
+CREATE INDEX IF NOT EXISTS idx_alert_snoozes_rule_name ON alert_snoozes(rule_name);
+CREATE INDEX IF NOT EXISTS idx_alert_snoozes_until ON alert_snoozes(until);
+CREATE INDEX IF NOT EXISTS idx_alert_snoozes_deleted_at ON alert_snoozes(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_backtest_runs_status ON backtest_runs(status);
+CREATE INDEX IF NOT EXISTS idx_backtest_runs_deleted_at ON backtest_runs(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_rules_shadow_of ON rules(shadow_of);
+CREATE INDEX IF NOT EXISTS idx_shadow_violations_check_name ON shadow_violations(check_name);
+CREATE INDEX IF NOT EXISTS idx_shadow_violations_tx_hash ON shadow_violations(tx_hash);
+CREATE INDEX IF NOT EXISTS idx_shadow_violations_created_at ON shadow_violations(created_at);
+CREATE INDEX IF NOT EXISTS idx_shadow_violations_deleted_at ON shadow_violations(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_rules_type ON rules(type);
+CREATE INDEX IF NOT EXISTS idx_rule_violations_rule_version_id ON rule_violations(rule_version_id);
+CREATE INDEX IF NOT EXISTS idx_rule_evaluations_status ON rule_evaluations(status);
+CREATE INDEX IF NOT EXISTS idx_rule_evaluations_deleted_at ON rule_evaluations(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_alert_labels_tx_hash ON alert_labels(tx_hash);
+CREATE INDEX IF NOT EXISTS idx_alert_labels_rule_id ON alert_labels(rule_id);
+CREATE INDEX IF NOT EXISTS idx_alert_labels_label ON alert_labels(label);
+CREATE INDEX IF NOT EXISTS idx_alert_labels_deleted_at ON alert_labels(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_whitelist_addresses_address ON whitelist_addresses(address);
+CREATE INDEX IF NOT EXISTS idx_whitelist_addresses_status ON whitelist_addresses(status);
+CREATE INDEX IF NOT EXISTS idx_whitelist_addresses_expires_at ON whitelist_addresses(expires_at);
+CREATE INDEX IF NOT EXISTS idx_whitelist_exemptions_whitelist_id ON whitelist_exemptions(whitelist_id);
+CREATE INDEX IF NOT EXISTS idx_whitelist_exemptions_address ON whitelist_exemptions(address);
+CREATE INDEX IF NOT EXISTS idx_whitelist_exemptions_created_at ON whitelist_exemptions(created_at);
+CREATE INDEX IF NOT EXISTS idx_watchlist_sources_next_sync_at ON watchlist_sources(next_sync_at);
+CREATE INDEX IF NOT EXISTS idx_watchlist_sources_deleted_at ON watchlist_sources(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_watchlist_versions_source_id ON watchlist_versions(source_id);
+CREATE INDEX IF NOT EXISTS idx_watchlist_entries_address ON watchlist_entries(address);
+CREATE INDEX IF NOT EXISTS idx_watchlist_entries_status ON watchlist_entries(status);
+CREATE INDEX IF NOT EXISTS idx_watchlist_entries_deleted_at ON watchlist_entries(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_watchlist_entries_screened_at ON watchlist_entries(screened_at);
+CREATE INDEX IF NOT EXISTS idx_watchlist_changes_source_id ON watchlist_changes(source_id);
+CREATE INDEX IF NOT EXISTS idx_watchlist_changes_version_id ON watchlist_changes(version_id);
+CREATE INDEX IF NOT EXISTS idx_watchlist_changes_address ON watchlist_changes(address);
+CREATE INDEX IF NOT EXISTS idx_watchlist_changes_created_at ON watchlist_changes(created_at);
+CREATE INDEX IF NOT EXISTS idx_watchlist_matches_source_id ON watchlist_matches(source_id);
+CREATE INDEX IF NOT EXISTS idx_watchlist_matches_address ON watchlist_matches(address);
+CREATE INDEX IF NOT EXISTS idx_watchlist_matches_created_at ON watchlist_matches(created_at);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_created_at ON audit_logs(created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_request_id ON audit_logs(request_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_actor ON audit_logs(actor);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_action ON audit_logs(action);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_tx_hash ON audit_logs(tx_hash);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_result ON audit_logs(result);
+CREATE INDEX IF NOT EXISTS idx_transactions_timestamp ON transactions(timestamp);
+CREATE INDEX IF NOT EXISTS idx_preemptive_blacklists_address ON preemptive_blacklists(address);
+CREATE INDEX IF NOT EXISTS idx_preemptive_blacklists_target_tx_hash ON preemptive_blacklists(target_tx_hash);
+CREATE INDEX IF NOT EXISTS idx_preemptive_blacklists_status ON preemptive_blacklists(status);
+CREATE INDEX IF NOT EXISTS idx_preemptive_blacklists_outcome ON preemptive_blacklists(outcome);
+CREATE INDEX IF NOT EXISTS idx_restriction_events_address ON restriction_events(address);
+CREATE INDEX IF NOT EXISTS idx_restriction_events_block_number ON restriction_events(block_number);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_restriction_events_log ON restriction_events(tx_hash, log_index);
+CREATE INDEX IF NOT EXISTS idx_blacklist_drifts_address ON blacklist_drifts(address);
+CREATE INDEX IF NOT EXISTS idx_blacklist_drifts_kind ON blacklist_drifts(kind);
+CREATE INDEX IF NOT EXISTS idx_blacklist_drifts_resolved_at ON blacklist_drifts(resolved_at);
 CREATE INDEX IF NOT EXISTS idx_token_transfers_transaction_hash ON token_transfers(transaction_hash);
 CREATE INDEX IF NOT EXISTS idx_token_transfers_from ON token_transfers(from_address);
 CREATE INDEX IF NOT EXISTS idx_token_transfers_to ON token_transfers(to_address);
 CREATE INDEX IF NOT EXISTS idx_token_transfers_token ON token_transfers(token_address);
 CREATE INDEX IF NOT EXISTS idx_blacklisted_addresses_tx_hash ON blacklisted_addresses(tx_hash);
 CREATE INDEX IF NOT EXISTS idx_blacklisted_addresses_block ON blacklisted_addresses(block_number);
+CREATE INDEX IF NOT EXISTS idx_blacklisted_addresses_status ON blacklisted_addresses(status);
+CREATE INDEX IF NOT EXISTS idx_blacklisted_addresses_expires_at ON blacklisted_addresses(expires_at);
+CREATE INDEX IF NOT EXISTS idx_blacklisted_addresses_review_at ON blacklisted_addresses(review_at);
+CREATE INDEX IF NOT EXISTS idx_blacklist_status_changes_address ON blacklist_status_changes(address);
 CREATE INDEX IF NOT EXISTS idx_suspicious_transfers_from ON suspicious_transfers(from_address);
 CREATE INDEX IF NOT EXISTS idx_suspicious_transfers_to ON suspicious_transfers(to_address);
 CREATE INDEX IF NOT EXISTS idx_suspicious_transfer_related_txs_transfer ON suspicious_transfer_related_txs(suspicious_transfer_id);
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AlertIncident groups the violations of one rule by one address. Violations arriving within
+// the rule's suppression window of the last one join the open incident as suppressed repeats
+// instead of alerting again; a later one closes it and opens a new incident.
+type AlertIncident struct {
+	gorm.Model
+	Fingerprint     string `gorm:"index;not null"` // Hash of the rule name and address
+	RuleID          uint   `gorm:"index"`
+	RuleName        string `gorm:"index"`
+	Address         string `gorm:"index"`
+	Severity        string
+	Status          string `gorm:"index;default:'open'"` // "open", "closed" or "resolved"
+	FirstSeenAt     time.Time
+	LastSeenAt      time.Time
+	ExpiresAt       time.Time `gorm:"index"` // End of the suppression window
+	Count           int       // Violations grouped into the incident
+	SuppressedCount int       // Violations that did not alert
+	FirstTxHash     string
+	LastTxHash      string
+	ResolvedBy      string
+	ResolvedAt      *time.Time
+}
+
+// AlertSnooze silences alerts on an address until a time. Violations are still recorded,
+// as suppressed, and nothing is blacklisted for them.
+type AlertSnooze struct {
+	gorm.Model
+	Address   string    `gorm:"index;not null"`
+	RuleName  string    `gorm:"index"` // Empty to snooze every rule
+	Until     time.Time `gorm:"index"`
+	Reason    string    `gorm:"type:text"`
+	CreatedBy string
+}
@@ -0,0 +1,17 @@
+package models
+
+import "gorm.io/gorm"
+
+// AlertLabel is an analyst's verdict on an alert: a suspicious transfer or a rule violation.
+// An alert has one label; labeling it again replaces it. A violation without a label of its
+// own takes the label of the suspicious transfer of its transaction.
+type AlertLabel struct {
+	gorm.Model
+	TargetType string `gorm:"not null;uniqueIndex:idx_alert_labels_target"` // "suspicious_transfer" or "rule_violation"
+	TargetID   uint   `gorm:"not null;uniqueIndex:idx_alert_labels_target"`
+	TxHash     string `gorm:"index"`
+	RuleID     uint   `gorm:"index"`          // Rule of a violation
+	Label      string `gorm:"index;not null"` // "true_positive", "false_positive" or "needs_info"
+	Note       string `gorm:"type:text"`
+	LabeledBy  string
+}
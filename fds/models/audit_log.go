@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+)
+
+// AuditLog is an entry of the append-only audit log of operator and enforcement actions.
+// Each entry carries the hash of the previous one, so any change to the log breaks the chain.
+// The table has no soft delete; the database rejects updates and deletes.
+type AuditLog struct {
+	ID         uint      `gorm:"primarykey"`
+	Seq        uint64    `gorm:"uniqueIndex;not null"` // Position in the chain, starting at 1
+	CreatedAt  time.Time `gorm:"index;not null"`
+	RequestID  string    `gorm:"index"` // Shared by the started and result entries of one request
+	Actor      string    `gorm:"index"`
+	Role       string
+	Action     string `gorm:"index;not null"`
+	Method     string
+	Path       string
+	Parameters string `gorm:"type:text"` // JSON; secrets are redacted
+	Reason     string `gorm:"type:text"`
+	TxHash     string `gorm:"index"`          // On-chain transaction, when the action has one
+	Result     string `gorm:"index;not null"` // "started", "succeeded" or "failed"
+	Status     int    // HTTP status of the response
+	Error      string `gorm:"type:text"`
+	PrevHash   string `gorm:"not null"`
+	Hash       string `gorm:"uniqueIndex;not null"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// BacktestRun is a replay of stored transactions through the rules with candidate
+// parameters, requested through fds-api and run by the monitor. Runs start "pending", go
+// "running" and end "done" with a report or "failed" with an error.
+type BacktestRun struct {
+	gorm.Model
+	Status      string `gorm:"index;default:'pending'"`
+	RequestedBy string
+	Request     string `gorm:"type:text"` // services.BacktestRequest as JSON
+	Report      string `gorm:"type:text"` // services.BacktestReport as JSON, once done
+	Error       string `gorm:"type:text"`
+	StartedAt   *time.Time
+	FinishedAt  *time.Time
+}
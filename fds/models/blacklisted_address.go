@@ -12,17 +12,68 @@ type BlacklistedAddress struct {
 	Address     string `gorm:"uniqueIndex;not null"`
 	TxHash      string `gorm:"index;not null"` // Transaction hash that added this address to blacklist
 	BlockNumber uint64 `gorm:"index;not null"` // Block number when address was blacklisted
+	Direction   string `gorm:"default:'both'"` // Restricted direction: "both", "from" (sending) or "to" (receiving)
 	Reason      string // Reason for blacklisting
 	Severity    string // Severity level of the suspicious behavior
 	Details     string // Additional details about the blacklisting
+
+	// Lifecycle: automated freezes start "provisional" and are lifted when ExpiresAt passes
+	// unless an officer confirms them; manual blacklists and law-enforcement holds start
+	// "confirmed" and are only reviewed at ReviewAt. Lifted entries are kept soft-deleted.
+	Status         string     `gorm:"index;default:'confirmed'"` // "provisional", "confirmed" or "lifted"
+	HoldType       string     `gorm:"default:'manual'"`          // "automated", "manual" or "law_enforcement"
+	LegalReference string     // Order or case reference of a law-enforcement hold
+	ExpiresAt      *time.Time `gorm:"index"` // When a provisional entry is lifted automatically
+	ReviewAt       *time.Time `gorm:"index"` // When a confirmed entry is due for review
+	ConfirmedBy    string
+	ConfirmedAt    *time.Time
+	LiftedAt       *time.Time
+	LiftReason     string
 }
 
-// WhitelistAddress represents a whitelisted address
+// BlacklistStatusChange is an audit record of a blacklist lifecycle transition
+type BlacklistStatusChange struct {
+	gorm.Model
+	Address             string `gorm:"index;not null"`
+	FromStatus          string // Empty when the entry was created
+	ToStatus            string `gorm:"not null"`
+	Actor               string // Officer, or the component that made the change
+	Reason              string
+	EnforcementActionID uint // Enforcement action that carried the change on-chain, if any
+}
+
+// WhitelistAddress exempts transfers of an address from rule checks. An entry may be scoped
+// to some rule types, to one direction and to one counterparty; it exempts nothing until an
+// officer other than the requester approves it, nor once it expires or is revoked.
 type WhitelistAddress struct {
-	ID        uint   `gorm:"primaryKey"`
-	Address   string `gorm:"uniqueIndex"`
-	Reason    string
-	CreatedAt time.Time
+	ID           uint   `gorm:"primaryKey"`
+	Address      string `gorm:"index"`
+	Reason       string
+	Rules        string     // Comma-separated rule types exempted; empty for every rule
+	Direction    string     `gorm:"default:'both'"` // Transfers "from" the address, "to" it, or "both"
+	Counterparty string     // Only transfers with this address are exempted, if set
+	Status       string     `gorm:"index;default:'active'"` // "pending", "active" or "revoked"
+	ExpiresAt    *time.Time `gorm:"index"`                  // Never, if nil
+	RequestedBy  string
+	ApprovedBy   string
+	ApprovedAt   *time.Time
+	RevokedBy    string
+	RevokedAt    *time.Time
+	CreatedAt    time.Time
+}
+
+// WhitelistExemption records a rule check a whitelist entry exempted a transaction from, and
+// whether the check would have flagged it
+type WhitelistExemption struct {
+	ID          uint   `gorm:"primaryKey"`
+	WhitelistID uint   `gorm:"index;uniqueIndex:idx_whitelist_exemptions_tx_check"`
+	Address     string `gorm:"index"` // Whitelisted address
+	TxHash      string `gorm:"not null;uniqueIndex:idx_whitelist_exemptions_tx_check"`
+	BlockNumber uint64
+	CheckName   string `gorm:"not null;uniqueIndex:idx_whitelist_exemptions_tx_check"`
+	Fired       bool
+	Details     string    `gorm:"type:jsonb"` // Violation details, when fired
+	CreatedAt   time.Time `gorm:"index"`
 }
 
 // SuspiciousAddress represents a suspicious address
@@ -0,0 +1,74 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Case groups the suspicious transfers, rule violations and addresses of one investigation.
+// Status is "open", "investigating", "escalated", "closed-false-positive", "closed-reported"
+// or "merged" once folded into another case.
+type Case struct {
+	gorm.Model
+	Title        string `gorm:"not null"`
+	Status       string `gorm:"index;not null;default:'open'"`
+	Severity     string `gorm:"index"` // Highest severity of the findings in the case
+	Assignee     string `gorm:"index"` // Officer investigating the case
+	Summary      string `gorm:"type:text"`
+	MergedIntoID *uint  `gorm:"index"` // Case this case was merged into
+	ClosedAt     *time.Time
+}
+
+// CaseAddress links an address to a case; cases sharing an address are merged
+type CaseAddress struct {
+	gorm.Model
+	CaseID  uint   `gorm:"uniqueIndex:idx_case_addresses_case_address;not null"`
+	Address string `gorm:"uniqueIndex:idx_case_addresses_case_address;index;not null"`
+}
+
+// CaseTransfer links a suspicious transfer to a case
+type CaseTransfer struct {
+	gorm.Model
+	CaseID               uint   `gorm:"uniqueIndex:idx_case_transfers_case_transfer;not null"`
+	SuspiciousTransferID uint   `gorm:"uniqueIndex:idx_case_transfers_case_transfer;not null"`
+	TxHash               string `gorm:"index"`
+}
+
+// CaseViolation links a rule violation to a case
+type CaseViolation struct {
+	gorm.Model
+	CaseID          uint `gorm:"uniqueIndex:idx_case_violations_case_violation;not null"`
+	RuleViolationID uint `gorm:"uniqueIndex:idx_case_violations_case_violation;not null"`
+}
+
+// CaseNote is an investigator's note on a case
+type CaseNote struct {
+	gorm.Model
+	CaseID uint   `gorm:"index;not null"`
+	Author string `gorm:"not null"`
+	Body   string `gorm:"type:text;not null"`
+}
+
+// CaseAttachment is the metadata of a file attached to a case; the file itself is stored elsewhere
+type CaseAttachment struct {
+	gorm.Model
+	CaseID      uint   `gorm:"index;not null"`
+	FileName    string `gorm:"not null"`
+	ContentType string
+	Size        int64
+	StorageURI  string // Where the file is stored
+	SHA256      string // Checksum of the file
+	UploadedBy  string
+}
+
+// CaseEvent is an entry of a case's audit history
+type CaseEvent struct {
+	gorm.Model
+	CaseID    uint   `gorm:"index;not null"`
+	Actor     string // Officer, or the component that made the change
+	Action    string `gorm:"not null"` // e.g. "created", "status_changed", "assigned", "merged", "transfer_added"
+	FromValue string
+	ToValue   string
+	Details   string
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CtrReport is the large-value transaction report of one reporting period. The report
+// itself is a file in the report directory; the record keeps its checksum.
+type CtrReport struct {
+	gorm.Model
+	Period        string    `gorm:"uniqueIndex:idx_ctr_reports_period;not null"` // "daily", "weekly" or "monthly"
+	PeriodStart   time.Time `gorm:"uniqueIndex:idx_ctr_reports_period;not null"`
+	PeriodEnd     time.Time `gorm:"not null"`
+	Threshold     string    `gorm:"not null"` // Reporting threshold in token base units
+	TransferCount int
+	EntityCount   int
+	TotalAmount   string
+	FileName      string `gorm:"not null"` // Name of the file in the report directory
+	Size          int64
+	SHA256        string
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// EnforcementAction is a queued on-chain enforcement (blacklisting or unblacklisting an address).
+// Actions that need approval start "awaiting_approval" and become "pending" once approved or
+// end as "rejected". Pending actions move to "submitted" once sent and end as "mined",
+// "failed" or "cancelled".
+type EnforcementAction struct {
+	gorm.Model
+	Address           string    `gorm:"index;not null"`                     // Address the action applies to
+	Action            string    `gorm:"index;not null;default:'blacklist'"` // "blacklist" or "unblacklist"
+	Direction         string    `gorm:"not null;default:'both'"`            // "both", "from" (sending) or "to" (receiving)
+	Status            string    `gorm:"index;default:'pending'"`            // "awaiting_approval", "pending", "submitted", "mined", "failed", "rejected" or "cancelled"
+	Source            string    // Component that requested the action
+	TriggerTxHash     string    `gorm:"index"` // Transaction that triggered the action
+	Reason            string    // Reason for the action
+	OnChainReason     string    // Reason written to the contract, e.g. "large_transfer#42"
+	Severity          string    // Severity of the behavior that triggered the action
+	Details           string    // Additional details about the action
+	HoldType          string    // Hold type of the resulting blacklist entry; empty for automated
+	LegalReference    string    // Order or case reference of a law-enforcement hold
+	RequestedBy       string    // Officer who requested the action, if any
+	RequiredApprovals int       // Distinct approvals needed before the action is sent
+	TxHash            string    `gorm:"index"` // Transaction that carries the action (shared by a batch)
+	Nonce             uint64    // Nonce of the carrying transaction
+	BlockNumber       uint64    // Block the carrying transaction was mined in
+	Attempts          int       // Number of times the action was sent
+	LastError         string    // Error of the last failed attempt
+	NextAttemptAt     time.Time `gorm:"index"` // Earliest time the action may be sent again
+	SubmittedAt       *time.Time
+	CompletedAt       *time.Time
+}
+
+// ActionApproval is an officer's decision on an enforcement action awaiting approval
+type ActionApproval struct {
+	gorm.Model
+	EnforcementActionID uint   `gorm:"index;not null"`
+	Approver            string `gorm:"not null"`
+	Decision            string `gorm:"not null"` // "approve" or "reject"
+	Comment             string
+}
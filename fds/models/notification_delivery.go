@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// NotificationDelivery is an alert queued for a notification channel and the log of its
+// delivery. Deliveries start "pending" and end "sent" or, once out of attempts, "failed".
+type NotificationDelivery struct {
+	gorm.Model
+	Channel         string    `gorm:"index;not null"` // Name of the configured channel
+	ChannelType     string    // "webhook", "email", "slack" or "telegram"
+	RuleName        string    `gorm:"index"`
+	RuleViolationID uint      `gorm:"index"`
+	Severity        string    `gorm:"index"`
+	TxHash          string    `gorm:"index"`
+	Payload         string    `gorm:"type:text"`               // The alert as JSON, rendered when sent
+	Status          string    `gorm:"index;default:'pending'"` // "pending", "sent" or "failed"
+	Attempts        int       // Number of times delivery was tried
+	LastError       string    `gorm:"type:text"`
+	ResponseStatus  int       // HTTP status of the last attempt of HTTP channels
+	NextAttemptAt   time.Time `gorm:"index"` // Earliest time delivery may be tried again
+	SentAt          *time.Time
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PreemptiveBlacklist tracks a blacklist transaction sent to front-run a suspicious pending transaction
+type PreemptiveBlacklist struct {
+	gorm.Model
+	Address           string `gorm:"index;not null"` // Address being blacklisted
+	Direction         string `gorm:"default:'both'"` // "both", "from" (sending) or "to" (receiving)
+	Reason            string // Reason written to the contract
+	TargetTxHash      string `gorm:"index;not null"` // Suspicious pending transaction to front-run
+	TargetGasTipCap   string // Priority fee of the target transaction
+	TargetGasFeeCap   string // Fee cap of the target transaction
+	TxHash            string `gorm:"index"` // Latest blacklist transaction hash
+	ReplacedTxHashes  string // Comma-separated hashes of blacklist transactions replaced by a speed-up
+	Nonce             uint64
+	GasTipCap         string // Priority fee of the latest blacklist transaction
+	GasFeeCap         string // Fee cap of the latest blacklist transaction
+	Replacements      int    // Number of speed-ups sent
+	LastSentAt        time.Time
+	Status            string `gorm:"index;default:'submitted'"` // "submitted", "mined" or "failed"
+	BlockNumber       uint64 // Block the blacklist transaction was mined in
+	TxIndex           uint   // Position of the blacklist transaction in its block
+	TargetStatus      string // "mined", "reverted" or "dropped"
+	TargetBlockNumber uint64 // Block the target transaction was mined in
+	TargetTxIndex     uint   // Position of the target transaction in its block
+	Outcome           string `gorm:"index"` // "preceded", "too_late", "target_dropped" or "failed"
+}
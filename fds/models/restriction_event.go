@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RestrictionEvent is a blacklist or unblacklist event emitted by the restriction contract
+type RestrictionEvent struct {
+	gorm.Model
+	Address     string    `gorm:"index;not null"` // Restricted or unrestricted address
+	Event       string    `gorm:"not null"`       // Event name, e.g. "AddressBlacklistedTo"
+	Direction   string    `gorm:"not null"`       // "both", "from" (sending) or "to" (receiving)
+	Restricted  bool      // True for blacklist events, false for unblacklist events
+	Reason      string    // Reason stored with the event
+	Actor       string    // Account that changed the restriction
+	Timestamp   time.Time // Block timestamp reported by the event
+	TxHash      string    `gorm:"uniqueIndex:idx_restriction_events_log;not null"`
+	LogIndex    uint      `gorm:"uniqueIndex:idx_restriction_events_log"`
+	BlockNumber uint64    `gorm:"index;not null"`
+}
+
+// BlacklistDrift is a discrepancy between the blacklisted_addresses table and the
+// restriction state of the contract
+type BlacklistDrift struct {
+	gorm.Model
+	Address        string     `gorm:"index;not null"`
+	Kind           string     `gorm:"index;not null"` // "missing_in_db", "missing_on_chain" or "direction_mismatch"
+	DBDirection    string     // Direction recorded in the database, empty if there is no row
+	ChainDirection string     // Direction restricted on-chain, empty if not restricted
+	Repaired       bool       // Whether the database was repaired to match the chain
+	RepairAction   string     // What the repair did
+	LastSeenAt     time.Time  // Last reconciliation run that detected the drift
+	ResolvedAt     *time.Time `gorm:"index"` // Set once the drift no longer exists
+}
@@ -9,13 +9,17 @@ import (
 type Rule struct {
 	gorm.Model
 	Name        string `gorm:"uniqueIndex;not null"` // Unique name/identifier for the rule
+	Type        string `gorm:"index"`               // Check the rule configures, e.g. "large_transfer"; defaults to its name
 	Description string `gorm:"type:text;not null"`   // Detailed description of the rule
-	Status      string `gorm:"not null"`             // Status: "active" or "inactive"
+	Status      string `gorm:"not null"`             // Status: "active", "inactive" or "shadow" (observe only)
 	Severity    string `gorm:"not null"`             // Severity level: "low", "medium", "high"
 	Parameters  string `gorm:"type:json"`           // JSON string containing rule-specific parameters
 	Actions     string `gorm:"type:json"`           // JSON string containing actions to take when rule is violated
 	Violations  uint64 `gorm:"default:0"`           // Number of times the rule has been violated
 	LastViolationAt time.Time // Timestamp of the last violation
+	ShadowOf    string `gorm:"index"`               // Active rule whose check a shadow rule runs with its own parameters
+	Version     int    `gorm:"default:0"`           // Number of the rule's current version
+	VersionID   uint                                // Current RuleVersion
 }
 
 // RuleHit represents a record of when a rule was triggered
@@ -29,4 +33,7 @@ type RuleViolation struct {
 	BlockNumber uint64 `gorm:"index;not null"`      // Block number when rule was violated
 	Details     string `gorm:"type:json"`           // JSON string containing details about the violation
 	ActionTaken string `gorm:"type:json"`           // JSON string containing actions taken in response
+	IncidentID  uint   `gorm:"index"`               // Incident the violation was grouped into
+	Suppressed  bool   `gorm:"default:false"`       // Repeat or snoozed alert; recorded without alerting or enforcing
+	RuleVersionID uint `gorm:"index"`               // Version of the rule that produced the violation
 } 
\ No newline at end of file
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RuleEvaluation is a dry run of the rules on a hypothetical transfer, requested through
+// fds-api and run by the monitor. Evaluations start "pending" and end "done" with a result
+// or "failed" with an error.
+type RuleEvaluation struct {
+	gorm.Model
+	Status      string `gorm:"index;default:'pending'"`
+	RequestedBy string
+	Request     string `gorm:"type:text"` // services.EvaluationRequest as JSON
+	Result      string `gorm:"type:text"` // services.Evaluation as JSON, once done
+	Error       string `gorm:"type:text"`
+	FinishedAt  *time.Time
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+)
+
+// RuleVersion is an immutable snapshot of a rule, written by fds-api on every change of the
+// rule. Violations reference the version that produced them; rolling a rule back copies an
+// earlier version into a new one.
+type RuleVersion struct {
+	ID          uint   `gorm:"primaryKey"`
+	RuleID      uint   `gorm:"not null;uniqueIndex:idx_rule_versions_rule_version"`
+	Version     int    `gorm:"not null;uniqueIndex:idx_rule_versions_rule_version"`
+	Name        string `gorm:"not null"`
+	Type        string `gorm:"not null"`
+	Description string `gorm:"type:text"`
+	Status      string `gorm:"not null"`
+	Severity    string `gorm:"not null"`
+	Parameters  string `gorm:"type:jsonb"`
+	Actions     string `gorm:"type:jsonb"`
+	ShadowOf    string
+	Change      string `gorm:"not null"` // create, update, clone, rollback, promote or delete
+	Comment     string `gorm:"type:text"`
+	ChangedBy   string
+	CreatedAt   time.Time
+}
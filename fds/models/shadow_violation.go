@@ -0,0 +1,20 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+// ShadowViolation is a violation a shadow rule would have recorded. Shadow rules observe live
+// traffic only: their hits are kept here, apart from rule violations, and never reported,
+// alerted or enforced.
+type ShadowViolation struct {
+	gorm.Model
+	RuleID        uint `gorm:"not null;uniqueIndex:idx_shadow_rule_tx"` // The shadow rule
+	RuleVersionID uint // Version of the shadow rule that produced the hit
+	RuleName      string
+	CheckName     string `gorm:"index"` // Rule whose check the shadow rule runs
+	TxHash        string `gorm:"not null;uniqueIndex:idx_shadow_rule_tx;index"`
+	BlockNumber   uint64
+	Address       string // Address the alert would have been grouped by
+	Details       string `gorm:"type:jsonb"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+// StrReport is a Suspicious Transaction Report filed with the State Bank, kept as the
+// exact document that was generated
+type StrReport struct {
+	gorm.Model
+	ReportNumber        string `gorm:"index"`
+	CaseID              *uint  `gorm:"index"`     // Case the report was generated from, if any
+	TransferIDs         string `gorm:"type:text"` // JSON list of the suspicious transfers reported
+	Format              string `gorm:"not null"`  // "xml", "json", "html" or "pdf"
+	SchemaVersion       string // Version of the report schema used for xml and json
+	FileName            string `gorm:"not null"`
+	ContentType         string
+	Content             []byte `gorm:"type:bytea"`
+	SHA256              string // Checksum of Content
+	FiledBy             string `gorm:"index;not null"` // Compliance officer who filed the report
+	SubmissionReference string // Reference the State Bank returned for the submission
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+)
+
+// StreamEvent is an alert published to fds-api stream subscribers. Database triggers write
+// one for each new suspicious transfer, rule violation and blacklist change and announce it
+// on the fds_stream notification channel; its ID is the event ID clients resume from.
+type StreamEvent struct {
+	ID          uint64    `gorm:"primarykey"`
+	CreatedAt   time.Time `gorm:"index"`
+	Type        string    `gorm:"not null"` // "suspicious_transfer", "rule_violation" or "blacklist"
+	Severity    string
+	Rule        string // Name of the violated rule, for rule violations
+	FromAddress string `gorm:"index"`
+	ToAddress   string `gorm:"index"`
+	Payload     string `gorm:"type:jsonb;not null;default:'{}'"` // The row that triggered the event
+}
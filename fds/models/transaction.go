@@ -18,19 +18,27 @@ type Transaction struct {
 	IsAnalyzed  bool
 	IsPending   bool   `gorm:"default:false"`
 	Status      string `gorm:"default:'confirmed'"`
+	TxType      string // Transaction type of a TypedTransfer; empty for plain transfers
 }
 
 // PendingTransaction represents a transaction in the mempool
 type PendingTransaction struct {
 	gorm.Model
-	Hash        string `gorm:"uniqueIndex"`
-	From        string `gorm:"column:from_address;index:idx_pending_from_block"`
-	To          string `gorm:"column:to_address;index:idx_pending_to_block"`
-	Value       string
-	BlockNumber uint64 `gorm:"type:bigint;index:idx_pending_from_block,idx_pending_to_block"`
-	Timestamp   time.Time
-	IsAnalyzed  bool
-	Status      string `gorm:"default:'pending'"`
+	Hash             string `gorm:"uniqueIndex"`
+	From             string `gorm:"column:from_address;index:idx_pending_from_block"`
+	To               string `gorm:"column:to_address;index:idx_pending_to_block"`
+	Value            string
+	BlockNumber      uint64 `gorm:"type:bigint;index:idx_pending_from_block,idx_pending_to_block"`
+	Timestamp        time.Time
+	IsAnalyzed       bool
+	Status           string `gorm:"default:'pending'"`
+	Effects          string `gorm:"type:json"` // JSON list of decoded events from the simulation
+	RevertReason     string // Decoded revert reason when the simulation reverted
+	RevertError      string // Name of the error the simulation reverted with
+	RevertData       string // Raw revert data as hex
+	ComplianceModule string `gorm:"index"` // Compliance module that blocked the transfer
+	GasTipCap        string // Priority fee offered by the transaction (gas price for legacy transactions)
+	GasFeeCap        string // Fee cap offered by the transaction (gas price for legacy transactions)
 }
 
 type TokenTransfer struct {
@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// User is a local user of fds-api. Users signed in by the OIDC provider are not stored.
+type User struct {
+	gorm.Model
+	Username     string `gorm:"uniqueIndex;not null"`
+	PasswordHash string `gorm:"not null"`                  // bcrypt hash
+	Role         string `gorm:"not null;default:'viewer'"` // "viewer", "analyst", "compliance_officer" or "admin"
+	Disabled     bool   `gorm:"default:false"`
+	LastLoginAt  *time.Time
+}
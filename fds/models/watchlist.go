@@ -0,0 +1,79 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// WatchlistSource is a sanctions or watchlist list the monitor imports on a schedule, from
+// an http(s):// or file:// URL, in "csv", "json" or "ofac_xml" format
+type WatchlistSource struct {
+	gorm.Model
+	Name          string     `gorm:"uniqueIndex;not null"`
+	URL           string     `gorm:"not null"`
+	Format        string     `gorm:"not null"`
+	Enabled       bool       `gorm:"default:true"`
+	SyncInterval  int        `gorm:"default:1440"` // Minutes between syncs
+	NextSyncAt    *time.Time `gorm:"index"`        // Due at once if nil
+	LastSyncAt    *time.Time
+	LastVersionID uint
+	LastChecksum  string // SHA-256 of the list last imported
+	LastError     string `gorm:"type:text"`
+	CreatedBy     string
+}
+
+// WatchlistVersion is a distinct content of a source's list, with how it differs from the
+// previous one
+type WatchlistVersion struct {
+	ID        uint   `gorm:"primaryKey"`
+	SourceID  uint   `gorm:"index;not null"`
+	Version   string // Publication date or version of the list, or a prefix of its checksum
+	Checksum  string
+	Entries   int // Addresses listed
+	Added     int
+	Removed   int
+	Skipped   int // Entries without an Ethereum address
+	CreatedAt time.Time
+}
+
+// WatchlistEntry is an address a source lists. An address dropped from the list is kept as
+// "removed" and becomes "active" again if listed anew.
+type WatchlistEntry struct {
+	gorm.Model
+	SourceID         uint       `gorm:"not null;uniqueIndex:idx_watchlist_entries_source_address"`
+	Address          string     `gorm:"not null;uniqueIndex:idx_watchlist_entries_source_address;index"`
+	Name             string     // Listed person or entity
+	Reference        string     // Identifier of the entry in the list, such as the OFAC uid
+	Status           string     `gorm:"index;default:'active'"` // "active" or "removed"
+	AddedVersionID   uint       // Version that last listed the address
+	RemovedVersionID uint       // Version that dropped it, once removed
+	ScreenedAt       *time.Time `gorm:"index"` // When its past transactions were screened; nil until done
+}
+
+// WatchlistChange is an address a version of a source's list added or removed
+type WatchlistChange struct {
+	ID        uint   `gorm:"primaryKey"`
+	SourceID  uint   `gorm:"index;not null"`
+	VersionID uint   `gorm:"index;not null"`
+	Address   string `gorm:"index;not null"`
+	Change    string `gorm:"not null"` // "added" or "removed"
+	Name      string
+	CreatedAt time.Time `gorm:"index"`
+}
+
+// WatchlistMatch is a past transaction of a newly listed address, found by retroactive
+// screening
+type WatchlistMatch struct {
+	ID          uint   `gorm:"primaryKey"`
+	EntryID     uint   `gorm:"not null;uniqueIndex:idx_watchlist_matches_entry_tx"`
+	SourceID    uint   `gorm:"index"`
+	VersionID   uint   // Version that listed the address
+	Address     string `gorm:"index"`
+	TxHash      string `gorm:"not null;uniqueIndex:idx_watchlist_matches_entry_tx"`
+	BlockNumber uint64
+	Direction   string // Side of the transaction the address is on: "from" or "to"
+	Amount      string
+	TxTimestamp time.Time
+	CreatedAt   time.Time `gorm:"index"`
+}
@@ -12,15 +12,14 @@ import (
 	"sync"
 	"time"
 
-	"token-monitor/contracts/restrict"
 	"token-monitor/models"
 
 	"github.com/ethereum/go-ethereum"
-	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // Analyzer provides comprehensive transaction and transfer analysis
@@ -37,6 +36,16 @@ type Analyzer struct {
 	maxBlocks       int
 	interval        time.Duration
 	rules           map[string]*models.Rule
+	rulesMu         sync.RWMutex            // Guards rules and shadows, reloaded while analysis runs
+	rulesVersion    uint                    // Latest RuleVersion when the rules were loaded
+	rulesBlock      uint64                  // Block rule changes were last looked for at
+	preemption      *PreemptionTracker      // Front-runs suspicious pending transactions when set
+	notifier        *Notifier               // Sends violations to notification channels when set
+	suppression     time.Duration           // Default window grouping repeats of an alert into one incident
+	replay          bool                    // Backtest: checks only see history up to the transaction checked
+	quiet           bool                    // Detections are not logged (backtests and shadow rules)
+	shadows         []*models.Rule          // Shadow rules, recording would-be violations only
+	outcomes        map[string]*RuleOutcome // Dry runs: why each check fired or not, by check
 }
 
 // NewAnalyzer creates a new analyzer instance
@@ -65,20 +74,102 @@ func NewAnalyzer(db *gorm.DB, largeAmount float64, shortTimeBlocks int64, suspic
 	return analyzer
 }
 
-// loadRules loads all active rules from the database
+// SetPreemptionTracker enables pre-emptive blacklisting of suspicious pending transactions
+func (a *Analyzer) SetPreemptionTracker(tracker *PreemptionTracker) {
+	a.preemption = tracker
+}
+
+// SetNotifier enables alerts of rule violations on the notification channels
+func (a *Analyzer) SetNotifier(notifier *Notifier) {
+	a.notifier = notifier
+}
+
+// SetSuppressionWindow sets how long repeats of a rule's alert on one address are grouped
+// and suppressed, for rules whose actions do not set suppression_window_minutes
+func (a *Analyzer) SetSuppressionWindow(window time.Duration) {
+	a.suppression = window
+}
+
+// loadRules loads all active and shadow rules from the database, replacing those loaded
+// before. The rules are kept if they cannot be read.
 func (a *Analyzer) loadRules() {
+	// Read first, so a change made while loading is picked up by the next reload
+	version, err := a.latestRuleVersion()
+	if err != nil {
+		log.Printf("Error loading rules: %v", err)
+		return
+	}
 	var rules []models.Rule
-	if err := a.db.Where("status = ?", "active").Find(&rules).Error; err != nil {
+	if err := a.db.Where("status = ?", "active").Order("id").Find(&rules).Error; err != nil {
 		log.Printf("Error loading rules: %v", err)
 		return
 	}
+	var shadowRules []models.Rule
+	if err := a.db.Where("status = ?", "shadow").Find(&shadowRules).Error; err != nil {
+		log.Printf("Error loading shadow rules: %v", err)
+		return
+	}
 
+	loaded := make(map[string]*models.Rule)
 	for _, rule := range rules {
 		// Create a copy of the rule to avoid pointer issues
 		ruleCopy := rule
-		a.rules[rule.Name] = &ruleCopy
-		log.Printf("Loaded rule: %s with parameters: %s", rule.Name, rule.Parameters)
+		check := ruleType(&ruleCopy)
+		if first, ok := loaded[check]; ok {
+			log.Printf("Skipping rule %s: %s already configures the %s check", rule.Name, first.Name, check)
+			continue
+		}
+		loaded[check] = &ruleCopy
+		log.Printf("Loaded rule: %s (%s, version %d) with parameters: %s", rule.Name, check, rule.Version, rule.Parameters)
+	}
+	var shadows []*models.Rule
+	for i := range shadowRules {
+		shadows = append(shadows, &shadowRules[i])
+		log.Printf("Loaded shadow rule: %s of %s with parameters: %s", shadowRules[i].Name, shadowCheck(&shadowRules[i]), shadowRules[i].Parameters)
+	}
+
+	a.rulesMu.Lock()
+	a.rules, a.shadows, a.rulesVersion = loaded, shadows, version
+	a.rulesMu.Unlock()
+}
+
+// latestRuleVersion returns the ID of the latest rule version. Every change to a rule
+// through the API records a version, so a new one means the rules changed.
+func (a *Analyzer) latestRuleVersion() (uint, error) {
+	var latest uint
+	err := a.db.Model(&models.RuleVersion{}).Select("COALESCE(MAX(id), 0)").Scan(&latest).Error
+	return latest, err
+}
+
+// reloadRules loads the rules again if they changed, looking once per block
+func (a *Analyzer) reloadRules(block uint64) {
+	a.rulesMu.Lock()
+	if block == a.rulesBlock {
+		a.rulesMu.Unlock()
+		return
+	}
+	a.rulesBlock = block
+	loaded := a.rulesVersion
+	a.rulesMu.Unlock()
+
+	latest, err := a.latestRuleVersion()
+	if err != nil {
+		log.Printf("Error checking for rule changes: %v", err)
+		return
+	}
+	if latest != loaded {
+		log.Printf("Rules changed (version %d), reloading", latest)
+		a.loadRules()
+	}
+}
+
+// ruleType returns the check a rule configures; rules from before rule types run the check
+// named like them
+func ruleType(rule *models.Rule) string {
+	if rule.Type != "" {
+		return rule.Type
 	}
+	return rule.Name
 }
 
 // getRuleParameter gets a parameter value from a rule's parameters JSON
@@ -98,6 +189,12 @@ func (a *Analyzer) getRuleParameter(ruleName, paramName string) (string, error)
 		return "", fmt.Errorf("parameter %s not found in rule %s", paramName, ruleName)
 	}
 
+	if outcome := a.outcome(ruleName); outcome != nil {
+		if outcome.Parameters == nil {
+			outcome.Parameters = make(map[string]interface{})
+		}
+		outcome.Parameters[paramName] = value
+	}
 	return fmt.Sprintf("%v", value), nil
 }
 
@@ -137,6 +234,9 @@ func (a *Analyzer) Start(ctx context.Context) {
 			select {
 			case <-ticker.C:
 				a.processUnanalyzedTransactions()
+				if _, err := closeExpiredIncidents(a.db, time.Now()); err != nil {
+					log.Printf("Error closing expired alert incidents: %v", err)
+				}
 			case <-ctx.Done():
 				return
 			case <-a.stopChan:
@@ -198,16 +298,75 @@ func (a *Analyzer) QueueTransaction(tx *models.Transaction) {
 	}
 }
 
+// ruleDetection is a rule a transaction violates, with the behaviors it is reported as
+type ruleDetection struct {
+	Rule      string
+	Details   map[string]interface{} // Recorded with the violation
+	Behaviors []map[string]interface{}
+}
+
 // AnalyzeTransaction analyzes a single transaction for suspicious behaviors
 func (a *Analyzer) AnalyzeTransaction(ctx context.Context, tx *models.Transaction) ([]map[string]interface{}, error) {
-	// Check whitelist: skip rule checks if from or to is whitelisted
-	var whitelisted []models.WhitelistAddress
-	if err := a.db.Model(&models.WhitelistAddress{}).Where("address IN (?, ?)", tx.From, tx.To).Find(&whitelisted).Error; err == nil && len(whitelisted) > 0 {
-		log.Printf("Skipping analysis for whitelisted addresses in transaction %s", tx.Hash)
-		return nil, nil
+	a.reloadRules(tx.BlockNumber)
+	a.rulesMu.RLock()
+	defer a.rulesMu.RUnlock()
+
+	// Checks the whitelist exempts run all the same, to log what they would have flagged
+	exemptions := a.whitelistExemptions(tx)
+	a.evaluateShadows(tx, exemptions)
+
+	detections := a.detect(tx)
+	if len(exemptions) > 0 {
+		log.Printf("Whitelist exempts transaction %s from %d checks", tx.Hash, len(exemptions))
+		a.logExemptions(tx, exemptions, detections)
 	}
 
 	var behaviors []map[string]interface{}
+	for _, d := range detections {
+		if len(exemptions[d.Rule]) > 0 {
+			continue
+		}
+		// Suppressed repeats are recorded but not reported
+		if !a.recordRuleViolation(d.Rule, tx, d.Details) {
+			behaviors = append(behaviors, d.Behaviors...)
+		}
+	}
+
+	// Update recent transfers and balances
+	if !isBlockedAttempt(tx) {
+		a.updateState(tx)
+	}
+
+	if len(behaviors) > 0 {
+		log.Printf("Found %d suspicious behaviors in transaction %s", len(behaviors), tx.Hash)
+	}
+
+	return behaviors, nil
+}
+
+// logf logs a detection, unless the analyzer is quiet
+func (a *Analyzer) logf(format string, args ...interface{}) {
+	if !a.quiet {
+		log.Printf(format, args...)
+	}
+}
+
+// isBlockedAttempt reports whether tx is a pending transaction that reverted, blocked by a
+// compliance module
+func isBlockedAttempt(tx *models.Transaction) bool {
+	return tx.IsPending && tx.Status == "revert"
+}
+
+// detect runs the rule checks on a transaction and returns the rules it violates. It only
+// reads; recording the violations is up to the caller.
+func (a *Analyzer) detect(tx *models.Transaction) []ruleDetection {
+	// Pending transactions that reverted were blocked by a compliance module;
+	// only the repeated blocked attempts rule applies to them
+	if isBlockedAttempt(tx) {
+		return a.detectBlockedAttempt(tx)
+	}
+
+	var detections []ruleDetection
 
 	// Convert transaction value to big.Float
 	value := new(big.Float)
@@ -217,24 +376,28 @@ func (a *Analyzer) AnalyzeTransaction(ctx context.Context, tx *models.Transactio
 	if threshold, err := a.getRuleParameter("large_transfer", "threshold"); err == nil {
 		thresholdFloat := new(big.Float)
 		thresholdFloat.SetString(threshold)
+		evidence := map[string]interface{}{"amount": tx.Value, "threshold": threshold}
 		if value.Cmp(thresholdFloat) > 0 {
-			log.Printf("Large transfer detected in transaction %s: %s > %s", tx.Hash, value.String(), threshold)
+			a.logf("Large transfer detected in transaction %s: %s > %s", tx.Hash, value.String(), threshold)
+			a.explain("large_transfer", true, evidence, "amount %s exceeds the threshold %s", tx.Value, threshold)
 			details := map[string]interface{}{
 				"from":      tx.From,
 				"to":        tx.To,
 				"amount":    tx.Value,
 				"threshold": threshold,
 			}
-			behaviors = append(behaviors, map[string]interface{}{
+			detections = append(detections, ruleDetection{Rule: "large_transfer", Details: details, Behaviors: []map[string]interface{}{{
 				"type":        "large_transfer",
 				"description": "Large amount transfer detected",
 				"severity":    "high",
 				"details":     details,
-			})
-			a.recordRuleViolation("large_transfer", tx, details)
+			}}})
+		} else {
+			a.explain("large_transfer", false, evidence, "amount %s does not exceed the threshold %s", tx.Value, threshold)
 		}
 	} else {
-		log.Printf("Error getting large_transfer threshold: %v", err)
+		a.logf("Error getting large_transfer threshold: %v", err)
+		a.explain("large_transfer", false, nil, "%v", err)
 	}
 
 	// 2. Check for multiple transfers in short time
@@ -243,21 +406,22 @@ func (a *Analyzer) AnalyzeTransaction(ctx context.Context, tx *models.Transactio
 			minTransfersInt, _ := strconv.Atoi(minTransfers)
 			blockRangeInt, _ := strconv.Atoi(blockRange)
 			if multipleBehaviors := a.checkMultipleTransfers(tx, minTransfersInt, blockRangeInt); len(multipleBehaviors) > 0 {
-				log.Printf("Multiple transfers detected in transaction %s", tx.Hash)
+				a.logf("Multiple transfers detected in transaction %s", tx.Hash)
 				details := map[string]interface{}{
 					"from":          tx.From,
 					"count":         len(multipleBehaviors),
 					"min_transfers": minTransfers,
 					"block_range":   blockRange,
 				}
-				behaviors = append(behaviors, multipleBehaviors...)
-				a.recordRuleViolation("multiple_transfers", tx, details)
+				detections = append(detections, ruleDetection{Rule: "multiple_transfers", Details: details, Behaviors: multipleBehaviors})
 			}
 		} else {
-			log.Printf("Error getting multiple_transfers block_range: %v", err)
+			a.logf("Error getting multiple_transfers block_range: %v", err)
+			a.explain("multiple_transfers", false, nil, "%v", err)
 		}
 	} else {
-		log.Printf("Error getting multiple_transfers min_transfers: %v", err)
+		a.logf("Error getting multiple_transfers min_transfers: %v", err)
+		a.explain("multiple_transfers", false, nil, "%v", err)
 	}
 
 	// 3. Check for multiple incoming transfers in short time
@@ -267,51 +431,82 @@ func (a *Analyzer) AnalyzeTransaction(ctx context.Context, tx *models.Transactio
 			thresholdFloat.SetString(threshold)
 			blockRangeInt, _ := strconv.Atoi(blockRange)
 			if incomingBehaviors := a.checkMultipleIncomingTransfers(tx, thresholdFloat, blockRangeInt); len(incomingBehaviors) > 0 {
-				log.Printf("Multiple incoming transfers detected in transaction %s -- number tx %d", tx.Hash, blockRangeInt)
+				a.logf("Multiple incoming transfers detected in transaction %s -- number tx %d", tx.Hash, blockRangeInt)
 				details := map[string]interface{}{
 					"to":          tx.To,
 					"count":       len(incomingBehaviors),
 					"threshold":   threshold,
 					"block_range": blockRange,
 				}
-				behaviors = append(behaviors, incomingBehaviors...)
-				a.recordRuleViolation("multiple_incoming_transfers", tx, details)
+				detections = append(detections, ruleDetection{Rule: "multiple_incoming_transfers", Details: details, Behaviors: incomingBehaviors})
 			}
 		} else {
-			log.Printf("Error getting multiple_incoming_transfers block_range: %v", err)
+			a.logf("Error getting multiple_incoming_transfers block_range: %v", err)
+			a.explain("multiple_incoming_transfers", false, nil, "%v", err)
 		}
 	} else {
-		log.Printf("Error getting multiple_incoming_transfers threshold: %v", err)
+		a.logf("Error getting multiple_incoming_transfers threshold: %v", err)
+		a.explain("multiple_incoming_transfers", false, nil, "%v", err)
 	}
 
 	// 4. Check for transfers to/from suspicious addresses
+	listed := false
 	var suspiciousAddrs []models.SuspiciousAddress
 	if err := a.db.Model(&models.SuspiciousAddress{}).Find(&suspiciousAddrs).Error; err == nil {
+		a.explain("suspicious_address", false, map[string]interface{}{"suspicious_addresses": len(suspiciousAddrs)},
+			"neither %s nor %s is among the %d suspicious addresses or on a watchlist", tx.From, tx.To, len(suspiciousAddrs))
 		for _, addr := range suspiciousAddrs {
 			if tx.From == addr.Address || tx.To == addr.Address {
-				log.Printf("Suspicious address detected in transaction %s: %s", tx.Hash, addr.Address)
+				listed = true
+				a.logf("Suspicious address detected in transaction %s: %s", tx.Hash, addr.Address)
+				a.explain("suspicious_address", true, map[string]interface{}{"address": addr.Address, "reason": addr.Reason},
+					"%s is a suspicious address", addr.Address)
 				details := map[string]interface{}{
 					"from": tx.From,
 					"to":   tx.To,
 				}
-				behaviors = append(behaviors, map[string]interface{}{
+				detections = append(detections, ruleDetection{Rule: "suspicious_address", Details: details, Behaviors: []map[string]interface{}{{
 					"type":        "suspicious_address",
 					"description": "Transaction involves suspicious address",
 					"severity":    "high",
 					"details":     details,
-				})
-				a.recordRuleViolation("suspicious_address", tx, details)
+				}}})
 				break
 			}
 		}
 	} else {
-		log.Printf("Error checking suspicious addresses: %v", err)
+		a.logf("Error checking suspicious addresses: %v", err)
+		a.explain("suspicious_address", false, nil, "error loading suspicious addresses: %v", err)
+	}
+
+	// Addresses on an imported watchlist are suspicious too
+	if !listed {
+		if hit, err := a.watchlisted(tx); err != nil {
+			a.logf("Error checking watchlists: %v", err)
+		} else if hit != nil {
+			a.logf("Watchlisted address detected in transaction %s: %s (%s)", tx.Hash, hit.Address, hit.Source)
+			a.explain("suspicious_address", true, map[string]interface{}{"address": hit.Address, "watchlist": hit.Source, "version": hit.Version, "name": hit.Name},
+				"%s is on the %s watchlist (version %s)", hit.Address, hit.Source, hit.Version)
+			details := map[string]interface{}{
+				"from":      tx.From,
+				"to":        tx.To,
+				"watchlist": hit.Source,
+				"version":   hit.Version,
+				"listed":    hit.Address,
+			}
+			detections = append(detections, ruleDetection{Rule: "suspicious_address", Details: details, Behaviors: []map[string]interface{}{{
+				"type":        "suspicious_address",
+				"description": "Transaction involves watchlisted address",
+				"severity":    "high",
+				"details":     details,
+			}}})
+		}
 	}
 
 	// 5. Check for insufficient balance transfers
 	/* 	if checkBlocks, err := a.getRuleParameter("insufficient_balance", "check_blocks"); err == nil {
 	   		if insufficientBehaviors := a.checkInsufficientBalance(tx, value, checkBlocks); len(insufficientBehaviors) > 0 {
-	   			log.Printf("Insufficient balance detected in transaction %s", tx.Hash)
+	   			a.logf("Insufficient balance detected in transaction %s", tx.Hash)
 	   			details := map[string]interface{}{
 	   				"from":         tx.From,
 	   				"to":           tx.To,
@@ -322,17 +517,36 @@ func (a *Analyzer) AnalyzeTransaction(ctx context.Context, tx *models.Transactio
 	   			a.recordRuleViolation("insufficient_balance", tx, details)
 	   		}
 	   	} else {
-	   		log.Printf("Error getting insufficient_balance check_blocks: %v", err)
+	   		a.logf("Error getting insufficient_balance check_blocks: %v", err)
 	   	} */
 
-	// Update recent transfers and balances
-	a.updateState(tx)
+	return detections
+}
 
-	if len(behaviors) > 0 {
-		log.Printf("Found %d suspicious behaviors in transaction %s", len(behaviors), tx.Hash)
+// detectBlockedAttempt checks whether the sender of a compliance-blocked transaction
+// keeps retrying blocked transfers
+func (a *Analyzer) detectBlockedAttempt(tx *models.Transaction) []ruleDetection {
+	minAttempts, err := a.getRuleParameter("repeated_blocked_attempts", "min_attempts")
+	if err != nil {
+		a.logf("Error getting repeated_blocked_attempts min_attempts: %v", err)
+		a.explain("repeated_blocked_attempts", false, nil, "%v", err)
+		return nil
+	}
+	blockRange, err := a.getRuleParameter("repeated_blocked_attempts", "block_range")
+	if err != nil {
+		a.logf("Error getting repeated_blocked_attempts block_range: %v", err)
+		a.explain("repeated_blocked_attempts", false, nil, "%v", err)
+		return nil
 	}
+	minAttemptsInt, _ := strconv.Atoi(minAttempts)
+	blockRangeInt, _ := strconv.Atoi(blockRange)
 
-	return behaviors, nil
+	behaviors := a.checkRepeatedBlockedAttempts(tx, minAttemptsInt, blockRangeInt)
+	if len(behaviors) == 0 {
+		return nil
+	}
+	a.logf("Repeated blocked attempts detected in transaction %s", tx.Hash)
+	return []ruleDetection{{Rule: "repeated_blocked_attempts", Details: behaviors[0]["details"].(map[string]interface{}), Behaviors: behaviors}}
 }
 
 // handleSuspiciousBehaviors processes suspicious behaviors and triggers appropriate actions
@@ -350,68 +564,23 @@ func (a *Analyzer) handleSuspiciousBehaviors(tx *models.Transaction, behaviors [
 		}
 	}
 
-	// If high severity, perform blacklist operation immediately
-	isBlacklisted := false
+	// If high severity, front-run a pending transaction; otherwise the blacklist is queued below
+	// The violated rules decide the blacklist direction, the on-chain reason and whether
+	// enforcement is automatic. Pre-emption leaves no time for approval, so it is auto only.
+	preempted := false
+	direction, onChainReason, policy := DirectionBoth, "", PolicyAuto
 	if highestSeverity == "high" {
-		// Connect to mainnet for blacklist operations
-		mainnetClient, err := ethclient.Dial(os.Getenv("MAINNET_RPC_URL"))
-		if err != nil {
-			log.Printf("Failed to connect to mainnet for blacklist operation: %v", err)
+		direction, onChainReason, policy = violationEnforcement(a.db, tx.Hash)
+		if policy != PolicyAuto {
+			log.Printf("Enforcement for %s is %s by rule policy", tx.To, policy)
+		}
+	}
+	if highestSeverity == "high" && policy == PolicyAuto && tx.IsPending && a.preemption != nil {
+		// The tracker records the outcome once mined
+		if _, err := a.preemption.Submit(context.Background(), tx.Hash, tx.To, direction, onChainReason); err != nil {
+			log.Printf("Failed to send pre-emptive blacklist for %s: %v", tx.To, err)
 		} else {
-			defer mainnetClient.Close()
-
-			// Get the restrict contract address from environment
-			restrictAddr := common.HexToAddress(os.Getenv("RESTRICT_CONTRACT_ADDRESS"))
-			restrictContract, err := restrict.NewRestrict(restrictAddr, mainnetClient)
-			if err != nil {
-				log.Printf("Failed to create restrict contract instance: %v", err)
-			} else {
-				// Get auth for transaction
-				auth, err := a.getAuth(mainnetClient)
-				if err != nil {
-					log.Printf("Failed to get auth for blacklist operation: %v", err)
-				} else {
-					// Prepare addresses to blacklist
-					addressesToBlacklist := []common.Address{
-						common.HexToAddress(tx.To),
-					}
-
-					// Call blacklist on the restrict contract
-					blacklistTx, err := restrictContract.Blacklist(auth, addressesToBlacklist)
-					if err != nil {
-						log.Printf("Failed to add address to blacklist: %v", err)
-					} else {
-						log.Printf("Blacklist transaction sent: %s", blacklistTx.Hash().Hex())
-
-						// Wait for transaction to be mined
-						receipt, err := bind.WaitMined(context.Background(), mainnetClient, blacklistTx)
-						if err != nil {
-							log.Printf("Error waiting for blacklist transaction: %v", err)
-						} else if receipt.Status == 1 {
-							log.Printf("Successfully blacklisted address %s", tx.To)
-							isBlacklisted = true
-
-							// Store in blacklisted table
-							blacklistedAddr := &models.BlacklistedAddress{
-								Address:     tx.To,
-								TxHash:      blacklistTx.Hash().Hex(),
-								BlockNumber: receipt.BlockNumber.Uint64(),
-								Reason:      "Multiple suspicious transfers",
-								Severity:    "high",
-								Details:     "Automatically blacklisted due to suspicious behavior",
-							}
-
-							if err := a.db.Create(blacklistedAddr).Error; err != nil {
-								log.Printf("Error storing blacklisted address %s: %v", tx.To, err)
-							} else {
-								log.Printf("Added address %s to blacklist table", tx.To)
-							}
-						} else {
-							log.Printf("Failed to blacklist address %s", tx.To)
-						}
-					}
-				}
-			}
+			preempted = true
 		}
 	}
 
@@ -524,25 +693,49 @@ func (a *Analyzer) handleSuspiciousBehaviors(tx *models.Transaction, behaviors [
 	err = a.db.Transaction(func(db *gorm.DB) error {
 		// Create a single suspicious transfer record for all behaviors
 		suspiciousTransfer := &models.SuspiciousTransfer{
-			From:          tx.From,
-			To:            tx.To,
-			Amount:        tx.Value,
-			TxHash:        tx.Hash,
-			BlockNumber:   tx.BlockNumber,
-			Timestamp:     time.Now(),
-			Reason:        reason,
-			Severity:      highestSeverity,
-			Details:       string(detailsJSON),
-			IsBlacklisted: isBlacklisted,
+			From:        tx.From,
+			To:          tx.To,
+			Amount:      tx.Value,
+			TxHash:      tx.Hash,
+			BlockNumber: tx.BlockNumber,
+			Timestamp:   time.Now(),
+			Reason:      reason,
+			Severity:    highestSeverity,
+			Details:     string(detailsJSON),
 		}
 
-		// Create new suspicious transfer record
-		if err := db.Create(suspiciousTransfer).Error; err != nil {
-			return fmt.Errorf("error creating suspicious transfer: %w", err)
+		// Create new suspicious transfer record. Simulated pending transactions are
+		// analyzed once per transfer, so a transaction may already have a record; the
+		// new findings are merged into it.
+		result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(suspiciousTransfer)
+		if result.Error != nil {
+			return fmt.Errorf("error creating suspicious transfer: %w", result.Error)
+		}
+		linked := make(map[string]bool)
+		if result.RowsAffected == 0 {
+			if err := db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("tx_hash = ?", tx.Hash).First(suspiciousTransfer).Error; err != nil {
+				return fmt.Errorf("error loading suspicious transfer: %w", err)
+			}
+			if err := mergeSuspiciousTransfer(db, suspiciousTransfer, tx, reason, highestSeverity, allDetails); err != nil {
+				return err
+			}
+			var hashes []string
+			if err := db.Model(&models.SuspiciousTransferRelatedTx{}).
+				Where("suspicious_transfer_id = ?", suspiciousTransfer.ID).
+				Pluck("transaction_hash", &hashes).Error; err != nil {
+				return fmt.Errorf("error loading related transactions: %w", err)
+			}
+			for _, hash := range hashes {
+				linked[hash] = true
+			}
 		}
 
 		// Create related transaction records
 		for _, relatedTx := range relatedTxs {
+			if linked[relatedTx.Hash] {
+				continue
+			}
+			linked[relatedTx.Hash] = true
 			relatedTxRecord := &models.SuspiciousTransferRelatedTx{
 				SuspiciousTransferID: suspiciousTransfer.ID,
 				TransactionHash:      relatedTx.Hash,
@@ -575,38 +768,30 @@ func (a *Analyzer) handleSuspiciousBehaviors(tx *models.Transaction, behaviors [
 		log.Printf("Error in database transaction: %v", err)
 		return
 	}
-}
 
-// getAuth creates an authorized transactor for contract interactions
-func (a *Analyzer) getAuth(client *ethclient.Client) (*bind.TransactOpts, error) {
-	privateKey, err := crypto.HexToECDSA(os.Getenv("BLACKLIST_PRIVATE_KEY"))
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse private key: %v", err)
+	// File the finding into an investigation case
+	if _, err := CaseFromSuspiciousTransfer(a.db, tx.Hash); err != nil {
+		log.Printf("Failed to file case for %s: %v", tx.Hash, err)
 	}
 
-	chainID, err := client.ChainID(context.Background())
-	if err != nil {
-		return nil, fmt.Errorf("failed to get chain ID: %v", err)
-	}
-
-	auth, err := bind.NewKeyedTransactorWithChainID(privateKey, chainID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create authorized transactor: %v", err)
+	// Queue the blacklist; the enforcement executor sends it and marks the transfer once mined
+	if highestSeverity == "high" && !preempted {
+		action := models.EnforcementAction{
+			Address:       tx.To,
+			Direction:     direction,
+			Source:        "analyzer",
+			TriggerTxHash: tx.Hash,
+			Reason:        reason,
+			OnChainReason: onChainReason,
+			Severity:      highestSeverity,
+			Details:       "Automatically blacklisted due to suspicious behavior",
+		}
+		if applyPolicy(&action, policy) {
+			if _, err := EnqueueBlacklist(a.db, action); err != nil {
+				log.Printf("Failed to queue blacklist for %s: %v", tx.To, err)
+			}
+		}
 	}
-
-	// Get current gas price
-	//gasPrice, err := client.SuggestGasPrice(context.Background())
-	//if err != nil {
-	//	return nil, fmt.Errorf("failed to get gas price: %v", err)
-	//}
-
-	// Increase gas price by 50% to speed up transaction
-	//gasPrice = new(big.Int).Mul(gasPrice, big.NewInt(20))
-	//gasPrice = new(big.Int).Div(gasPrice, big.NewInt(10))
-	gasPrice := new(big.Int).Mul(big.NewInt(80), big.NewInt(1e9))
-	auth.GasPrice = gasPrice
-
-	return auth, nil
 }
 
 // processUnanalyzedTransactions processes any transactions that haven't been analyzed yet
@@ -648,12 +833,21 @@ func (a *Analyzer) processUnanalyzedTransactions() {
 	}
 }
 
+// history returns the query the checks read past transactions from. Confirmed transactions
+// are pruned from analysis but kept soft-deleted, so replays read them unscoped.
+func (a *Analyzer) history() *gorm.DB {
+	if a.replay {
+		return a.db.Unscoped()
+	}
+	return a.db
+}
+
 // checkMultipleTransfers checks if an address has made multiple transfers in a short time
 func (a *Analyzer) checkMultipleTransfers(tx *models.Transaction, minTransfers, blockRange int) []map[string]interface{} {
 	var behaviors []map[string]interface{}
 
 	var recentTxs []models.Transaction
-	query := a.db.Model(&models.Transaction{}).
+	query := a.history().Model(&models.Transaction{}).
 		Select("hash, from_address, to_address, value, block_number, timestamp")
 
 	if tx.BlockNumber >= uint64(blockRange) {
@@ -662,15 +856,24 @@ func (a *Analyzer) checkMultipleTransfers(tx *models.Transaction, minTransfers,
 		query = query.Where("from_address = ? AND block_number >= ?", tx.From, 0)
 	}
 
+	if a.replay {
+		query = query.Where("block_number <= ?", tx.BlockNumber)
+	}
+
 	query = query.Order("block_number DESC")
 
 	err := query.Find(&recentTxs).Error
 	if err != nil {
 		log.Printf("Error querying recent transactions: %v", err)
+		a.explain("multiple_transfers", false, nil, "error querying recent transactions: %v", err)
 		return behaviors
 	}
 
 	transferCount := len(recentTxs)
+	evidence := map[string]interface{}{"address": tx.From, "transfers": transferCount, "min_transfers": minTransfers, "block_range": blockRange}
+	if transferCount < minTransfers {
+		a.explain("multiple_transfers", false, evidence, "%s sent %d transfers within %d blocks, fewer than %d", tx.From, transferCount, blockRange, minTransfers)
+	}
 	if transferCount >= minTransfers {
 		var oldestBlock, newestBlock uint64
 		if len(recentTxs) > 0 {
@@ -682,8 +885,12 @@ func (a *Analyzer) checkMultipleTransfers(tx *models.Transaction, minTransfers,
 		}
 
 		blockRange := newestBlock - oldestBlock
+		if blockRange == 0 {
+			a.explain("multiple_transfers", false, evidence, "%s sent %d transfers, all in block %d; they must span more than one block", tx.From, transferCount, newestBlock)
+		}
 		if blockRange > 0 {
 			transfersPerBlock := float64(transferCount) / float64(blockRange)
+			a.explain("multiple_transfers", true, evidence, "%s sent %d transfers between blocks %d and %d, at least %d", tx.From, transferCount, oldestBlock, newestBlock, minTransfers)
 
 			severity := "medium"
 			if transfersPerBlock >= 2.0 {
@@ -715,14 +922,18 @@ func (a *Analyzer) checkMultipleIncomingTransfers(tx *models.Transaction, thresh
 	var behaviors []map[string]interface{}
 
 	var recentTxs []models.Transaction
-	query := a.db.Where("to_address = ?", tx.To)
+	query := a.history().Where("to_address = ?", tx.To)
 	if tx.BlockNumber > uint64(blockRange) {
 		query = query.Where("block_number >= ?", tx.BlockNumber-uint64(blockRange))
 	}
+	if a.replay {
+		query = query.Where("block_number <= ?", tx.BlockNumber)
+	}
 	err := query.Order("block_number DESC").Find(&recentTxs).Error
 
 	if err != nil {
 		log.Printf("Error querying recent incoming transactions: %v", err)
+		a.explain("multiple_incoming_transfers", false, nil, "error querying recent incoming transactions: %v", err)
 		return behaviors
 	}
 
@@ -736,6 +947,14 @@ func (a *Analyzer) checkMultipleIncomingTransfers(tx *models.Transaction, thresh
 	}
 
 	// Check if total amount exceeds threshold
+	evidence := map[string]interface{}{"address": tx.To, "total_amount": totalAmount.Text('f', 0), "transfers": len(recentTxs) + 1, "threshold": threshold.Text('f', 0), "block_range": blockRange}
+	if totalAmount.Cmp(threshold) <= 0 {
+		a.explain("multiple_incoming_transfers", false, evidence, "%s received %s in %d transfers within %d blocks, not above the threshold %s",
+			tx.To, totalAmount.Text('f', 0), len(recentTxs)+1, blockRange, threshold.Text('f', 0))
+	} else {
+		a.explain("multiple_incoming_transfers", true, evidence, "%s received %s in %d transfers within %d blocks, above the threshold %s",
+			tx.To, totalAmount.Text('f', 0), len(recentTxs)+1, blockRange, threshold.Text('f', 0))
+	}
 	if totalAmount.Cmp(threshold) > 0 {
 		var blockRange uint64
 		if len(recentTxs) > 0 {
@@ -759,6 +978,55 @@ func (a *Analyzer) checkMultipleIncomingTransfers(tx *models.Transaction, thresh
 	return behaviors
 }
 
+// checkRepeatedBlockedAttempts checks if an address has had multiple transfers blocked
+// by compliance modules in a short time period
+func (a *Analyzer) checkRepeatedBlockedAttempts(tx *models.Transaction, minAttempts, blockRange int) []map[string]interface{} {
+	var behaviors []map[string]interface{}
+
+	var attempts []models.PendingTransaction
+	query := a.history().Where("from_address = ? AND status = ? AND compliance_module <> ''", tx.From, "revert")
+	if tx.BlockNumber > uint64(blockRange) {
+		query = query.Where("block_number >= ?", tx.BlockNumber-uint64(blockRange))
+	}
+	if a.replay {
+		query = query.Where("block_number <= ?", tx.BlockNumber)
+	}
+	if err := query.Order("block_number DESC").Find(&attempts).Error; err != nil {
+		log.Printf("Error querying blocked attempts: %v", err)
+		a.explain("repeated_blocked_attempts", false, nil, "error querying blocked attempts: %v", err)
+		return behaviors
+	}
+
+	if len(attempts) < minAttempts {
+		a.explain("repeated_blocked_attempts", false, map[string]interface{}{"address": tx.From, "attempts": len(attempts), "min_attempts": minAttempts, "block_range": blockRange},
+			"%s had %d transfers blocked within %d blocks, fewer than %d", tx.From, len(attempts), blockRange, minAttempts)
+		return behaviors
+	}
+
+	modules := make(map[string]int)
+	for _, attempt := range attempts {
+		modules[attempt.ComplianceModule]++
+	}
+	a.explain("repeated_blocked_attempts", true, map[string]interface{}{"address": tx.From, "attempts": len(attempts), "min_attempts": minAttempts, "block_range": blockRange, "modules": modules},
+		"%s had %d transfers blocked within %d blocks, at least %d", tx.From, len(attempts), blockRange, minAttempts)
+
+	behaviors = append(behaviors, map[string]interface{}{
+		"type":        "repeated_blocked_attempts",
+		"description": "Address repeatedly attempted transfers blocked by compliance",
+		"severity":    "medium",
+		"details": map[string]interface{}{
+			"address":      tx.From,
+			"attempts":     len(attempts),
+			"min_attempts": minAttempts,
+			"block_range":  blockRange,
+			"modules":      modules,
+			"last_reason":  attempts[0].RevertReason,
+		},
+	})
+
+	return behaviors
+}
+
 // checkBalanceExceeded checks if a transfer amount exceeds the sender's previous balance
 func (a *Analyzer) checkBalanceExceeded(tx *models.Transaction) map[string]interface{} {
 	value := new(big.Float)
@@ -814,6 +1082,76 @@ func (a *Analyzer) updateState(tx *models.Transaction) {
 	a.cleanupOldBlocks(tx.BlockNumber)
 }
 
+// mergeSuspiciousTransfer adds the reasons, severity and details of another finding on the
+// same transaction to its suspicious transfer. Findings on another transfer of the
+// transaction name its parties.
+func mergeSuspiciousTransfer(db *gorm.DB, transfer *models.SuspiciousTransfer, tx *models.Transaction, reason, severity string, details map[string]interface{}) error {
+	otherTransfer := tx.From != transfer.From || tx.To != transfer.To
+
+	reasons := strings.Split(transfer.Reason, "; ")
+	known := make(map[string]bool)
+	for _, r := range reasons {
+		known[r] = true
+	}
+	for _, r := range strings.Split(reason, "; ") {
+		if otherTransfer {
+			r = fmt.Sprintf("%s (%s -> %s)", r, tx.From, tx.To)
+		}
+		if r != "" && !known[r] {
+			known[r] = true
+			reasons = append(reasons, r)
+		}
+	}
+
+	// Details that are not a JSON object are kept as they are next to the new findings
+	merged := make(map[string]interface{})
+	if existing := strings.TrimSuffix(transfer.Details, " blocked by fds"); existing != "" {
+		if err := json.Unmarshal([]byte(existing), &merged); err != nil {
+			log.Printf("Error parsing details of suspicious transfer %s, keeping them as previous_details: %v", transfer.TxHash, err)
+			merged = map[string]interface{}{"previous_details": existing}
+		}
+	}
+	if otherTransfer {
+		others, _ := merged["other_transfers"].([]interface{})
+		seen := false
+		for _, other := range others {
+			o, _ := other.(map[string]interface{})
+			seen = seen || o["from"] == tx.From && o["to"] == tx.To && o["amount"] == tx.Value
+		}
+		if !seen {
+			merged["other_transfers"] = append(others, map[string]interface{}{
+				"from":     tx.From,
+				"to":       tx.To,
+				"amount":   tx.Value,
+				"findings": details,
+			})
+		}
+	} else {
+		for key, value := range details {
+			if _, ok := merged[key]; !ok {
+				merged[key] = value
+			}
+		}
+	}
+	detailsJSON, err := json.Marshal(merged)
+	if err != nil {
+		return fmt.Errorf("error marshaling details: %w", err)
+	}
+
+	updates := map[string]interface{}{
+		"reason":  strings.TrimPrefix(strings.Join(reasons, "; "), "; "),
+		"details": string(detailsJSON) + " blocked by fds",
+	}
+	if severityRank(severity) > severityRank(transfer.Severity) {
+		updates["severity"] = severity
+	}
+	if err := db.Model(transfer).Updates(updates).Error; err != nil {
+		return fmt.Errorf("error merging suspicious transfer: %w", err)
+	}
+	log.Printf("Merged new findings on transaction %s into its suspicious transfer", tx.Hash)
+	return nil
+}
+
 // cleanupOldBlocks removes data for blocks older than maxBlocks
 func (a *Analyzer) cleanupOldBlocks(currentBlock uint64) {
 	if currentBlock > uint64(a.maxBlocks) {
@@ -831,18 +1169,31 @@ func (a *Analyzer) checkRuleStatus(ruleName string) bool {
 	return true
 }
 
-// recordRuleViolation records a violation for an active rule
-func (a *Analyzer) recordRuleViolation(ruleName string, tx *models.Transaction, details map[string]interface{}) {
+// recordRuleViolation records a violation of the active rule configuring a check and reports
+// whether its alert is suppressed, in which case the caller neither reports nor enforces it
+func (a *Analyzer) recordRuleViolation(ruleName string, tx *models.Transaction, details map[string]interface{}) (suppressed bool) {
+	loaded, ok := a.rules[ruleName]
+	if !ok {
+		return false
+	}
 	var rule models.Rule
-	if err := a.db.Where("name = ? AND status = ?", ruleName, "active").First(&rule).Error; err != nil {
-		return // Rule not found or not active
+	if err := a.db.Where("id = ? AND status = ?", loaded.ID, "active").First(&rule).Error; err != nil {
+		return false // Rule deleted or no longer active
+	}
+
+	// A transaction analyzed again, pending then mined, violates the rule only once
+	var recorded models.RuleViolation
+	if err := a.db.Where("rule_id = ? AND tx_hash = ?", rule.ID, tx.Hash).Limit(1).Find(&recorded).Error; err != nil {
+		log.Printf("Error checking earlier %s violations of %s: %v", ruleName, tx.Hash, err)
+	} else if recorded.ID != 0 {
+		return recorded.Suppressed
 	}
 
 	// Convert details to JSON
 	detailsJSON, err := json.Marshal(details)
 	if err != nil {
 		log.Printf("Error marshaling violation details: %v", err)
-		return
+		return false
 	}
 
 	// Create violation record
@@ -851,10 +1202,20 @@ func (a *Analyzer) recordRuleViolation(ruleName string, tx *models.Transaction,
 		TxHash:      tx.Hash,
 		BlockNumber: tx.BlockNumber,
 		Details:     string(detailsJSON),
+		// The parameters that produced the violation are those loaded, not the rule's latest
+		RuleVersionID: loaded.VersionID,
 	}
 
 	// Use transaction to ensure atomicity
 	err = a.db.Transaction(func(db *gorm.DB) error {
+		// Group the violation into its incident; repeats and snoozed addresses do not alert
+		incident, groupSuppressed, err := groupAlert(db, &rule, ruleGroupAddress(rule.Actions, tx), tx.Hash, time.Now(), ruleSuppressionWindow(rule.Actions, a.suppression))
+		if err != nil {
+			return fmt.Errorf("error grouping violation into its incident: %w", err)
+		}
+		violation.IncidentID = incident.ID
+		violation.Suppressed = groupSuppressed
+
 		// Create violation record
 		if err := db.Create(violation).Error; err != nil {
 			return fmt.Errorf("error creating violation record: %w", err)
@@ -868,12 +1229,39 @@ func (a *Analyzer) recordRuleViolation(ruleName string, tx *models.Transaction,
 			return fmt.Errorf("error updating rule violation count: %w", err)
 		}
 
+		// A failure to queue alerts must not lose the violation
+		if a.notifier != nil && !violation.Suppressed {
+			alert := Alert{
+				ViolationID: violation.ID,
+				Rule:        rule.Name,
+				Description: rule.Description,
+				Severity:    rule.Severity,
+				TxHash:      tx.Hash,
+				BlockNumber: tx.BlockNumber,
+				From:        tx.From,
+				To:          tx.To,
+				Amount:      tx.Value,
+				Details:     details,
+				DetectedAt:  violation.CreatedAt,
+			}
+			if err := db.Transaction(func(db *gorm.DB) error {
+				return a.notifier.Enqueue(db, &rule, alert)
+			}); err != nil {
+				log.Printf("Error queueing alerts of %s violation: %v", ruleName, err)
+			}
+		}
+
 		return nil
 	})
 
 	if err != nil {
 		log.Printf("Error recording %s violation: %v", ruleName, err)
+		return false
+	}
+	if violation.Suppressed {
+		log.Printf("Suppressed %s alert of %s, grouped into incident %d", ruleName, tx.Hash, violation.IncidentID)
 	}
+	return violation.Suppressed
 }
 
 // getERC20BalanceAt returns the ERC20 token balance for a given address at a specific block (or nil for latest)
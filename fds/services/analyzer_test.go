@@ -0,0 +1,133 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"token-monitor/models"
+)
+
+func TestMergeSuspiciousTransfer(t *testing.T) {
+	db := testDB(t, &models.SuspiciousTransfer{})
+	const (
+		sender    = "0x1111111111111111111111111111111111111111"
+		recipient = "0x2222222222222222222222222222222222222222"
+		onward    = "0x3333333333333333333333333333333333333333"
+	)
+	transfer := models.SuspiciousTransfer{
+		From: sender, To: recipient, Amount: "10", TxHash: "0xabc", Severity: "medium",
+		Reason:  "Multiple transfers in short time",
+		Details: `{"multiple_transfers":{"count":6}} blocked by fds`,
+	}
+	if err := db.Create(&transfer).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// A second finding on the same transfer
+	same := &models.Transaction{Hash: "0xabc", From: sender, To: recipient, Value: "10"}
+	if err := mergeSuspiciousTransfer(db, &transfer, same, "Large amount transfer detected", "high",
+		map[string]interface{}{"large_transfer": map[string]interface{}{"amount": "10"}}); err != nil {
+		t.Fatal(err)
+	}
+	// A finding on another transfer of the transaction, reported twice
+	other := &models.Transaction{Hash: "0xabc", From: recipient, To: onward, Value: "9"}
+	for i := 0; i < 2; i++ {
+		if err := db.First(&transfer, transfer.ID).Error; err != nil {
+			t.Fatal(err)
+		}
+		if err := mergeSuspiciousTransfer(db, &transfer, other, "Large amount transfer detected", "high",
+			map[string]interface{}{"large_transfer": map[string]interface{}{"amount": "9"}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var merged models.SuspiciousTransfer
+	if err := db.First(&merged, transfer.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	want := "Multiple transfers in short time; Large amount transfer detected; Large amount transfer detected (" + recipient + " -> " + onward + ")"
+	if merged.Reason != want {
+		t.Errorf("reason %q, want %q", merged.Reason, want)
+	}
+	if merged.Severity != "high" {
+		t.Errorf("severity %s, want high", merged.Severity)
+	}
+	for _, part := range []string{`"multiple_transfers"`, `"large_transfer"`, `"other_transfers"`} {
+		if !strings.Contains(merged.Details, part) {
+			t.Errorf("details %s lack %s", merged.Details, part)
+		}
+	}
+	if strings.Count(merged.Details, onward) != 1 {
+		t.Errorf("details %s record the other transfer more than once", merged.Details)
+	}
+}
+
+func TestMergeSuspiciousTransferKeepsUnparsedDetails(t *testing.T) {
+	db := testDB(t, &models.SuspiciousTransfer{})
+	transfer := models.SuspiciousTransfer{
+		From: "0x1111111111111111111111111111111111111111", To: "0x2222222222222222222222222222222222222222",
+		Amount: "10", TxHash: "0xdef", Severity: "medium", Reason: "Manual review",
+		Details: "flagged by an officer blocked by fds",
+	}
+	if err := db.Create(&transfer).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	tx := &models.Transaction{Hash: "0xdef", From: transfer.From, To: transfer.To, Value: "10"}
+	if err := mergeSuspiciousTransfer(db, &transfer, tx, "Large amount transfer detected", "high",
+		map[string]interface{}{"large_transfer": map[string]interface{}{"amount": "10"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	var merged models.SuspiciousTransfer
+	if err := db.First(&merged, transfer.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	for _, part := range []string{`"previous_details":"flagged by an officer"`, `"large_transfer"`} {
+		if !strings.Contains(merged.Details, part) {
+			t.Errorf("details %s lack %s", merged.Details, part)
+		}
+	}
+}
+
+func TestReloadRulesPicksUpChanges(t *testing.T) {
+	db := testDB(t, &models.Rule{}, &models.RuleVersion{})
+	addRule := func(rule models.Rule) {
+		t.Helper()
+		if err := db.Create(&rule).Error; err != nil {
+			t.Fatalf("creating rule %s: %v", rule.Name, err)
+		}
+		version := models.RuleVersion{RuleID: rule.ID, Version: 1, Name: rule.Name, Type: ruleType(&rule),
+			Status: rule.Status, Severity: rule.Severity, Change: "create"}
+		if err := db.Create(&version).Error; err != nil {
+			t.Fatalf("creating version of %s: %v", rule.Name, err)
+		}
+	}
+	addRule(models.Rule{Name: "large_transfer", Description: "Large transfer", Status: "active", Severity: "high",
+		Parameters: `{"threshold": "1000"}`})
+
+	a := NewAnalyzer(db, 1000, 10, nil, 0)
+	a.reloadRules(100)
+	if len(a.rules) != 1 || len(a.shadows) != 0 {
+		t.Fatalf("loaded %d rules and %d shadows, want 1 and 0", len(a.rules), len(a.shadows))
+	}
+
+	addRule(models.Rule{Name: "large_transfer_low", Type: "large_transfer", Description: "Lower threshold",
+		Status: "shadow", Severity: "high", Parameters: `{"threshold": "500"}`, ShadowOf: "large_transfer"})
+	a.reloadRules(100)
+	if len(a.shadows) != 0 {
+		t.Errorf("rules reloaded twice in block 100")
+	}
+	a.reloadRules(101)
+	if len(a.shadows) != 1 || a.shadows[0].Name != "large_transfer_low" {
+		t.Errorf("shadow rule created through the API not loaded at the next block")
+	}
+
+	db.Model(&models.Rule{}).Where("name = ?", "large_transfer").Update("status", "inactive")
+	db.Create(&models.RuleVersion{RuleID: 1, Version: 2, Name: "large_transfer", Type: "large_transfer",
+		Status: "inactive", Severity: "high", Change: "update"})
+	a.reloadRules(102)
+	if _, ok := a.rules["large_transfer"]; ok {
+		t.Errorf("deactivated rule still loaded")
+	}
+}
@@ -0,0 +1,351 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"token-monitor/models"
+
+	"gorm.io/gorm"
+)
+
+// backtestListLimit caps each list of transactions and addresses in a backtest report
+const backtestListLimit = 1000
+
+// BacktestRequest selects the stored transactions to replay and the candidate rule
+// parameters to replay them with
+type BacktestRequest struct {
+	FromBlock  *uint64                           `json:"from_block,omitempty"`
+	ToBlock    *uint64                           `json:"to_block,omitempty"`
+	Since      *time.Time                        `json:"since,omitempty"`
+	Until      *time.Time                        `json:"until,omitempty"` // Exclusive
+	Parameters map[string]map[string]interface{} `json:"parameters"`      // By rule name, over the current parameters
+}
+
+// BacktestRuleResult compares the hits of a rule with its current and candidate parameters
+type BacktestRuleResult struct {
+	Rule                string                 `json:"rule"`
+	CurrentParameters   map[string]interface{} `json:"current_parameters,omitempty"` // Nil if no rule of its check is active
+	CandidateParameters map[string]interface{} `json:"candidate_parameters"`
+	CurrentHits         int                    `json:"current_hits"`
+	CandidateHits       int                    `json:"candidate_hits"`
+	Added               []string               `json:"added"`             // Transactions only hit with the candidate parameters
+	Removed             []string               `json:"removed"`           // Transactions only hit with the current parameters
+	Addresses           []string               `json:"addresses"`         // Addresses alerted on with the candidate parameters
+	NewAddresses        []string               `json:"new_addresses"`     // Of those, addresses not alerted on now
+	ClearedAddresses    []string               `json:"cleared_addresses"` // Addresses alerted on now but not with the candidate parameters
+
+	currentAddresses   map[string]bool
+	candidateAddresses map[string]bool
+}
+
+// BacktestReport is the outcome of a backtest. Hits count violations before alert
+// suppression, one per rule and transaction.
+type BacktestReport struct {
+	FromBlock     uint64                `json:"from_block"` // Range of the transactions replayed
+	ToBlock       uint64                `json:"to_block"`
+	Transactions  int                   `json:"transactions"`
+	Whitelisted   int                   `json:"whitelisted"` // Exempted from some check by the whitelist
+	CurrentHits   int                   `json:"current_hits"`
+	CandidateHits int                   `json:"candidate_hits"`
+	Rules         []*BacktestRuleResult `json:"rules"`
+	Truncated     bool                  `json:"truncated"` // Whether a list was capped
+}
+
+// capped appends value to list unless the list is full, flagging the report as truncated
+func (r *BacktestReport) capped(list []string, value string) []string {
+	if len(list) >= backtestListLimit {
+		r.Truncated = true
+		return list
+	}
+	return append(list, value)
+}
+
+// backtestRules returns the active rules and the rules with the candidate parameters
+// applied, by the check they configure. A rule given candidate parameters is backtested even
+// if it is not active, in place of the active rule of its check.
+func backtestRules(db *gorm.DB, candidates map[string]map[string]interface{}) (map[string]*models.Rule, map[string]*models.Rule, error) {
+	var rules []models.Rule
+	if err := db.Order("id").Find(&rules).Error; err != nil {
+		return nil, nil, err
+	}
+	byName := make(map[string]*models.Rule)
+	current := make(map[string]*models.Rule)
+	candidate := make(map[string]*models.Rule)
+	for i := range rules {
+		byName[rules[i].Name] = &rules[i]
+		check := ruleType(&rules[i])
+		if _, loaded := current[check]; !loaded && rules[i].Status == "active" {
+			current[check] = &rules[i]
+			candidate[check] = &rules[i]
+		}
+	}
+
+	for name, overrides := range candidates {
+		rule, ok := byName[name]
+		if !ok {
+			return nil, nil, fmt.Errorf("unknown rule %s", name)
+		}
+		params := make(map[string]interface{})
+		if rule.Parameters != "" {
+			if err := json.Unmarshal([]byte(rule.Parameters), &params); err != nil {
+				return nil, nil, fmt.Errorf("rule %s has invalid parameters: %w", name, err)
+			}
+		}
+		for param, value := range overrides {
+			if _, ok := params[param]; !ok {
+				return nil, nil, fmt.Errorf("rule %s has no parameter %s", name, param)
+			}
+			params[param] = value
+		}
+		merged, err := json.Marshal(params)
+		if err != nil {
+			return nil, nil, err
+		}
+		copied := *rule
+		copied.Parameters = string(merged)
+		candidate[ruleType(rule)] = &copied
+	}
+	return current, candidate, nil
+}
+
+// RunBacktest replays the stored transactions of a block or date range through the rules
+// with their current and candidate parameters and compares the hits. It only reads: no
+// violation, alert or enforcement is recorded.
+func RunBacktest(ctx context.Context, db *gorm.DB, req BacktestRequest) (*BacktestReport, error) {
+	if req.FromBlock == nil && req.ToBlock == nil && req.Since == nil && req.Until == nil {
+		return nil, fmt.Errorf("a block or date range is required")
+	}
+	if req.FromBlock != nil && req.ToBlock != nil && *req.FromBlock > *req.ToBlock {
+		return nil, fmt.Errorf("from_block is after to_block")
+	}
+	current, candidate, err := backtestRules(db, req.Parameters)
+	if err != nil {
+		return nil, err
+	}
+	live := &Analyzer{db: db, rules: current, replay: true, quiet: true}
+	proposed := &Analyzer{db: db, rules: candidate, replay: true, quiet: true}
+
+	report := &BacktestReport{}
+	results := make(map[string]*BacktestRuleResult)
+	for check, rule := range candidate {
+		result := &BacktestRuleResult{
+			Rule:               rule.Name,
+			Added:              []string{},
+			Removed:            []string{},
+			Addresses:          []string{},
+			NewAddresses:       []string{},
+			ClearedAddresses:   []string{},
+			currentAddresses:   make(map[string]bool),
+			candidateAddresses: make(map[string]bool),
+		}
+		json.Unmarshal([]byte(rule.Parameters), &result.CandidateParameters)
+		if live, ok := current[check]; ok {
+			json.Unmarshal([]byte(live.Parameters), &result.CurrentParameters)
+		}
+		results[check] = result
+	}
+
+	// Confirmed transactions are pruned from analysis but kept soft-deleted, so the backtest
+	// reads them unscoped
+	query := db.Unscoped().Model(&models.Transaction{})
+	if req.FromBlock != nil {
+		query = query.Where("block_number >= ?", *req.FromBlock)
+	}
+	if req.ToBlock != nil {
+		query = query.Where("block_number <= ?", *req.ToBlock)
+	}
+	if req.Since != nil {
+		query = query.Where("timestamp >= ?", *req.Since)
+	}
+	if req.Until != nil {
+		query = query.Where("timestamp < ?", *req.Until)
+	}
+
+	var batch []models.Transaction
+	err = query.Order("id").FindInBatches(&batch, 500, func(_ *gorm.DB, _ int) error {
+		for i := range batch {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			tx := &batch[i]
+			if report.Transactions == 0 || tx.BlockNumber < report.FromBlock {
+				report.FromBlock = tx.BlockNumber
+			}
+			if tx.BlockNumber > report.ToBlock {
+				report.ToBlock = tx.BlockNumber
+			}
+			report.Transactions++
+			// The whitelist exempts the current and candidate rules alike
+			exemptions := live.whitelistExemptions(tx)
+			if len(exemptions) > 0 {
+				report.Whitelisted++
+			}
+
+			hits := make(map[string]bool)
+			for _, d := range live.detect(tx) {
+				if len(exemptions[d.Rule]) > 0 {
+					continue
+				}
+				result := results[d.Rule]
+				result.CurrentHits++
+				report.CurrentHits++
+				result.currentAddresses[ruleGroupAddress(current[d.Rule].Actions, tx)] = true
+				hits[d.Rule] = true
+			}
+			for _, d := range proposed.detect(tx) {
+				if len(exemptions[d.Rule]) > 0 {
+					continue
+				}
+				result := results[d.Rule]
+				result.CandidateHits++
+				report.CandidateHits++
+				address := ruleGroupAddress(candidate[d.Rule].Actions, tx)
+				if !result.candidateAddresses[address] {
+					result.candidateAddresses[address] = true
+					result.Addresses = report.capped(result.Addresses, address)
+				}
+				if !hits[d.Rule] {
+					result.Added = report.capped(result.Added, tx.Hash)
+				}
+				delete(hits, d.Rule)
+			}
+			// The rules left were only hit with the current parameters
+			for name := range hits {
+				results[name].Removed = report.capped(results[name].Removed, tx.Hash)
+			}
+		}
+		return nil
+	}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, result := range results {
+		for address := range result.candidateAddresses {
+			if !result.currentAddresses[address] {
+				result.NewAddresses = report.capped(result.NewAddresses, address)
+			}
+		}
+		for address := range result.currentAddresses {
+			if !result.candidateAddresses[address] {
+				result.ClearedAddresses = report.capped(result.ClearedAddresses, address)
+			}
+		}
+		sort.Strings(result.NewAddresses)
+		sort.Strings(result.ClearedAddresses)
+		report.Rules = append(report.Rules, result)
+	}
+	sort.Slice(report.Rules, func(i, j int) bool { return report.Rules[i].Rule < report.Rules[j].Rule })
+	return report, nil
+}
+
+// BacktestRunner runs the backtests requested through fds-api
+type BacktestRunner struct {
+	db       *gorm.DB
+	interval time.Duration
+	stopChan chan struct{}
+	wg       sync.WaitGroup
+	cancel   context.CancelFunc // Interrupts the running backtest on Stop
+}
+
+// NewBacktestRunner creates a runner checking for requested backtests every interval
+func NewBacktestRunner(db *gorm.DB, interval time.Duration) *BacktestRunner {
+	return &BacktestRunner{
+		db:       db,
+		interval: interval,
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Start begins running requested backtests, one at a time
+func (r *BacktestRunner) Start(ctx context.Context) {
+	// Runs interrupted by a restart are run again
+	if err := r.db.Model(&models.BacktestRun{}).Where("status = ?", "running").Update("status", "pending").Error; err != nil {
+		log.Printf("Error requeueing interrupted backtests: %v", err)
+	}
+
+	ctx, r.cancel = context.WithCancel(ctx)
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				r.runPending(ctx)
+			case <-ctx.Done():
+				return
+			case <-r.stopChan:
+				return
+			}
+		}
+	}()
+}
+
+// Stop gracefully stops the runner
+func (r *BacktestRunner) Stop() {
+	r.cancel()
+	close(r.stopChan)
+	r.wg.Wait()
+}
+
+// runPending runs the pending backtests in the order they were requested
+func (r *BacktestRunner) runPending(ctx context.Context) {
+	for ctx.Err() == nil {
+		var run models.BacktestRun
+		if err := r.db.Where("status = ?", "pending").Order("id").Limit(1).Find(&run).Error; err != nil {
+			log.Printf("Error loading pending backtests: %v", err)
+			return
+		}
+		if run.ID == 0 {
+			return
+		}
+		r.run(ctx, &run)
+	}
+}
+
+// run runs a backtest and records its report or error
+func (r *BacktestRunner) run(ctx context.Context, run *models.BacktestRun) {
+	started := time.Now()
+	if err := r.db.Model(run).Updates(map[string]interface{}{"status": "running", "started_at": started}).Error; err != nil {
+		log.Printf("Error starting backtest %d: %v", run.ID, err)
+		return
+	}
+	log.Printf("Running backtest %d requested by %s", run.ID, run.RequestedBy)
+
+	var report *BacktestReport
+	var req BacktestRequest
+	err := json.Unmarshal([]byte(run.Request), &req)
+	if err == nil {
+		report, err = RunBacktest(ctx, r.db, req)
+	}
+	if ctx.Err() != nil {
+		// Left running; it is requeued on the next start
+		return
+	}
+
+	finished := time.Now()
+	updates := map[string]interface{}{"finished_at": finished}
+	if err != nil {
+		log.Printf("Backtest %d failed: %v", run.ID, err)
+		updates["status"] = "failed"
+		updates["error"] = err.Error()
+	} else {
+		encoded, _ := json.Marshal(report)
+		updates["status"] = "done"
+		updates["report"] = string(encoded)
+		log.Printf("Backtest %d replayed %d transactions in %s: %d hits now, %d with the candidate parameters",
+			run.ID, report.Transactions, finished.Sub(started).Round(time.Millisecond), report.CurrentHits, report.CandidateHits)
+	}
+	if err := r.db.Model(run).Updates(updates).Error; err != nil {
+		log.Printf("Error recording backtest %d: %v", run.ID, err)
+	}
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"token-monitor/models"
+)
+
+func TestRunBacktestReplaysPrunedTransactions(t *testing.T) {
+	db := testDB(t, &models.Transaction{}, &models.PendingTransaction{}, &models.Rule{}, &models.SuspiciousAddress{},
+		&models.WhitelistAddress{}, &models.WatchlistSource{}, &models.WatchlistEntry{}, &models.WatchlistVersion{})
+	rules := []models.Rule{
+		{Name: "large_transfer", Status: "active", Severity: "high", Parameters: `{"threshold": 1000}`},
+		{Name: "multiple_transfers", Status: "active", Severity: "medium", Parameters: `{"min_transfers": 3, "block_range": 10}`},
+	}
+	if err := db.Create(&rules).Error; err != nil {
+		t.Fatal(err)
+	}
+	const sender = "0x1111111111111111111111111111111111111111"
+	start := time.Now().Add(-time.Hour)
+	for i := 0; i < 4; i++ {
+		tx := models.Transaction{
+			Hash:        fmt.Sprintf("0x%064x", i+1),
+			From:        sender,
+			To:          "0x2222222222222222222222222222222222222222",
+			Value:       "5000",
+			BlockNumber: uint64(100 + i),
+			Timestamp:   start.Add(time.Duration(i) * time.Minute),
+			IsAnalyzed:  true,
+		}
+		if err := db.Create(&tx).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The analyzer prunes transactions a few blocks old from analysis
+	if err := db.Where("block_number >= ?", 0).Delete(&models.Transaction{}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	from, to := uint64(100), uint64(103)
+	report, err := RunBacktest(context.Background(), db, BacktestRequest{FromBlock: &from, ToBlock: &to})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Transactions != 4 {
+		t.Errorf("replayed %d transactions, want 4", report.Transactions)
+	}
+	hits := make(map[string]int)
+	for _, result := range report.Rules {
+		hits[result.Rule] = result.CurrentHits
+	}
+	if hits["large_transfer"] != 4 {
+		t.Errorf("large_transfer hit %d times, want 4", hits["large_transfer"])
+	}
+	// The third and fourth transfers see the earlier ones as history
+	if hits["multiple_transfers"] != 2 {
+		t.Errorf("multiple_transfers hit %d times, want 2", hits["multiple_transfers"])
+	}
+}
@@ -0,0 +1,273 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"token-monitor/blacklist"
+	"token-monitor/models"
+
+	"github.com/ethereum/go-ethereum/common"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Blacklist hold types, each with its own lifecycle
+const (
+	HoldAutomated      = blacklist.HoldAutomated
+	HoldManual         = blacklist.HoldManual
+	HoldLawEnforcement = blacklist.HoldLawEnforcement
+)
+
+// holdRank orders hold types by strength
+func holdRank(holdType string) int {
+	return blacklist.HoldRank(holdType)
+}
+
+// initialStatus returns the status a new entry of holdType starts in
+func initialStatus(holdType string) string {
+	if holdType == HoldAutomated {
+		return "provisional"
+	}
+	return "confirmed"
+}
+
+// actionActor returns who requested an action, for the audit trail
+func actionActor(action models.EnforcementAction) string {
+	return blacklist.ActionActor(action)
+}
+
+// auditStatusChange records a blacklist lifecycle transition
+func auditStatusChange(db *gorm.DB, address, from, to, actor, reason string, actionID uint) error {
+	return blacklist.RecordStatusChange(db, address, from, to, actor, reason, actionID)
+}
+
+// upgradeHold raises the hold type of an entry when action requests a stronger hold,
+// confirming a provisional entry on the way
+func upgradeHold(db *gorm.DB, existing *models.BlacklistedAddress, action models.EnforcementAction) error {
+	return blacklist.UpgradeHold(db, existing, action)
+}
+
+// removeDirection returns the direction that stays restricted after lifting lifted from
+// existing, or "" when nothing stays restricted
+func removeDirection(existing, lifted string) string {
+	if existing == "" {
+		existing = DirectionBoth
+	}
+	switch {
+	case lifted == DirectionBoth || lifted == "" || lifted == existing:
+		return ""
+	case existing == DirectionBoth && lifted == DirectionFrom:
+		return DirectionTo
+	case existing == DirectionBoth && lifted == DirectionTo:
+		return DirectionFrom
+	}
+	return existing
+}
+
+// recordLifted stores the result of a mined unblacklist: the entry is narrowed when a
+// direction stays restricted, otherwise it is marked as lifted. The suspicious transfers
+// that led to the blacklist stay marked, so the blacklist monitor does not queue them again.
+func recordLifted(db *gorm.DB, action models.EnforcementAction) error {
+	var existing models.BlacklistedAddress
+	if err := db.Where("address = ?", action.Address).First(&existing).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil
+		}
+		return err
+	}
+
+	if remaining := removeDirection(existing.Direction, action.Direction); remaining != "" {
+		return db.Model(&existing).Update("direction", remaining).Error
+	}
+	return liftBlacklisted(db, &existing, actionActor(action), action.Reason, action.ID)
+}
+
+// liftBlacklisted marks an entry as lifted and soft-deletes it, keeping it for the audit trail
+func liftBlacklisted(db *gorm.DB, existing *models.BlacklistedAddress, actor, reason string, actionID uint) error {
+	now := time.Now()
+	if err := db.Model(existing).Updates(map[string]interface{}{
+		"status":      "lifted",
+		"lifted_at":   &now,
+		"lift_reason": reason,
+	}).Error; err != nil {
+		return err
+	}
+	if err := db.Delete(existing).Error; err != nil {
+		return err
+	}
+	return auditStatusChange(db, existing.Address, existing.Status, "lifted", actor, reason, actionID)
+}
+
+// EnqueueUnblacklist queues an unblacklist action for action.Address in action.Direction.
+// Nothing is queued when such an unblacklist is already in flight. An action with
+// RequiredApprovals waits for approval before it is sent. It reports whether a new action
+// was queued.
+func EnqueueUnblacklist(db *gorm.DB, action models.EnforcementAction) (bool, error) {
+	if !common.IsHexAddress(action.Address) {
+		return false, fmt.Errorf("invalid address %q", action.Address)
+	}
+	action.Address = common.HexToAddress(action.Address).Hex()
+	action.Action = "unblacklist"
+	if action.Direction != DirectionFrom && action.Direction != DirectionTo {
+		action.Direction = DirectionBoth
+	}
+	action.Status = blacklist.QueuedStatus(action)
+	action.NextAttemptAt = time.Now()
+
+	var count int64
+	if err := db.Model(&models.EnforcementAction{}).
+		Where("address = ? AND action = ? AND direction IN ? AND status IN ?",
+			action.Address, action.Action, []string{action.Direction, DirectionBoth}, blacklist.ActiveStatuses).
+		Count(&count).Error; err != nil {
+		return false, fmt.Errorf("error queueing unblacklist for %s: %w", action.Address, err)
+	}
+	if count > 0 {
+		return false, nil
+	}
+
+	// The partial unique index on active actions catches concurrent enqueues
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&action)
+	if result.Error != nil {
+		return false, fmt.Errorf("error queueing unblacklist for %s: %w", action.Address, result.Error)
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("Queued unblacklist of %s, direction %s (source: %s)", action.Address, action.Direction, action.Source)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// LifecyclePolicy holds the durations that drive the blacklist lifecycle
+type LifecyclePolicy struct {
+	ProvisionalTTL               time.Duration // How long an automated freeze lasts unless confirmed
+	ReviewInterval               time.Duration // How often a confirmed manual blacklist is reviewed
+	LawEnforcementReviewInterval time.Duration // How often a law-enforcement hold is reviewed
+}
+
+// lifecycleDates returns the expiry and review dates an entry should carry under policy,
+// keeping dates that were set explicitly
+func lifecycleDates(policy LifecyclePolicy, entry models.BlacklistedAddress) (*time.Time, *time.Time) {
+	expiresAt, reviewAt := entry.ExpiresAt, entry.ReviewAt
+
+	switch entry.Status {
+	case "provisional":
+		if expiresAt == nil {
+			t := entry.CreatedAt.Add(policy.ProvisionalTTL)
+			expiresAt = &t
+		}
+	case "confirmed":
+		if reviewAt == nil {
+			since := entry.CreatedAt
+			if entry.ConfirmedAt != nil {
+				since = *entry.ConfirmedAt
+			}
+			interval := policy.ReviewInterval
+			if entry.HoldType == HoldLawEnforcement {
+				interval = policy.LawEnforcementReviewInterval
+			}
+			t := since.Add(interval)
+			reviewAt = &t
+		}
+	}
+	return expiresAt, reviewAt
+}
+
+// BlacklistLifecycle dates blacklist entries according to their hold type and lifts
+// provisional freezes that expired without being confirmed
+type BlacklistLifecycle struct {
+	db       *gorm.DB
+	policy   LifecyclePolicy
+	interval time.Duration
+	stopChan chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewBlacklistLifecycle creates a new blacklist lifecycle scheduler
+func NewBlacklistLifecycle(db *gorm.DB, policy LifecyclePolicy, interval time.Duration) *BlacklistLifecycle {
+	return &BlacklistLifecycle{
+		db:       db,
+		policy:   policy,
+		interval: interval,
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Start begins scheduling blacklist expiries and reviews
+func (l *BlacklistLifecycle) Start(ctx context.Context) {
+	l.wg.Add(1)
+	go func() {
+		defer l.wg.Done()
+		ticker := time.NewTicker(l.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				l.assignDates()
+				l.expireProvisional()
+			case <-ctx.Done():
+				return
+			case <-l.stopChan:
+				return
+			}
+		}
+	}()
+}
+
+// Stop gracefully stops the scheduler
+func (l *BlacklistLifecycle) Stop() {
+	close(l.stopChan)
+	l.wg.Wait()
+}
+
+// assignDates sets the expiry of provisional entries and the review date of confirmed ones
+func (l *BlacklistLifecycle) assignDates() {
+	var entries []models.BlacklistedAddress
+	if err := l.db.Where("(status = ? AND expires_at IS NULL) OR (status = ? AND review_at IS NULL)", "provisional", "confirmed").
+		Find(&entries).Error; err != nil {
+		log.Printf("Error querying undated blacklist entries: %v", err)
+		return
+	}
+
+	for _, entry := range entries {
+		expiresAt, reviewAt := lifecycleDates(l.policy, entry)
+		if err := l.db.Model(&entry).Updates(map[string]interface{}{
+			"expires_at": expiresAt,
+			"review_at":  reviewAt,
+		}).Error; err != nil {
+			log.Printf("Error dating blacklist entry %s: %v", entry.Address, err)
+		}
+	}
+}
+
+// expireProvisional queues an unblacklist for every provisional freeze past its expiry.
+// The entry is marked as lifted once the unblacklist is mined.
+func (l *BlacklistLifecycle) expireProvisional() {
+	var entries []models.BlacklistedAddress
+	if err := l.db.Where("status = ? AND hold_type = ? AND expires_at <= ?", "provisional", HoldAutomated, time.Now()).
+		Find(&entries).Error; err != nil {
+		log.Printf("Error querying expired provisional blacklists: %v", err)
+		return
+	}
+
+	for _, entry := range entries {
+		queued, err := EnqueueUnblacklist(l.db, models.EnforcementAction{
+			Address:       entry.Address,
+			Direction:     entry.Direction,
+			Source:        "expiry",
+			Reason:        fmt.Sprintf("Provisional freeze expired at %s without confirmation", entry.ExpiresAt.Format(time.RFC3339)),
+			OnChainReason: "provisional freeze expired",
+			Severity:      entry.Severity,
+		})
+		if err != nil {
+			log.Printf("Error queueing expiry of %s: %v", entry.Address, err)
+			continue
+		}
+		if queued {
+			log.Printf("Provisional blacklist of %s expired, unblacklist queued", entry.Address)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"token-monitor/models"
+)
+
+func TestRemoveDirection(t *testing.T) {
+	cases := []struct {
+		existing, lifted, want string
+	}{
+		{DirectionBoth, DirectionBoth, ""},
+		{"", DirectionBoth, ""},
+		{DirectionBoth, DirectionFrom, DirectionTo},
+		{DirectionBoth, DirectionTo, DirectionFrom},
+		{DirectionFrom, DirectionFrom, ""},
+		{DirectionFrom, DirectionTo, DirectionFrom},
+	}
+	for _, c := range cases {
+		if got := removeDirection(c.existing, c.lifted); got != c.want {
+			t.Errorf("removeDirection(%q, %q) = %q, want %q", c.existing, c.lifted, got, c.want)
+		}
+	}
+}
+
+func TestLifecycleDates(t *testing.T) {
+	policy := LifecyclePolicy{
+		ProvisionalTTL:               72 * time.Hour,
+		ReviewInterval:               90 * 24 * time.Hour,
+		LawEnforcementReviewInterval: 180 * 24 * time.Hour,
+	}
+	created := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	entry := models.BlacklistedAddress{Status: "provisional", HoldType: HoldAutomated}
+	entry.CreatedAt = created
+	expiresAt, reviewAt := lifecycleDates(policy, entry)
+	if expiresAt == nil || !expiresAt.Equal(created.Add(72*time.Hour)) || reviewAt != nil {
+		t.Errorf("provisional: got expiry %v, review %v", expiresAt, reviewAt)
+	}
+
+	confirmed := created.Add(24 * time.Hour)
+	entry = models.BlacklistedAddress{Status: "confirmed", HoldType: HoldLawEnforcement, ConfirmedAt: &confirmed}
+	entry.CreatedAt = created
+	expiresAt, reviewAt = lifecycleDates(policy, entry)
+	if expiresAt != nil || reviewAt == nil || !reviewAt.Equal(confirmed.Add(180*24*time.Hour)) {
+		t.Errorf("law enforcement: got expiry %v, review %v", expiresAt, reviewAt)
+	}
+
+	explicit := created.Add(time.Hour)
+	entry = models.BlacklistedAddress{Status: "confirmed", HoldType: HoldManual, ReviewAt: &explicit}
+	if _, reviewAt = lifecycleDates(policy, entry); reviewAt != &explicit {
+		t.Errorf("explicit review date was replaced: %v", reviewAt)
+	}
+}
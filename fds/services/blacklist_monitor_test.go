@@ -0,0 +1,92 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"token-monitor/models"
+)
+
+func TestBlacklistMonitorSkipsLiftedAddresses(t *testing.T) {
+	db := testDB(t, &models.SuspiciousTransfer{}, &models.BlacklistedAddress{}, &models.BlacklistStatusChange{},
+		&models.EnforcementAction{}, &models.Rule{}, &models.RuleViolation{})
+	monitor := NewBlacklistMonitor(db, time.Second)
+
+	const address = "0x722122dF12D4e14e13Ac3b6895a86e84145b6967"
+	queued := func() int64 {
+		var count int64
+		db.Model(&models.EnforcementAction{}).Where("address = ? AND action = ?", address, "blacklist").Count(&count)
+		return count
+	}
+
+	// A provisional freeze from a flagged transfer, and a transfer found while it was in place
+	db.Create(&models.SuspiciousTransfer{To: address, TxHash: "0x01", Severity: "high", IsBlacklisted: true})
+	earlier := models.SuspiciousTransfer{To: address, TxHash: "0x02", Severity: "high"}
+	earlier.CreatedAt = time.Now().Add(-time.Hour)
+	db.Create(&earlier)
+	db.Create(&models.BlacklistedAddress{Address: address, TxHash: "0x03", BlockNumber: 1, Direction: DirectionBoth,
+		Status: "provisional", HoldType: HoldAutomated})
+
+	// The freeze expired and its unblacklist was mined
+	if err := recordLifted(db, models.EnforcementAction{Address: address, Action: "unblacklist", Direction: DirectionBoth,
+		Source: "expiry", Reason: "Provisional freeze expired"}); err != nil {
+		t.Fatalf("recording the lift: %v", err)
+	}
+	monitor.processNewSuspiciousAddresses()
+	if n := queued(); n != 0 {
+		t.Fatalf("lifted address queued again: %d blacklist actions", n)
+	}
+	var flagged models.SuspiciousTransfer
+	db.Where("tx_hash = ?", "0x01").First(&flagged)
+	if !flagged.IsBlacklisted {
+		t.Errorf("lift cleared the blacklisted flag of the transfer that led to it")
+	}
+
+	// A new violation after the lift blacklists the address again
+	db.Create(&models.SuspiciousTransfer{To: address, TxHash: "0x04", Severity: "high"})
+	monitor.processNewSuspiciousAddresses()
+	if n := queued(); n != 1 {
+		t.Errorf("new violation queued %d blacklist actions, want 1", n)
+	}
+}
+
+func TestBlacklistMonitorHonoursClosedActions(t *testing.T) {
+	db := testDB(t, &models.SuspiciousTransfer{}, &models.BlacklistedAddress{}, &models.BlacklistStatusChange{},
+		&models.EnforcementAction{}, &models.Rule{}, &models.RuleViolation{})
+	monitor := NewBlacklistMonitor(db, time.Second)
+
+	cases := []struct{ status, address string }{
+		{"rejected", "0x722122dF12D4e14e13Ac3b6895a86e84145b6967"},
+		{"failed", "0x8589427373D6D84E98730D7795D8f6f8731FDA16"},
+	}
+	for _, c := range cases {
+		status, address := c.status, c.address
+		queued := func() int64 {
+			var count int64
+			db.Model(&models.EnforcementAction{}).Where("address = ? AND action = ?", address, "blacklist").Count(&count)
+			return count
+		}
+
+		db.Create(&models.SuspiciousTransfer{To: address, TxHash: address + "01", Severity: "high"})
+		monitor.processNewSuspiciousAddresses()
+		if n := queued(); n != 1 {
+			t.Fatalf("%s: violation queued %d blacklist actions, want 1", status, n)
+		}
+
+		// An approver rejects the blacklist, or the executor gives up on it
+		now := time.Now()
+		db.Model(&models.EnforcementAction{}).Where("address = ?", address).
+			Updates(map[string]interface{}{"status": status, "completed_at": &now})
+		monitor.processNewSuspiciousAddresses()
+		if n := queued(); n != 1 {
+			t.Errorf("%s blacklist queued again: %d blacklist actions", status, n)
+		}
+
+		// A new violation asks again
+		db.Create(&models.SuspiciousTransfer{To: address, TxHash: address + "02", Severity: "high"})
+		monitor.processNewSuspiciousAddresses()
+		if n := queued(); n != 2 {
+			t.Errorf("%s: new violation queued %d blacklist actions, want 2", status, n)
+		}
+	}
+}
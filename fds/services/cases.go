@@ -0,0 +1,251 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"token-monitor/models"
+
+	"github.com/ethereum/go-ethereum/common"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Case statuses that still take new findings
+var activeCaseStatuses = []string{"open", "investigating", "escalated"}
+
+// severityRank orders severities so a case carries the highest one of its findings
+func severityRank(severity string) int {
+	switch severity {
+	case "high":
+		return 2
+	case "medium":
+		return 1
+	}
+	return 0
+}
+
+// caseAddresses returns the checksummed addresses a finding links to a case, leaving out
+// the zero address (mints and burns) and whitelisted addresses, which would otherwise merge
+// unrelated cases
+func caseAddresses(db *gorm.DB, addresses ...string) []string {
+	var whitelisted []string
+	if err := db.Model(&models.WhitelistAddress{}).
+		Where("status = ? AND (expires_at IS NULL OR expires_at > ?)", "active", time.Now()).
+		Pluck("address", &whitelisted).Error; err != nil {
+		log.Printf("Error querying whitelist: %v", err)
+	}
+	skip := make(map[string]bool)
+	for _, addr := range whitelisted {
+		skip[common.HexToAddress(addr).Hex()] = true
+	}
+	skip[common.Address{}.Hex()] = true
+
+	var result []string
+	for _, addr := range addresses {
+		if !common.IsHexAddress(addr) {
+			continue
+		}
+		addr = common.HexToAddress(addr).Hex()
+		if !skip[addr] {
+			result = append(result, addr)
+			skip[addr] = true
+		}
+	}
+	return result
+}
+
+// CaseFromSuspiciousTransfer files the suspicious transfer of txHash, its rule violations
+// and addresses into a case. A new case is opened unless active cases already hold one of
+// the addresses; those cases are merged into the oldest of them, which receives the finding.
+func CaseFromSuspiciousTransfer(db *gorm.DB, txHash string) (*models.Case, error) {
+	var transfer models.SuspiciousTransfer
+	if err := db.Where("tx_hash = ?", txHash).First(&transfer).Error; err != nil {
+		return nil, fmt.Errorf("error loading suspicious transfer %s: %w", txHash, err)
+	}
+
+	var violationIDs []uint
+	if err := db.Model(&models.RuleViolation{}).Where("tx_hash = ?", txHash).Pluck("id", &violationIDs).Error; err != nil {
+		return nil, fmt.Errorf("error loading violations of %s: %w", txHash, err)
+	}
+
+	addresses := caseAddresses(db, transfer.From, transfer.To)
+
+	var result models.Case
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var caseIDs []uint
+		if len(addresses) > 0 {
+			if err := tx.Table("case_addresses").
+				Joins("JOIN cases ON cases.id = case_addresses.case_id").
+				Where("case_addresses.address IN ? AND cases.status IN ? AND case_addresses.deleted_at IS NULL AND cases.deleted_at IS NULL",
+					addresses, activeCaseStatuses).
+				Distinct().
+				Pluck("case_addresses.case_id", &caseIDs).Error; err != nil {
+				return err
+			}
+		}
+		sort.Slice(caseIDs, func(i, j int) bool { return caseIDs[i] < caseIDs[j] })
+
+		if len(caseIDs) == 0 {
+			result = models.Case{
+				Title:    fmt.Sprintf("%s: %s", transfer.To, transfer.Reason),
+				Status:   "open",
+				Severity: transfer.Severity,
+				Summary:  transfer.Reason,
+			}
+			if err := tx.Create(&result).Error; err != nil {
+				return err
+			}
+			if err := addCaseEvent(tx, result.ID, "analyzer", "created", "", "open", fmt.Sprintf("Opened for suspicious transfer %s", txHash)); err != nil {
+				return err
+			}
+		} else {
+			if err := tx.First(&result, caseIDs[0]).Error; err != nil {
+				return err
+			}
+			for _, sourceID := range caseIDs[1:] {
+				if err := mergeCase(tx, sourceID, &result, "analyzer"); err != nil {
+					return err
+				}
+			}
+		}
+
+		return addFinding(tx, &result, transfer, violationIDs, addresses)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error filing %s into a case: %w", txHash, err)
+	}
+	return &result, nil
+}
+
+// addFinding links a suspicious transfer, its violations and addresses to c
+func addFinding(tx *gorm.DB, c *models.Case, transfer models.SuspiciousTransfer, violationIDs []uint, addresses []string) error {
+	link := tx.Clauses(clause.OnConflict{DoNothing: true})
+
+	result := link.Create(&models.CaseTransfer{CaseID: c.ID, SuspiciousTransferID: transfer.ID, TxHash: transfer.TxHash})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		if err := addCaseEvent(tx, c.ID, "analyzer", "transfer_added", "", transfer.TxHash, transfer.Reason); err != nil {
+			return err
+		}
+	}
+
+	for _, id := range violationIDs {
+		if err := link.Create(&models.CaseViolation{CaseID: c.ID, RuleViolationID: id}).Error; err != nil {
+			return err
+		}
+	}
+
+	for _, addr := range addresses {
+		result := link.Create(&models.CaseAddress{CaseID: c.ID, Address: addr})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			if err := addCaseEvent(tx, c.ID, "analyzer", "address_added", "", addr, ""); err != nil {
+				return err
+			}
+		}
+	}
+
+	if severityRank(transfer.Severity) > severityRank(c.Severity) {
+		if err := addCaseEvent(tx, c.ID, "analyzer", "severity_changed", c.Severity, transfer.Severity, ""); err != nil {
+			return err
+		}
+		if err := tx.Model(c).Update("severity", transfer.Severity).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mergeCase moves the findings, notes and attachments of case sourceID into target and
+// marks the source as merged. Each case's history records the merge.
+func mergeCase(tx *gorm.DB, sourceID uint, target *models.Case, actor string) error {
+	var source models.Case
+	if err := tx.First(&source, sourceID).Error; err != nil {
+		return err
+	}
+	link := tx.Clauses(clause.OnConflict{DoNothing: true})
+
+	var addresses []models.CaseAddress
+	if err := tx.Where("case_id = ?", source.ID).Find(&addresses).Error; err != nil {
+		return err
+	}
+	for _, a := range addresses {
+		if err := link.Create(&models.CaseAddress{CaseID: target.ID, Address: a.Address}).Error; err != nil {
+			return err
+		}
+	}
+
+	var transfers []models.CaseTransfer
+	if err := tx.Where("case_id = ?", source.ID).Find(&transfers).Error; err != nil {
+		return err
+	}
+	for _, t := range transfers {
+		if err := link.Create(&models.CaseTransfer{CaseID: target.ID, SuspiciousTransferID: t.SuspiciousTransferID, TxHash: t.TxHash}).Error; err != nil {
+			return err
+		}
+	}
+
+	var violations []models.CaseViolation
+	if err := tx.Where("case_id = ?", source.ID).Find(&violations).Error; err != nil {
+		return err
+	}
+	for _, v := range violations {
+		if err := link.Create(&models.CaseViolation{CaseID: target.ID, RuleViolationID: v.RuleViolationID}).Error; err != nil {
+			return err
+		}
+	}
+
+	for _, model := range []interface{}{&models.CaseNote{}, &models.CaseAttachment{}} {
+		if err := tx.Model(model).Where("case_id = ?", source.ID).Update("case_id", target.ID).Error; err != nil {
+			return err
+		}
+	}
+
+	// Updates writes the new status back into source
+	prevStatus := source.Status
+	if err := tx.Model(&source).Updates(map[string]interface{}{
+		"status":         "merged",
+		"merged_into_id": target.ID,
+	}).Error; err != nil {
+		return err
+	}
+
+	// The target keeps the stronger severity and, if it has none, the source's assignee
+	updates := map[string]interface{}{}
+	if severityRank(source.Severity) > severityRank(target.Severity) {
+		updates["severity"] = source.Severity
+	}
+	if target.Assignee == "" && source.Assignee != "" {
+		updates["assignee"] = source.Assignee
+	}
+	if len(updates) > 0 {
+		if err := tx.Model(target).Updates(updates).Error; err != nil {
+			return err
+		}
+	}
+
+	if err := addCaseEvent(tx, source.ID, actor, "merged", prevStatus, "merged", fmt.Sprintf("Merged into case %d", target.ID)); err != nil {
+		return err
+	}
+	log.Printf("Merged case %d into case %d", source.ID, target.ID)
+	return addCaseEvent(tx, target.ID, actor, "merged", "", fmt.Sprintf("%d", source.ID), fmt.Sprintf("Case %d merged in on shared addresses", source.ID))
+}
+
+// addCaseEvent appends an entry to a case's audit history
+func addCaseEvent(tx *gorm.DB, caseID uint, actor, action, from, to, details string) error {
+	return tx.Create(&models.CaseEvent{
+		CaseID:    caseID,
+		Actor:     actor,
+		Action:    action,
+		FromValue: from,
+		ToValue:   to,
+		Details:   details,
+	}).Error
+}
@@ -0,0 +1,31 @@
+package services
+
+import (
+	"testing"
+
+	"token-monitor/models"
+)
+
+func TestMergeCaseLogsPreviousStatus(t *testing.T) {
+	db := testDB(t, &models.Case{}, &models.CaseAddress{}, &models.CaseTransfer{}, &models.CaseViolation{},
+		&models.CaseNote{}, &models.CaseAttachment{}, &models.CaseEvent{})
+	source := models.Case{Title: "source", Status: "investigating", Severity: "high"}
+	target := models.Case{Title: "target", Status: "open", Severity: "medium"}
+	db.Create(&source)
+	db.Create(&target)
+
+	if err := mergeCase(db, source.ID, &target, "analyzer"); err != nil {
+		t.Fatalf("mergeCase: %v", err)
+	}
+	var event models.CaseEvent
+	if err := db.Where("case_id = ? AND action = ?", source.ID, "merged").First(&event).Error; err != nil {
+		t.Fatalf("no merge event on the source case: %v", err)
+	}
+	if event.FromValue != "investigating" || event.ToValue != "merged" {
+		t.Errorf("merge logged %s -> %s, want investigating -> merged", event.FromValue, event.ToValue)
+	}
+	db.First(&target, target.ID)
+	if target.Severity != "high" {
+		t.Errorf("target severity %s, want the source's high", target.Severity)
+	}
+}
@@ -0,0 +1,307 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"token-monitor/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Reporting periods of large-value transaction reports
+const (
+	PeriodDaily   = "daily"
+	PeriodWeekly  = "weekly"
+	PeriodMonthly = "monthly"
+)
+
+// ctrBackfill is how many missed periods of each kind are generated at most, counting
+// back from the last completed one
+const ctrBackfill = 7
+
+// periodBounds returns the start and end of the period of kind that contains t, in loc.
+// Weeks start on Monday.
+func periodBounds(kind string, t time.Time, loc *time.Location) (time.Time, time.Time) {
+	t = t.In(loc)
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	switch kind {
+	case PeriodWeekly:
+		start := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+		return start, start.AddDate(0, 0, 7)
+	case PeriodMonthly:
+		start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc)
+		return start, start.AddDate(0, 1, 0)
+	}
+	return day, day.AddDate(0, 0, 1)
+}
+
+// CtrDocument is the content of a large-value transaction report
+type CtrDocument struct {
+	Period        string     `json:"period"`
+	PeriodStart   time.Time  `json:"period_start"`
+	PeriodEnd     time.Time  `json:"period_end"`
+	Threshold     string     `json:"threshold"`
+	TransferCount int        `json:"transfer_count"`
+	EntityCount   int        `json:"entity_count"`
+	TotalAmount   string     `json:"total_amount"`
+	Groups        []CtrGroup `json:"groups"`
+}
+
+// CtrGroup holds the reported transfers of one entity and transaction type
+type CtrGroup struct {
+	Entity        string        `json:"entity"`  // Originating address
+	TxType        string        `json:"tx_type"` // "transfer" for plain transfers
+	TransferCount int           `json:"transfer_count"`
+	TotalAmount   string        `json:"total_amount"`
+	Transfers     []CtrTransfer `json:"transfers"`
+}
+
+// CtrTransfer is a reported transfer
+type CtrTransfer struct {
+	TxHash      string    `json:"tx_hash"`
+	BlockNumber uint64    `json:"block_number"`
+	Timestamp   time.Time `json:"timestamp"`
+	From        string    `json:"from"`
+	To          string    `json:"to"`
+	Amount      string    `json:"amount"`
+}
+
+// buildCtrDocument groups the transfers of at least threshold by originating entity and
+// transaction type. Groups and transfers are sorted, so the same transactions always give
+// the same document.
+func buildCtrDocument(period string, start, end time.Time, threshold *big.Int, txs []models.Transaction) CtrDocument {
+	doc := CtrDocument{
+		Period:      period,
+		PeriodStart: start,
+		PeriodEnd:   end,
+		Threshold:   threshold.String(),
+		Groups:      []CtrGroup{},
+	}
+
+	type groupKey struct{ entity, txType string }
+	groups := make(map[groupKey]*CtrGroup)
+	totals := make(map[groupKey]*big.Int)
+	entities := make(map[string]bool)
+	total := new(big.Int)
+
+	for _, tx := range txs {
+		amount, ok := new(big.Int).SetString(tx.Value, 10)
+		if !ok || amount.Cmp(threshold) < 0 {
+			continue
+		}
+		txType := tx.TxType
+		if txType == "" {
+			txType = "transfer"
+		}
+		key := groupKey{tx.From, txType}
+		if groups[key] == nil {
+			groups[key] = &CtrGroup{Entity: tx.From, TxType: txType}
+			totals[key] = new(big.Int)
+		}
+		groups[key].Transfers = append(groups[key].Transfers, CtrTransfer{
+			TxHash:      tx.Hash,
+			BlockNumber: tx.BlockNumber,
+			Timestamp:   tx.Timestamp.In(start.Location()),
+			From:        tx.From,
+			To:          tx.To,
+			Amount:      amount.String(),
+		})
+		totals[key].Add(totals[key], amount)
+		total.Add(total, amount)
+		entities[tx.From] = true
+		doc.TransferCount++
+	}
+
+	for key, group := range groups {
+		sort.Slice(group.Transfers, func(i, j int) bool {
+			if group.Transfers[i].BlockNumber != group.Transfers[j].BlockNumber {
+				return group.Transfers[i].BlockNumber < group.Transfers[j].BlockNumber
+			}
+			return group.Transfers[i].TxHash < group.Transfers[j].TxHash
+		})
+		group.TransferCount = len(group.Transfers)
+		group.TotalAmount = totals[key].String()
+		doc.Groups = append(doc.Groups, *group)
+	}
+	sort.Slice(doc.Groups, func(i, j int) bool {
+		if doc.Groups[i].Entity != doc.Groups[j].Entity {
+			return doc.Groups[i].Entity < doc.Groups[j].Entity
+		}
+		return doc.Groups[i].TxType < doc.Groups[j].TxType
+	})
+
+	doc.EntityCount = len(entities)
+	doc.TotalAmount = total.String()
+	return doc
+}
+
+// CtrReporter generates the large-value transaction report of every completed period
+type CtrReporter struct {
+	db        *gorm.DB
+	threshold *big.Int
+	dir       string
+	periods   []string
+	loc       *time.Location
+	interval  time.Duration
+	stopChan  chan struct{}
+	wg        sync.WaitGroup
+}
+
+// NewCtrReporter creates a new large-value transaction reporter. threshold is in token
+// base units; periods are reckoned at utcOffsetHours.
+func NewCtrReporter(db *gorm.DB, threshold, dir string, periods []string, utcOffsetHours int, interval time.Duration) (*CtrReporter, error) {
+	value, ok := new(big.Int).SetString(threshold, 10)
+	if !ok || value.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid CTR threshold %q", threshold)
+	}
+	for _, period := range periods {
+		if period != PeriodDaily && period != PeriodWeekly && period != PeriodMonthly {
+			return nil, fmt.Errorf("invalid CTR period %q", period)
+		}
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("error creating CTR report directory: %w", err)
+	}
+
+	return &CtrReporter{
+		db:        db,
+		threshold: value,
+		dir:       dir,
+		periods:   periods,
+		loc:       time.FixedZone(fmt.Sprintf("UTC%+d", utcOffsetHours), utcOffsetHours*3600),
+		interval:  interval,
+		stopChan:  make(chan struct{}),
+	}, nil
+}
+
+// Start begins generating reports as periods complete
+func (r *CtrReporter) Start(ctx context.Context) {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+
+		r.generateDue(time.Now())
+		for {
+			select {
+			case <-ticker.C:
+				r.generateDue(time.Now())
+			case <-ctx.Done():
+				return
+			case <-r.stopChan:
+				return
+			}
+		}
+	}()
+}
+
+// Stop gracefully stops the reporter
+func (r *CtrReporter) Stop() {
+	close(r.stopChan)
+	r.wg.Wait()
+}
+
+// generateDue generates the reports of completed periods that have none, going back at
+// most ctrBackfill periods
+func (r *CtrReporter) generateDue(now time.Time) {
+	for _, period := range r.periods {
+		current, _ := periodBounds(period, now, r.loc)
+
+		var due []time.Time
+		start := current
+		for i := 0; i < ctrBackfill; i++ {
+			start, _ = periodBounds(period, start.Add(-time.Nanosecond), r.loc)
+			var count int64
+			if err := r.db.Model(&models.CtrReport{}).Where("period = ? AND period_start = ?", period, start).Count(&count).Error; err != nil {
+				log.Printf("Error checking %s CTR report of %s: %v", period, start.Format("2006-01-02"), err)
+				break
+			}
+			if count > 0 {
+				break
+			}
+			due = append(due, start)
+		}
+
+		// Oldest first
+		for i := len(due) - 1; i >= 0; i-- {
+			if err := r.generate(period, due[i]); err != nil {
+				log.Printf("Error generating %s CTR report of %s: %v", period, due[i].Format("2006-01-02"), err)
+				break
+			}
+		}
+	}
+}
+
+// generate writes the report of the period starting at start, with a sha256sum file next
+// to it, and records it
+func (r *CtrReporter) generate(period string, start time.Time) error {
+	_, end := periodBounds(period, start, r.loc)
+
+	// Confirmed transactions are pruned from analysis but kept soft-deleted, so the report
+	// reads them unscoped
+	var txs []models.Transaction
+	if err := r.db.Unscoped().
+		Where("status = ? AND timestamp >= ? AND timestamp < ? AND value ~ '^[0-9]+$' AND CAST(value AS NUMERIC) >= CAST(? AS NUMERIC)",
+			"confirmed", start, end, r.threshold.String()).
+		Order("block_number, hash").
+		Find(&txs).Error; err != nil {
+		return err
+	}
+
+	doc := buildCtrDocument(period, start, end, r.threshold, txs)
+	content, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	content = append(content, '\n')
+	sum := sha256.Sum256(content)
+	checksum := hex.EncodeToString(sum[:])
+
+	fileName := fmt.Sprintf("ctr-%s-%s.json", period, start.Format("20060102"))
+	if err := writeFileAtomic(filepath.Join(r.dir, fileName), content); err != nil {
+		return err
+	}
+	if err := writeFileAtomic(filepath.Join(r.dir, fileName+".sha256"), []byte(checksum+"  "+fileName+"\n")); err != nil {
+		return err
+	}
+
+	report := models.CtrReport{
+		Period:        period,
+		PeriodStart:   start,
+		PeriodEnd:     end,
+		Threshold:     doc.Threshold,
+		TransferCount: doc.TransferCount,
+		EntityCount:   doc.EntityCount,
+		TotalAmount:   doc.TotalAmount,
+		FileName:      fileName,
+		Size:          int64(len(content)),
+		SHA256:        checksum,
+	}
+	if err := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&report).Error; err != nil {
+		return err
+	}
+	log.Printf("Generated %s CTR report %s: %d transfers of %d entities", period, fileName, doc.TransferCount, doc.EntityCount)
+	return nil
+}
+
+// writeFileAtomic writes a file through a temporary file, so readers never see it partially written
+func writeFileAtomic(path string, content []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, content, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
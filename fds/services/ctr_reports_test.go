@@ -0,0 +1,70 @@
+package services
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"token-monitor/models"
+)
+
+func TestPeriodBounds(t *testing.T) {
+	loc := time.FixedZone("UTC+7", 7*3600)
+	// 2026-03-04 20:00 UTC is Thursday 2026-03-05 03:00 at UTC+7
+	at := time.Date(2026, 3, 4, 20, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		kind       string
+		start, end time.Time
+	}{
+		{PeriodDaily, time.Date(2026, 3, 5, 0, 0, 0, 0, loc), time.Date(2026, 3, 6, 0, 0, 0, 0, loc)},
+		{PeriodWeekly, time.Date(2026, 3, 2, 0, 0, 0, 0, loc), time.Date(2026, 3, 9, 0, 0, 0, 0, loc)},
+		{PeriodMonthly, time.Date(2026, 3, 1, 0, 0, 0, 0, loc), time.Date(2026, 4, 1, 0, 0, 0, 0, loc)},
+	}
+	for _, c := range cases {
+		start, end := periodBounds(c.kind, at, loc)
+		if !start.Equal(c.start) || !end.Equal(c.end) {
+			t.Errorf("%s: got [%v, %v), want [%v, %v)", c.kind, start, end, c.start, c.end)
+		}
+	}
+}
+
+func TestBuildCtrDocument(t *testing.T) {
+	start := time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC)
+	txs := []models.Transaction{
+		{Hash: "0x03", From: "0xB", To: "0xA", Value: "500", BlockNumber: 3},
+		{Hash: "0x02", From: "0xA", To: "0xC", Value: "200", BlockNumber: 2, TxType: "1"},
+		{Hash: "0x01", From: "0xA", To: "0xB", Value: "100", BlockNumber: 2, TxType: "1"},
+		{Hash: "0x04", From: "0xA", To: "0xB", Value: "99", BlockNumber: 1},
+		{Hash: "0x05", From: "0xA", To: "0xB", Value: "300", BlockNumber: 4},
+	}
+
+	doc := buildCtrDocument(PeriodDaily, start, start.AddDate(0, 0, 1), big.NewInt(100), txs)
+	if doc.TransferCount != 4 || doc.EntityCount != 2 || doc.TotalAmount != "1100" {
+		t.Fatalf("got %d transfers of %d entities totalling %s", doc.TransferCount, doc.EntityCount, doc.TotalAmount)
+	}
+
+	want := []struct {
+		entity, txType, total string
+		hashes                []string
+	}{
+		{"0xA", "1", "300", []string{"0x01", "0x02"}},
+		{"0xA", "transfer", "300", []string{"0x05"}},
+		{"0xB", "transfer", "500", []string{"0x03"}},
+	}
+	if len(doc.Groups) != len(want) {
+		t.Fatalf("got %d groups, want %d", len(doc.Groups), len(want))
+	}
+	for i, w := range want {
+		g := doc.Groups[i]
+		if g.Entity != w.entity || g.TxType != w.txType || g.TotalAmount != w.total || len(g.Transfers) != len(w.hashes) {
+			t.Errorf("group %d: got %s/%s total %s with %d transfers", i, g.Entity, g.TxType, g.TotalAmount, len(g.Transfers))
+			continue
+		}
+		for j, hash := range w.hashes {
+			if g.Transfers[j].TxHash != hash {
+				t.Errorf("group %d transfer %d: got %s, want %s", i, j, g.Transfers[j].TxHash, hash)
+			}
+		}
+	}
+}
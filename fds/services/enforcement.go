@@ -0,0 +1,690 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"token-monitor/audit"
+	"token-monitor/blacklist"
+	"token-monitor/contracts/restrict"
+	"token-monitor/models"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"gorm.io/gorm"
+)
+
+// Blacklist directions, matching blacklist, blacklistFrom and blacklistTo of AddressRestrictionCompliance
+const (
+	DirectionBoth = blacklist.DirectionBoth
+	DirectionFrom = blacklist.DirectionFrom
+	DirectionTo   = blacklist.DirectionTo
+)
+
+// ruleBlacklistDirection reads the blacklist direction from a rule's actions JSON
+// ("blacklist_direction"), defaulting to both directions
+func ruleBlacklistDirection(actions string) string {
+	var parsed struct {
+		BlacklistDirection string `json:"blacklist_direction"`
+	}
+	if err := json.Unmarshal([]byte(actions), &parsed); err != nil {
+		return DirectionBoth
+	}
+	switch parsed.BlacklistDirection {
+	case DirectionFrom, DirectionTo:
+		return parsed.BlacklistDirection
+	}
+	return DirectionBoth
+}
+
+// Enforcement policies a rule can set in its actions JSON ("enforcement")
+const (
+	PolicyAuto            = "auto"             // Blacklist without review
+	PolicyRequireApproval = "require_approval" // Queue the blacklist until an officer approves it
+	PolicyAlertOnly       = "alert_only"       // Record the violation without blacklisting
+)
+
+// Approvals needed before an action is sent: blacklists held by policy need one officer,
+// unblacklists need two distinct officers (four-eyes)
+const (
+	blacklistApprovals   = 1
+	unblacklistApprovals = 2
+)
+
+// ruleEnforcementPolicy reads the enforcement policy from a rule's actions JSON
+// ("enforcement"), defaulting to auto
+func ruleEnforcementPolicy(actions string) string {
+	var parsed struct {
+		Enforcement string `json:"enforcement"`
+	}
+	if err := json.Unmarshal([]byte(actions), &parsed); err != nil {
+		return PolicyAuto
+	}
+	switch parsed.Enforcement {
+	case PolicyRequireApproval, PolicyAlertOnly:
+		return parsed.Enforcement
+	}
+	return PolicyAuto
+}
+
+// strongerPolicy returns the policy that enforces more directly: a rule that blacklists
+// automatically is not held back by another rule that only alerts
+func strongerPolicy(a, b string) string {
+	rank := map[string]int{PolicyAlertOnly: 0, PolicyRequireApproval: 1, PolicyAuto: 2}
+	if a == "" || rank[b] > rank[a] {
+		return b
+	}
+	return a
+}
+
+// applyPolicy prepares action for policy and reports whether it should be queued at all
+func applyPolicy(action *models.EnforcementAction, policy string) bool {
+	switch policy {
+	case PolicyAlertOnly:
+		return false
+	case PolicyRequireApproval:
+		action.RequiredApprovals = blacklistApprovals
+	}
+	return true
+}
+
+// mergeDirections returns the direction that covers both a and b
+func mergeDirections(a, b string) string {
+	if a == "" {
+		return b
+	}
+	if b == "" || a == b {
+		return a
+	}
+	return DirectionBoth
+}
+
+// coversDirection reports whether an existing restriction also covers the requested one.
+// Entries recorded before directions were tracked restrict both directions.
+func coversDirection(existing, requested string) bool {
+	return blacklist.CoversDirection(existing, requested)
+}
+
+// violationEnforcement derives the blacklist direction, the on-chain reason and the
+// enforcement policy from the high severity rules a transaction violated. The reason lists
+// each enforcing rule with its violation ID, e.g. "large_transfer#42, suspicious_address#43".
+// Alert-only rules do not contribute to the direction or reason; suppressed violations are
+// ignored.
+func violationEnforcement(db *gorm.DB, txHash string) (string, string, string) {
+	var violations []struct {
+		ID      uint
+		Name    string
+		Actions string
+	}
+	if err := db.Table("rule_violations").
+		Select("rule_violations.id, rules.name, rules.actions").
+		Joins("JOIN rules ON rules.id = rule_violations.rule_id").
+		Where("rule_violations.tx_hash = ? AND rules.severity = ? AND rule_violations.suppressed IS NOT TRUE AND rule_violations.deleted_at IS NULL", txHash, "high").
+		Order("rule_violations.id").
+		Scan(&violations).Error; err != nil {
+		log.Printf("Error querying violations of %s: %v", txHash, err)
+		return DirectionBoth, "", PolicyAuto
+	}
+	if len(violations) == 0 {
+		return DirectionBoth, "", PolicyAuto
+	}
+
+	direction, policy := "", ""
+	var reasons []string
+	for _, v := range violations {
+		rulePolicy := ruleEnforcementPolicy(v.Actions)
+		policy = strongerPolicy(policy, rulePolicy)
+		if rulePolicy == PolicyAlertOnly {
+			continue
+		}
+		direction = mergeDirections(direction, ruleBlacklistDirection(v.Actions))
+		reasons = append(reasons, fmt.Sprintf("%s#%d", v.Name, v.ID))
+	}
+	if direction == "" {
+		direction = DirectionBoth
+	}
+	return direction, strings.Join(reasons, ", "), policy
+}
+
+// recordBlacklisted stores the address of a mined blacklist action, widening the direction
+// and hold of an existing entry when needed and reviving a lifted one
+func recordBlacklisted(db *gorm.DB, action models.EnforcementAction, txHash string, blockNumber uint64) error {
+	holdType := action.HoldType
+	if holdType == "" {
+		holdType = HoldAutomated
+	}
+	status := initialStatus(holdType)
+
+	var existing models.BlacklistedAddress
+	err := db.Unscoped().Where("address = ?", action.Address).First(&existing).Error
+	if err == gorm.ErrRecordNotFound {
+		if err := db.Create(&models.BlacklistedAddress{
+			Address:        action.Address,
+			TxHash:         txHash,
+			BlockNumber:    blockNumber,
+			Direction:      action.Direction,
+			Reason:         action.Reason,
+			Severity:       action.Severity,
+			Details:        action.Details,
+			Status:         status,
+			HoldType:       holdType,
+			LegalReference: action.LegalReference,
+		}).Error; err != nil {
+			return err
+		}
+		return auditStatusChange(db, action.Address, "", status, actionActor(action), action.Reason, action.ID)
+	}
+	if err != nil {
+		return err
+	}
+
+	// A lifted entry starts a new lifecycle
+	if existing.DeletedAt.Valid {
+		if err := db.Unscoped().Model(&existing).Updates(map[string]interface{}{
+			"deleted_at":      nil,
+			"tx_hash":         txHash,
+			"block_number":    blockNumber,
+			"direction":       action.Direction,
+			"reason":          action.Reason,
+			"severity":        action.Severity,
+			"details":         action.Details,
+			"status":          status,
+			"hold_type":       holdType,
+			"legal_reference": action.LegalReference,
+			"expires_at":      nil,
+			"review_at":       nil,
+			"confirmed_by":    "",
+			"confirmed_at":    nil,
+			"lifted_at":       nil,
+			"lift_reason":     "",
+		}).Error; err != nil {
+			return err
+		}
+		return auditStatusChange(db, action.Address, existing.Status, status, actionActor(action), action.Reason, action.ID)
+	}
+
+	if !coversDirection(existing.Direction, action.Direction) {
+		if err := db.Model(&existing).Updates(map[string]interface{}{
+			"direction":    mergeDirections(existing.Direction, action.Direction),
+			"tx_hash":      txHash,
+			"block_number": blockNumber,
+			"reason":       existing.Reason + "; " + action.Reason,
+		}).Error; err != nil {
+			return err
+		}
+	}
+	return upgradeHold(db, &existing, action)
+}
+
+// EnqueueBlacklist queues a blacklist action for action.Address in action.Direction, unless
+// one is already in force or in flight; see blacklist.Enqueue. It reports whether a new
+// action was queued.
+func EnqueueBlacklist(db *gorm.DB, action models.EnforcementAction) (bool, error) {
+	return blacklist.Enqueue(db, action)
+}
+
+// NonceManager hands out nonces for the blacklist key so that concurrent senders
+// (the enforcement executor and the pre-emption tracker) never reuse a nonce
+type NonceManager struct {
+	client *ethclient.Client
+	from   common.Address
+	mu     sync.Mutex
+	next   uint64
+	synced bool
+}
+
+// NewNonceManager creates a nonce manager for the given sender
+func NewNonceManager(client *ethclient.Client, from common.Address) *NonceManager {
+	return &NonceManager{
+		client: client,
+		from:   from,
+	}
+}
+
+// Next returns the next unused nonce of the sender
+func (n *NonceManager) Next(ctx context.Context) (uint64, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	pending, err := n.client.PendingNonceAt(ctx, n.from)
+	if err != nil {
+		return 0, fmt.Errorf("error getting pending nonce: %w", err)
+	}
+	if !n.synced || pending > n.next {
+		n.next = pending
+		n.synced = true
+	}
+
+	nonce := n.next
+	n.next++
+	return nonce, nil
+}
+
+// Reset makes the next call resynchronise with the node, e.g. after a send failed and
+// left a gap in the allocated nonces
+func (n *NonceManager) Reset() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.synced = false
+}
+
+// EnforcementExecutor sends queued enforcement actions on-chain, batching blacklists of one
+// direction into a single call with per-address reasons, and follows them until they are
+// mined or fail
+type EnforcementExecutor struct {
+	db             *gorm.DB
+	client         *ethclient.Client
+	restrictClient *restrict.Restrict
+	ownerKey       *bind.TransactOpts
+	nonces         *NonceManager
+	interval       time.Duration
+	batchSize      int           // Number of addresses to blacklist in one transaction
+	maxAttempts    int           // Attempts before an action is marked as failed
+	retryBackoff   time.Duration // Delay before the first retry, doubled on every further attempt
+	stopChan       chan struct{}
+	wg             sync.WaitGroup
+}
+
+// NewEnforcementExecutor creates a new enforcement executor
+func NewEnforcementExecutor(db *gorm.DB, client *ethclient.Client, restrictClient *restrict.Restrict, ownerKey *bind.TransactOpts, nonces *NonceManager, maxAttempts int, retryBackoff time.Duration, interval time.Duration) *EnforcementExecutor {
+	return &EnforcementExecutor{
+		db:             db,
+		client:         client,
+		restrictClient: restrictClient,
+		ownerKey:       ownerKey,
+		nonces:         nonces,
+		interval:       interval,
+		batchSize:      10, // Default batch size for blacklisting
+		maxAttempts:    maxAttempts,
+		retryBackoff:   retryBackoff,
+		stopChan:       make(chan struct{}),
+	}
+}
+
+// Start begins executing queued enforcement actions
+func (e *EnforcementExecutor) Start(ctx context.Context) {
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		ticker := time.NewTicker(e.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				e.trackSubmitted(ctx)
+				e.submitPending(ctx)
+			case <-ctx.Done():
+				return
+			case <-e.stopChan:
+				return
+			}
+		}
+	}()
+}
+
+// Stop gracefully stops the executor
+func (e *EnforcementExecutor) Stop() {
+	close(e.stopChan)
+	e.wg.Wait()
+}
+
+// submitPending sends the next batch of pending actions in one transaction. A batch only
+// holds actions of one type and direction since each has its own contract call.
+func (e *EnforcementExecutor) submitPending(ctx context.Context) {
+	var first models.EnforcementAction
+	if err := e.db.Where("status = ? AND action IN ? AND next_attempt_at <= ?", "pending", []string{"blacklist", "unblacklist"}, time.Now()).
+		Order("id").
+		First(&first).Error; err != nil {
+		if err != gorm.ErrRecordNotFound {
+			log.Printf("Error querying pending enforcement actions: %v", err)
+		}
+		return
+	}
+
+	var actions []models.EnforcementAction
+	if err := e.db.Where("status = ? AND action = ? AND direction = ? AND next_attempt_at <= ?", "pending", first.Action, first.Direction, time.Now()).
+		Order("id").
+		Limit(e.batchSize).
+		Find(&actions).Error; err != nil {
+		log.Printf("Error querying pending enforcement actions: %v", err)
+		return
+	}
+
+	var batch []models.EnforcementAction
+	var send func(opts *bind.TransactOpts) (*types.Transaction, error)
+	if first.Action == "unblacklist" {
+		batch, send = e.unblacklistBatch(first, actions)
+	} else {
+		batch, send = e.blacklistBatch(ctx, first.Direction, actions)
+	}
+	if len(batch) == 0 {
+		return
+	}
+
+	nonce, err := e.nonces.Next(ctx)
+	if err != nil {
+		log.Printf("Error allocating nonce for %s batch: %v", first.Action, err)
+		return
+	}
+
+	opts := *e.ownerKey
+	opts.Context = ctx
+	opts.Nonce = new(big.Int).SetUint64(nonce)
+
+	tx, err := send(&opts)
+	if err != nil {
+		e.nonces.Reset()
+		log.Printf("Error sending %s batch of %d addresses: %v", first.Action, len(batch), err)
+		for i := range batch {
+			batch[i].Attempts++
+		}
+		e.retry(batch, err)
+		return
+	}
+
+	ids := make([]uint, len(batch))
+	for i, action := range batch {
+		ids[i] = action.ID
+	}
+	now := time.Now()
+	if err := e.db.Model(&models.EnforcementAction{}).
+		Where("id IN ?", ids).
+		Updates(map[string]interface{}{
+			"status":       "submitted",
+			"tx_hash":      tx.Hash().Hex(),
+			"nonce":        nonce,
+			"attempts":     gorm.Expr("attempts + 1"),
+			"last_error":   "",
+			"submitted_at": &now,
+		}).Error; err != nil {
+		log.Printf("Error updating submitted enforcement actions: %v", err)
+		return
+	}
+
+	log.Printf("%s transaction sent for %d addresses (direction %s): %s (nonce %d)", first.Action, len(batch), first.Direction, tx.Hash().Hex(), nonce)
+}
+
+// blacklistBatch selects the blacklist actions that still need a transaction and returns
+// them with the call that blacklists them, one reason per address
+func (e *EnforcementExecutor) blacklistBatch(ctx context.Context, direction string, actions []models.EnforcementAction) ([]models.EnforcementAction, func(*bind.TransactOpts) (*types.Transaction, error)) {
+	var batch []models.EnforcementAction
+	var addresses []common.Address
+	var reasons []string
+	for _, action := range actions {
+		// The address may have been blacklisted since it was queued, e.g. pre-emptively
+		var existing models.BlacklistedAddress
+		if err := e.db.Where("address = ?", action.Address).First(&existing).Error; err == nil && coversDirection(existing.Direction, action.Direction) {
+			e.markAlreadyRestricted(action, existing.TxHash, existing.BlockNumber)
+			log.Printf("Address %s is already blacklisted, skipping", action.Address)
+			continue
+		}
+
+		// Directional blacklists revert for addresses that are already restricted on-chain
+		if restricted, err := e.restrictedOnChain(ctx, action.Address, action.Direction); err == nil && restricted {
+			e.markAlreadyRestricted(action, "", 0)
+			log.Printf("Address %s is already restricted on-chain, skipping", action.Address)
+			continue
+		}
+
+		batch = append(batch, action)
+		addresses = append(addresses, common.HexToAddress(action.Address))
+		reasons = append(reasons, onChainReason(action))
+	}
+
+	return batch, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return e.sendBlacklist(opts, direction, addresses, reasons)
+	}
+}
+
+// unblacklistBatch returns the unblacklist actions that share the reason of first, since
+// the contract takes one reason per call, with the call that unblacklists them
+func (e *EnforcementExecutor) unblacklistBatch(first models.EnforcementAction, actions []models.EnforcementAction) ([]models.EnforcementAction, func(*bind.TransactOpts) (*types.Transaction, error)) {
+	reason := onChainReason(first)
+
+	var batch []models.EnforcementAction
+	var addresses []common.Address
+	for _, action := range actions {
+		if onChainReason(action) != reason {
+			continue
+		}
+		batch = append(batch, action)
+		addresses = append(addresses, common.HexToAddress(action.Address))
+	}
+
+	return batch, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		switch first.Direction {
+		case DirectionFrom:
+			return e.restrictClient.UnblacklistFrom(opts, addresses, reason)
+		case DirectionTo:
+			return e.restrictClient.UnblacklistTo(opts, addresses, reason)
+		default:
+			return e.restrictClient.Unblacklist(opts, addresses, reason)
+		}
+	}
+}
+
+// onChainReason returns the reason written to the contract for action
+func onChainReason(action models.EnforcementAction) string {
+	if action.OnChainReason != "" {
+		return action.OnChainReason
+	}
+	if action.Reason != "" {
+		return action.Reason
+	}
+	return "fds"
+}
+
+// sendBlacklist calls the blacklist function of the contract that matches direction,
+// writing one reason per address
+func (e *EnforcementExecutor) sendBlacklist(opts *bind.TransactOpts, direction string, addresses []common.Address, reasons []string) (*types.Transaction, error) {
+	switch direction {
+	case DirectionFrom:
+		return e.restrictClient.BlacklistFromWithReasons(opts, addresses, reasons)
+	case DirectionTo:
+		return e.restrictClient.BlacklistToWithReasons(opts, addresses, reasons)
+	default:
+		return e.restrictClient.BlacklistWithReasons(opts, addresses, reasons)
+	}
+}
+
+// restrictedOnChain reports whether address is already restricted in direction on-chain
+func (e *EnforcementExecutor) restrictedOnChain(ctx context.Context, address, direction string) (bool, error) {
+	opts := &bind.CallOpts{Context: ctx}
+	account := common.HexToAddress(address)
+
+	if direction != DirectionTo {
+		from, err := e.restrictClient.IsBlacklistedFrom(opts, account)
+		if err != nil || !from {
+			return false, err
+		}
+	}
+	if direction != DirectionFrom {
+		to, err := e.restrictClient.IsBlacklistedTo(opts, account)
+		if err != nil || !to {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// markAlreadyRestricted completes an action that needs no transaction because the
+// address is already restricted
+func (e *EnforcementExecutor) markAlreadyRestricted(action models.EnforcementAction, txHash string, blockNumber uint64) {
+	now := time.Now()
+	err := e.db.Transaction(func(db *gorm.DB) error {
+		if err := db.Model(&action).Updates(map[string]interface{}{
+			"status":       "mined",
+			"tx_hash":      txHash,
+			"block_number": blockNumber,
+			"completed_at": &now,
+		}).Error; err != nil {
+			return err
+		}
+		return recordBlacklisted(db, action, txHash, blockNumber)
+	})
+	if err != nil {
+		log.Printf("Error completing enforcement action %d: %v", action.ID, err)
+	}
+}
+
+// trackSubmitted resolves submitted actions once their transaction is mined or dropped
+func (e *EnforcementExecutor) trackSubmitted(ctx context.Context) {
+	var actions []models.EnforcementAction
+	if err := e.db.Where("status = ?", "submitted").Order("id").Find(&actions).Error; err != nil {
+		log.Printf("Error querying submitted enforcement actions: %v", err)
+		return
+	}
+
+	// Actions of one batch share their transaction
+	byTx := make(map[string][]models.EnforcementAction)
+	var hashes []string
+	for _, action := range actions {
+		if _, ok := byTx[action.TxHash]; !ok {
+			hashes = append(hashes, action.TxHash)
+		}
+		byTx[action.TxHash] = append(byTx[action.TxHash], action)
+	}
+
+	for _, hash := range hashes {
+		batch := byTx[hash]
+
+		// Read the confirmed nonce before the receipt so a transaction mined in between is not taken as dropped
+		confirmed, nonceErr := e.client.NonceAt(ctx, e.ownerKey.From, nil)
+
+		receipt, err := e.client.TransactionReceipt(ctx, common.HexToHash(hash))
+		if err == nil && receipt != nil {
+			if receipt.Status == types.ReceiptStatusSuccessful {
+				e.markMined(batch, receipt)
+			} else {
+				log.Printf("Enforcement transaction %s reverted", hash)
+				e.retry(batch, fmt.Errorf("%s transaction %s reverted", batch[0].Action, hash))
+			}
+			continue
+		}
+
+		// Not mined: if its nonce was consumed by another transaction it was replaced or dropped
+		if nonceErr == nil && confirmed > batch[0].Nonce {
+			log.Printf("Enforcement transaction %s was dropped", hash)
+			e.retry(batch, fmt.Errorf("%s transaction %s was dropped", batch[0].Action, hash))
+		}
+	}
+}
+
+// markMined completes mined actions and stores the blacklisted or lifted addresses
+func (e *EnforcementExecutor) markMined(batch []models.EnforcementAction, receipt *types.Receipt) {
+	now := time.Now()
+	err := e.db.Transaction(func(db *gorm.DB) error {
+		for _, action := range batch {
+			if err := db.Model(&action).Updates(map[string]interface{}{
+				"status":       "mined",
+				"block_number": receipt.BlockNumber.Uint64(),
+				"completed_at": &now,
+			}).Error; err != nil {
+				return err
+			}
+			if err := auditEnforcement(db, action, receipt); err != nil {
+				return err
+			}
+
+			if action.Action == "unblacklist" {
+				if err := recordLifted(db, action); err != nil {
+					return err
+				}
+				continue
+			}
+
+			// Store in blacklisted table unless it is already there
+			if err := recordBlacklisted(db, action, receipt.TxHash.Hex(), receipt.BlockNumber.Uint64()); err != nil {
+				return err
+			}
+
+			// Update suspicious transfer records
+			if err := db.Model(&models.SuspiciousTransfer{}).
+				Where("to_address = ?", action.Address).
+				Update("is_blacklisted", true).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Error recording mined enforcement transaction %s: %v", receipt.TxHash.Hex(), err)
+		return
+	}
+
+	for _, action := range batch {
+		if action.Action == "unblacklist" {
+			log.Printf("Removed address %s from blacklist. Transaction: %s", action.Address, receipt.TxHash.Hex())
+		} else {
+			log.Printf("Added address %s to blacklist. Transaction: %s", action.Address, receipt.TxHash.Hex())
+		}
+	}
+}
+
+// auditEnforcement records a mined enforcement action in the audit log with its transaction
+func auditEnforcement(db *gorm.DB, action models.EnforcementAction, receipt *types.Receipt) error {
+	params, err := json.Marshal(map[string]interface{}{
+		"enforcement_action_id": action.ID,
+		"address":               action.Address,
+		"direction":             action.Direction,
+		"source":                action.Source,
+		"requested_by":          action.RequestedBy,
+		"block_number":          receipt.BlockNumber.Uint64(),
+	})
+	if err != nil {
+		return err
+	}
+	return audit.Append(db, &models.AuditLog{
+		Actor:      "monitor",
+		Action:     "enforcement_" + action.Action,
+		Parameters: string(params),
+		Reason:     action.Reason,
+		TxHash:     receipt.TxHash.Hex(),
+		Result:     "succeeded",
+	})
+}
+
+// retry puts actions back in the queue with exponential backoff, or marks them as failed
+// once they have used up their attempts
+func (e *EnforcementExecutor) retry(batch []models.EnforcementAction, cause error) {
+	for _, action := range batch {
+		updates := map[string]interface{}{
+			"attempts":   action.Attempts,
+			"last_error": cause.Error(),
+			"tx_hash":    "",
+		}
+		if action.Attempts >= e.maxAttempts {
+			now := time.Now()
+			updates["status"] = "failed"
+			updates["completed_at"] = &now
+			log.Printf("Giving up %s of %s after %d attempts: %v", action.Action, action.Address, action.Attempts, cause)
+		} else {
+			updates["status"] = "pending"
+			updates["next_attempt_at"] = time.Now().Add(retryDelay(e.retryBackoff, action.Attempts))
+		}
+
+		if err := e.db.Model(&action).Updates(updates).Error; err != nil {
+			log.Printf("Error updating enforcement action %d: %v", action.ID, err)
+		}
+	}
+}
+
+// retryDelay returns the backoff before the next attempt, doubling with every attempt made
+func retryDelay(backoff time.Duration, attempts int) time.Duration {
+	if attempts < 1 {
+		return backoff
+	}
+	if attempts > 10 {
+		attempts = 10
+	}
+	return backoff << uint(attempts-1)
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryDelay(t *testing.T) {
+	backoff := 5 * time.Second
+	cases := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{0, 5 * time.Second},
+		{1, 5 * time.Second},
+		{2, 10 * time.Second},
+		{4, 40 * time.Second},
+		{50, backoff << 9},
+	}
+	for _, c := range cases {
+		if got := retryDelay(backoff, c.attempts); got != c.want {
+			t.Errorf("retryDelay(%s, %d) = %s, want %s", backoff, c.attempts, got, c.want)
+		}
+	}
+}
+
+func TestBlacklistDirections(t *testing.T) {
+	if got := ruleBlacklistDirection(`{"action": "record_violation", "blacklist_direction": "to"}`); got != DirectionTo {
+		t.Errorf("want %s, got %s", DirectionTo, got)
+	}
+	if got := ruleBlacklistDirection(`{"action": "record_violation"}`); got != DirectionBoth {
+		t.Errorf("want %s, got %s", DirectionBoth, got)
+	}
+	if got := ruleBlacklistDirection(`not json`); got != DirectionBoth {
+		t.Errorf("want %s, got %s", DirectionBoth, got)
+	}
+
+	if got := mergeDirections(DirectionTo, DirectionTo); got != DirectionTo {
+		t.Errorf("want %s, got %s", DirectionTo, got)
+	}
+	if got := mergeDirections(DirectionFrom, DirectionTo); got != DirectionBoth {
+		t.Errorf("want %s, got %s", DirectionBoth, got)
+	}
+	if got := mergeDirections("", DirectionFrom); got != DirectionFrom {
+		t.Errorf("want %s, got %s", DirectionFrom, got)
+	}
+
+	if !coversDirection(DirectionBoth, DirectionTo) || !coversDirection("", DirectionFrom) {
+		t.Error("both directions must cover a single direction")
+	}
+	if coversDirection(DirectionTo, DirectionFrom) || coversDirection(DirectionTo, DirectionBoth) {
+		t.Error("a single direction must not cover another")
+	}
+}
+
+func TestEnforcementPolicy(t *testing.T) {
+	if got := ruleEnforcementPolicy(`{"enforcement": "require_approval"}`); got != PolicyRequireApproval {
+		t.Errorf("want %s, got %s", PolicyRequireApproval, got)
+	}
+	if got := ruleEnforcementPolicy(`{"enforcement": "bogus"}`); got != PolicyAuto {
+		t.Errorf("want %s, got %s", PolicyAuto, got)
+	}
+
+	if got := strongerPolicy(PolicyAlertOnly, PolicyRequireApproval); got != PolicyRequireApproval {
+		t.Errorf("want %s, got %s", PolicyRequireApproval, got)
+	}
+	if got := strongerPolicy(PolicyAuto, PolicyAlertOnly); got != PolicyAuto {
+		t.Errorf("want %s, got %s", PolicyAuto, got)
+	}
+	if got := strongerPolicy("", PolicyAlertOnly); got != PolicyAlertOnly {
+		t.Errorf("want %s, got %s", PolicyAlertOnly, got)
+	}
+}
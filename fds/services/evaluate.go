@@ -0,0 +1,351 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"token-monitor/models"
+
+	"github.com/ethereum/go-ethereum/common"
+	"gorm.io/gorm"
+)
+
+// Checks the analyzer runs, in the order it runs them
+var ruleChecks = []string{"large_transfer", "multiple_transfers", "multiple_incoming_transfers", "suspicious_address", "insufficient_balance", "repeated_blocked_attempts"}
+
+// errDryRun rolls back the database transaction of an evaluation
+var errDryRun = errors.New("dry run")
+
+// RuleOutcome is what a rule concluded about a transaction, with the parameter values it
+// read and the evidence it decided on
+type RuleOutcome struct {
+	Rule       string                 `json:"rule,omitempty"` // Empty for a check no rule configures
+	Check      string                 `json:"check"`
+	Status     string                 `json:"status,omitempty"`
+	Version    int                    `json:"version,omitempty"`
+	Severity   string                 `json:"severity,omitempty"`
+	Evaluated  bool                   `json:"evaluated"`
+	Fired      bool                   `json:"fired"`
+	Reason     string                 `json:"reason"`
+	Parameters map[string]interface{} `json:"parameters,omitempty"`
+	Evidence   map[string]interface{} `json:"evidence,omitempty"`
+	Effect     string                 `json:"effect,omitempty"` // What firing would lead to
+}
+
+// outcome returns the outcome of a check when the analyzer is explaining, or nil
+func (a *Analyzer) outcome(check string) *RuleOutcome {
+	if a.outcomes == nil {
+		return nil
+	}
+	outcome, ok := a.outcomes[check]
+	if !ok {
+		outcome = &RuleOutcome{Check: check}
+		a.outcomes[check] = outcome
+	}
+	return outcome
+}
+
+// explain records why a check fired or not, when the analyzer is explaining
+func (a *Analyzer) explain(check string, fired bool, evidence map[string]interface{}, format string, args ...interface{}) {
+	outcome := a.outcome(check)
+	if outcome == nil {
+		return
+	}
+	outcome.Evaluated = true
+	outcome.Fired = fired
+	outcome.Reason = fmt.Sprintf(format, args...)
+	outcome.Evidence = evidence
+}
+
+// EvaluationRequest is a hypothetical transfer to run the rules on
+type EvaluationRequest struct {
+	From             string  `json:"from"`
+	To               string  `json:"to"`
+	Amount           string  `json:"amount"`                 // In base units
+	BlockNumber      *uint64 `json:"block_number,omitempty"` // Defaults to the latest stored block
+	Blocked          bool    `json:"blocked"`                // A pending transfer a compliance module blocked
+	ComplianceModule string  `json:"compliance_module,omitempty"`
+}
+
+// Evaluation is the outcome of every rule on a hypothetical transfer
+type Evaluation struct {
+	From        string         `json:"from"`
+	To          string         `json:"to"`
+	Amount      string         `json:"amount"`
+	BlockNumber uint64         `json:"block_number"`
+	Blocked     bool           `json:"blocked"`
+	Whitelisted bool           `json:"whitelisted"` // Exempted from some check by the whitelist
+	Flagged     bool           `json:"flagged"`     // Would be recorded as a suspicious transfer
+	Rules       []*RuleOutcome `json:"rules"`
+}
+
+// exemptedBy names the whitelist entries exempting a transfer from a check
+func exemptedBy(entries []*models.WhitelistAddress) string {
+	names := make([]string, len(entries))
+	for i, entry := range entries {
+		names[i] = fmt.Sprintf("#%d (%s)", entry.ID, entry.Address)
+	}
+	return "exempted by whitelist entry " + strings.Join(names, ", ")
+}
+
+// notEvaluated explains why the analyzer does not run a check on tx
+func notEvaluated(check string, tx *models.Transaction) *RuleOutcome {
+	outcome := &RuleOutcome{Check: check}
+	switch {
+	case check == "insufficient_balance":
+		outcome.Reason = "the insufficient_balance check is disabled in the analyzer"
+	case check == "repeated_blocked_attempts":
+		outcome.Reason = "only transfers blocked by a compliance module are checked for repeated blocked attempts"
+	case isBlockedAttempt(tx):
+		outcome.Reason = "transfers blocked by a compliance module are only checked for repeated blocked attempts"
+	default:
+		outcome.Reason = fmt.Sprintf("the analyzer has no %s check", check)
+	}
+	return outcome
+}
+
+// EvaluateTransaction runs every rule on a hypothetical transfer against the stored history,
+// as the analyzer would when the transfer is stored. The transfer is stored in a database
+// transaction that is rolled back: no violation, alert or enforcement is recorded.
+// suppression is the default suppression window of alerts.
+func EvaluateTransaction(ctx context.Context, db *gorm.DB, req EvaluationRequest, suppression time.Duration) (*Evaluation, error) {
+	if !common.IsHexAddress(req.From) || !common.IsHexAddress(req.To) {
+		return nil, fmt.Errorf("from and to must be addresses")
+	}
+	if amount, ok := new(big.Int).SetString(req.Amount, 10); !ok || amount.Sign() < 0 {
+		return nil, fmt.Errorf("amount must be an integer amount in base units")
+	}
+	if req.BlockNumber == nil {
+		var latest uint64
+		if err := db.Model(&models.Transaction{}).Select("COALESCE(MAX(block_number), 0)").Scan(&latest).Error; err != nil {
+			return nil, err
+		}
+		req.BlockNumber = &latest
+	}
+
+	hash := make([]byte, 32)
+	rand.Read(hash)
+	tx := &models.Transaction{
+		Hash:        "0x" + hex.EncodeToString(hash),
+		From:        common.HexToAddress(req.From).Hex(),
+		To:          common.HexToAddress(req.To).Hex(),
+		Value:       req.Amount,
+		BlockNumber: *req.BlockNumber,
+		Timestamp:   time.Now(),
+		Status:      "confirmed",
+	}
+	evaluation := &Evaluation{From: tx.From, To: tx.To, Amount: tx.Value, BlockNumber: tx.BlockNumber, Blocked: req.Blocked, Rules: []*RuleOutcome{}}
+
+	err := db.WithContext(ctx).Transaction(func(dbtx *gorm.DB) error {
+		if req.Blocked {
+			if req.ComplianceModule == "" {
+				req.ComplianceModule = "unknown"
+			}
+			tx.IsPending = true
+			tx.Status = "revert"
+			if err := dbtx.Create(&models.PendingTransaction{
+				Hash:             tx.Hash,
+				From:             tx.From,
+				To:               tx.To,
+				Value:            tx.Value,
+				BlockNumber:      tx.BlockNumber,
+				Timestamp:        tx.Timestamp,
+				Status:           "revert",
+				ComplianceModule: req.ComplianceModule,
+			}).Error; err != nil {
+				return err
+			}
+		} else if err := dbtx.Create(tx).Error; err != nil {
+			return err
+		}
+
+		var rules []models.Rule
+		if err := dbtx.Order("id").Find(&rules).Error; err != nil {
+			return err
+		}
+		active := make(map[string]*models.Rule)
+		for i := range rules {
+			if _, loaded := active[ruleType(&rules[i])]; !loaded && rules[i].Status == "active" {
+				active[ruleType(&rules[i])] = &rules[i]
+			}
+		}
+
+		live := &Analyzer{db: dbtx, rules: active, replay: true, quiet: true, outcomes: make(map[string]*RuleOutcome)}
+		exemptions := live.whitelistExemptions(tx)
+		evaluation.Whitelisted = len(exemptions) > 0
+
+		fired := make(map[string]bool)
+		for _, d := range live.detect(tx) {
+			fired[d.Rule] = true
+		}
+		explained := func(analyzer *Analyzer, check string) *RuleOutcome {
+			if outcome, ok := analyzer.outcomes[check]; ok && outcome.Evaluated {
+				copied := *outcome
+				return &copied
+			}
+			return notEvaluated(check, tx)
+		}
+
+		for i := range rules {
+			rule := &rules[i]
+			check := ruleType(rule)
+			var outcome *RuleOutcome
+			if active[check] == rule {
+				outcome = explained(live, check)
+				outcome.Fired = fired[check]
+			} else {
+				// Rules not in effect run on their own, as shadow rules do
+				single := &Analyzer{db: dbtx, rules: map[string]*models.Rule{check: rule}, replay: true, quiet: true, outcomes: make(map[string]*RuleOutcome)}
+				single.detect(tx)
+				outcome = explained(single, check)
+			}
+			outcome.Rule, outcome.Status, outcome.Version, outcome.Severity = rule.Name, rule.Status, rule.Version, rule.Severity
+
+			switch {
+			case !outcome.Fired:
+			case len(exemptions[check]) > 0:
+				outcome.Effect = "none; " + exemptedBy(exemptions[check])
+			case active[check] == rule:
+				_, suppressed, err := groupAlert(dbtx, rule, ruleGroupAddress(rule.Actions, tx), tx.Hash, time.Now(), ruleSuppressionWindow(rule.Actions, suppression))
+				if err != nil {
+					return err
+				}
+				outcome.Effect = "violation"
+				if suppressed {
+					outcome.Effect = "violation; its alert is suppressed as a repeat or snoozed"
+				} else {
+					evaluation.Flagged = true
+				}
+			case rule.Status == "shadow":
+				outcome.Effect = "shadow violation only"
+			case rule.Status == "active":
+				outcome.Effect = fmt.Sprintf("none; %s is the active %s rule", active[check].Name, check)
+			default:
+				outcome.Effect = "none; the rule is " + rule.Status
+			}
+			evaluation.Rules = append(evaluation.Rules, outcome)
+		}
+
+		// A check no rule configures still flags the transfer
+		for _, check := range ruleChecks {
+			if fired[check] && active[check] == nil {
+				outcome := explained(live, check)
+				if len(exemptions[check]) > 0 {
+					outcome.Effect = "none; " + exemptedBy(exemptions[check])
+				} else {
+					outcome.Effect = "suspicious transfer; no rule records a violation"
+					evaluation.Flagged = true
+				}
+				evaluation.Rules = append(evaluation.Rules, outcome)
+			}
+		}
+		return errDryRun
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		return nil, err
+	}
+	return evaluation, nil
+}
+
+// RuleEvaluator runs the dry runs requested through fds-api
+type RuleEvaluator struct {
+	db          *gorm.DB
+	interval    time.Duration
+	suppression time.Duration
+	stopChan    chan struct{}
+	wg          sync.WaitGroup
+}
+
+// NewRuleEvaluator creates an evaluator checking for requested dry runs every interval;
+// suppression is the default suppression window of alerts
+func NewRuleEvaluator(db *gorm.DB, interval, suppression time.Duration) *RuleEvaluator {
+	return &RuleEvaluator{
+		db:          db,
+		interval:    interval,
+		suppression: suppression,
+		stopChan:    make(chan struct{}),
+	}
+}
+
+// Start begins running requested dry runs
+func (e *RuleEvaluator) Start(ctx context.Context) {
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		ticker := time.NewTicker(e.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				e.runPending(ctx)
+			case <-ctx.Done():
+				return
+			case <-e.stopChan:
+				return
+			}
+		}
+	}()
+}
+
+// Stop gracefully stops the evaluator
+func (e *RuleEvaluator) Stop() {
+	close(e.stopChan)
+	e.wg.Wait()
+}
+
+// runPending runs the pending dry runs and drops those finished over a day ago
+func (e *RuleEvaluator) runPending(ctx context.Context) {
+	var pending []models.RuleEvaluation
+	if err := e.db.Where("status = ?", "pending").Order("id").Limit(100).Find(&pending).Error; err != nil {
+		log.Printf("Error loading pending rule evaluations: %v", err)
+		return
+	}
+	for i := range pending {
+		if ctx.Err() != nil {
+			return
+		}
+		e.run(ctx, &pending[i])
+	}
+
+	if err := e.db.Unscoped().Where("status <> ? AND created_at < ?", "pending", time.Now().Add(-24*time.Hour)).
+		Delete(&models.RuleEvaluation{}).Error; err != nil {
+		log.Printf("Error dropping old rule evaluations: %v", err)
+	}
+}
+
+// run runs a dry run and records its result or error
+func (e *RuleEvaluator) run(ctx context.Context, run *models.RuleEvaluation) {
+	var evaluation *Evaluation
+	var req EvaluationRequest
+	err := json.Unmarshal([]byte(run.Request), &req)
+	if err == nil {
+		evaluation, err = EvaluateTransaction(ctx, e.db, req, e.suppression)
+	}
+	if ctx.Err() != nil {
+		return
+	}
+
+	finished := time.Now()
+	updates := map[string]interface{}{"finished_at": finished}
+	if err != nil {
+		updates["status"] = "failed"
+		updates["error"] = err.Error()
+	} else {
+		encoded, _ := json.Marshal(evaluation)
+		updates["status"] = "done"
+		updates["result"] = string(encoded)
+	}
+	if err := e.db.Model(run).Updates(updates).Error; err != nil {
+		log.Printf("Error recording rule evaluation %d: %v", run.ID, err)
+	}
+}
@@ -0,0 +1,45 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"token-monitor/models"
+)
+
+func TestExplainOutcomes(t *testing.T) {
+	rule := &models.Rule{Name: "large_transfer", Parameters: `{"threshold": "1000"}`}
+
+	// Explaining is off outside dry runs
+	a := &Analyzer{rules: map[string]*models.Rule{"large_transfer": rule}}
+	a.getRuleParameter("large_transfer", "threshold")
+	a.explain("large_transfer", true, nil, "fired")
+	if a.outcomes != nil {
+		t.Fatal("outcomes recorded outside a dry run")
+	}
+
+	a.outcomes = make(map[string]*RuleOutcome)
+	if _, err := a.getRuleParameter("large_transfer", "threshold"); err != nil {
+		t.Fatal(err)
+	}
+	a.explain("large_transfer", false, map[string]interface{}{"amount": "10"}, "amount %s does not exceed the threshold %s", "10", "1000")
+	outcome := a.outcomes["large_transfer"]
+	if outcome == nil || !outcome.Evaluated || outcome.Fired {
+		t.Fatalf("outcome %+v", outcome)
+	}
+	if outcome.Parameters["threshold"] != "1000" || outcome.Reason != "amount 10 does not exceed the threshold 1000" {
+		t.Errorf("outcome %+v", outcome)
+	}
+
+	blocked := &models.Transaction{IsPending: true, Status: "revert"}
+	confirmed := &models.Transaction{Status: "confirmed"}
+	if reason := notEvaluated("large_transfer", blocked).Reason; !strings.Contains(reason, "blocked") {
+		t.Errorf("blocked transfer: %s", reason)
+	}
+	if reason := notEvaluated("repeated_blocked_attempts", confirmed).Reason; !strings.Contains(reason, "only transfers blocked") {
+		t.Errorf("confirmed transfer: %s", reason)
+	}
+	if reason := notEvaluated("insufficient_balance", confirmed).Reason; !strings.Contains(reason, "disabled") {
+		t.Errorf("insufficient_balance: %s", reason)
+	}
+}
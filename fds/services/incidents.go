@@ -0,0 +1,134 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"token-monitor/models"
+
+	"gorm.io/gorm"
+)
+
+// Alert incident statuses
+const (
+	IncidentOpen     = "open"     // Collecting repeats of its alert
+	IncidentClosed   = "closed"   // Its suppression window passed; the next violation opens a new incident
+	IncidentResolved = "resolved" // Resolved by an analyst
+)
+
+// ruleSuppressionWindow reads how long repeats of a rule's alert on one address are grouped
+// and suppressed from its actions JSON ("suppression_window_minutes"), defaulting to def.
+// Zero alerts on every violation.
+func ruleSuppressionWindow(actions string, def time.Duration) time.Duration {
+	var parsed struct {
+		SuppressionWindowMinutes *int `json:"suppression_window_minutes"`
+	}
+	if err := json.Unmarshal([]byte(actions), &parsed); err != nil || parsed.SuppressionWindowMinutes == nil || *parsed.SuppressionWindowMinutes < 0 {
+		return def
+	}
+	return time.Duration(*parsed.SuppressionWindowMinutes) * time.Minute
+}
+
+// ruleGroupAddress returns the address of tx a rule's alerts are grouped by, read from its
+// actions JSON ("group_by": "from" or "to"). The default is the recipient, the address the
+// analyzer blacklists.
+func ruleGroupAddress(actions string, tx *models.Transaction) string {
+	var parsed struct {
+		GroupBy string `json:"group_by"`
+	}
+	if err := json.Unmarshal([]byte(actions), &parsed); err == nil && parsed.GroupBy == "from" {
+		return tx.From
+	}
+	return tx.To
+}
+
+// alertFingerprint identifies the alerts of a rule on an address
+func alertFingerprint(ruleName, address string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(ruleName) + "|" + strings.ToLower(address)))
+	return hex.EncodeToString(sum[:])
+}
+
+// suppresses reports whether a violation at at repeats the alert of an open incident. The
+// window runs from the incident's first alert and is not extended by repeats, so a steady
+// stream of violations still alerts once per window.
+func suppresses(incident *models.AlertIncident, at time.Time, window time.Duration) bool {
+	return window > 0 && !at.After(incident.FirstSeenAt.Add(window))
+}
+
+// groupAlert files a violation of rule by address into its incident and reports whether
+// its alert is suppressed: it repeats the open incident's alert within the suppression
+// window, or the address is snoozed. It must run in the transaction recording the violation.
+func groupAlert(db *gorm.DB, rule *models.Rule, address, txHash string, at time.Time, window time.Duration) (*models.AlertIncident, bool, error) {
+	fingerprint := alertFingerprint(rule.Name, address)
+	// Concurrent violations of one fingerprint must not open two incidents
+	if err := db.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", fingerprint).Error; err != nil {
+		return nil, false, err
+	}
+
+	var snoozes int64
+	if err := db.Model(&models.AlertSnooze{}).
+		Where("LOWER(address) = LOWER(?) AND (rule_name = '' OR rule_name IS NULL OR rule_name = ?) AND until > ?", address, rule.Name, at).
+		Count(&snoozes).Error; err != nil {
+		return nil, false, err
+	}
+	snoozed := snoozes > 0
+
+	var incident models.AlertIncident
+	err := db.Where("fingerprint = ? AND status = ?", fingerprint, IncidentOpen).Order("id DESC").First(&incident).Error
+	switch {
+	case err == nil && suppresses(&incident, at, window):
+		incident.Count++
+		incident.SuppressedCount++
+		incident.LastSeenAt = at
+		incident.LastTxHash = txHash
+		if err := db.Model(&incident).Updates(map[string]interface{}{
+			"count":            gorm.Expr("count + 1"),
+			"suppressed_count": gorm.Expr("suppressed_count + 1"),
+			"last_seen_at":     at,
+			"last_tx_hash":     txHash,
+		}).Error; err != nil {
+			return nil, false, err
+		}
+		return &incident, true, nil
+	case err == nil:
+		if err := db.Model(&incident).Update("status", IncidentClosed).Error; err != nil {
+			return nil, false, err
+		}
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, false, err
+	}
+
+	incident = models.AlertIncident{
+		Fingerprint: fingerprint,
+		RuleID:      rule.ID,
+		RuleName:    rule.Name,
+		Address:     address,
+		Severity:    rule.Severity,
+		Status:      IncidentOpen,
+		FirstSeenAt: at,
+		LastSeenAt:  at,
+		ExpiresAt:   at.Add(window),
+		Count:       1,
+		FirstTxHash: txHash,
+		LastTxHash:  txHash,
+	}
+	if snoozed {
+		incident.SuppressedCount = 1
+	}
+	if err := db.Create(&incident).Error; err != nil {
+		return nil, false, err
+	}
+	return &incident, snoozed, nil
+}
+
+// closeExpiredIncidents closes the open incidents whose suppression window has passed
+func closeExpiredIncidents(db *gorm.DB, now time.Time) (int64, error) {
+	result := db.Model(&models.AlertIncident{}).
+		Where("status = ? AND expires_at <= ?", IncidentOpen, now).
+		Update("status", IncidentClosed)
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"token-monitor/models"
+)
+
+func TestAlertGroupingSettings(t *testing.T) {
+	def := time.Hour
+	cases := []struct {
+		actions string
+		want    time.Duration
+	}{
+		{`{"suppression_window_minutes": 15}`, 15 * time.Minute},
+		{`{"suppression_window_minutes": 0}`, 0},
+		{`{"suppression_window_minutes": -5}`, def},
+		{`{"enforcement": "auto"}`, def},
+		{`not json`, def},
+	}
+	for _, c := range cases {
+		if got := ruleSuppressionWindow(c.actions, def); got != c.want {
+			t.Errorf("ruleSuppressionWindow(%s) = %s, want %s", c.actions, got, c.want)
+		}
+	}
+
+	tx := &models.Transaction{From: "0x01", To: "0x02"}
+	if got := ruleGroupAddress(`{"group_by": "from"}`, tx); got != tx.From {
+		t.Errorf("group_by from grouped by %s", got)
+	}
+	if got := ruleGroupAddress(`{}`, tx); got != tx.To {
+		t.Errorf("default grouping by %s, want the recipient", got)
+	}
+
+	if alertFingerprint("large_transfer", "0xAbC") != alertFingerprint("Large_Transfer", "0xabc") {
+		t.Error("fingerprints must not depend on case")
+	}
+	if alertFingerprint("large_transfer", "0xabc") == alertFingerprint("multiple_transfers", "0xabc") {
+		t.Error("fingerprints of different rules must differ")
+	}
+}
+
+func TestSuppressionWindowFromFirstAlert(t *testing.T) {
+	first := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	// Repeats every 20 minutes have kept the incident's last violation recent
+	incident := &models.AlertIncident{FirstSeenAt: first, LastSeenAt: first.Add(50 * time.Minute)}
+	cases := []struct {
+		name   string
+		at     time.Time
+		window time.Duration
+		want   bool
+	}{
+		{"within the window", first.Add(40 * time.Minute), time.Hour, true},
+		{"at its end", first.Add(time.Hour), time.Hour, true},
+		{"past it despite recent repeats", first.Add(61 * time.Minute), time.Hour, false},
+		{"no window", first, 0, false},
+	}
+	for _, tc := range cases {
+		if got := suppresses(incident, tc.at, tc.window); got != tc.want {
+			t.Errorf("%s: suppresses = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
 	"math/big"
@@ -14,6 +15,7 @@ import (
 
 	"token-monitor/models"
 
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -24,10 +26,17 @@ import (
 
 // EventInfo represents decoded event information
 type EventInfo struct {
-	Event string `json:"event"`
-	From  string `json:"from,omitempty"`
-	To    string `json:"to,omitempty"`
-	Value string `json:"value"`
+	Event     string `json:"event"`
+	Contract  string `json:"contract,omitempty"`
+	LogIndex  uint   `json:"logIndex"`
+	From      string `json:"from,omitempty"`
+	To        string `json:"to,omitempty"`
+	Value     string `json:"value"`
+	TxType    string `json:"txType,omitempty"`
+	FromToken string `json:"fromToken,omitempty"` // ExchangeExecuted only
+	ToToken   string `json:"toToken,omitempty"`   // ExchangeExecuted only
+	AmountOut string `json:"amountOut,omitempty"` // ExchangeExecuted only
+	Fee       string `json:"fee,omitempty"`       // ExchangeExecuted only
 }
 
 // TxResult represents the result of a transaction simulation
@@ -35,6 +44,7 @@ type TxResult struct {
 	TxHash string      `json:"txHash"`
 	Status string      `json:"status"`
 	Events []EventInfo `json:"events,omitempty"`
+	Revert *RevertInfo `json:"revert,omitempty"`
 	Error  string      `json:"error,omitempty"`
 }
 
@@ -47,15 +57,18 @@ type ForkProcess struct {
 
 // MempoolMonitor monitors pending transactions in the mempool
 type MempoolMonitor struct {
-	client      *ethclient.Client
-	db          *gorm.DB
-	contract    common.Address
-	analyzer    AnalyzerService
-	stopChan    chan struct{}
-	wg          sync.WaitGroup
-	interval    time.Duration
-	rpcClient   *rpc.Client
-	contractABI abi.ABI
+	client          *ethclient.Client
+	db              *gorm.DB
+	contract        common.Address
+	systemContracts map[common.Address]bool // Contracts whose pending calls are simulated and whose events are decoded
+	analyzer        AnalyzerService
+	stopChan        chan struct{}
+	wg              sync.WaitGroup
+	interval        time.Duration
+	rpcClient       *rpc.Client
+	contractABI     abi.ABI
+	errorsABI       abi.ABI
+	methodsABI      abi.ABI
 }
 
 // NewForkProcess creates a new fork process
@@ -110,49 +123,50 @@ func (f *ForkProcess) Stop() {
 	f.cancel()
 }
 
-// NewMempoolMonitor creates a new mempool monitor
-func NewMempoolMonitor(client *ethclient.Client, rpcClient *rpc.Client, db *gorm.DB, contract common.Address, analyzer AnalyzerService, interval time.Duration) *MempoolMonitor {
-	// Parse the ERC20 ABI
-	contractABI, err := abi.JSON(strings.NewReader(erc20ABIJSON))
+// NewMempoolMonitor creates a new mempool monitor. The token contract is always
+// part of the system contracts; systemContracts adds e.g. the ExchangePortal.
+func NewMempoolMonitor(client *ethclient.Client, rpcClient *rpc.Client, db *gorm.DB, contract common.Address, systemContracts []common.Address, analyzer AnalyzerService, interval time.Duration) *MempoolMonitor {
+	// Parse the system events ABI
+	contractABI, err := abi.JSON(strings.NewReader(systemEventsABIJSON))
 	if err != nil {
-		log.Printf("Error parsing ERC20 ABI: %v", err)
+		log.Printf("Error parsing system events ABI: %v", err)
 		return nil
 	}
 
+	// Parse the system errors ABI used to decode reverts
+	errorsABI, err := abi.JSON(strings.NewReader(systemErrorsABIJSON))
+	if err != nil {
+		log.Printf("Error parsing system errors ABI: %v", err)
+		return nil
+	}
+
+	// Parse the system methods ABI used to decode calldata the simulation shows nothing for
+	methodsABI, err := abi.JSON(strings.NewReader(systemMethodsABIJSON))
+	if err != nil {
+		log.Printf("Error parsing system methods ABI: %v", err)
+		return nil
+	}
+
+	contracts := map[common.Address]bool{contract: true}
+	for _, addr := range systemContracts {
+		contracts[addr] = true
+	}
+
 	return &MempoolMonitor{
-		client:      client,
-		db:          db,
-		contract:    contract,
-		analyzer:    analyzer,
-		stopChan:    make(chan struct{}),
-		interval:    interval,
-		rpcClient:   rpcClient,
-		contractABI: contractABI,
+		client:          client,
+		db:              db,
+		contract:        contract,
+		systemContracts: contracts,
+		analyzer:        analyzer,
+		stopChan:        make(chan struct{}),
+		interval:        interval,
+		rpcClient:       rpcClient,
+		contractABI:     contractABI,
+		errorsABI:       errorsABI,
+		methodsABI:      methodsABI,
 	}
 }
 
-// ERC20 ABI for event decoding
-const erc20ABIJSON = `[
-    { "anonymous": false,
-      "inputs": [
-        {"indexed": true, "internalType": "address", "name": "from", "type": "address"},
-        {"indexed": true, "internalType": "address", "name": "to", "type": "address"},
-        {"indexed": false,"internalType": "uint256","name": "value","type": "uint256"}
-      ],
-      "name": "Transfer",
-      "type": "event"
-    },
-    { "anonymous": false,
-      "inputs": [
-        {"indexed": true, "internalType": "address", "name": "owner",   "type": "address"},
-        {"indexed": true, "internalType": "address", "name": "spender", "type": "address"},
-        {"indexed": false,"internalType": "uint256","name": "value",   "type": "uint256"}
-      ],
-      "name": "Approval",
-      "type": "event"
-    }
-]`
-
 // Start begins monitoring the mempool
 func (m *MempoolMonitor) Start(ctx context.Context) {
 	m.wg.Add(1)
@@ -309,22 +323,24 @@ func (m *MempoolMonitor) simulateTransaction(ctx context.Context, tx *types.Tran
 		return result
 	}
 
+	signer := types.LatestSignerForChainID(chainID)
+	fromAddr, err := signer.Sender(tx)
+	if err != nil {
+		result.Error = fmt.Sprintf("Failed to get sender: %v", err)
+		return result
+	}
+
 	// Determine status
 	if receipt.Status == types.ReceiptStatusSuccessful {
 		result.Status = "success"
 	} else {
 		result.Status = "revert"
+		revert := m.replayRevert(simCtx, anvilClient, tx, fromAddr, receipt.BlockNumber)
+		result.Revert = &revert
 	}
 
 	// Handle ETH transfer
 	if tx.Value() != nil && tx.Value().Cmp(big.NewInt(0)) > 0 {
-		signer := types.LatestSignerForChainID(chainID)
-		fromAddr, err := signer.Sender(tx)
-		if err != nil {
-			result.Error = fmt.Sprintf("Failed to get sender: %v", err)
-			return result
-		}
-
 		ev := EventInfo{
 			Event: "ETHTransfer",
 			From:  fromAddr.Hex(),
@@ -334,58 +350,38 @@ func (m *MempoolMonitor) simulateTransaction(ctx context.Context, tx *types.Tran
 		result.Events = append(result.Events, ev)
 	}
 
-	// Decode events
-	for _, logEntry := range receipt.Logs {
-		if !strings.EqualFold(logEntry.Address.Hex(), targetAddr.Hex()) {
-			continue
-		}
-		if len(logEntry.Topics) == 0 {
-			continue
-		}
+	// Decode events emitted by the system contracts
+	result.Events = append(result.Events, decodeSystemLogs(m.contractABI, receipt.Logs, m.systemContracts)...)
 
-		switch logEntry.Topics[0] {
-		case m.contractABI.Events["Transfer"].ID:
-			var transferEv struct {
-				Value *big.Int
-			}
-			if err := m.contractABI.UnpackIntoInterface(&transferEv, "Transfer", logEntry.Data); err != nil {
-				continue
-			}
-			var fromAddr, toAddr common.Address
-			if len(logEntry.Topics) > 2 {
-				fromAddr = common.HexToAddress(logEntry.Topics[1].Hex())
-				toAddr = common.HexToAddress(logEntry.Topics[2].Hex())
-			}
-			ev := EventInfo{
-				Event: "Transfer",
-				From:  fromAddr.Hex(),
-				To:    toAddr.Hex(),
-				Value: transferEv.Value.String(),
-			}
-			result.Events = append(result.Events, ev)
-		case m.contractABI.Events["Approval"].ID:
-			var approvalEv struct {
-				Value *big.Int
-			}
-			if err := m.contractABI.UnpackIntoInterface(&approvalEv, "Approval", logEntry.Data); err != nil {
-				continue
-			}
-			var ownerAddr, spenderAddr common.Address
-			if len(logEntry.Topics) > 2 {
-				ownerAddr = common.HexToAddress(logEntry.Topics[1].Hex())
-				spenderAddr = common.HexToAddress(logEntry.Topics[2].Hex())
-			}
-			ev := EventInfo{
-				Event: "Approval",
-				From:  ownerAddr.Hex(),
-				To:    spenderAddr.Hex(),
-				Value: approvalEv.Value.String(),
+	return result
+}
+
+// replayRevert re-executes a reverted transaction as a call against the parent
+// of the block it was mined in and decodes the returned revert data
+func (m *MempoolMonitor) replayRevert(ctx context.Context, client *ethclient.Client, tx *types.Transaction, from common.Address, blockNumber *big.Int) RevertInfo {
+	msg := ethereum.CallMsg{
+		From:  from,
+		To:    tx.To(),
+		Gas:   tx.Gas(),
+		Value: tx.Value(),
+		Data:  tx.Data(),
+	}
+
+	parent := new(big.Int).Sub(blockNumber, big.NewInt(1))
+	_, err := client.CallContract(ctx, msg, parent)
+	if err == nil {
+		return RevertInfo{}
+	}
+
+	if dataErr, ok := err.(rpc.DataError); ok {
+		if hexData, ok := dataErr.ErrorData().(string); ok {
+			if revert := decodeRevert(m.errorsABI, common.FromHex(hexData)); revert.Reason != "" {
+				return revert
 			}
-			result.Events = append(result.Events, ev)
 		}
 	}
 
-	return result
+	return RevertInfo{Reason: err.Error()}
 }
 
 // processPendingTransaction processes a pending transaction
@@ -402,8 +398,8 @@ func (m *MempoolMonitor) processPendingTransaction(ctx context.Context, txHash c
 		return
 	}
 
-	// Check if transaction is for our contract
-	if tx.To() == nil || *tx.To() != m.contract {
+	// Check if transaction targets one of the system contracts
+	if tx.To() == nil || !m.systemContracts[*tx.To()] {
 		return
 	}
 
@@ -446,31 +442,51 @@ func (m *MempoolMonitor) processPendingTransaction(ctx context.Context, txHash c
 	}
 
 	// Simulate the transaction
-	result := m.simulateTransaction(ctx, tx, m.contract, chainID)
-
-	// Decode the result based on the method signature
-	var to common.Address
-	var value *big.Int
-	if len(tx.Data()) >= 4 {
-		methodID := tx.Data()[:4]
-		if common.Bytes2Hex(methodID) == "a9059cbb" { // transfer(address,uint256)
-			if len(tx.Data()) >= 68 {
-				to = common.BytesToAddress(tx.Data()[16:36])
-				value = new(big.Int).SetBytes(tx.Data()[36:68])
-			}
-		}
+	result := m.simulateTransaction(ctx, tx, *tx.To(), chainID)
+
+	// Every token movement observed in the simulation, or asked for by the calldata when the
+	// simulation shows none, is analyzed on its own
+	transfers := pendingTransfers(m.methodsABI, result, from, tx)
+
+	effectsJSON, err := json.Marshal(result.Events)
+	if err != nil {
+		log.Printf("Error marshaling simulated effects for %s: %v", txHash.Hex(), err)
+		effectsJSON = []byte("[]")
+	}
+
+	// The pending record summarises the first movement; the full list is kept in Effects
+	to := tx.To().Hex()
+	value := "0"
+	if len(transfers) > 0 {
+		to = transfers[0].To
+		value = transfers[0].Value
+	}
+
+	// A transfer blocked by compliance still tells us who it was meant for
+	if result.Revert != nil && result.Revert.To != "" {
+		to = result.Revert.To
+		value = result.Revert.Amount
 	}
 
 	// Create pending transaction record
 	pendingTx := &models.PendingTransaction{
 		Hash:        txHash.Hex(),
 		From:        from.Hex(),
-		To:          to.Hex(),
-		Value:       value.String(),
+		To:          to,
+		Value:       value,
 		BlockNumber: currentBlock, // Use current block number instead of 0
 		Timestamp:   time.Now(),
 		IsAnalyzed:  false,
 		Status:      result.Status,
+		Effects:     string(effectsJSON),
+		GasTipCap:   tx.GasTipCap().String(),
+		GasFeeCap:   tx.GasFeeCap().String(),
+	}
+	if result.Revert != nil {
+		pendingTx.RevertReason = result.Revert.Reason
+		pendingTx.RevertError = result.Revert.Error
+		pendingTx.RevertData = result.Revert.Data
+		pendingTx.ComplianceModule = result.Revert.ComplianceModule
 	}
 
 	// Save to database using FirstOrCreate
@@ -479,26 +495,46 @@ func (m *MempoolMonitor) processPendingTransaction(ctx context.Context, txHash c
 		return
 	}
 
-	// Queue for analysis using the pending transaction data
-	m.analyzer.QueueTransaction(&models.Transaction{
-		Hash:        pendingTx.Hash,
-		From:        pendingTx.From,
-		To:          pendingTx.To,
-		Value:       pendingTx.Value,
-		BlockNumber: pendingTx.BlockNumber,
-		Timestamp:   pendingTx.Timestamp,
-		IsAnalyzed:  pendingTx.IsAnalyzed,
-		IsPending:   true,
-		Status:      pendingTx.Status,
-	})
+	// A blocked attempt is analyzed as a single reverted transfer so that
+	// repeated attempts from the same address can be detected
+	if result.Revert != nil && result.Revert.ComplianceModule != "" {
+		m.analyzer.QueueTransaction(&models.Transaction{
+			Hash:        pendingTx.Hash,
+			From:        pendingTx.From,
+			To:          pendingTx.To,
+			Value:       pendingTx.Value,
+			BlockNumber: pendingTx.BlockNumber,
+			Timestamp:   pendingTx.Timestamp,
+			IsPending:   true,
+			Status:      pendingTx.Status,
+		})
+	}
+
+	// Queue each simulated transfer for analysis
+	for _, transfer := range transfers {
+		m.analyzer.QueueTransaction(&models.Transaction{
+			Hash:        pendingTx.Hash,
+			From:        transfer.From,
+			To:          transfer.To,
+			Value:       transfer.Value,
+			BlockNumber: pendingTx.BlockNumber,
+			Timestamp:   pendingTx.Timestamp,
+			IsAnalyzed:  pendingTx.IsAnalyzed,
+			IsPending:   true,
+			Status:      pendingTx.Status,
+		})
+	}
 
 	// Log simulation results
 	if result.Error != "" {
 		log.Printf("Simulation error for tx %s: %s", txHash.Hex(), result.Error)
 	} else {
-		log.Printf("Simulation result for tx %s: %s", txHash.Hex(), result.Status)
+		log.Printf("Simulation result for tx %s: %s (%d transfers)", txHash.Hex(), result.Status, len(transfers))
+		if result.Revert != nil {
+			log.Printf("  Revert: %s (%s) module: %s", result.Revert.Reason, result.Revert.Error, result.Revert.ComplianceModule)
+		}
 		for _, ev := range result.Events {
-			log.Printf("  Event: %s From: %s To: %s Value: %s", ev.Event, ev.From, ev.To, ev.Value)
+			log.Printf("  Event: %s Contract: %s From: %s To: %s Value: %s", ev.Event, ev.Contract, ev.From, ev.To, ev.Value)
 		}
 	}
 }
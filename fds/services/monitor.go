@@ -38,6 +38,7 @@ type monitor struct {
 	client         *ethclient.Client
 	contractAddr   common.Address
 	contractABI    abi.ABI
+	systemABI      abi.ABI // Events of the system contracts, for TypedTransfer
 	db             *gorm.DB
 	config         config.MonitorConfig
 	analyzer       AnalyzerService
@@ -63,11 +64,16 @@ func NewMonitor(db *gorm.DB, config config.MonitorConfig, analyzer AnalyzerServi
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse contract ABI: %w", err)
 	}
+	systemABI, err := abi.JSON(strings.NewReader(systemEventsABIJSON))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse system events ABI: %w", err)
+	}
 
 	return &monitor{
 		client:         client,
 		contractAddr:   common.HexToAddress(config.ContractAddress),
 		contractABI:    parsedABI,
+		systemABI:      systemABI,
 		db:             db,
 		config:         config,
 		analyzer:       analyzer,
@@ -118,6 +124,12 @@ func (m *monitor) processEvents(ctx context.Context, logs chan types.Log) {
 			return
 
 		case eventLog := <-logs:
+			// A TypedTransfer follows the Transfer of the same transaction and only adds its type
+			if len(eventLog.Topics) >= 4 && eventLog.Topics[0] == m.systemABI.Events["TypedTransfer"].ID {
+				m.recordTxType(eventLog)
+				continue
+			}
+
 			// Get event name from the first topic
 			eventName := m.getEventName(eventLog.Topics[0])
 			if eventName == "" {
@@ -220,6 +232,16 @@ func (m *monitor) processEvents(ctx context.Context, logs chan types.Log) {
 	}
 }
 
+// recordTxType stores the transaction type of a TypedTransfer on its transaction
+func (m *monitor) recordTxType(eventLog types.Log) {
+	txType := new(big.Int).SetBytes(eventLog.Topics[3].Bytes()).String()
+	if err := m.db.Model(&models.Transaction{}).
+		Where("hash = ?", eventLog.TxHash.Hex()).
+		Update("tx_type", txType).Error; err != nil {
+		log.Printf("Error recording transaction type of %s: %v", eventLog.TxHash.Hex(), err)
+	}
+}
+
 // reconnect attempts to reestablish the subscription
 func (m *monitor) reconnect(ctx context.Context) {
 	log.Printf("Attempting to reconnect to event stream...")
@@ -349,13 +371,15 @@ func (m *monitor) handleSuspiciousBehaviors(tx *models.Transaction, behaviors []
 		}
 
 		// Record the violation
-		if err := m.recordRuleViolation(rule.ID, tx.Hash, tx.BlockNumber, map[string]interface{}{"behavior": behavior}); err != nil {
+		violation, err := m.recordRuleViolation(rule.ID, tx.Hash, tx.BlockNumber, map[string]interface{}{"behavior": behavior})
+		if err != nil {
 			log.Printf("Error recording %s violation: %v", behavior, err)
 			continue
 		}
 
-		// Handle blacklisting if severity is high
-		if rule.Severity == "high" {
+		// Handle blacklisting if severity is high, unless the rule only alerts
+		policy := ruleEnforcementPolicy(rule.Actions)
+		if rule.Severity == "high" && policy != PolicyAlertOnly {
 			// Check addresses involved in the behavior
 			addresses := []string{}
 			if tx.From != "" {
@@ -384,51 +408,21 @@ func (m *monitor) handleSuspiciousBehaviors(tx *models.Transaction, behaviors []
 					continue
 				}
 
-				// Add to blacklist
-				blacklistAddr := models.BlacklistedAddress{
-					Address:     addr,
-					TxHash:      tx.Hash,
-					BlockNumber: tx.BlockNumber,
-					Reason:      fmt.Sprintf("%s (Severity: %s): %s", behavior, rule.Severity, behavior),
-					Severity:    rule.Severity,
-					Details:     fmt.Sprintf("%v", map[string]interface{}{"behavior": behavior}),
+				// Queue the blacklist; the entry is stored once the transaction is mined
+				reason := fmt.Sprintf("%s (Severity: %s): %s", behavior, rule.Severity, behavior)
+				details := fmt.Sprintf("%v", map[string]interface{}{"behavior": behavior})
+				template := models.EnforcementAction{
+					Direction:     ruleBlacklistDirection(rule.Actions),
+					TriggerTxHash: tx.Hash,
+					Reason:        reason,
+					OnChainReason: fmt.Sprintf("%s#%d", rule.Name, violation.ID),
+					Severity:      rule.Severity,
+					Details:       details,
 				}
-
-				// Use a transaction to ensure atomicity
-				err := m.db.Transaction(func(tx *gorm.DB) error {
-					// Double-check if address is still not blacklisted
-					if err := tx.Where("address = ?", addr).First(&models.BlacklistedAddress{}).Error; err == nil {
-						return fmt.Errorf("address %s was blacklisted concurrently", addr)
-					} else if err != gorm.ErrRecordNotFound {
-						return err
-					}
-
-					// Create the blacklist entry
-					if err := tx.Create(&blacklistAddr).Error; err != nil {
-						return err
-					}
-
-					// Call blacklist contract
-					if err := m.callBlacklistContract([]string{addr}); err != nil {
-						log.Printf("Error calling blacklist contract for address %s: %v", addr, err)
-						// Don't return error here to avoid rolling back the database transaction
-					} else {
-						log.Printf("Successfully blacklisted address %s", addr)
-					}
-
-					return nil
-				})
-
-				if err != nil {
-					if strings.Contains(err.Error(), "duplicate key value") || strings.Contains(err.Error(), "was blacklisted concurrently") {
-						log.Printf("Address %s was blacklisted concurrently, skipping", addr)
-						continue
-					}
-					log.Printf("Error adding address %s to blacklist: %v", addr, err)
-					continue
+				applyPolicy(&template, policy)
+				if err := m.callBlacklistContract([]string{addr}, template); err != nil {
+					log.Printf("Error queueing blacklist for address %s: %v", addr, err)
 				}
-
-				log.Printf("Added address %s to blacklist table", addr)
 			}
 		}
 
@@ -440,24 +434,29 @@ func (m *monitor) handleSuspiciousBehaviors(tx *models.Transaction, behaviors []
 }
 
 // recordRuleViolation records a rule violation in the database
-func (m *monitor) recordRuleViolation(ruleID uint, txHash string, blockNumber uint64, details map[string]interface{}) error {
-	violation := models.RuleViolation{
+func (m *monitor) recordRuleViolation(ruleID uint, txHash string, blockNumber uint64, details map[string]interface{}) (*models.RuleViolation, error) {
+	violation := &models.RuleViolation{
 		RuleID:      ruleID,
 		TxHash:      txHash,
 		BlockNumber: blockNumber,
 		Details:     fmt.Sprintf("%v", details),
 	}
-	return m.db.Create(&violation).Error
+	if err := m.db.Create(violation).Error; err != nil {
+		return nil, err
+	}
+	return violation, nil
 }
 
-// callBlacklistContract calls the blacklist contract to add addresses to the blacklist
-func (m *monitor) callBlacklistContract(addresses []string) error {
-	if len(addresses) == 0 {
-		return nil
+// callBlacklistContract queues addresses for the enforcement executor, which sends the
+// blacklist transaction. template carries the direction and reasons shared by all addresses.
+func (m *monitor) callBlacklistContract(addresses []string, template models.EnforcementAction) error {
+	for _, addr := range addresses {
+		action := template
+		action.Address = addr
+		action.Source = "monitor"
+		if _, err := EnqueueBlacklist(m.db, action); err != nil {
+			return err
+		}
 	}
-
-	// TODO: Implement actual contract call
-	// This is a placeholder for the actual implementation
-	log.Printf("Would call blacklist contract for addresses: %v", addresses)
 	return nil
 }
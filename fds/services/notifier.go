@@ -0,0 +1,522 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/smtp"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"token-monitor/models"
+
+	"gorm.io/gorm"
+)
+
+// Notification channel types
+const (
+	ChannelWebhook  = "webhook"  // JSON POST signed with HMAC-SHA256
+	ChannelEmail    = "email"    // Plain text mail over SMTP
+	ChannelSlack    = "slack"    // Slack-compatible incoming webhook
+	ChannelTelegram = "telegram" // Telegram bot message
+)
+
+// Headers of signed webhook requests
+const (
+	WebhookSignatureHeader = "X-FDS-Signature" // "sha256=" and the hex HMAC of the timestamp, "." and the body
+	WebhookTimestampHeader = "X-FDS-Timestamp" // Unix time the request was signed at
+	WebhookDeliveryHeader  = "X-FDS-Delivery"  // Delivery ID, the same on every retry
+)
+
+// Default templates of the text sent to chat and email channels
+const (
+	defaultAlertTemplate = `[{{upper .Severity}}] {{.Rule}} rule violated
+Transaction: {{.TxHash}} (block {{.BlockNumber}})
+From: {{.From}}
+To: {{.To}}
+Amount: {{.Amount}}{{if .Description}}
+{{.Description}}{{end}}`
+	defaultSubjectTemplate = `[FDS {{upper .Severity}}] {{.Rule}} on {{.TxHash}}`
+)
+
+// telegramAPI is the Telegram Bot API used when a channel sets no URL
+const telegramAPI = "https://api.telegram.org"
+
+// Alert is a finding sent to notification channels
+type Alert struct {
+	ViolationID uint                   `json:"violation_id"`
+	Rule        string                 `json:"rule"`
+	Description string                 `json:"description"`
+	Severity    string                 `json:"severity"`
+	TxHash      string                 `json:"tx_hash"`
+	BlockNumber uint64                 `json:"block_number"`
+	From        string                 `json:"from"`
+	To          string                 `json:"to"`
+	Amount      string                 `json:"amount"`
+	Details     map[string]interface{} `json:"details"`
+	DetectedAt  time.Time              `json:"detected_at"`
+}
+
+// NotificationChannel is a configured destination of alerts
+type NotificationChannel struct {
+	Name        string   `json:"name"`
+	Type        string   `json:"type"`
+	MinSeverity string   `json:"min_severity"` // Lowest severity sent; all when empty
+	Default     bool     `json:"default"`      // Receives alerts of rules that name no channels
+	URL         string   `json:"url"`          // Webhook or Slack URL; Telegram API base URL
+	Secret      string   `json:"secret"`       // HMAC key of webhook signatures
+	Template    string   `json:"template"`     // text/template of the message; the alert JSON for webhooks when empty
+	Subject     string   `json:"subject"`      // text/template of the email subject
+	SMTPHost    string   `json:"smtp_host"`
+	SMTPPort    int      `json:"smtp_port"`
+	Username    string   `json:"username"`
+	Password    string   `json:"password"`
+	From        string   `json:"from"`
+	To          []string `json:"to"`
+	BotToken    string   `json:"bot_token"`
+	ChatID      string   `json:"chat_id"`
+
+	body    *template.Template
+	subject *template.Template
+}
+
+var templateFuncs = template.FuncMap{"upper": strings.ToUpper}
+
+// prepare validates a channel and parses its templates
+func (ch *NotificationChannel) prepare() error {
+	if ch.Name == "" {
+		return errors.New("channel without a name")
+	}
+	switch ch.Type {
+	case ChannelWebhook, ChannelSlack:
+		if ch.URL == "" {
+			return fmt.Errorf("channel %s: url required", ch.Name)
+		}
+	case ChannelEmail:
+		if ch.SMTPHost == "" || ch.From == "" || len(ch.To) == 0 {
+			return fmt.Errorf("channel %s: smtp_host, from and to required", ch.Name)
+		}
+		if ch.SMTPPort == 0 {
+			ch.SMTPPort = 587
+		}
+	case ChannelTelegram:
+		if ch.BotToken == "" || ch.ChatID == "" {
+			return fmt.Errorf("channel %s: bot_token and chat_id required", ch.Name)
+		}
+		if ch.URL == "" {
+			ch.URL = telegramAPI
+		}
+	default:
+		return fmt.Errorf("channel %s: unknown type %q", ch.Name, ch.Type)
+	}
+	switch ch.MinSeverity {
+	case "", "low", "medium", "high":
+	default:
+		return fmt.Errorf("channel %s: invalid min_severity %q", ch.Name, ch.MinSeverity)
+	}
+
+	text := ch.Template
+	if text == "" && ch.Type != ChannelWebhook {
+		text = defaultAlertTemplate
+	}
+	if text != "" {
+		tmpl, err := template.New(ch.Name).Funcs(templateFuncs).Parse(text)
+		if err != nil {
+			return fmt.Errorf("channel %s: invalid template: %w", ch.Name, err)
+		}
+		ch.body = tmpl
+	}
+	if ch.Type == ChannelEmail {
+		text := ch.Subject
+		if text == "" {
+			text = defaultSubjectTemplate
+		}
+		tmpl, err := template.New(ch.Name + " subject").Funcs(templateFuncs).Parse(text)
+		if err != nil {
+			return fmt.Errorf("channel %s: invalid subject template: %w", ch.Name, err)
+		}
+		ch.subject = tmpl
+	}
+	return nil
+}
+
+// accepts reports whether the channel takes alerts of severity
+func (ch *NotificationChannel) accepts(severity string) bool {
+	return ch.MinSeverity == "" || severityRank(severity) >= severityRank(ch.MinSeverity)
+}
+
+// render executes the message template of the channel for alert
+func (ch *NotificationChannel) render(alert *Alert) (string, error) {
+	if ch.body == nil {
+		content, err := json.Marshal(alert)
+		return string(content), err
+	}
+	var buf bytes.Buffer
+	if err := ch.body.Execute(&buf, alert); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// LoadNotificationChannels reads the channels from a JSON file of the form
+// {"channels": [...]}. ${VAR} references are replaced with environment variables, so
+// secrets can stay out of the file. No channels are configured when path is empty.
+func LoadNotificationChannels(path string) ([]*NotificationChannel, error) {
+	if path == "" {
+		return nil, nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading notification channels: %w", err)
+	}
+	var file struct {
+		Channels []*NotificationChannel `json:"channels"`
+	}
+	if err := json.Unmarshal([]byte(os.ExpandEnv(string(content))), &file); err != nil {
+		return nil, fmt.Errorf("error parsing notification channels: %w", err)
+	}
+	names := make(map[string]bool)
+	for _, ch := range file.Channels {
+		if err := ch.prepare(); err != nil {
+			return nil, err
+		}
+		if names[ch.Name] {
+			return nil, fmt.Errorf("duplicate notification channel %s", ch.Name)
+		}
+		names[ch.Name] = true
+	}
+	return file.Channels, nil
+}
+
+// ruleNotifyChannels reads the channels a rule's actions JSON sends its alerts to
+// ("notify"); ok is false when the rule does not say
+func ruleNotifyChannels(actions string) (channels []string, ok bool) {
+	var parsed struct {
+		Notify *[]string `json:"notify"`
+	}
+	if err := json.Unmarshal([]byte(actions), &parsed); err != nil || parsed.Notify == nil {
+		return nil, false
+	}
+	return *parsed.Notify, true
+}
+
+// SignWebhook returns the signature of a webhook body sent at timestamp, as found in the
+// X-FDS-Signature header
+func SignWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Notifier delivers alerts to the configured channels. Alerts are queued in the delivery
+// log with the violation that raised them and sent in the background, with retries.
+type Notifier struct {
+	db           *gorm.DB
+	channels     []*NotificationChannel
+	byName       map[string]*NotificationChannel
+	client       *http.Client
+	timeout      time.Duration
+	maxAttempts  int
+	retryBackoff time.Duration
+	interval     time.Duration
+	stopChan     chan struct{}
+	wg           sync.WaitGroup
+}
+
+// NewNotifier creates a notifier for channels. Deliveries are given up after maxAttempts,
+// waiting retryBackoff after the first failure and twice as long after each further one.
+func NewNotifier(db *gorm.DB, channels []*NotificationChannel, maxAttempts int, retryBackoff, timeout, interval time.Duration) *Notifier {
+	byName := make(map[string]*NotificationChannel)
+	for _, ch := range channels {
+		byName[ch.Name] = ch
+	}
+	return &Notifier{
+		db:           db,
+		channels:     channels,
+		byName:       byName,
+		client:       &http.Client{Timeout: timeout},
+		timeout:      timeout,
+		maxAttempts:  maxAttempts,
+		retryBackoff: retryBackoff,
+		interval:     interval,
+		stopChan:     make(chan struct{}),
+	}
+}
+
+// routes returns the channels an alert of rule goes to: those the rule names, or the
+// default channels if it names none, that accept the rule's severity
+func (n *Notifier) routes(rule *models.Rule) []*NotificationChannel {
+	var candidates []*NotificationChannel
+	if names, ok := ruleNotifyChannels(rule.Actions); ok {
+		for _, name := range names {
+			ch, found := n.byName[name]
+			if !found {
+				log.Printf("Rule %s notifies unknown channel %s", rule.Name, name)
+				continue
+			}
+			candidates = append(candidates, ch)
+		}
+	} else {
+		for _, ch := range n.channels {
+			if ch.Default {
+				candidates = append(candidates, ch)
+			}
+		}
+	}
+
+	var routed []*NotificationChannel
+	for _, ch := range candidates {
+		if ch.accepts(rule.Severity) {
+			routed = append(routed, ch)
+		}
+	}
+	return routed
+}
+
+// Enqueue queues alert for the channels of rule. Call it in the transaction recording the
+// violation so an alert is queued if and only if its violation is recorded.
+func (n *Notifier) Enqueue(db *gorm.DB, rule *models.Rule, alert Alert) error {
+	channels := n.routes(rule)
+	if len(channels) == 0 {
+		return nil
+	}
+	payload, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, ch := range channels {
+		delivery := models.NotificationDelivery{
+			Channel:         ch.Name,
+			ChannelType:     ch.Type,
+			RuleName:        alert.Rule,
+			RuleViolationID: alert.ViolationID,
+			Severity:        alert.Severity,
+			TxHash:          alert.TxHash,
+			Payload:         string(payload),
+			Status:          "pending",
+			NextAttemptAt:   now,
+		}
+		if err := db.Create(&delivery).Error; err != nil {
+			return fmt.Errorf("error queueing notification for %s: %w", ch.Name, err)
+		}
+	}
+	return nil
+}
+
+// Start begins sending queued alerts
+func (n *Notifier) Start(ctx context.Context) {
+	n.wg.Add(1)
+	go func() {
+		defer n.wg.Done()
+		ticker := time.NewTicker(n.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				n.deliverDue(ctx)
+			case <-ctx.Done():
+				return
+			case <-n.stopChan:
+				return
+			}
+		}
+	}()
+}
+
+// Stop gracefully stops the notifier
+func (n *Notifier) Stop() {
+	close(n.stopChan)
+	n.wg.Wait()
+}
+
+// deliverDue sends the queued alerts whose next attempt is due
+func (n *Notifier) deliverDue(ctx context.Context) {
+	var deliveries []models.NotificationDelivery
+	if err := n.db.Where("status = ? AND next_attempt_at <= ?", "pending", time.Now()).
+		Order("id").Limit(50).Find(&deliveries).Error; err != nil {
+		log.Printf("Error loading pending notifications: %v", err)
+		return
+	}
+	for i := range deliveries {
+		if ctx.Err() != nil {
+			return
+		}
+		n.deliver(ctx, &deliveries[i])
+	}
+}
+
+// deliver makes one attempt at a delivery and records its outcome
+func (n *Notifier) deliver(ctx context.Context, delivery *models.NotificationDelivery) {
+	delivery.Attempts++
+	status, err := n.send(ctx, delivery)
+
+	updates := map[string]interface{}{
+		"attempts":        delivery.Attempts,
+		"response_status": status,
+	}
+	switch {
+	case err == nil:
+		now := time.Now()
+		updates["status"] = "sent"
+		updates["sent_at"] = &now
+		updates["last_error"] = ""
+	case delivery.Attempts >= n.maxAttempts:
+		log.Printf("Giving up notification %d to %s after %d attempts: %v", delivery.ID, delivery.Channel, delivery.Attempts, err)
+		updates["status"] = "failed"
+		updates["last_error"] = err.Error()
+	default:
+		log.Printf("Error sending notification %d to %s, retrying: %v", delivery.ID, delivery.Channel, err)
+		updates["last_error"] = err.Error()
+		updates["next_attempt_at"] = time.Now().Add(retryDelay(n.retryBackoff, delivery.Attempts))
+	}
+	if err := n.db.Model(delivery).Updates(updates).Error; err != nil {
+		log.Printf("Error updating notification %d: %v", delivery.ID, err)
+	}
+}
+
+// send delivers an alert to its channel and returns the HTTP status of HTTP channels
+func (n *Notifier) send(ctx context.Context, delivery *models.NotificationDelivery) (int, error) {
+	ch, ok := n.byName[delivery.Channel]
+	if !ok {
+		return 0, fmt.Errorf("channel %s is not configured", delivery.Channel)
+	}
+	var alert Alert
+	if err := json.Unmarshal([]byte(delivery.Payload), &alert); err != nil {
+		return 0, fmt.Errorf("invalid alert: %w", err)
+	}
+	text, err := ch.render(&alert)
+	if err != nil {
+		return 0, fmt.Errorf("error rendering template: %w", err)
+	}
+
+	switch ch.Type {
+	case ChannelWebhook:
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		headers := map[string]string{
+			WebhookTimestampHeader: timestamp,
+			WebhookDeliveryHeader:  strconv.FormatUint(uint64(delivery.ID), 10),
+		}
+		if ch.Secret != "" {
+			headers[WebhookSignatureHeader] = SignWebhook(ch.Secret, timestamp, []byte(text))
+		}
+		return n.postJSON(ctx, ch.URL, []byte(text), headers)
+	case ChannelSlack:
+		body, _ := json.Marshal(map[string]string{"text": text})
+		return n.postJSON(ctx, ch.URL, body, nil)
+	case ChannelTelegram:
+		body, _ := json.Marshal(map[string]string{"chat_id": ch.ChatID, "text": text})
+		return n.postJSON(ctx, strings.TrimRight(ch.URL, "/")+"/bot"+ch.BotToken+"/sendMessage", body, nil)
+	case ChannelEmail:
+		var subject bytes.Buffer
+		if err := ch.subject.Execute(&subject, &alert); err != nil {
+			return 0, fmt.Errorf("error rendering subject: %w", err)
+		}
+		return 0, n.sendMail(ch, subject.String(), text)
+	}
+	return 0, fmt.Errorf("unknown channel type %q", ch.Type)
+}
+
+// postJSON posts body and fails on any status but 2xx
+func (n *Notifier) postJSON(ctx context.Context, endpoint string, body []byte, headers map[string]string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return 0, withoutURL(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return 0, withoutURL(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("responded %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// withoutURL strips the URL from a request error. Slack webhook URLs and Telegram URLs,
+// which hold the bot token, are secrets, and delivery errors are logged and shown to viewers.
+func withoutURL(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return fmt.Errorf("%s request failed: %w", urlErr.Op, urlErr.Err)
+	}
+	return err
+}
+
+// sendMail sends a plain text mail, upgrading to TLS when the server offers STARTTLS
+func (n *Notifier) sendMail(ch *NotificationChannel, subject, text string) error {
+	addr := net.JoinHostPort(ch.SMTPHost, strconv.Itoa(ch.SMTPPort))
+	conn, err := net.DialTimeout("tcp", addr, n.timeout)
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Now().Add(n.timeout))
+	client, err := smtp.NewClient(conn, ch.SMTPHost)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: ch.SMTPHost}); err != nil {
+			return err
+		}
+	}
+	if ch.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", ch.Username, ch.Password, ch.SMTPHost)); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(ch.From); err != nil {
+		return err
+	}
+	for _, to := range ch.To {
+		if err := client.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	// Keep headers on one line each; the subject comes from alert content
+	subject = strings.NewReplacer("\r", " ", "\n", " ").Replace(subject)
+	message := "From: " + ch.From + "\r\n" +
+		"To: " + strings.Join(ch.To, ", ") + "\r\n" +
+		"Subject: " + subject + "\r\n" +
+		"Date: " + time.Now().Format(time.RFC1123Z) + "\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: text/plain; charset=UTF-8\r\n" +
+		"\r\n" +
+		strings.ReplaceAll(text, "\n", "\r\n") + "\r\n"
+	if _, err := w.Write([]byte(message)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
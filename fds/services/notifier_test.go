@@ -0,0 +1,183 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"token-monitor/models"
+)
+
+// smtpStandIn accepts one mail on a local port and returns its address and the received data
+func smtpStandIn(t *testing.T) (string, <-chan string) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	received := make(chan string, 1)
+	go func() {
+		defer ln.Close()
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+		reply("220 stand-in ready")
+		var data strings.Builder
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			switch cmd := strings.ToUpper(strings.Fields(line)[0]); cmd {
+			case "EHLO", "HELO":
+				reply("250 stand-in")
+			case "DATA":
+				reply("354 go ahead")
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil || line == ".\r\n" {
+						break
+					}
+					data.WriteString(line)
+				}
+				received <- data.String()
+				reply("250 queued")
+			case "QUIT":
+				reply("221 bye")
+				return
+			default:
+				reply("250 ok")
+			}
+		}
+	}()
+	return ln.Addr().String(), received
+}
+
+func TestNotifierChannels(t *testing.T) {
+	requests := make(map[string]*http.Request)
+	bodies := make(map[string][]byte)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests[r.URL.Path] = r
+		bodies[r.URL.Path] = body
+		if r.URL.Path == "/down" {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer server.Close()
+	smtpAddr, mails := smtpStandIn(t)
+	smtpHost, smtpPort, _ := net.SplitHostPort(smtpAddr)
+
+	channels := []*NotificationChannel{
+		{Name: "hook", Type: ChannelWebhook, URL: server.URL + "/hook", Secret: "s3cret"},
+		{Name: "chat", Type: ChannelSlack, URL: server.URL + "/slack", MinSeverity: "high", Template: "{{.Rule}} by {{.From}}"},
+		{Name: "tg", Type: ChannelTelegram, URL: server.URL, BotToken: "123:abc", ChatID: "-42"},
+		{Name: "mail", Type: ChannelEmail, SMTPHost: smtpHost, From: "fds@example.com", To: []string{"officer@example.com"}},
+		{Name: "down", Type: ChannelWebhook, URL: server.URL + "/down"},
+	}
+	for _, ch := range channels {
+		if ch.Type == ChannelEmail {
+			ch.SMTPPort, _ = strconv.Atoi(smtpPort)
+		}
+		if err := ch.prepare(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	n := NewNotifier(nil, channels, 3, time.Second, 5*time.Second, time.Second)
+
+	// Routing follows the rule's notify list and the channels' minimum severity
+	medium := &models.Rule{Name: "multiple_transfers", Severity: "medium", Actions: `{"notify": ["hook", "chat", "gone"]}`}
+	if routed := n.routes(medium); len(routed) != 1 || routed[0].Name != "hook" {
+		t.Errorf("medium alert routed to %v, want hook only", routed)
+	}
+	if routed := n.routes(&models.Rule{Severity: "high", Actions: `{}`}); len(routed) != 0 {
+		t.Errorf("rule naming no channels routed to %d channels, none is a default", len(routed))
+	}
+
+	alert := Alert{ViolationID: 7, Rule: "large_transfer", Severity: "high", TxHash: "0xabc", BlockNumber: 12, From: "0x01", To: "0x02", Amount: "5"}
+	payload, _ := json.Marshal(alert)
+	send := func(channel string) (int, error) {
+		return n.send(context.Background(), &models.NotificationDelivery{Channel: channel, Payload: string(payload)})
+	}
+
+	for _, channel := range []string{"hook", "chat", "tg", "mail"} {
+		if _, err := send(channel); err != nil {
+			t.Fatalf("%s: %v", channel, err)
+		}
+	}
+
+	hook := requests["/hook"]
+	if hook == nil {
+		t.Fatal("webhook not called")
+	}
+	if got := hook.Header.Get(WebhookSignatureHeader); got != SignWebhook("s3cret", hook.Header.Get(WebhookTimestampHeader), bodies["/hook"]) {
+		t.Errorf("webhook signature %q does not verify", got)
+	}
+	var sent Alert
+	if err := json.Unmarshal(bodies["/hook"], &sent); err != nil || sent.TxHash != "0xabc" {
+		t.Errorf("webhook body %s is not the alert", bodies["/hook"])
+	}
+	if got := string(bodies["/slack"]); got != `{"text":"large_transfer by 0x01"}` {
+		t.Errorf("slack body %s", got)
+	}
+	if !strings.Contains(string(bodies["/bot123:abc/sendMessage"]), `"chat_id":"-42"`) {
+		t.Errorf("telegram body %s", bodies["/bot123:abc/sendMessage"])
+	}
+	mail := <-mails
+	if !strings.Contains(mail, "Subject: [FDS HIGH] large_transfer on 0xabc") || !strings.Contains(mail, "Transaction: 0xabc (block 12)") {
+		t.Errorf("unexpected mail:\n%s", mail)
+	}
+
+	if status, err := send("down"); err == nil || status != http.StatusBadGateway {
+		t.Errorf("failing webhook returned %d, %v", status, err)
+	}
+}
+
+func TestNotifierErrorsHideSecretURLs(t *testing.T) {
+	db := testDB(t, &models.NotificationDelivery{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	unreachable := server.URL
+	server.Close()
+
+	channels := []*NotificationChannel{
+		{Name: "tg", Type: ChannelTelegram, URL: unreachable, BotToken: "123456:SECRET-token", ChatID: "-42"},
+		{Name: "chat", Type: ChannelSlack, URL: unreachable + "/services/T000/B000/SECRET-hook"},
+	}
+	for _, ch := range channels {
+		if err := ch.prepare(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	n := NewNotifier(db, channels, 3, time.Second, 5*time.Second, time.Second)
+
+	payload, _ := json.Marshal(Alert{Rule: "large_transfer", Severity: "high", TxHash: "0xabc"})
+	for _, ch := range channels {
+		delivery := models.NotificationDelivery{Channel: ch.Name, ChannelType: ch.Type, Payload: string(payload)}
+		if err := db.Create(&delivery).Error; err != nil {
+			t.Fatal(err)
+		}
+		n.deliver(context.Background(), &delivery)
+
+		var stored models.NotificationDelivery
+		if err := db.First(&stored, delivery.ID).Error; err != nil {
+			t.Fatal(err)
+		}
+		if stored.LastError == "" {
+			t.Errorf("%s: delivery to a closed server recorded no error", ch.Name)
+		}
+		if strings.Contains(stored.LastError, "SECRET") {
+			t.Errorf("%s: last_error %q leaks the channel URL", ch.Name, stored.LastError)
+		}
+	}
+}
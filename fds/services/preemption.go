@@ -0,0 +1,453 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"token-monitor/contracts/restrict"
+	"token-monitor/models"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"gorm.io/gorm"
+)
+
+// PreemptionConfig holds the fee bidding and speed-up settings for pre-emptive blacklisting
+type PreemptionConfig struct {
+	TipBumpPercent  int64         // How much higher than the target's tip to bid, in percent
+	ReplaceAfter    time.Duration // How long a blacklist transaction may stay pending before it is sped up
+	MaxReplacements int           // Maximum number of speed-ups per blacklist transaction
+}
+
+// PreemptionTracker sends blacklist transactions that front-run suspicious pending
+// transactions, speeds them up while they are stuck and records whether they landed first
+type PreemptionTracker struct {
+	db             *gorm.DB
+	client         *ethclient.Client
+	restrictClient *restrict.Restrict
+	ownerKey       *bind.TransactOpts
+	nonces         *NonceManager
+	config         PreemptionConfig
+	interval       time.Duration
+	stopChan       chan struct{}
+	wg             sync.WaitGroup
+	mu             sync.Mutex // Serialises nonce allocation and replacements
+}
+
+// NewPreemptionTracker creates a new pre-emptive blacklist tracker
+func NewPreemptionTracker(db *gorm.DB, client *ethclient.Client, restrictClient *restrict.Restrict, ownerKey *bind.TransactOpts, nonces *NonceManager, config PreemptionConfig, interval time.Duration) *PreemptionTracker {
+	return &PreemptionTracker{
+		db:             db,
+		client:         client,
+		restrictClient: restrictClient,
+		ownerKey:       ownerKey,
+		nonces:         nonces,
+		config:         config,
+		interval:       interval,
+		stopChan:       make(chan struct{}),
+	}
+}
+
+// Start begins tracking submitted blacklist transactions
+func (p *PreemptionTracker) Start(ctx context.Context) {
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				p.trackSubmitted(ctx)
+				p.resolveOutcomes(ctx)
+			case <-ctx.Done():
+				return
+			case <-p.stopChan:
+				return
+			}
+		}
+	}()
+}
+
+// Stop gracefully stops the tracker
+func (p *PreemptionTracker) Stop() {
+	close(p.stopChan)
+	p.wg.Wait()
+}
+
+// Submit sends a blacklist transaction for address in direction that outbids the pending
+// target transaction. reason is written to the contract.
+func (p *PreemptionTracker) Submit(ctx context.Context, targetTxHash, address, direction, reason string) (*models.PreemptiveBlacklist, error) {
+	// Skip if a blacklist for this address is already in flight
+	var inFlight int64
+	if err := p.db.Model(&models.PreemptiveBlacklist{}).
+		Where("address = ? AND status = ?", address, "submitted").
+		Count(&inFlight).Error; err != nil {
+		return nil, fmt.Errorf("error checking in-flight blacklist: %w", err)
+	}
+	if inFlight > 0 {
+		return nil, fmt.Errorf("blacklist for %s is already in flight", address)
+	}
+
+	targetTip, targetFeeCap := p.targetFees(targetTxHash)
+
+	tip, feeCap, err := p.bidFees(ctx, targetTip, targetFeeCap)
+	if err != nil {
+		return nil, fmt.Errorf("error bidding fees: %w", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	nonce, err := p.nonces.Next(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if reason == "" {
+		reason = "fds: pre-emptive blacklist"
+	}
+
+	tx, err := p.send(ctx, address, direction, reason, nonce, tip, feeCap)
+	if err != nil {
+		p.nonces.Reset()
+		return nil, err
+	}
+
+	record := &models.PreemptiveBlacklist{
+		Address:         address,
+		Direction:       direction,
+		Reason:          reason,
+		TargetTxHash:    targetTxHash,
+		TargetGasTipCap: targetTip.String(),
+		TargetGasFeeCap: targetFeeCap.String(),
+		TxHash:          tx.Hash().Hex(),
+		Nonce:           nonce,
+		GasTipCap:       tip.String(),
+		GasFeeCap:       feeCap.String(),
+		LastSentAt:      time.Now(),
+		Status:          "submitted",
+	}
+	if err := p.db.Create(record).Error; err != nil {
+		return nil, fmt.Errorf("error storing pre-emptive blacklist: %w", err)
+	}
+
+	log.Printf("Pre-emptive blacklist for %s sent: %s (tip %s, target tip %s)", address, tx.Hash().Hex(), tip, targetTip)
+	return record, nil
+}
+
+// targetFees returns the tip and fee cap of the target transaction as recorded by the mempool monitor
+func (p *PreemptionTracker) targetFees(targetTxHash string) (*big.Int, *big.Int) {
+	tip, feeCap := new(big.Int), new(big.Int)
+
+	var pendingTx models.PendingTransaction
+	if err := p.db.Where("hash = ?", targetTxHash).First(&pendingTx).Error; err != nil {
+		return tip, feeCap
+	}
+	tip.SetString(pendingTx.GasTipCap, 10)
+	feeCap.SetString(pendingTx.GasFeeCap, 10)
+	return tip, feeCap
+}
+
+// bidFees returns EIP-1559 fees that outbid the target transaction's tip by TipBumpPercent
+func (p *PreemptionTracker) bidFees(ctx context.Context, targetTip, targetFeeCap *big.Int) (*big.Int, *big.Int, error) {
+	suggestedTip, err := p.client.SuggestGasTipCap(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to suggest gas tip: %w", err)
+	}
+	header, err := p.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get latest header: %w", err)
+	}
+	baseFee := header.BaseFee
+	if baseFee == nil {
+		baseFee = new(big.Int)
+	}
+
+	return computeBid(targetTip, targetFeeCap, suggestedTip, baseFee, p.config.TipBumpPercent)
+}
+
+// computeBid outbids targetTip by bumpPercent (and at least by one wei), never bids below the
+// suggested tip, and caps the fee so that it covers twice the base fee plus the tip
+func computeBid(targetTip, targetFeeCap, suggestedTip, baseFee *big.Int, bumpPercent int64) (*big.Int, *big.Int, error) {
+	tip := bumpByPercent(targetTip, bumpPercent)
+	if tip.Cmp(targetTip) <= 0 {
+		tip = new(big.Int).Add(targetTip, big.NewInt(1))
+	}
+	if tip.Cmp(suggestedTip) < 0 {
+		tip = new(big.Int).Set(suggestedTip)
+	}
+
+	feeCap := new(big.Int).Add(new(big.Int).Mul(baseFee, big.NewInt(2)), tip)
+	if bumped := bumpByPercent(targetFeeCap, bumpPercent); bumped.Cmp(feeCap) > 0 {
+		feeCap = bumped
+	}
+	if feeCap.Cmp(tip) < 0 {
+		return nil, nil, errors.New("fee cap below tip")
+	}
+
+	return tip, feeCap, nil
+}
+
+// bumpByPercent returns value increased by percent
+func bumpByPercent(value *big.Int, percent int64) *big.Int {
+	bumped := new(big.Int).Mul(value, big.NewInt(100+percent))
+	return bumped.Div(bumped, big.NewInt(100))
+}
+
+// send signs and sends a blacklist transaction with the given nonce and fees
+func (p *PreemptionTracker) send(ctx context.Context, address, direction, reason string, nonce uint64, tip, feeCap *big.Int) (*types.Transaction, error) {
+	opts := *p.ownerKey
+	opts.Context = ctx
+	opts.Nonce = new(big.Int).SetUint64(nonce)
+	opts.GasPrice = nil
+	opts.GasTipCap = tip
+	opts.GasFeeCap = feeCap
+
+	accounts := []common.Address{common.HexToAddress(address)}
+	var tx *types.Transaction
+	var err error
+	switch direction {
+	case DirectionFrom:
+		tx, err = p.restrictClient.BlacklistFrom(&opts, accounts, reason)
+	case DirectionTo:
+		tx, err = p.restrictClient.BlacklistTo(&opts, accounts, reason)
+	default:
+		tx, err = p.restrictClient.Blacklist(&opts, accounts, reason)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to send blacklist transaction: %w", err)
+	}
+	return tx, nil
+}
+
+// trackSubmitted checks submitted blacklist transactions and speeds up the ones that are stuck
+func (p *PreemptionTracker) trackSubmitted(ctx context.Context) {
+	var records []models.PreemptiveBlacklist
+	if err := p.db.Where("status = ?", "submitted").Find(&records).Error; err != nil {
+		log.Printf("Error querying submitted pre-emptive blacklists: %v", err)
+		return
+	}
+
+	for i := range records {
+		record := &records[i]
+
+		// Any of the sent transactions may be the one that got mined
+		receipt := p.findReceipt(ctx, record)
+		if receipt != nil {
+			p.recordMined(record, receipt)
+			continue
+		}
+
+		if time.Since(record.LastSentAt) < p.config.ReplaceAfter {
+			continue
+		}
+		if record.Replacements >= p.config.MaxReplacements {
+			p.failIfNonceConsumed(ctx, record)
+			continue
+		}
+		if err := p.speedUp(ctx, record); err != nil {
+			log.Printf("Error speeding up blacklist transaction %s: %v", record.TxHash, err)
+		}
+	}
+}
+
+// findReceipt returns the receipt of whichever of the record's transactions was mined
+func (p *PreemptionTracker) findReceipt(ctx context.Context, record *models.PreemptiveBlacklist) *types.Receipt {
+	hashes := []string{record.TxHash}
+	if record.ReplacedTxHashes != "" {
+		hashes = append(hashes, strings.Split(record.ReplacedTxHashes, ",")...)
+	}
+
+	for _, hash := range hashes {
+		receipt, err := p.client.TransactionReceipt(ctx, common.HexToHash(hash))
+		if err == nil && receipt != nil {
+			return receipt
+		}
+	}
+	return nil
+}
+
+// speedUp replaces a stuck blacklist transaction with one using the same nonce and higher fees
+func (p *PreemptionTracker) speedUp(ctx context.Context, record *models.PreemptiveBlacklist) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	prevTip, _ := new(big.Int).SetString(record.GasTipCap, 10)
+	prevFeeCap, _ := new(big.Int).SetString(record.GasFeeCap, 10)
+	if prevTip == nil || prevFeeCap == nil {
+		return fmt.Errorf("invalid recorded fees")
+	}
+
+	// Nodes require at least a 10% bump on both fees to accept a replacement
+	bump := p.config.TipBumpPercent
+	if bump < 10 {
+		bump = 10
+	}
+	tip, feeCap, err := p.bidFees(ctx, prevTip, prevFeeCap)
+	if err != nil {
+		return err
+	}
+	if minTip := bumpByPercent(prevTip, bump); tip.Cmp(minTip) < 0 {
+		tip = minTip
+	}
+	if minFeeCap := bumpByPercent(prevFeeCap, bump); feeCap.Cmp(minFeeCap) < 0 {
+		feeCap = minFeeCap
+	}
+
+	tx, err := p.send(ctx, record.Address, record.Direction, record.Reason, record.Nonce, tip, feeCap)
+	if err != nil {
+		if strings.Contains(err.Error(), "nonce too low") {
+			// The nonce was consumed; the next pass either finds our receipt or gives up
+			return p.db.Model(record).Update("replacements", p.config.MaxReplacements).Error
+		}
+		return err
+	}
+
+	replaced := record.TxHash
+	if record.ReplacedTxHashes != "" {
+		replaced = record.ReplacedTxHashes + "," + replaced
+	}
+
+	log.Printf("Sped up blacklist for %s: %s -> %s (tip %s)", record.Address, record.TxHash, tx.Hash().Hex(), tip)
+	return p.db.Model(record).Updates(map[string]interface{}{
+		"tx_hash":            tx.Hash().Hex(),
+		"replaced_tx_hashes": replaced,
+		"gas_tip_cap":        tip.String(),
+		"gas_fee_cap":        feeCap.String(),
+		"replacements":       record.Replacements + 1,
+		"last_sent_at":       time.Now(),
+	}).Error
+}
+
+// failIfNonceConsumed marks a record as failed when its nonce was used by a transaction
+// other than the ones it sent
+func (p *PreemptionTracker) failIfNonceConsumed(ctx context.Context, record *models.PreemptiveBlacklist) {
+	nonce, err := p.client.NonceAt(ctx, p.ownerKey.From, nil)
+	if err != nil || nonce <= record.Nonce {
+		return
+	}
+
+	if err := p.db.Model(record).Updates(map[string]interface{}{
+		"status":  "failed",
+		"outcome": "failed",
+	}).Error; err != nil {
+		log.Printf("Error updating pre-emptive blacklist %d: %v", record.ID, err)
+		return
+	}
+	log.Printf("Pre-emptive blacklist for %s failed: nonce %d was consumed by another transaction", record.Address, record.Nonce)
+}
+
+// recordMined stores the mined position of a blacklist transaction
+func (p *PreemptionTracker) recordMined(record *models.PreemptiveBlacklist, receipt *types.Receipt) {
+	updates := map[string]interface{}{
+		"tx_hash":      receipt.TxHash.Hex(),
+		"block_number": receipt.BlockNumber.Uint64(),
+		"tx_index":     receipt.TransactionIndex,
+	}
+
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		updates["status"] = "failed"
+		updates["outcome"] = "failed"
+		if err := p.db.Model(record).Updates(updates).Error; err != nil {
+			log.Printf("Error updating pre-emptive blacklist %d: %v", record.ID, err)
+		}
+		log.Printf("Pre-emptive blacklist transaction %s for %s failed", receipt.TxHash.Hex(), record.Address)
+		return
+	}
+
+	updates["status"] = "mined"
+	err := p.db.Transaction(func(db *gorm.DB) error {
+		if err := db.Model(record).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		// Store in blacklisted table unless it is already there
+		if err := recordBlacklisted(db, models.EnforcementAction{
+			Address:   record.Address,
+			Direction: record.Direction,
+			Source:    "preemption",
+			Reason:    "Pre-emptive blacklist of suspicious pending transaction",
+			Severity:  "high",
+			Details:   fmt.Sprintf("Front-run of pending transaction %s (%s)", record.TargetTxHash, record.Reason),
+		}, receipt.TxHash.Hex(), receipt.BlockNumber.Uint64()); err != nil {
+			return err
+		}
+
+		return db.Model(&models.SuspiciousTransfer{}).
+			Where("tx_hash = ?", record.TargetTxHash).
+			Update("is_blacklisted", true).Error
+	})
+	if err != nil {
+		log.Printf("Error recording mined pre-emptive blacklist %d: %v", record.ID, err)
+		return
+	}
+
+	log.Printf("Pre-emptive blacklist for %s mined in block %d at index %d", record.Address, receipt.BlockNumber.Uint64(), receipt.TransactionIndex)
+}
+
+// resolveOutcomes compares the position of mined blacklist transactions with their targets
+func (p *PreemptionTracker) resolveOutcomes(ctx context.Context) {
+	var records []models.PreemptiveBlacklist
+	if err := p.db.Where("status = ? AND (outcome = '' OR outcome IS NULL)", "mined").Find(&records).Error; err != nil {
+		log.Printf("Error querying unresolved pre-emptive blacklists: %v", err)
+		return
+	}
+
+	for i := range records {
+		record := &records[i]
+		targetHash := common.HexToHash(record.TargetTxHash)
+
+		receipt, err := p.client.TransactionReceipt(ctx, targetHash)
+		if err != nil || receipt == nil {
+			// Target not mined yet; it is dropped once the node no longer knows it
+			if _, _, err := p.client.TransactionByHash(ctx, targetHash); errors.Is(err, ethereum.NotFound) {
+				p.db.Model(record).Updates(map[string]interface{}{
+					"target_status": "dropped",
+					"outcome":       "target_dropped",
+				})
+			}
+			continue
+		}
+
+		targetStatus := "mined"
+		if receipt.Status != types.ReceiptStatusSuccessful {
+			targetStatus = "reverted"
+		}
+
+		outcome := "too_late"
+		if precedes(record.BlockNumber, record.TxIndex, receipt.BlockNumber.Uint64(), receipt.TransactionIndex) {
+			outcome = "preceded"
+		}
+
+		if err := p.db.Model(record).Updates(map[string]interface{}{
+			"target_status":       targetStatus,
+			"target_block_number": receipt.BlockNumber.Uint64(),
+			"target_tx_index":     receipt.TransactionIndex,
+			"outcome":             outcome,
+		}).Error; err != nil {
+			log.Printf("Error updating pre-emptive blacklist outcome %d: %v", record.ID, err)
+			continue
+		}
+
+		log.Printf("Pre-emptive blacklist for %s %s target %s (%s)", record.Address, outcome, record.TargetTxHash, targetStatus)
+	}
+}
+
+// precedes reports whether position (block, index) comes before (targetBlock, targetIndex)
+func precedes(block uint64, index uint, targetBlock uint64, targetIndex uint) bool {
+	if block != targetBlock {
+		return block < targetBlock
+	}
+	return index < targetIndex
+}
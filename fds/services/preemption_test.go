@@ -0,0 +1,49 @@
+package services
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestComputeBid(t *testing.T) {
+	gwei := big.NewInt(1e9)
+	targetTip := new(big.Int).Mul(big.NewInt(4), gwei)
+	targetFeeCap := new(big.Int).Mul(big.NewInt(100), gwei)
+	suggestedTip := new(big.Int).Mul(big.NewInt(1), gwei)
+	baseFee := new(big.Int).Mul(big.NewInt(10), gwei)
+
+	tip, feeCap, err := computeBid(targetTip, targetFeeCap, suggestedTip, baseFee, 25)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := new(big.Int).Mul(big.NewInt(5), gwei); tip.Cmp(want) != 0 {
+		t.Errorf("want tip %s, got %s", want, tip)
+	}
+	if want := new(big.Int).Mul(big.NewInt(125), gwei); feeCap.Cmp(want) != 0 {
+		t.Errorf("want fee cap %s, got %s", want, feeCap)
+	}
+
+	// Unknown target fees fall back to the suggested tip
+	tip, feeCap, err = computeBid(new(big.Int), new(big.Int), suggestedTip, baseFee, 25)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tip.Cmp(suggestedTip) != 0 {
+		t.Errorf("want tip %s, got %s", suggestedTip, tip)
+	}
+	if want := new(big.Int).Mul(big.NewInt(21), gwei); feeCap.Cmp(want) != 0 {
+		t.Errorf("want fee cap %s, got %s", want, feeCap)
+	}
+}
+
+func TestPrecedes(t *testing.T) {
+	if !precedes(10, 5, 11, 0) {
+		t.Error("earlier block must precede")
+	}
+	if !precedes(10, 1, 10, 2) {
+		t.Error("lower index in the same block must precede")
+	}
+	if precedes(10, 2, 10, 1) || precedes(11, 0, 10, 9) {
+		t.Error("later position must not precede")
+	}
+}
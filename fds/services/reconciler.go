@@ -0,0 +1,470 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"token-monitor/contracts/restrict"
+	"token-monitor/models"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// restrictionEvents maps the restriction contract events to the direction they change
+// and whether they restrict or lift a restriction
+var restrictionEvents = map[string]struct {
+	direction  string
+	restricted bool
+}{
+	"AddressBlacklisted":       {DirectionBoth, true},
+	"AddressBlacklistedFrom":   {DirectionFrom, true},
+	"AddressBlacklistedTo":     {DirectionTo, true},
+	"AddressUnblacklisted":     {DirectionBoth, false},
+	"AddressUnblacklistedFrom": {DirectionFrom, false},
+	"AddressUnblacklistedTo":   {DirectionTo, false},
+}
+
+// Reconciler keeps the blacklisted_addresses table in line with the restriction contract.
+// It ingests the contract's blacklist events and periodically compares the database with
+// isBlacklistedFrom/isBlacklistedTo, recording and optionally repairing discrepancies.
+type Reconciler struct {
+	db             *gorm.DB
+	client         *ethclient.Client
+	restrictClient *restrict.Restrict
+	restrictAddr   common.Address
+	restrictABI    abi.ABI
+	startBlock     uint64 // First block to ingest events from
+	nextBlock      uint64 // Next block to ingest events from, 0 until initialised
+	chunkSize      uint64 // Number of blocks per log query
+	repair         bool   // Whether discrepancies are repaired or only reported
+	interval       time.Duration
+	stopChan       chan struct{}
+	wg             sync.WaitGroup
+}
+
+// NewReconciler creates a new blacklist reconciler
+func NewReconciler(db *gorm.DB, client *ethclient.Client, restrictClient *restrict.Restrict, restrictAddr common.Address, startBlock uint64, repair bool, interval time.Duration) (*Reconciler, error) {
+	restrictABI, err := abi.JSON(strings.NewReader(restrict.RestrictABI))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing restrict ABI: %w", err)
+	}
+
+	return &Reconciler{
+		db:             db,
+		client:         client,
+		restrictClient: restrictClient,
+		restrictAddr:   restrictAddr,
+		restrictABI:    restrictABI,
+		startBlock:     startBlock,
+		chunkSize:      2000,
+		repair:         repair,
+		interval:       interval,
+		stopChan:       make(chan struct{}),
+	}, nil
+}
+
+// Start begins ingesting events and reconciling the blacklist
+func (r *Reconciler) Start(ctx context.Context) {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+
+		for {
+			r.ingestEvents(ctx)
+			r.reconcile(ctx)
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			case <-r.stopChan:
+				return
+			}
+		}
+	}()
+}
+
+// Stop gracefully stops the reconciler
+func (r *Reconciler) Stop() {
+	close(r.stopChan)
+	r.wg.Wait()
+}
+
+// ingestEvents stores the restriction events emitted since the last run
+func (r *Reconciler) ingestEvents(ctx context.Context) {
+	if r.nextBlock == 0 {
+		// Resume from the last ingested block; duplicates are ignored on insert
+		var last uint64
+		if err := r.db.Model(&models.RestrictionEvent{}).
+			Select("COALESCE(MAX(block_number), 0)").
+			Scan(&last).Error; err != nil {
+			log.Printf("Error getting last restriction event block: %v", err)
+			return
+		}
+		r.nextBlock = r.startBlock
+		if last > r.nextBlock {
+			r.nextBlock = last
+		}
+	}
+
+	latest, err := r.client.BlockNumber(ctx)
+	if err != nil {
+		log.Printf("Error getting latest block: %v", err)
+		return
+	}
+
+	var topics []common.Hash
+	for name := range restrictionEvents {
+		topics = append(topics, r.restrictABI.Events[name].ID)
+	}
+
+	for from := r.nextBlock; from <= latest; from += r.chunkSize {
+		to := from + r.chunkSize - 1
+		if to > latest {
+			to = latest
+		}
+
+		logs, err := r.client.FilterLogs(ctx, ethereum.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(from),
+			ToBlock:   new(big.Int).SetUint64(to),
+			Addresses: []common.Address{r.restrictAddr},
+			Topics:    [][]common.Hash{topics},
+		})
+		if err != nil {
+			log.Printf("Error filtering restriction events in blocks %d-%d: %v", from, to, err)
+			return
+		}
+
+		for _, lg := range logs {
+			event, ok := decodeRestrictionLog(r.restrictABI, lg)
+			if !ok {
+				continue
+			}
+			if err := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(event).Error; err != nil {
+				log.Printf("Error storing restriction event %s/%d: %v", event.TxHash, event.LogIndex, err)
+				return
+			}
+		}
+
+		r.nextBlock = to + 1
+	}
+}
+
+// decodeRestrictionLog decodes a blacklist or unblacklist event of the restriction contract
+func decodeRestrictionLog(contractABI abi.ABI, lg types.Log) (*models.RestrictionEvent, bool) {
+	if len(lg.Topics) < 3 {
+		return nil, false
+	}
+	event, err := contractABI.EventByID(lg.Topics[0])
+	if err != nil {
+		return nil, false
+	}
+	kind, ok := restrictionEvents[event.Name]
+	if !ok {
+		return nil, false
+	}
+
+	var data struct {
+		Reason    string
+		Timestamp *big.Int
+	}
+	if err := contractABI.UnpackIntoInterface(&data, event.Name, lg.Data); err != nil {
+		return nil, false
+	}
+
+	return &models.RestrictionEvent{
+		Address:     common.HexToAddress(lg.Topics[1].Hex()).Hex(),
+		Event:       event.Name,
+		Direction:   kind.direction,
+		Restricted:  kind.restricted,
+		Reason:      data.Reason,
+		Actor:       common.HexToAddress(lg.Topics[2].Hex()).Hex(),
+		Timestamp:   time.Unix(data.Timestamp.Int64(), 0),
+		TxHash:      lg.TxHash.Hex(),
+		LogIndex:    lg.Index,
+		BlockNumber: lg.BlockNumber,
+	}, true
+}
+
+// restrictionDrift compares the direction recorded in the database with the direction
+// restricted on-chain and returns the kind of discrepancy, or "" if they agree.
+// An empty direction means not restricted (or no row); legacy rows restrict both directions.
+func restrictionDrift(inDB bool, dbDirection, chainDirection string) string {
+	if inDB && dbDirection == "" {
+		dbDirection = DirectionBoth
+	}
+
+	switch {
+	case !inDB && chainDirection == "":
+		return ""
+	case !inDB:
+		return "missing_in_db"
+	case chainDirection == "":
+		return "missing_on_chain"
+	case dbDirection != chainDirection:
+		return "direction_mismatch"
+	}
+	return ""
+}
+
+// chainDirection returns the direction address is restricted in on-chain, or "" if it is not restricted
+func (r *Reconciler) chainDirection(ctx context.Context, address string) (string, error) {
+	opts := &bind.CallOpts{Context: ctx}
+	account := common.HexToAddress(address)
+
+	from, err := r.restrictClient.IsBlacklistedFrom(opts, account)
+	if err != nil {
+		return "", err
+	}
+	to, err := r.restrictClient.IsBlacklistedTo(opts, account)
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case from && to:
+		return DirectionBoth, nil
+	case from:
+		return DirectionFrom, nil
+	case to:
+		return DirectionTo, nil
+	}
+	return "", nil
+}
+
+// reconcile compares every known address with the contract and records the drift
+func (r *Reconciler) reconcile(ctx context.Context) {
+	var rows []models.BlacklistedAddress
+	if err := r.db.Find(&rows).Error; err != nil {
+		log.Printf("Error querying blacklisted addresses: %v", err)
+		return
+	}
+	byAddress := make(map[string]*models.BlacklistedAddress)
+	for i := range rows {
+		byAddress[common.HexToAddress(rows[i].Address).Hex()] = &rows[i]
+	}
+
+	var eventAddresses []string
+	if err := r.db.Model(&models.RestrictionEvent{}).Distinct().Pluck("address", &eventAddresses).Error; err != nil {
+		log.Printf("Error querying restriction event addresses: %v", err)
+		return
+	}
+
+	addresses := make([]string, 0, len(byAddress)+len(eventAddresses))
+	for addr := range byAddress {
+		addresses = append(addresses, addr)
+	}
+	for _, addr := range eventAddresses {
+		if _, ok := byAddress[addr]; !ok {
+			addresses = append(addresses, addr)
+		}
+	}
+
+	seen := make(map[uint]bool)
+	var checked []string
+	for _, addr := range addresses {
+		if r.inFlight(addr) {
+			continue
+		}
+
+		chainDir, err := r.chainDirection(ctx, addr)
+		if err != nil {
+			log.Printf("Error reading restriction of %s: %v", addr, err)
+			continue
+		}
+		checked = append(checked, addr)
+
+		row := byAddress[addr]
+		dbDir := ""
+		if row != nil {
+			dbDir = row.Direction
+			if dbDir == "" {
+				dbDir = DirectionBoth
+			}
+			if row.BlockNumber == 0 && chainDir != "" && r.repair {
+				r.fillBlacklistTx(row)
+			}
+		}
+
+		kind := restrictionDrift(row != nil, dbDir, chainDir)
+		if kind == "" {
+			continue
+		}
+
+		repaired, action := false, ""
+		if r.repair {
+			var err error
+			action, err = r.repairDrift(kind, addr, row, chainDir)
+			if err != nil {
+				log.Printf("Error repairing %s drift of %s: %v", kind, addr, err)
+			} else {
+				repaired = true
+			}
+		}
+
+		log.Printf("Blacklist drift for %s: %s (db: %q, chain: %q) %s", addr, kind, dbDir, chainDir, action)
+		if id := r.recordDrift(addr, kind, dbDir, chainDir, repaired, action); id != 0 {
+			seen[id] = true
+		}
+	}
+
+	// Open drifts of the addresses checked that were not detected again are resolved;
+	// addresses skipped keep theirs until checked
+	if len(checked) == 0 {
+		return
+	}
+	query := r.db.Model(&models.BlacklistDrift{}).Where("resolved_at IS NULL AND address IN ?", checked)
+	if len(seen) > 0 {
+		ids := make([]uint, 0, len(seen))
+		for id := range seen {
+			ids = append(ids, id)
+		}
+		query = query.Where("id NOT IN ?", ids)
+	}
+	if err := query.Update("resolved_at", time.Now()).Error; err != nil {
+		log.Printf("Error resolving blacklist drift: %v", err)
+	}
+}
+
+// inFlight reports whether address has a blacklist transaction that is not mined yet,
+// in which case the database and the chain are expected to differ
+func (r *Reconciler) inFlight(address string) bool {
+	var count int64
+	r.db.Model(&models.EnforcementAction{}).
+		Where("address = ? AND status IN ?", address, []string{"pending", "submitted"}).
+		Count(&count)
+	if count > 0 {
+		return true
+	}
+	r.db.Model(&models.PreemptiveBlacklist{}).
+		Where("address = ? AND status = ?", address, "submitted").
+		Count(&count)
+	return count > 0
+}
+
+// latestEvent returns the most recent restriction event of address, blacklist or
+// unblacklist, if any
+func (r *Reconciler) latestEvent(address string) *models.RestrictionEvent {
+	var event models.RestrictionEvent
+	if err := r.db.Where("address = ?", address).
+		Order("block_number DESC, log_index DESC").
+		First(&event).Error; err != nil {
+		return nil
+	}
+	return &event
+}
+
+// repairDrift brings the database in line with the chain, which is authoritative.
+// A row whose restriction never reached the chain is queued for blacklisting again.
+func (r *Reconciler) repairDrift(kind, address string, row *models.BlacklistedAddress, chainDir string) (string, error) {
+	switch kind {
+	case "missing_in_db":
+		txHash, blockNumber, reason := "", uint64(0), "Restricted on-chain"
+		if event := r.latestEvent(address); event != nil && event.Restricted {
+			txHash, blockNumber, reason = event.TxHash, event.BlockNumber, event.Reason
+		}
+		// Restricted outside FDS, so the entry is not a provisional freeze
+		if err := recordBlacklisted(r.db, models.EnforcementAction{
+			Address:   address,
+			Direction: chainDir,
+			Source:    "reconciler",
+			Reason:    reason,
+			Details:   "Recovered from on-chain restriction by reconciler",
+			HoldType:  HoldManual,
+		}, txHash, blockNumber); err != nil {
+			return "", err
+		}
+		return "added database entry", nil
+
+	case "missing_on_chain":
+		latest := r.latestEvent(address)
+		if latest != nil && latest.Restricted {
+			// The unblacklist event that lifted it has not been ingested yet
+			return "", fmt.Errorf("latest restriction event at block %d still restricts %s", latest.BlockNumber, address)
+		}
+		if err := liftBlacklisted(r.db, row, "reconciler", "Not restricted on-chain", 0); err != nil {
+			return "", err
+		}
+		if latest != nil {
+			// The restriction was lifted on-chain
+			return "removed database entry", nil
+		}
+		// The restriction never reached the chain
+		if _, err := EnqueueBlacklist(r.db, models.EnforcementAction{
+			Address:   address,
+			Direction: row.Direction,
+			Source:    "reconciler",
+			Reason:    row.Reason,
+			Severity:  row.Severity,
+			Details:   row.Details,
+			HoldType:  row.HoldType,
+		}); err != nil {
+			return "", err
+		}
+		return "removed database entry and queued blacklist", nil
+
+	case "direction_mismatch":
+		if err := r.db.Model(row).Update("direction", chainDir).Error; err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("set direction to %s", chainDir), nil
+	}
+	return "", fmt.Errorf("unknown drift kind %s", kind)
+}
+
+// fillBlacklistTx sets the transaction and block of a row written before its transaction was mined
+func (r *Reconciler) fillBlacklistTx(row *models.BlacklistedAddress) {
+	event := r.latestEvent(common.HexToAddress(row.Address).Hex())
+	if event == nil || !event.Restricted {
+		return
+	}
+	if err := r.db.Model(row).Updates(map[string]interface{}{
+		"tx_hash":      event.TxHash,
+		"block_number": event.BlockNumber,
+	}).Error; err != nil {
+		log.Printf("Error updating blacklist transaction of %s: %v", row.Address, err)
+	}
+}
+
+// recordDrift creates or refreshes the open drift record of address and kind and returns its ID
+func (r *Reconciler) recordDrift(address, kind, dbDir, chainDir string, repaired bool, action string) uint {
+	now := time.Now()
+
+	var drift models.BlacklistDrift
+	err := r.db.Where("address = ? AND kind = ? AND resolved_at IS NULL", address, kind).First(&drift).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		log.Printf("Error querying blacklist drift of %s: %v", address, err)
+		return 0
+	}
+
+	drift.Address = address
+	drift.Kind = kind
+	drift.DBDirection = dbDir
+	drift.ChainDirection = chainDir
+	drift.Repaired = repaired
+	drift.RepairAction = action
+	drift.LastSeenAt = now
+	if repaired {
+		drift.ResolvedAt = &now
+	}
+
+	if err := r.db.Save(&drift).Error; err != nil {
+		log.Printf("Error storing blacklist drift of %s: %v", address, err)
+		return 0
+	}
+	return drift.ID
+}
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+
+	"token-monitor/models"
+)
+
+func TestRestrictionDrift(t *testing.T) {
+	cases := []struct {
+		inDB           bool
+		dbDirection    string
+		chainDirection string
+		want           string
+	}{
+		{false, "", "", ""},
+		{false, "", DirectionTo, "missing_in_db"},
+		{true, DirectionBoth, "", "missing_on_chain"},
+		{true, DirectionFrom, DirectionBoth, "direction_mismatch"},
+		{true, DirectionTo, DirectionTo, ""},
+		{true, "", DirectionBoth, ""},
+	}
+	for _, c := range cases {
+		if got := restrictionDrift(c.inDB, c.dbDirection, c.chainDirection); got != c.want {
+			t.Errorf("restrictionDrift(%v, %q, %q) = %q, want %q", c.inDB, c.dbDirection, c.chainDirection, got, c.want)
+		}
+	}
+}
+
+func TestRepairMissingOnChainFollowsLatestEvent(t *testing.T) {
+	db := testDB(t, &models.RestrictionEvent{}, &models.BlacklistedAddress{}, &models.BlacklistStatusChange{},
+		&models.EnforcementAction{})
+	r := &Reconciler{db: db, repair: true}
+	const address = "0x476C88ED464EFD251a8b18Eb84785F7C46807873"
+	event := func(block uint64, index uint, restricted bool) {
+		t.Helper()
+		if err := db.Create(&models.RestrictionEvent{Address: address, Event: "event", Direction: DirectionBoth, Restricted: restricted,
+			TxHash: fmt.Sprintf("0x%x", block), LogIndex: index, BlockNumber: block}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	row := models.BlacklistedAddress{Address: address, Direction: DirectionBoth, Status: "confirmed"}
+	if err := db.Create(&row).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// Lifted once, then restricted again in a later log of the same block
+	event(5, 0, true)
+	event(7, 0, false)
+	event(7, 1, true)
+	if latest := r.latestEvent(address); latest == nil || latest.BlockNumber != 7 || !latest.Restricted {
+		t.Fatalf("latest event %+v, want the restriction at block 7", latest)
+	}
+	// The chain reads unrestricted but the events have not caught up: nothing is repaired
+	if _, err := r.repairDrift("missing_on_chain", address, &row, ""); err == nil {
+		t.Error("repaired against a stale restriction event")
+	}
+	var count int64
+	db.Model(&models.BlacklistedAddress{}).Count(&count)
+	if count != 1 {
+		t.Errorf("entry lifted against a stale restriction event")
+	}
+
+	event(8, 0, false)
+	action, err := r.repairDrift("missing_on_chain", address, &row, "")
+	if err != nil || action != "removed database entry" {
+		t.Errorf("repair after the unblacklist event: %q, %v", action, err)
+	}
+}
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"encoding/json"
+	"log"
+
+	"token-monitor/models"
+
+	"gorm.io/gorm/clause"
+)
+
+// shadowCheck returns the check a shadow rule runs: that of the active rule it shadows, or
+// its own for a new rule
+func shadowCheck(rule *models.Rule) string {
+	if rule.Type == "" && rule.ShadowOf != "" {
+		return rule.ShadowOf
+	}
+	return ruleType(rule)
+}
+
+// evaluateShadows runs the shadow rules on a transaction, but for the checks the whitelist
+// exempts it from, and records their would-be violations. Nothing else happens: no
+// suspicious transfer, alert or enforcement.
+func (a *Analyzer) evaluateShadows(tx *models.Transaction, exemptions map[string][]*models.WhitelistAddress) {
+	for _, rule := range a.shadows {
+		check := shadowCheck(rule)
+		if len(exemptions[check]) > 0 {
+			continue
+		}
+		shadow := &Analyzer{db: a.db, rules: map[string]*models.Rule{check: rule}, quiet: true}
+		for _, d := range shadow.detect(tx) {
+			// Checks that do not read parameters run for every rule set
+			if d.Rule != check {
+				continue
+			}
+			details, _ := json.Marshal(d.Details)
+			violation := &models.ShadowViolation{
+				RuleID:        rule.ID,
+				RuleVersionID: rule.VersionID,
+				RuleName:      rule.Name,
+				CheckName:     check,
+				TxHash:        tx.Hash,
+				BlockNumber:   tx.BlockNumber,
+				Address:       ruleGroupAddress(rule.Actions, tx),
+				Details:       string(details),
+			}
+			// A transaction analyzed pending and again mined is recorded once
+			if err := a.db.Clauses(clause.OnConflict{DoNothing: true}).Create(violation).Error; err != nil {
+				log.Printf("Error recording shadow violation of %s in %s: %v", rule.Name, tx.Hash, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package services
+
+import (
+	"testing"
+
+	"token-monitor/models"
+)
+
+func TestRuleChecks(t *testing.T) {
+	cases := []struct {
+		rule   models.Rule
+		check  string
+		shadow string
+	}{
+		{models.Rule{Name: "large_transfer"}, "large_transfer", "large_transfer"},
+		{models.Rule{Name: "large_transfer_strict", Type: "large_transfer"}, "large_transfer", "large_transfer"},
+		// Shadow rules from before rule types run the check of the rule they shadow
+		{models.Rule{Name: "multiple_transfers_shadow", ShadowOf: "multiple_transfers"}, "multiple_transfers_shadow", "multiple_transfers"},
+		{models.Rule{Name: "burst_shadow", Type: "multiple_transfers", ShadowOf: "burst"}, "multiple_transfers", "multiple_transfers"},
+	}
+	for _, c := range cases {
+		if got := ruleType(&c.rule); got != c.check {
+			t.Errorf("ruleType(%s) = %s, want %s", c.rule.Name, got, c.check)
+		}
+		if got := shadowCheck(&c.rule); got != c.shadow {
+			t.Errorf("shadowCheck(%s) = %s, want %s", c.rule.Name, got, c.shadow)
+		}
+	}
+}
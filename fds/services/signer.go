@@ -0,0 +1,346 @@
+package services
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"token-monitor/audit"
+	"token-monitor/config"
+	"token-monitor/models"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"gorm.io/gorm"
+)
+
+// Signer signs the monitor's transactions without handing out the key
+type Signer interface {
+	Address() common.Address
+	SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
+}
+
+// MemorySigner signs with a key held in memory. It is meant for tests and local chains.
+type MemorySigner struct {
+	key     *ecdsa.PrivateKey
+	address common.Address
+}
+
+// NewMemorySigner creates a signer for a private key
+func NewMemorySigner(key *ecdsa.PrivateKey) *MemorySigner {
+	return &MemorySigner{key: key, address: crypto.PubkeyToAddress(key.PublicKey)}
+}
+
+// Address returns the signing account
+func (s *MemorySigner) Address() common.Address {
+	return s.address
+}
+
+// SignTx signs a transaction for chainID
+func (s *MemorySigner) SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return types.SignTx(tx, types.LatestSignerForChainID(chainID), s.key)
+}
+
+// NewKeystoreSigner decrypts an encrypted keystore file with the passphrase stored in
+// passphraseFile
+func NewKeystoreSigner(path, passphraseFile string) (*MemorySigner, error) {
+	keyJSON, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading keystore file: %w", err)
+	}
+	passphrase, err := os.ReadFile(passphraseFile)
+	if err != nil {
+		return nil, fmt.Errorf("error reading keystore passphrase: %w", err)
+	}
+	key, err := keystore.DecryptKey(keyJSON, strings.TrimRight(string(passphrase), "\r\n"))
+	if err != nil {
+		return nil, fmt.Errorf("error decrypting keystore file: %w", err)
+	}
+	return NewMemorySigner(key.PrivateKey), nil
+}
+
+// clefSignResult is the result of account_signTransaction
+type clefSignResult struct {
+	Raw hexutil.Bytes      `json:"raw"`
+	Tx  *types.Transaction `json:"tx"`
+}
+
+// RemoteSigner signs through an external signer speaking Clef's account_* JSON-RPC API,
+// so the key never enters the monitor
+type RemoteSigner struct {
+	client  *rpc.Client
+	address common.Address
+	timeout time.Duration
+}
+
+// NewRemoteSigner connects to a remote signer. When address is empty the first account
+// the signer lists is used.
+func NewRemoteSigner(ctx context.Context, url, address string, timeout time.Duration) (*RemoteSigner, error) {
+	client, err := rpc.DialContext(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to remote signer: %w", err)
+	}
+	s := &RemoteSigner{client: client, timeout: timeout}
+	if address != "" {
+		if !common.IsHexAddress(address) {
+			return nil, fmt.Errorf("invalid signer address %q", address)
+		}
+		s.address = common.HexToAddress(address)
+		return s, nil
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	var accounts []common.Address
+	if err := client.CallContext(callCtx, &accounts, "account_list"); err != nil {
+		return nil, fmt.Errorf("error listing remote signer accounts: %w", err)
+	}
+	if len(accounts) == 0 {
+		return nil, errors.New("remote signer has no accounts")
+	}
+	s.address = accounts[0]
+	return s, nil
+}
+
+// Address returns the signing account
+func (s *RemoteSigner) Address() common.Address {
+	return s.address
+}
+
+// SignTx asks the remote signer to sign a transaction and checks that what it signed is
+// the transaction that was asked for
+func (s *RemoteSigner) SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	args := apitypes.SendTxArgs{
+		From:    common.NewMixedcaseAddress(s.address),
+		Gas:     hexutil.Uint64(tx.Gas()),
+		Value:   hexutil.Big(*tx.Value()),
+		Nonce:   hexutil.Uint64(tx.Nonce()),
+		ChainID: (*hexutil.Big)(chainID),
+	}
+	if to := tx.To(); to != nil {
+		mixed := common.NewMixedcaseAddress(*to)
+		args.To = &mixed
+	}
+	data := hexutil.Bytes(tx.Data())
+	args.Input = &data
+	if tx.Type() == types.DynamicFeeTxType {
+		args.MaxFeePerGas = (*hexutil.Big)(tx.GasFeeCap())
+		args.MaxPriorityFeePerGas = (*hexutil.Big)(tx.GasTipCap())
+	} else {
+		args.GasPrice = (*hexutil.Big)(tx.GasPrice())
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	var result clefSignResult
+	if err := s.client.CallContext(callCtx, &result, "account_signTransaction", args); err != nil {
+		return nil, fmt.Errorf("remote signer: %w", err)
+	}
+
+	signed := new(types.Transaction)
+	if err := signed.UnmarshalBinary(result.Raw); err != nil {
+		return nil, fmt.Errorf("remote signer returned an invalid transaction: %w", err)
+	}
+	from, err := types.Sender(types.LatestSignerForChainID(chainID), signed)
+	if err != nil {
+		return nil, fmt.Errorf("remote signer returned an invalid signature: %w", err)
+	}
+	if from != s.address || signed.Nonce() != tx.Nonce() || signed.Gas() != tx.Gas() ||
+		signed.Value().Cmp(tx.Value()) != 0 || !sameRecipient(signed.To(), tx.To()) ||
+		string(signed.Data()) != string(tx.Data()) {
+		return nil, errors.New("remote signer signed a different transaction")
+	}
+	return signed, nil
+}
+
+func sameRecipient(a, b *common.Address) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// clefStandIn serves account_list and account_signTransaction for a signer, like Clef
+// with every request approved
+type clefStandIn struct {
+	signer Signer
+}
+
+func (c *clefStandIn) List(ctx context.Context) ([]common.Address, error) {
+	return []common.Address{c.signer.Address()}, nil
+}
+
+func (c *clefStandIn) SignTransaction(ctx context.Context, args apitypes.SendTxArgs, methodSelector *string) (*clefSignResult, error) {
+	if args.From.Address() != c.signer.Address() {
+		return nil, fmt.Errorf("unknown account %s", args.From.Address().Hex())
+	}
+	if args.ChainID == nil {
+		return nil, errors.New("chainId required")
+	}
+	signed, err := c.signer.SignTx(ctx, args.ToTransaction(), (*big.Int)(args.ChainID))
+	if err != nil {
+		return nil, err
+	}
+	raw, err := signed.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return &clefSignResult{Raw: raw, Tx: signed}, nil
+}
+
+// NewClefStandIn returns a JSON-RPC server speaking the remote signer protocol for signer,
+// to run the remote signer against a local chain or in tests. Serve it over HTTP.
+func NewClefStandIn(signer Signer) (*rpc.Server, error) {
+	server := rpc.NewServer()
+	if err := server.RegisterName("account", &clefStandIn{signer: signer}); err != nil {
+		return nil, err
+	}
+	return server, nil
+}
+
+// ErrSignRateLimited is returned when the signer is asked to sign more often than allowed
+var ErrSignRateLimited = errors.New("signing rate limit exceeded")
+
+// AuditedSigner rate-limits signing requests and writes each one to the audit log. A
+// transaction is only handed out once its signature is logged.
+type AuditedSigner struct {
+	signer Signer
+	db     *gorm.DB
+
+	mu        sync.Mutex
+	perMinute int
+	tokens    float64
+	refilled  time.Time
+}
+
+// NewAuditedSigner wraps a signer, allowing perMinute signatures a minute with bursts of as many
+func NewAuditedSigner(signer Signer, db *gorm.DB, perMinute int) *AuditedSigner {
+	return &AuditedSigner{
+		signer:    signer,
+		db:        db,
+		perMinute: perMinute,
+		tokens:    float64(perMinute),
+		refilled:  time.Now(),
+	}
+}
+
+// Address returns the signing account
+func (s *AuditedSigner) Address() common.Address {
+	return s.signer.Address()
+}
+
+// allow takes a token from the bucket if there is one
+func (s *AuditedSigner) allow(now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens += now.Sub(s.refilled).Minutes() * float64(s.perMinute)
+	if s.tokens > float64(s.perMinute) {
+		s.tokens = float64(s.perMinute)
+	}
+	s.refilled = now
+	if s.tokens < 1 {
+		return false
+	}
+	s.tokens--
+	return true
+}
+
+// SignTx signs a transaction if the rate limit allows it and records the request
+func (s *AuditedSigner) SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	var signed *types.Transaction
+	var err error
+	if s.allow(time.Now()) {
+		signed, err = s.signer.SignTx(ctx, tx, chainID)
+	} else {
+		err = ErrSignRateLimited
+	}
+
+	entry := signAuditEntry(s.signer.Address(), tx, chainID)
+	if err != nil {
+		entry.Result = "failed"
+		entry.Error = err.Error()
+	} else {
+		entry.TxHash = signed.Hash().Hex()
+	}
+	if auditErr := audit.Append(s.db, entry); auditErr != nil {
+		log.Printf("Error writing audit log of signing request: %v", auditErr)
+		if err == nil {
+			return nil, fmt.Errorf("signing request could not be audited: %w", auditErr)
+		}
+	}
+	return signed, err
+}
+
+// signAuditEntry describes a signing request in the audit log
+func signAuditEntry(from common.Address, tx *types.Transaction, chainID *big.Int) *models.AuditLog {
+	params := map[string]interface{}{
+		"from":      from.Hex(),
+		"nonce":     tx.Nonce(),
+		"gas":       tx.Gas(),
+		"value":     tx.Value().String(),
+		"chain_id":  chainID.String(),
+		"data_size": len(tx.Data()),
+	}
+	if to := tx.To(); to != nil {
+		params["to"] = to.Hex()
+	}
+	if len(tx.Data()) >= 4 {
+		params["method"] = hexutil.Encode(tx.Data()[:4])
+	}
+	content, _ := json.Marshal(params)
+	return &models.AuditLog{
+		Actor:      "monitor",
+		Action:     "sign_transaction",
+		Parameters: string(content),
+		Result:     "succeeded",
+	}
+}
+
+// NewSigner creates the signer configured by cfg
+func NewSigner(ctx context.Context, cfg config.SignerConfig) (Signer, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	switch cfg.Type {
+	case "keystore":
+		return NewKeystoreSigner(cfg.KeystorePath, cfg.PassphraseFile)
+	case "remote":
+		return NewRemoteSigner(ctx, cfg.RemoteURL, cfg.Address, cfg.Timeout)
+	case "memory":
+		key, err := crypto.HexToECDSA(strings.TrimPrefix(cfg.PrivateKey, "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid private key: %w", err)
+		}
+		log.Printf("Warning: signing with a private key from the environment; use it on local chains only")
+		return NewMemorySigner(key), nil
+	}
+	return nil, fmt.Errorf("unknown signer type %q", cfg.Type)
+}
+
+// NewSignerTransactor returns transact options that sign through signer
+func NewSignerTransactor(signer Signer, chainID *big.Int) *bind.TransactOpts {
+	return &bind.TransactOpts{
+		From:    signer.Address(),
+		Context: context.Background(),
+		Signer: func(address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if address != signer.Address() {
+				return nil, bind.ErrNotAuthorized
+			}
+			return signer.SignTx(context.Background(), tx, chainID)
+		},
+	}
+}
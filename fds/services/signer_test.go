@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"math/big"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestRemoteSigner(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	local := NewMemorySigner(key)
+	standIn, err := NewClefStandIn(local)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(standIn)
+	defer server.Close()
+
+	remote, err := NewRemoteSigner(context.Background(), server.URL, "", 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if remote.Address() != local.Address() {
+		t.Fatalf("remote signer account %s, want %s", remote.Address().Hex(), local.Address().Hex())
+	}
+
+	chainID := big.NewInt(31337)
+	to := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	for _, tx := range []*types.Transaction{
+		types.NewTx(&types.LegacyTx{Nonce: 3, To: &to, Gas: 50000, GasPrice: big.NewInt(1e9), Data: []byte{0xde, 0xad, 0xbe, 0xef}}),
+		types.NewTx(&types.DynamicFeeTx{ChainID: chainID, Nonce: 4, To: &to, Gas: 80000, GasTipCap: big.NewInt(2e9), GasFeeCap: big.NewInt(30e9), Data: []byte{0x01}}),
+	} {
+		signed, err := remote.SignTx(context.Background(), tx, chainID)
+		if err != nil {
+			t.Fatalf("type %d: %v", tx.Type(), err)
+		}
+		want, _ := local.SignTx(context.Background(), tx, chainID)
+		if signed.Hash() != want.Hash() {
+			t.Errorf("type %d: remote signature differs from local signature", tx.Type())
+		}
+	}
+
+	// The stand-in refuses accounts it does not hold
+	other, _ := crypto.GenerateKey()
+	remote.address = crypto.PubkeyToAddress(other.PublicKey)
+	if _, err := remote.SignTx(context.Background(), types.NewTx(&types.LegacyTx{To: &to, Gas: 21000, GasPrice: big.NewInt(1)}), chainID); err == nil {
+		t.Error("signing for an unknown account must fail")
+	}
+}
+
+func TestKeystoreSigner(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyJSON, err := keystore.EncryptKey(&keystore.Key{Address: crypto.PubkeyToAddress(key.PublicKey), PrivateKey: key}, "secret", keystore.LightScryptN, keystore.LightScryptP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	keyPath := filepath.Join(dir, "key.json")
+	passPath := filepath.Join(dir, "passphrase")
+	os.WriteFile(keyPath, keyJSON, 0o600)
+	os.WriteFile(passPath, []byte("secret\n"), 0o600)
+
+	signer, err := NewKeystoreSigner(keyPath, passPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signer.Address() != crypto.PubkeyToAddress(key.PublicKey) {
+		t.Error("keystore signer has the wrong account")
+	}
+
+	os.WriteFile(passPath, []byte("wrong"), 0o600)
+	if _, err := NewKeystoreSigner(keyPath, passPath); err == nil {
+		t.Error("a wrong passphrase must fail")
+	}
+}
+
+func TestSignRateLimit(t *testing.T) {
+	s := NewAuditedSigner(nil, nil, 2)
+	now := s.refilled
+	if !s.allow(now) || !s.allow(now) {
+		t.Fatal("the burst must be allowed")
+	}
+	if s.allow(now) {
+		t.Error("a third signature within the minute must be refused")
+	}
+	if !s.allow(now.Add(30 * time.Second)) {
+		t.Error("a token must be refilled after half a minute")
+	}
+	if s.allow(now.Add(30 * time.Second)) {
+		t.Error("only one token is refilled after half a minute")
+	}
+}
@@ -0,0 +1,434 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// systemEventsABIJSON contains the events emitted by the system contracts
+// (CompliantToken and ExchangePortal) that are decoded from simulated receipts
+const systemEventsABIJSON = `[
+    { "anonymous": false,
+      "inputs": [
+        {"indexed": true, "internalType": "address", "name": "from", "type": "address"},
+        {"indexed": true, "internalType": "address", "name": "to", "type": "address"},
+        {"indexed": false,"internalType": "uint256","name": "value","type": "uint256"}
+      ],
+      "name": "Transfer",
+      "type": "event"
+    },
+    { "anonymous": false,
+      "inputs": [
+        {"indexed": true, "internalType": "address", "name": "owner",   "type": "address"},
+        {"indexed": true, "internalType": "address", "name": "spender", "type": "address"},
+        {"indexed": false,"internalType": "uint256","name": "value",   "type": "uint256"}
+      ],
+      "name": "Approval",
+      "type": "event"
+    },
+    { "anonymous": false,
+      "inputs": [
+        {"indexed": true, "internalType": "address", "name": "from",   "type": "address"},
+        {"indexed": true, "internalType": "address", "name": "to",     "type": "address"},
+        {"indexed": false,"internalType": "uint256", "name": "value",  "type": "uint256"},
+        {"indexed": true, "internalType": "TxType",  "name": "txType", "type": "uint8"}
+      ],
+      "name": "TypedTransfer",
+      "type": "event"
+    },
+    { "anonymous": false,
+      "inputs": [
+        {"indexed": true, "internalType": "address", "name": "fromToken", "type": "address"},
+        {"indexed": true, "internalType": "address", "name": "toToken",   "type": "address"},
+        {"indexed": true, "internalType": "address", "name": "user",      "type": "address"},
+        {"indexed": false,"internalType": "uint256", "name": "amountIn",  "type": "uint256"},
+        {"indexed": false,"internalType": "uint256", "name": "amountOut", "type": "uint256"},
+        {"indexed": false,"internalType": "uint256", "name": "feeAmount", "type": "uint256"}
+      ],
+      "name": "ExchangeExecuted",
+      "type": "event"
+    }
+]`
+
+// decodeSystemLogs decodes Transfer, Approval, TypedTransfer and ExchangeExecuted
+// logs emitted by any of the given system contracts
+func decodeSystemLogs(contractABI abi.ABI, logs []*types.Log, systemContracts map[common.Address]bool) []EventInfo {
+	var events []EventInfo
+
+	for _, logEntry := range logs {
+		if !systemContracts[logEntry.Address] {
+			continue
+		}
+		if len(logEntry.Topics) == 0 {
+			continue
+		}
+
+		ev := EventInfo{
+			Contract: logEntry.Address.Hex(),
+			LogIndex: logEntry.Index,
+		}
+
+		switch logEntry.Topics[0] {
+		case contractABI.Events["Transfer"].ID:
+			if len(logEntry.Topics) < 3 {
+				continue
+			}
+			var transferEv struct {
+				Value *big.Int
+			}
+			if err := contractABI.UnpackIntoInterface(&transferEv, "Transfer", logEntry.Data); err != nil {
+				continue
+			}
+			ev.Event = "Transfer"
+			ev.From = common.HexToAddress(logEntry.Topics[1].Hex()).Hex()
+			ev.To = common.HexToAddress(logEntry.Topics[2].Hex()).Hex()
+			ev.Value = transferEv.Value.String()
+
+		case contractABI.Events["Approval"].ID:
+			if len(logEntry.Topics) < 3 {
+				continue
+			}
+			var approvalEv struct {
+				Value *big.Int
+			}
+			if err := contractABI.UnpackIntoInterface(&approvalEv, "Approval", logEntry.Data); err != nil {
+				continue
+			}
+			ev.Event = "Approval"
+			ev.From = common.HexToAddress(logEntry.Topics[1].Hex()).Hex()
+			ev.To = common.HexToAddress(logEntry.Topics[2].Hex()).Hex()
+			ev.Value = approvalEv.Value.String()
+
+		case contractABI.Events["TypedTransfer"].ID:
+			if len(logEntry.Topics) < 4 {
+				continue
+			}
+			var typedEv struct {
+				Value *big.Int
+			}
+			if err := contractABI.UnpackIntoInterface(&typedEv, "TypedTransfer", logEntry.Data); err != nil {
+				continue
+			}
+			txType := new(big.Int).SetBytes(logEntry.Topics[3].Bytes())
+			ev.Event = "TypedTransfer"
+			ev.From = common.HexToAddress(logEntry.Topics[1].Hex()).Hex()
+			ev.To = common.HexToAddress(logEntry.Topics[2].Hex()).Hex()
+			ev.Value = typedEv.Value.String()
+			ev.TxType = txType.String()
+
+		case contractABI.Events["ExchangeExecuted"].ID:
+			if len(logEntry.Topics) < 4 {
+				continue
+			}
+			var exchangeEv struct {
+				AmountIn  *big.Int
+				AmountOut *big.Int
+				FeeAmount *big.Int
+			}
+			if err := contractABI.UnpackIntoInterface(&exchangeEv, "ExchangeExecuted", logEntry.Data); err != nil {
+				continue
+			}
+			user := common.HexToAddress(logEntry.Topics[3].Hex()).Hex()
+			ev.Event = "ExchangeExecuted"
+			ev.From = user
+			ev.To = user
+			ev.Value = exchangeEv.AmountIn.String()
+			ev.FromToken = common.HexToAddress(logEntry.Topics[1].Hex()).Hex()
+			ev.ToToken = common.HexToAddress(logEntry.Topics[2].Hex()).Hex()
+			ev.AmountOut = exchangeEv.AmountOut.String()
+			ev.Fee = exchangeEv.FeeAmount.String()
+
+		default:
+			continue
+		}
+
+		events = append(events, ev)
+	}
+
+	return events
+}
+
+// simulatedTransfers returns one entry per token movement in the decoded events.
+// CompliantToken emits a Transfer followed by a TypedTransfer for the same movement,
+// so the TypedTransfer only contributes its transaction type to the preceding Transfer.
+// An exchange through the ExchangePortal is a movement of the user's amount in, unless
+// Transfer events of the user's tokens already show it.
+func simulatedTransfers(events []EventInfo) []EventInfo {
+	var transfers, exchanges []EventInfo
+
+	for _, ev := range events {
+		switch ev.Event {
+		case "Transfer":
+			transfers = append(transfers, ev)
+		case "TypedTransfer":
+			matched := false
+			for i := len(transfers) - 1; i >= 0; i-- {
+				t := &transfers[i]
+				if t.TxType == "" && t.Contract == ev.Contract && t.From == ev.From && t.To == ev.To && t.Value == ev.Value {
+					t.TxType = ev.TxType
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				transfers = append(transfers, ev)
+			}
+		case "ExchangeExecuted":
+			exchanges = append(exchanges, ev)
+		}
+	}
+
+	for _, ex := range exchanges {
+		covered := false
+		for _, t := range transfers {
+			covered = covered || t.Event != "ExchangeExecuted" && (t.From == ex.From || t.To == ex.From)
+		}
+		if !covered {
+			transfers = append(transfers, ex)
+		}
+	}
+
+	return transfers
+}
+
+// systemMethodsABIJSON contains the token movements of the system contracts whose
+// calldata is decoded when a simulation shows none
+const systemMethodsABIJSON = `[
+    { "type": "function", "name": "transfer",
+      "inputs": [
+        {"internalType": "address", "name": "to",     "type": "address"},
+        {"internalType": "uint256", "name": "amount", "type": "uint256"}
+      ]
+    },
+    { "type": "function", "name": "transferFrom",
+      "inputs": [
+        {"internalType": "address", "name": "from",   "type": "address"},
+        {"internalType": "address", "name": "to",     "type": "address"},
+        {"internalType": "uint256", "name": "amount", "type": "uint256"}
+      ]
+    },
+    { "type": "function", "name": "transferWithType",
+      "inputs": [
+        {"internalType": "address", "name": "to",     "type": "address"},
+        {"internalType": "uint256", "name": "amount", "type": "uint256"},
+        {"internalType": "TxType",  "name": "txType", "type": "uint8"}
+      ]
+    },
+    { "type": "function", "name": "transferFromWithType",
+      "inputs": [
+        {"internalType": "address", "name": "from",   "type": "address"},
+        {"internalType": "address", "name": "to",     "type": "address"},
+        {"internalType": "uint256", "name": "amount", "type": "uint256"},
+        {"internalType": "TxType",  "name": "txType", "type": "uint8"}
+      ]
+    },
+    { "type": "function", "name": "exchange",
+      "inputs": [
+        {"internalType": "address", "name": "fromToken",    "type": "address"},
+        {"internalType": "address", "name": "toToken",      "type": "address"},
+        {"internalType": "uint256", "name": "amountIn",     "type": "uint256"},
+        {"internalType": "uint256", "name": "minAmountOut", "type": "uint256"}
+      ]
+    }
+]`
+
+// calldataTransfers decodes the token movement a call to a system contract asks for, in
+// the shape of simulatedTransfers. It returns nil for calls that move no tokens.
+func calldataTransfers(methodsABI abi.ABI, from, contract common.Address, data []byte) []EventInfo {
+	if len(data) < 4 {
+		return nil
+	}
+	method, err := methodsABI.MethodById(data[:4])
+	if err != nil {
+		return nil
+	}
+	args := map[string]interface{}{}
+	if err := method.Inputs.UnpackIntoMap(args, data[4:]); err != nil {
+		return nil
+	}
+
+	ev := EventInfo{Event: "Transfer", Contract: contract.Hex(), From: from.Hex()}
+	if sender, ok := args["from"].(common.Address); ok {
+		ev.From = sender.Hex()
+	}
+	if to, ok := args["to"].(common.Address); ok {
+		ev.To = to.Hex()
+	}
+	if amount, ok := args["amount"].(*big.Int); ok {
+		ev.Value = amount.String()
+	}
+	if txType, ok := args["txType"].(uint8); ok {
+		ev.Event = "TypedTransfer"
+		ev.TxType = fmt.Sprint(txType)
+	}
+
+	if method.Name == "exchange" {
+		ev.Event = "ExchangeExecuted"
+		ev.To = ev.From
+		if fromToken, ok := args["fromToken"].(common.Address); ok {
+			ev.FromToken = fromToken.Hex()
+		}
+		if toToken, ok := args["toToken"].(common.Address); ok {
+			ev.ToToken = toToken.Hex()
+		}
+		if amountIn, ok := args["amountIn"].(*big.Int); ok {
+			ev.Value = amountIn.String()
+		}
+	}
+
+	return []EventInfo{ev}
+}
+
+// pendingTransfers returns the token movements of a simulated pending call. When the
+// simulation failed or reverted for a reason other than compliance, it shows none, and
+// the movement is decoded from the calldata instead. A compliance revert is analyzed on
+// its own.
+func pendingTransfers(methodsABI abi.ABI, result TxResult, from common.Address, tx *types.Transaction) []EventInfo {
+	if transfers := simulatedTransfers(result.Events); len(transfers) > 0 {
+		return transfers
+	}
+	if result.Revert != nil && result.Revert.ComplianceModule != "" {
+		return nil
+	}
+	return calldataTransfers(methodsABI, from, *tx.To(), tx.Data())
+}
+
+// systemErrorsABIJSON contains the custom errors of the system contracts that a
+// simulated transaction can revert with
+const systemErrorsABIJSON = `[
+    { "type": "error", "name": "ComplianceCheckFailed",
+      "inputs": [
+        {"internalType": "address", "name": "from",   "type": "address"},
+        {"internalType": "address", "name": "to",     "type": "address"},
+        {"internalType": "uint256", "name": "amount", "type": "uint256"},
+        {"internalType": "string",  "name": "reason", "type": "string"}
+      ]
+    },
+    { "type": "error", "name": "ERC20InsufficientBalance",
+      "inputs": [
+        {"internalType": "address", "name": "sender",  "type": "address"},
+        {"internalType": "uint256", "name": "balance", "type": "uint256"},
+        {"internalType": "uint256", "name": "needed",  "type": "uint256"}
+      ]
+    },
+    { "type": "error", "name": "ERC20InsufficientAllowance",
+      "inputs": [
+        {"internalType": "address", "name": "spender",   "type": "address"},
+        {"internalType": "uint256", "name": "allowance", "type": "uint256"},
+        {"internalType": "uint256", "name": "needed",    "type": "uint256"}
+      ]
+    },
+    { "type": "error", "name": "InvalidTokenPair", "inputs": [] },
+    { "type": "error", "name": "InvalidAmount", "inputs": [] },
+    { "type": "error", "name": "ExcessiveSlippage", "inputs": [] }
+]`
+
+// complianceReasonPrefixes maps the failure reasons returned by canTransferWithFailureReason
+// to the compliance module that produced them
+var complianceReasonPrefixes = []struct {
+	prefix string
+	module string
+}{
+	{"Sender address is blacklisted", "AddressRestrictionCompliance"},
+	{"Recipient address is blacklisted", "AddressRestrictionCompliance"},
+	{"Transfer between these entity types", "EntityTypeCompliance"},
+	{"Transaction type is not usable", "TransactionTypeCompliance"},
+	{"Sender entity type is not allowed", "TransactionTypeCompliance"},
+	{"Receiver entity type is not allowed", "TransactionTypeCompliance"},
+	{"Supply limit exceeded", "SupplyCompliance"},
+	{"Sender is not a verified entity", "VerificationCompliance"},
+	{"Recipient is not a verified entity", "VerificationCompliance"},
+	{"Sender for burning is not a verified entity", "VerificationCompliance"},
+	{"Recipient for minting is not a verified entity", "VerificationCompliance"},
+}
+
+// RevertInfo holds the decoded revert of a simulated transaction
+type RevertInfo struct {
+	Data             string `json:"data,omitempty"`             // Raw revert data as hex
+	Error            string `json:"error,omitempty"`            // Name of the custom error, "Error" or "Panic"
+	Reason           string `json:"reason,omitempty"`           // Human readable reason
+	ComplianceModule string `json:"complianceModule,omitempty"` // Compliance module that blocked the transfer
+	From             string `json:"from,omitempty"`             // ComplianceCheckFailed only
+	To               string `json:"to,omitempty"`               // ComplianceCheckFailed only
+	Amount           string `json:"amount,omitempty"`           // ComplianceCheckFailed only
+}
+
+// complianceModuleForReason returns the compliance module that produced a failure reason
+func complianceModuleForReason(reason string) string {
+	for _, p := range complianceReasonPrefixes {
+		if strings.HasPrefix(reason, p.prefix) {
+			return p.module
+		}
+	}
+	return ""
+}
+
+// decodeRevert turns raw revert data into a RevertInfo. Custom errors of the system
+// contracts are decoded with errorsABI; Error(string) and Panic(uint256) are handled natively.
+func decodeRevert(errorsABI abi.ABI, data []byte) RevertInfo {
+	info := RevertInfo{}
+	if len(data) < 4 {
+		return info
+	}
+	info.Data = "0x" + common.Bytes2Hex(data)
+
+	// Error(string) and Panic(uint256)
+	if reason, err := abi.UnpackRevert(data); err == nil {
+		info.Error = "Error"
+		if bytes.Equal(data[:4], common.FromHex("0x4e487b71")) {
+			info.Error = "Panic"
+		}
+		info.Reason = reason
+		info.ComplianceModule = complianceModuleForReason(reason)
+		return info
+	}
+
+	var selector [4]byte
+	copy(selector[:], data[:4])
+	customErr, err := errorsABI.ErrorByID(selector)
+	if err != nil {
+		info.Reason = info.Data
+		return info
+	}
+	info.Error = customErr.Name
+	info.Reason = customErr.Name
+
+	unpacked, err := customErr.Unpack(data)
+	if err != nil {
+		return info
+	}
+	values, ok := unpacked.([]interface{})
+	if !ok {
+		return info
+	}
+
+	if customErr.Name == "ComplianceCheckFailed" && len(values) == 4 {
+		if from, ok := values[0].(common.Address); ok {
+			info.From = from.Hex()
+		}
+		if to, ok := values[1].(common.Address); ok {
+			info.To = to.Hex()
+		}
+		if amount, ok := values[2].(*big.Int); ok {
+			info.Amount = amount.String()
+		}
+		if reason, ok := values[3].(string); ok {
+			info.Reason = reason
+			info.ComplianceModule = complianceModuleForReason(reason)
+		}
+		return info
+	}
+
+	var args []string
+	for _, v := range values {
+		args = append(args, fmt.Sprintf("%v", v))
+	}
+	info.Reason = fmt.Sprintf("%s(%s)", customErr.Name, strings.Join(args, ", "))
+	return info
+}
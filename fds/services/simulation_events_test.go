@@ -0,0 +1,193 @@
+package services
+
+import (
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestDecodeSystemLogsTypedTransfer(t *testing.T) {
+	contractABI, err := abi.JSON(strings.NewReader(systemEventsABIJSON))
+	if err != nil {
+		t.Fatalf("error parsing ABI: %v", err)
+	}
+
+	token := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	other := common.HexToAddress("0x2000000000000000000000000000000000000002")
+	from := common.HexToAddress("0x93BDBe2c9f0F5cec59175C51D0a39fAee42A4a6e")
+	to := common.HexToAddress("0x476C88ED464EFD251a8b18Eb84785F7C46807873")
+	value := common.LeftPadBytes(big.NewInt(1000).Bytes(), 32)
+
+	logs := []*types.Log{
+		{
+			Address: token,
+			Topics:  []common.Hash{contractABI.Events["Transfer"].ID, common.BytesToHash(from.Bytes()), common.BytesToHash(to.Bytes())},
+			Data:    value,
+			Index:   0,
+		},
+		{
+			Address: token,
+			Topics:  []common.Hash{contractABI.Events["TypedTransfer"].ID, common.BytesToHash(from.Bytes()), common.BytesToHash(to.Bytes()), common.BigToHash(big.NewInt(2))},
+			Data:    value,
+			Index:   1,
+		},
+		{
+			// Not a system contract, must be ignored
+			Address: other,
+			Topics:  []common.Hash{contractABI.Events["Transfer"].ID, common.BytesToHash(from.Bytes()), common.BytesToHash(to.Bytes())},
+			Data:    value,
+			Index:   2,
+		},
+	}
+
+	events := decodeSystemLogs(contractABI, logs, map[common.Address]bool{token: true})
+	if len(events) != 2 {
+		t.Fatalf("expected 2 decoded events, got %d", len(events))
+	}
+	if events[1].Event != "TypedTransfer" || events[1].TxType != "2" {
+		t.Errorf("wrong typed transfer decoding: %+v", events[1])
+	}
+
+	transfers := simulatedTransfers(events)
+	if len(transfers) != 1 {
+		t.Fatalf("expected 1 transfer, got %d", len(transfers))
+	}
+	if transfers[0].From != from.Hex() || transfers[0].To != to.Hex() || transfers[0].Value != "1000" || transfers[0].TxType != "2" {
+		t.Errorf("wrong transfer: %+v", transfers[0])
+	}
+}
+
+func TestDecodeRevert(t *testing.T) {
+	errorsABI, err := abi.JSON(strings.NewReader(systemErrorsABIJSON))
+	if err != nil {
+		t.Fatalf("error parsing ABI: %v", err)
+	}
+
+	// Error("Recipient is not a verified entity")
+	data := common.FromHex("0x08c379a0" +
+		"0000000000000000000000000000000000000000000000000000000000000020" +
+		"0000000000000000000000000000000000000000000000000000000000000022" +
+		"526563697069656e74206973206e6f74206120766572696669656420656e7469" +
+		"7479000000000000000000000000000000000000000000000000000000000000")
+	revert := decodeRevert(errorsABI, data)
+	if revert.Reason != "Recipient is not a verified entity" || revert.ComplianceModule != "VerificationCompliance" {
+		t.Errorf("wrong revert decoding: %+v", revert)
+	}
+
+	// ComplianceCheckFailed(from, to, amount, reason)
+	from := common.HexToAddress("0x93BDBe2c9f0F5cec59175C51D0a39fAee42A4a6e")
+	to := common.HexToAddress("0x476C88ED464EFD251a8b18Eb84785F7C46807873")
+	customErr := errorsABI.Errors["ComplianceCheckFailed"]
+	args, err := customErr.Inputs.Pack(from, to, big.NewInt(42), "Recipient address is blacklisted from receiving. Reason: mule")
+	if err != nil {
+		t.Fatalf("error packing error args: %v", err)
+	}
+	revert = decodeRevert(errorsABI, append(customErr.ID[:4], args...))
+	if revert.Error != "ComplianceCheckFailed" || revert.ComplianceModule != "AddressRestrictionCompliance" {
+		t.Errorf("wrong revert decoding: %+v", revert)
+	}
+	if revert.From != from.Hex() || revert.To != to.Hex() || revert.Amount != "42" {
+		t.Errorf("wrong revert arguments: %+v", revert)
+	}
+
+	if revert := decodeRevert(errorsABI, nil); revert.Reason != "" {
+		t.Errorf("want empty reason, got: %s", revert.Reason)
+	}
+}
+
+func TestSimulatedTransfersExchange(t *testing.T) {
+	const (
+		portal = "0x3000000000000000000000000000000000000003"
+		user   = "0x93BDBe2c9f0F5cec59175C51D0a39fAee42A4a6e"
+	)
+	exchange := EventInfo{Event: "ExchangeExecuted", Contract: portal, From: user, To: user, Value: "500", AmountOut: "490"}
+
+	transfers := simulatedTransfers([]EventInfo{exchange})
+	if len(transfers) != 1 || transfers[0].From != user || transfers[0].Value != "500" {
+		t.Errorf("exchange without token transfers: got %+v", transfers)
+	}
+
+	// The token's own Transfer already shows the user's movement
+	transfers = simulatedTransfers([]EventInfo{
+		{Event: "Transfer", Contract: "0x1000000000000000000000000000000000000001", From: user, To: portal, Value: "500"},
+		exchange,
+	})
+	if len(transfers) != 1 || transfers[0].Event != "Transfer" {
+		t.Errorf("exchange with token transfers: got %+v", transfers)
+	}
+}
+
+func TestComplianceModuleForReason(t *testing.T) {
+	cases := []struct {
+		reason string
+		module string
+	}{
+		{"Sender address is blacklisted", "AddressRestrictionCompliance"},
+		{"Transfer between these entity types is not allowed", "EntityTypeCompliance"},
+		{"Supply limit exceeded", "SupplyCompliance"},
+		{"Sender for burning is not a verified entity", "VerificationCompliance"},
+		{"Recipient for minting is not a verified entity", "VerificationCompliance"},
+		{"Paused", ""},
+	}
+	for _, tc := range cases {
+		if got := complianceModuleForReason(tc.reason); got != tc.module {
+			t.Errorf("complianceModuleForReason(%q) = %q, want %q", tc.reason, got, tc.module)
+		}
+	}
+}
+
+func TestPendingTransfersFromCalldata(t *testing.T) {
+	methodsABI, err := abi.JSON(strings.NewReader(systemMethodsABIJSON))
+	if err != nil {
+		t.Fatalf("error parsing ABI: %v", err)
+	}
+
+	token := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	portal := common.HexToAddress("0x3000000000000000000000000000000000000003")
+	other := common.HexToAddress("0x2000000000000000000000000000000000000002")
+	from := common.HexToAddress("0x93BDBe2c9f0F5cec59175C51D0a39fAee42A4a6e")
+	to := common.HexToAddress("0x476C88ED464EFD251a8b18Eb84785F7C46807873")
+	call := func(contract common.Address, method string, args ...interface{}) *types.Transaction {
+		data, err := methodsABI.Pack(method, args...)
+		if err != nil {
+			t.Fatalf("packing %s: %v", method, err)
+		}
+		return types.NewTx(&types.LegacyTx{To: &contract, Data: data})
+	}
+	failed := TxResult{Status: "error", Error: "fork unavailable"}
+	reverted := TxResult{Status: "reverted", Revert: &RevertInfo{Error: "Error", Reason: "Paused"}}
+
+	cases := []struct {
+		name   string
+		result TxResult
+		tx     *types.Transaction
+		want   []EventInfo
+	}{
+		{"transfer", failed, call(token, "transfer", to, big.NewInt(1000)),
+			[]EventInfo{{Event: "Transfer", Contract: token.Hex(), From: from.Hex(), To: to.Hex(), Value: "1000"}}},
+		{"transferFrom", reverted, call(token, "transferFrom", other, to, big.NewInt(7)),
+			[]EventInfo{{Event: "Transfer", Contract: token.Hex(), From: other.Hex(), To: to.Hex(), Value: "7"}}},
+		{"transferWithType", failed, call(token, "transferWithType", to, big.NewInt(1000), uint8(2)),
+			[]EventInfo{{Event: "TypedTransfer", Contract: token.Hex(), From: from.Hex(), To: to.Hex(), Value: "1000", TxType: "2"}}},
+		{"exchange", reverted, call(portal, "exchange", token, other, big.NewInt(500), big.NewInt(490)),
+			[]EventInfo{{Event: "ExchangeExecuted", Contract: portal.Hex(), From: from.Hex(), To: from.Hex(), Value: "500",
+				FromToken: token.Hex(), ToToken: other.Hex()}}},
+		{"no token movement", failed, types.NewTx(&types.LegacyTx{To: &token, Data: common.FromHex("0x095ea7b3")}), nil},
+		{"compliance revert", TxResult{Status: "reverted", Revert: &RevertInfo{Reason: "Sender address is blacklisted",
+			ComplianceModule: "AddressRestrictionCompliance"}}, call(token, "transfer", to, big.NewInt(1000)), nil},
+		{"simulated", TxResult{Status: "success", Events: []EventInfo{{Event: "Transfer", Contract: token.Hex(), From: from.Hex(),
+			To: other.Hex(), Value: "5"}}}, call(token, "transfer", to, big.NewInt(1000)),
+			[]EventInfo{{Event: "Transfer", Contract: token.Hex(), From: from.Hex(), To: other.Hex(), Value: "5"}}},
+	}
+	for _, tc := range cases {
+		got := pendingTransfers(methodsABI, tc.result, from, tc.tx)
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: got %+v, want %+v", tc.name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,569 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"token-monitor/models"
+
+	"github.com/ethereum/go-ethereum/common"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxWatchlistSize caps the size of a fetched list
+const maxWatchlistSize = 64 << 20
+
+// watchlistItem is an entry of a parsed list
+type watchlistItem struct {
+	Name      string
+	Reference string
+}
+
+// parsedWatchlist is a list as parsed, its entries keyed by checksummed address
+type parsedWatchlist struct {
+	Version string
+	Entries map[string]watchlistItem
+	Skipped int // Entries without an Ethereum address
+}
+
+// add lists address, keeping the first entry of an address listed twice
+func (l *parsedWatchlist) add(address, name, reference string) {
+	address = strings.TrimSpace(address)
+	if !common.IsHexAddress(address) {
+		l.Skipped++
+		return
+	}
+	key := common.HexToAddress(address).Hex()
+	if _, ok := l.Entries[key]; !ok {
+		l.Entries[key] = watchlistItem{Name: strings.TrimSpace(name), Reference: strings.TrimSpace(reference)}
+	}
+}
+
+// parseWatchlist parses a list in format "csv", "json" or "ofac_xml"
+func parseWatchlist(format string, data []byte) (*parsedWatchlist, error) {
+	list := &parsedWatchlist{Entries: make(map[string]watchlistItem)}
+	var err error
+	switch format {
+	case "csv":
+		err = parseWatchlistCSV(list, data)
+	case "json":
+		err = parseWatchlistJSON(list, data)
+	case "ofac_xml":
+		err = parseWatchlistOFAC(list, data)
+	default:
+		err = fmt.Errorf("unknown watchlist format %s", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// parseWatchlistCSV reads a CSV list. A header row names the address, name and reference
+// (or id or uid) columns; without one, the first column is the address and the second the
+// name. Lines starting with # are comments.
+func parseWatchlistCSV(list *parsedWatchlist, data []byte) error {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+	r.Comment = '#'
+	rows, err := r.ReadAll()
+	if err != nil {
+		return fmt.Errorf("invalid CSV: %w", err)
+	}
+
+	address, name, reference := 0, 1, -1
+	if len(rows) > 0 {
+		header := map[string]int{}
+		for i, cell := range rows[0] {
+			header[strings.ToLower(strings.TrimSpace(cell))] = i
+		}
+		if i, ok := header["address"]; ok {
+			address, name, reference = i, -1, -1
+			if i, ok := header["name"]; ok {
+				name = i
+			}
+			for _, column := range []string{"reference", "id", "uid"} {
+				if i, ok := header[column]; ok && reference < 0 {
+					reference = i
+				}
+			}
+			rows = rows[1:]
+		}
+	}
+	cell := func(row []string, i int) string {
+		if i < 0 || i >= len(row) {
+			return ""
+		}
+		return row[i]
+	}
+	for _, row := range rows {
+		list.add(cell(row, address), cell(row, name), cell(row, reference))
+	}
+	return nil
+}
+
+// parseWatchlistJSON reads a JSON list: an array of addresses, or of objects with an
+// address, name and reference, or an object holding such an array as entries or addresses
+// along with the list's version
+func parseWatchlistJSON(list *parsedWatchlist, data []byte) error {
+	var items []json.RawMessage
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &items); err != nil {
+			return fmt.Errorf("invalid JSON: %w", err)
+		}
+	} else {
+		var doc struct {
+			Version   string            `json:"version"`
+			Entries   []json.RawMessage `json:"entries"`
+			Addresses []json.RawMessage `json:"addresses"`
+		}
+		if err := json.Unmarshal(trimmed, &doc); err != nil {
+			return fmt.Errorf("invalid JSON: %w", err)
+		}
+		list.Version = doc.Version
+		items = append(doc.Entries, doc.Addresses...)
+	}
+
+	for _, raw := range items {
+		var address string
+		if err := json.Unmarshal(raw, &address); err == nil {
+			list.add(address, "", "")
+			continue
+		}
+		var item struct {
+			Address   string `json:"address"`
+			Name      string `json:"name"`
+			Reference string `json:"reference"`
+			ID        string `json:"id"`
+		}
+		if err := json.Unmarshal(raw, &item); err != nil {
+			list.Skipped++
+			continue
+		}
+		if item.Reference == "" {
+			item.Reference = item.ID
+		}
+		list.add(item.Address, item.Name, item.Reference)
+	}
+	return nil
+}
+
+// ofacList is the part of an OFAC SDN XML list read: the digital currency addresses of the
+// entries
+type ofacList struct {
+	PublishDate string `xml:"publshInformation>Publish_Date"`
+	Entries     []struct {
+		UID       string `xml:"uid"`
+		FirstName string `xml:"firstName"`
+		LastName  string `xml:"lastName"`
+		IDs       []struct {
+			Type   string `xml:"idType"`
+			Number string `xml:"idNumber"`
+		} `xml:"idList>id"`
+	} `xml:"sdnEntry"`
+}
+
+// parseWatchlistOFAC reads an OFAC SDN XML list, whose version is its publication date.
+// Digital currency addresses of other chains count as skipped.
+func parseWatchlistOFAC(list *parsedWatchlist, data []byte) error {
+	var doc ofacList
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("invalid XML: %w", err)
+	}
+	list.Version = strings.TrimSpace(doc.PublishDate)
+	for _, entry := range doc.Entries {
+		name := strings.TrimSpace(strings.TrimSpace(entry.FirstName) + " " + strings.TrimSpace(entry.LastName))
+		for _, id := range entry.IDs {
+			if strings.HasPrefix(strings.TrimSpace(id.Type), "Digital Currency Address") {
+				list.add(id.Number, name, entry.UID)
+			}
+		}
+	}
+	return nil
+}
+
+// watchlistHit is the watchlist entry of an address in a transaction
+type watchlistHit struct {
+	Address   string
+	Name      string
+	Reference string
+	Source    string
+	Version   string
+}
+
+// watchlisted returns the entry of tx's sender or recipient on an enabled watchlist, or nil
+func (a *Analyzer) watchlisted(tx *models.Transaction) (*watchlistHit, error) {
+	var hits []watchlistHit
+	err := a.db.Table("watchlist_entries e").
+		Select("e.address, e.name, e.reference, s.name AS source, v.version").
+		Joins("JOIN watchlist_sources s ON s.id = e.source_id AND s.enabled AND s.deleted_at IS NULL").
+		Joins("LEFT JOIN watchlist_versions v ON v.id = e.added_version_id").
+		Where("e.status = ? AND e.deleted_at IS NULL AND e.address IN ?", "active", []string{tx.From, tx.To}).
+		Order("e.id").
+		Limit(1).
+		Scan(&hits).Error
+	if err != nil || len(hits) == 0 {
+		return nil, err
+	}
+	return &hits[0], nil
+}
+
+// WatchlistSyncer imports the watchlist sources when due and screens the past transactions
+// of newly listed addresses
+type WatchlistSyncer struct {
+	db       *gorm.DB
+	interval time.Duration
+	lookback time.Duration // How far back newly listed addresses are screened
+	client   *http.Client
+	stopChan chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewWatchlistSyncer creates a syncer checking for due sources every interval and fetching
+// lists within timeout
+func NewWatchlistSyncer(db *gorm.DB, interval, lookback, timeout time.Duration) *WatchlistSyncer {
+	return &WatchlistSyncer{
+		db:       db,
+		interval: interval,
+		lookback: lookback,
+		client:   &http.Client{Timeout: timeout},
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Start begins syncing the sources
+func (s *WatchlistSyncer) Start(ctx context.Context) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				s.syncDue(ctx)
+			case <-ctx.Done():
+				return
+			case <-s.stopChan:
+				return
+			}
+		}
+	}()
+}
+
+// Stop gracefully stops the syncer
+func (s *WatchlistSyncer) Stop() {
+	close(s.stopChan)
+	s.wg.Wait()
+}
+
+// syncDue syncs the enabled sources whose next sync is due and schedules the next one
+func (s *WatchlistSyncer) syncDue(ctx context.Context) {
+	var sources []models.WatchlistSource
+	if err := s.db.Where("enabled AND (next_sync_at IS NULL OR next_sync_at <= ?)", time.Now()).
+		Order("id").Find(&sources).Error; err != nil {
+		log.Printf("Error loading due watchlist sources: %v", err)
+		return
+	}
+	for i := range sources {
+		if ctx.Err() != nil {
+			return
+		}
+		source := &sources[i]
+		err := s.sync(ctx, source)
+		if ctx.Err() != nil {
+			return
+		}
+
+		now := time.Now()
+		interval := source.SyncInterval
+		if interval < 1 {
+			interval = 1
+		}
+		updates := map[string]interface{}{
+			"last_sync_at": now,
+			"next_sync_at": now.Add(time.Duration(interval) * time.Minute),
+			"last_error":   "",
+		}
+		if err != nil {
+			log.Printf("Error syncing watchlist %s: %v", source.Name, err)
+			updates["last_error"] = err.Error()
+		}
+		if err := s.db.Model(source).Updates(updates).Error; err != nil {
+			log.Printf("Error scheduling watchlist %s: %v", source.Name, err)
+		}
+	}
+
+	// Screening of the addresses added is retried on every pass until it succeeds
+	s.screenPending(ctx)
+}
+
+// fetch reads a list from an http(s):// or file:// URL
+func (s *WatchlistSyncer) fetch(ctx context.Context, rawURL string) ([]byte, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+	var body io.Reader
+	switch u.Scheme {
+	case "file":
+		file, err := os.Open(u.Path)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		body = file
+	case "http", "https":
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := s.client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("fetching %s: %s", rawURL, resp.Status)
+		}
+		body = resp.Body
+	default:
+		return nil, fmt.Errorf("unsupported URL scheme %q", u.Scheme)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(body, maxWatchlistSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxWatchlistSize {
+		return nil, fmt.Errorf("list exceeds %d bytes", maxWatchlistSize)
+	}
+	return data, nil
+}
+
+// sync imports a source's list unless it is unchanged: it records the new version and the
+// addresses it adds and removes. The added ones are left unscreened for screenPending.
+func (s *WatchlistSyncer) sync(ctx context.Context, source *models.WatchlistSource) error {
+	data, err := s.fetch(ctx, source.URL)
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(data)
+	checksum := hex.EncodeToString(sum[:])
+	if checksum == source.LastChecksum {
+		return nil
+	}
+	list, err := parseWatchlist(source.Format, data)
+	if err != nil {
+		return err
+	}
+	if list.Version == "" {
+		list.Version = "sha256:" + checksum[:12]
+	}
+
+	version := &models.WatchlistVersion{
+		SourceID: source.ID,
+		Version:  list.Version,
+		Checksum: checksum,
+		Entries:  len(list.Entries),
+		Skipped:  list.Skipped,
+	}
+	added := 0
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		var current []models.WatchlistEntry
+		if err := tx.Where("source_id = ?", source.ID).Find(&current).Error; err != nil {
+			return err
+		}
+		existing := make(map[string]*models.WatchlistEntry, len(current))
+		active := 0
+		for i := range current {
+			existing[current[i].Address] = &current[i]
+			if current[i].Status == "active" {
+				active++
+			}
+		}
+		// An empty list is more likely a broken download than a list delisting everyone
+		if len(list.Entries) == 0 && active > 0 {
+			return fmt.Errorf("list has no Ethereum addresses (%d skipped); keeping the %d listed", list.Skipped, active)
+		}
+		if err := tx.Create(version).Error; err != nil {
+			return err
+		}
+
+		var changes []models.WatchlistChange
+		for address, item := range list.Entries {
+			entry := existing[address]
+			if entry != nil && entry.Status == "active" {
+				continue
+			}
+			if entry == nil {
+				entry = &models.WatchlistEntry{SourceID: source.ID, Address: address}
+			}
+			entry.Name, entry.Reference = item.Name, item.Reference
+			entry.Status, entry.AddedVersionID, entry.RemovedVersionID = "active", version.ID, 0
+			entry.ScreenedAt = nil
+			if err := tx.Save(entry).Error; err != nil {
+				return err
+			}
+			added++
+			changes = append(changes, models.WatchlistChange{SourceID: source.ID, VersionID: version.ID, Address: address, Change: "added", Name: item.Name})
+		}
+		for _, entry := range current {
+			if _, listed := list.Entries[entry.Address]; listed || entry.Status != "active" {
+				continue
+			}
+			if err := tx.Model(&entry).Updates(map[string]interface{}{"status": "removed", "removed_version_id": version.ID}).Error; err != nil {
+				return err
+			}
+			changes = append(changes, models.WatchlistChange{SourceID: source.ID, VersionID: version.ID, Address: entry.Address, Change: "removed", Name: entry.Name})
+			version.Removed++
+		}
+		version.Added = added
+		if len(changes) > 0 {
+			if err := tx.CreateInBatches(changes, 500).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(version).Updates(map[string]interface{}{"added": version.Added, "removed": version.Removed}).Error; err != nil {
+			return err
+		}
+		return tx.Model(source).Updates(map[string]interface{}{"last_checksum": checksum, "last_version_id": version.ID}).Error
+	})
+	if err != nil {
+		return err
+	}
+	log.Printf("Imported watchlist %s version %s: %d addresses, %d added, %d removed, %d skipped",
+		source.Name, version.Version, version.Entries, version.Added, version.Removed, version.Skipped)
+	return nil
+}
+
+// screenPending screens the active entries not screened yet, marking each once done. An
+// entry whose screening fails or is interrupted is tried again on the next pass.
+func (s *WatchlistSyncer) screenPending(ctx context.Context) {
+	var entries []models.WatchlistEntry
+	if err := s.db.Where("status = ? AND screened_at IS NULL", "active").Order("id").Find(&entries).Error; err != nil {
+		log.Printf("Error loading unscreened watchlist entries: %v", err)
+		return
+	}
+	sources := make(map[uint]*models.WatchlistSource)
+	versions := make(map[uint]*models.WatchlistVersion)
+	for i := range entries {
+		if ctx.Err() != nil {
+			return
+		}
+		entry := &entries[i]
+		source, ok := sources[entry.SourceID]
+		if !ok {
+			source = &models.WatchlistSource{}
+			if err := s.db.Unscoped().First(source, entry.SourceID).Error; err != nil {
+				log.Printf("Error loading watchlist source %d: %v", entry.SourceID, err)
+				continue
+			}
+			sources[entry.SourceID] = source
+		}
+		version, ok := versions[entry.AddedVersionID]
+		if !ok {
+			version = &models.WatchlistVersion{}
+			if err := s.db.First(version, entry.AddedVersionID).Error; err != nil {
+				log.Printf("Error loading watchlist version %d: %v", entry.AddedVersionID, err)
+				continue
+			}
+			versions[entry.AddedVersionID] = version
+		}
+
+		if err := s.screen(ctx, source, version, entry); err != nil {
+			log.Printf("Error screening transactions of %s listed by %s, retrying on the next pass: %v", entry.Address, source.Name, err)
+			continue
+		}
+		// Not marked if the entry was relisted by a newer version meanwhile
+		if err := s.db.Model(entry).Where("added_version_id = ?", entry.AddedVersionID).Update("screened_at", time.Now()).Error; err != nil {
+			log.Printf("Error marking %s listed by %s as screened: %v", entry.Address, source.Name, err)
+		}
+	}
+}
+
+// screen looks back over the transactions of a newly listed address. Each is recorded as a
+// match and, unless already suspicious, as a suspicious transfer for analysts to review.
+// Screening again records nothing twice.
+func (s *WatchlistSyncer) screen(ctx context.Context, source *models.WatchlistSource, version *models.WatchlistVersion, entry *models.WatchlistEntry) error {
+	reason := fmt.Sprintf("Retroactive screening: %s listed by %s (%s)", entry.Address, source.Name, version.Version)
+	details, _ := json.Marshal(map[string]interface{}{
+		"watchlist":   source.Name,
+		"version":     version.Version,
+		"address":     entry.Address,
+		"name":        entry.Name,
+		"reference":   entry.Reference,
+		"retroactive": true,
+	})
+
+	matches := 0
+	var batch []models.Transaction
+	err := s.db.Unscoped().
+		Where("(from_address = ? OR to_address = ?) AND timestamp >= ?", entry.Address, entry.Address, time.Now().Add(-s.lookback)).
+		Order("id").
+		FindInBatches(&batch, 500, func(_ *gorm.DB, _ int) error {
+			for i := range batch {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				tx := &batch[i]
+				direction := "to"
+				if tx.From == entry.Address {
+					direction = "from"
+				}
+				err := s.db.Transaction(func(db *gorm.DB) error {
+					result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.WatchlistMatch{
+						EntryID:     entry.ID,
+						SourceID:    source.ID,
+						VersionID:   version.ID,
+						Address:     entry.Address,
+						TxHash:      tx.Hash,
+						BlockNumber: tx.BlockNumber,
+						Direction:   direction,
+						Amount:      tx.Value,
+						TxTimestamp: tx.Timestamp,
+					})
+					if result.Error != nil || result.RowsAffected == 0 {
+						return result.Error
+					}
+					matches++
+					return db.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.SuspiciousTransfer{
+						From:        tx.From,
+						To:          tx.To,
+						Amount:      tx.Value,
+						TxHash:      tx.Hash,
+						BlockNumber: tx.BlockNumber,
+						Timestamp:   tx.Timestamp,
+						Reason:      reason,
+						Severity:    "high",
+						Details:     string(details),
+					}).Error
+				})
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+	if matches > 0 {
+		log.Printf("Retroactive screening found %d transactions of %s listed by %s", matches, entry.Address, source.Name)
+	}
+	return err
+}
@@ -0,0 +1,145 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"token-monitor/models"
+)
+
+func TestParseWatchlist(t *testing.T) {
+	const (
+		first  = "0x8589427373D6D84E98730D7795D8f6f8731FDA16"
+		second = "0x722122dF12D4e14e13Ac3b6895a86e84145b6967"
+	)
+	cases := []struct {
+		name    string
+		format  string
+		data    string
+		version string
+		entries map[string]watchlistItem
+		skipped int
+	}{
+		{
+			name:   "csv with header",
+			format: "csv",
+			data: "# exported list\n" +
+				"uid,name,address\n" +
+				"1001,Mixer A," + first + "\n" +
+				"1002,Mixer B,bc1qnotanethereumaddress\n" +
+				"1003,Mixer A again," + first + "\n",
+			entries: map[string]watchlistItem{first: {Name: "Mixer A", Reference: "1001"}},
+			skipped: 1,
+		},
+		{
+			name:    "csv without header",
+			format:  "csv",
+			data:    "0x8589427373d6d84e98730d7795d8f6f8731fda16,Mixer A\n" + second + "\n",
+			entries: map[string]watchlistItem{first: {Name: "Mixer A"}, second: {}},
+		},
+		{
+			name:    "json array",
+			format:  "json",
+			data:    `["` + first + `", {"address": "` + second + `", "name": "Mixer B", "id": "7"}, 42]`,
+			entries: map[string]watchlistItem{first: {}, second: {Name: "Mixer B", Reference: "7"}},
+			skipped: 1,
+		},
+		{
+			name:    "json document",
+			format:  "json",
+			data:    `{"version": "2024-05-01", "addresses": ["` + first + `"]}`,
+			version: "2024-05-01",
+			entries: map[string]watchlistItem{first: {}},
+		},
+		{
+			name:   "ofac xml",
+			format: "ofac_xml",
+			data: `<?xml version="1.0" standalone="yes"?>
+<sdnList xmlns="http://tempuri.org/sdnList.xsd">
+  <publshInformation><Publish_Date>05/01/2024</Publish_Date><Record_Count>2</Record_Count></publshInformation>
+  <sdnEntry>
+    <uid>36000</uid><firstName>Roman</firstName><lastName>SEMENOV</lastName>
+    <idList>
+      <id><uid>1</uid><idType>Digital Currency Address - ETH</idType><idNumber>` + first + `</idNumber></id>
+      <id><uid>2</uid><idType>Digital Currency Address - XBT</idType><idNumber>1BitcoinAddress</idNumber></id>
+      <id><uid>3</uid><idType>Passport</idType><idNumber>123456</idNumber></id>
+    </idList>
+  </sdnEntry>
+  <sdnEntry>
+    <uid>36001</uid><lastName>TORNADO CASH</lastName>
+    <idList><id><idType>Digital Currency Address - USDC</idType><idNumber>` + second + `</idNumber></id></idList>
+  </sdnEntry>
+</sdnList>`,
+			version: "05/01/2024",
+			entries: map[string]watchlistItem{first: {Name: "Roman SEMENOV", Reference: "36000"}, second: {Name: "TORNADO CASH", Reference: "36001"}},
+			skipped: 1,
+		},
+	}
+	for _, c := range cases {
+		list, err := parseWatchlist(c.format, []byte(c.data))
+		if err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		if list.Version != c.version {
+			t.Errorf("%s: version %q, want %q", c.name, list.Version, c.version)
+		}
+		if list.Skipped != c.skipped {
+			t.Errorf("%s: skipped %d, want %d", c.name, list.Skipped, c.skipped)
+		}
+		if len(list.Entries) != len(c.entries) {
+			t.Errorf("%s: %d entries, want %d", c.name, len(list.Entries), len(c.entries))
+		}
+		for address, want := range c.entries {
+			if got, ok := list.Entries[address]; !ok || got != want {
+				t.Errorf("%s: entry %s = %+v, want %+v", c.name, address, got, want)
+			}
+		}
+	}
+
+	if _, err := parseWatchlist("xlsx", nil); err == nil {
+		t.Error("unknown format parsed")
+	}
+}
+
+func TestScreenPendingRetriesUntilScreened(t *testing.T) {
+	db := testDB(t, &models.WatchlistSource{}, &models.WatchlistVersion{}, &models.WatchlistEntry{},
+		&models.WatchlistMatch{}, &models.Transaction{}, &models.SuspiciousTransfer{})
+	const listed = "0x8589427373d6d84e98730d7795d8f6f8731fda16"
+	source := models.WatchlistSource{Name: "ofac", URL: "file:///tmp/ofac.xml", Format: "ofac_xml", Enabled: true}
+	db.Create(&source)
+	version := models.WatchlistVersion{SourceID: source.ID, Version: "2024-01-01"}
+	db.Create(&version)
+	entry := models.WatchlistEntry{SourceID: source.ID, Address: listed, Status: "active", AddedVersionID: version.ID}
+	db.Create(&entry)
+	sent := time.Now().Add(-48 * time.Hour).Truncate(time.Second)
+	db.Create(&models.Transaction{Hash: "0x01", From: listed, To: "0x02", Value: "100", BlockNumber: 7, Timestamp: sent})
+
+	s := NewWatchlistSyncer(db, time.Minute, 30*24*time.Hour, time.Second)
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	s.screenPending(cancelled)
+	db.First(&entry, entry.ID)
+	if entry.ScreenedAt != nil {
+		t.Fatalf("entry marked screened by an interrupted pass")
+	}
+
+	s.screenPending(context.Background())
+	db.First(&entry, entry.ID)
+	if entry.ScreenedAt == nil {
+		t.Fatalf("entry not screened on the next pass")
+	}
+	var transfer models.SuspiciousTransfer
+	if err := db.Where("tx_hash = ?", "0x01").First(&transfer).Error; err != nil {
+		t.Fatalf("no suspicious transfer recorded: %v", err)
+	}
+	if !transfer.Timestamp.Equal(sent) {
+		t.Errorf("suspicious transfer dated %v, want the transaction's %v", transfer.Timestamp, sent)
+	}
+	var matches int64
+	db.Model(&models.WatchlistMatch{}).Count(&matches)
+	if matches != 1 {
+		t.Errorf("recorded %d matches, want 1", matches)
+	}
+}
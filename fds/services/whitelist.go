@@ -0,0 +1,88 @@
+package services
+
+import (
+	"encoding/json"
+	"log"
+	"strings"
+	"time"
+
+	"token-monitor/models"
+
+	"gorm.io/gorm/clause"
+)
+
+// whitelistExempts reports whether a whitelist entry exempts tx from a check at a time
+func whitelistExempts(entry *models.WhitelistAddress, tx *models.Transaction, check string, at time.Time) bool {
+	if entry.Status != "active" || entry.ExpiresAt != nil && !at.Before(*entry.ExpiresAt) {
+		return false
+	}
+	if entry.Rules != "" {
+		scoped := false
+		for _, rule := range strings.Split(entry.Rules, ",") {
+			scoped = scoped || strings.TrimSpace(rule) == check
+		}
+		if !scoped {
+			return false
+		}
+	}
+	matches := func(address, counterparty string) bool {
+		return strings.EqualFold(entry.Address, address) &&
+			(entry.Counterparty == "" || strings.EqualFold(entry.Counterparty, counterparty))
+	}
+	return entry.Direction != "to" && matches(tx.From, tx.To) ||
+		entry.Direction != "from" && matches(tx.To, tx.From)
+}
+
+// whitelistExemptions returns the whitelist entries exempting tx, by the checks they exempt
+// it from. Replays judge expiry at the time of the transaction.
+func (a *Analyzer) whitelistExemptions(tx *models.Transaction) map[string][]*models.WhitelistAddress {
+	at := time.Now()
+	if a.replay && !tx.Timestamp.IsZero() {
+		at = tx.Timestamp
+	}
+	var entries []models.WhitelistAddress
+	if err := a.db.Where("LOWER(address) IN (LOWER(?), LOWER(?)) AND status = ?", tx.From, tx.To, "active").Find(&entries).Error; err != nil {
+		log.Printf("Error querying whitelist: %v", err)
+		return nil
+	}
+	exemptions := make(map[string][]*models.WhitelistAddress)
+	for i := range entries {
+		for _, check := range ruleChecks {
+			if whitelistExempts(&entries[i], tx, check, at) {
+				exemptions[check] = append(exemptions[check], &entries[i])
+			}
+		}
+	}
+	return exemptions
+}
+
+// logExemptions records the checks tx was exempted from and the violations they would have
+// raised, so whitelists can be audited
+func (a *Analyzer) logExemptions(tx *models.Transaction, exemptions map[string][]*models.WhitelistAddress, detections []ruleDetection) {
+	fired := make(map[string]map[string]interface{})
+	for _, d := range detections {
+		fired[d.Rule] = d.Details
+	}
+	for check, entries := range exemptions {
+		d, hit := fired[check]
+		details := []byte("{}")
+		if hit {
+			details, _ = json.Marshal(d)
+		}
+		for _, entry := range entries {
+			exemption := &models.WhitelistExemption{
+				WhitelistID: entry.ID,
+				Address:     entry.Address,
+				TxHash:      tx.Hash,
+				BlockNumber: tx.BlockNumber,
+				CheckName:   check,
+				Fired:       hit,
+				Details:     string(details),
+			}
+			// A transaction analyzed pending and again mined is recorded once
+			if err := a.db.Clauses(clause.OnConflict{DoNothing: true}).Create(exemption).Error; err != nil {
+				log.Printf("Error recording whitelist exemption of %s from %s: %v", tx.Hash, check, err)
+			}
+		}
+	}
+}
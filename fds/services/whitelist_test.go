@@ -0,0 +1,47 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"token-monitor/models"
+)
+
+func TestWhitelistExempts(t *testing.T) {
+	const (
+		exchange = "0x1111111111111111111111111111111111111111"
+		customer = "0x2222222222222222222222222222222222222222"
+		treasury = "0x3333333333333333333333333333333333333333"
+	)
+	now := time.Now()
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+	deposit := &models.Transaction{From: customer, To: exchange}
+	payout := &models.Transaction{From: exchange, To: treasury}
+
+	cases := []struct {
+		name  string
+		entry models.WhitelistAddress
+		tx    *models.Transaction
+		check string
+		want  bool
+	}{
+		{"every rule", models.WhitelistAddress{Address: exchange, Status: "active"}, deposit, "large_transfer", true},
+		{"pending", models.WhitelistAddress{Address: exchange, Status: "pending"}, deposit, "large_transfer", false},
+		{"revoked", models.WhitelistAddress{Address: exchange, Status: "revoked"}, deposit, "large_transfer", false},
+		{"expired", models.WhitelistAddress{Address: exchange, Status: "active", ExpiresAt: &past}, deposit, "large_transfer", false},
+		{"not yet expired", models.WhitelistAddress{Address: exchange, Status: "active", ExpiresAt: &future}, deposit, "large_transfer", true},
+		{"scoped rule", models.WhitelistAddress{Address: exchange, Status: "active", Rules: "multiple_incoming_transfers, large_transfer"}, deposit, "large_transfer", true},
+		{"other rule", models.WhitelistAddress{Address: exchange, Status: "active", Rules: "multiple_incoming_transfers"}, deposit, "large_transfer", false},
+		{"incoming only", models.WhitelistAddress{Address: exchange, Status: "active", Direction: "to"}, deposit, "large_transfer", true},
+		{"incoming only, outgoing transfer", models.WhitelistAddress{Address: exchange, Status: "active", Direction: "to"}, payout, "large_transfer", false},
+		{"outgoing only", models.WhitelistAddress{Address: exchange, Status: "active", Direction: "from"}, payout, "large_transfer", true},
+		{"counterparty", models.WhitelistAddress{Address: exchange, Status: "active", Counterparty: treasury}, payout, "large_transfer", true},
+		{"other counterparty", models.WhitelistAddress{Address: exchange, Status: "active", Counterparty: treasury}, deposit, "large_transfer", false},
+		{"address case", models.WhitelistAddress{Address: "0xAbCdEf0000000000000000000000000000000001", Status: "active"}, &models.Transaction{From: "0xabcdef0000000000000000000000000000000001", To: customer}, "suspicious_address", true},
+	}
+	for _, c := range cases {
+		if got := whitelistExempts(&c.entry, c.tx, c.check, now); got != c.want {
+			t.Errorf("%s: whitelistExempts = %v, want %v", c.name, got, c.want)
+		}
+	}
+}